@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -46,9 +47,10 @@ func New(c Doer) *Client {
 }
 
 // HTTPClientDoer turns a stdlib http.Client into a Doer. Use it to enable to call New() with an http.Client.
+// The requests are sent with the context given to Do so that canceling it aborts them.
 func HTTPClientDoer(hc *http.Client) Doer {
-	return doFunc(func(_ context.Context, req *http.Request) (*http.Response, error) {
-		return hc.Do(req)
+	return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		return hc.Do(req.WithContext(ctx))
 	})
 }
 
@@ -221,8 +223,16 @@ func shortID() string {
 // It is private to avoid possible collisions with keys used by other packages.
 type clientKey int
 
-// ReqIDKey is the context key used to store the request ID value.
-const reqIDKey clientKey = 1
+const (
+	// ReqIDKey is the context key used to store the request ID value.
+	reqIDKey clientKey = iota + 1
+	// idempotencyKeyKey is the context key used to store the idempotency key value.
+	idempotencyKeyKey
+)
+
+// IdempotencyKeyHeader is the name of the header containing the idempotency key of requests
+// made to idempotent actions.
+const IdempotencyKeyHeader = "Idempotency-Key"
 
 // ContextRequestID extracts the Request ID from the context.
 func ContextRequestID(ctx context.Context) string {
@@ -249,3 +259,23 @@ func ContextWithRequestID(ctx context.Context) (context.Context, string) {
 func SetContextRequestID(ctx context.Context, reqID string) context.Context {
 	return context.WithValue(ctx, reqIDKey, reqID)
 }
+
+// IdempotencyKey returns the idempotency key set in the context with SetContextIdempotencyKey
+// or a new random key. The generated clients call it to set the Idempotency-Key header of the
+// requests made to idempotent actions, retrying a request with the same *http.Request reuses the
+// key.
+func IdempotencyKey(ctx context.Context) string {
+	if key, ok := ctx.Value(idempotencyKeyKey).(string); ok && key != "" {
+		return key
+	}
+	b := make([]byte, 16)
+	io.ReadFull(rand.Reader, b)
+	return hex.EncodeToString(b)
+}
+
+// SetContextIdempotencyKey sets the idempotency key used by the requests created with the
+// returned context. Use it to retry a request by creating it again, for example after the
+// process restarted.
+func SetContextIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey, key)
+}
@@ -44,5 +44,18 @@ var _ = Describe("client", func() {
 				Expect(reqID).To(Equal(customID))
 			})
 		})
+
+		Context("IdempotencyKey", func() {
+			It("should generate a new key", func() {
+				key := client.IdempotencyKey(ctx)
+				Expect(key).To(HaveLen(32))
+				Expect(client.IdempotencyKey(ctx)).ToNot(Equal(key))
+			})
+
+			It("should use the key set in the context", func() {
+				newCtx := client.SetContextIdempotencyKey(ctx, "foo")
+				Expect(client.IdempotencyKey(newCtx)).To(Equal("foo"))
+			})
+		})
 	})
 })
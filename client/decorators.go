@@ -0,0 +1,427 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// Decorator wraps a Doer to add behavior such as retries, use Decorate to compose
+	// decorators.
+	Decorator func(Doer) Doer
+
+	// RetryPolicy configures the Retry decorator. The zero value uses the defaults.
+	RetryPolicy struct {
+		// MaxAttempts is the maximum number of attempts including the first one. Defaults
+		// to 3.
+		MaxAttempts int
+		// BaseDelay is the maximum delay before the first retry, it doubles after each
+		// attempt. The actual delay is picked at random between 0 and this maximum.
+		// Defaults to 100ms.
+		BaseDelay time.Duration
+		// MaxDelay caps the delay between two attempts. Responses with a Retry-After header
+		// greater than MaxDelay are returned without retrying. Defaults to 10s.
+		MaxDelay time.Duration
+		// StatusCodes lists the response status codes that cause a retry. Defaults to 429,
+		// 502, 503 and 504.
+		StatusCodes []int
+	}
+
+	// BreakerPolicy configures the CircuitBreaker decorator. The zero value uses the defaults.
+	BreakerPolicy struct {
+		// FailureThreshold is the number of consecutive failures that opens the circuit of
+		// a host. Defaults to 5.
+		FailureThreshold int
+		// OpenTimeout is the duration a circuit stays open before a probe request is let
+		// through. Defaults to 30s.
+		OpenTimeout time.Duration
+	}
+
+	// HedgePolicy configures the Hedge decorator. The zero value uses the defaults.
+	HedgePolicy struct {
+		// Delay is the time to wait for a response before sending another copy of the
+		// request. Defaults to 100ms.
+		Delay time.Duration
+		// MaxRequests is the maximum number of copies of a request sent including the
+		// first one. Defaults to 2.
+		MaxRequests int
+	}
+
+	// breaker holds the state of the circuits of a CircuitBreaker decorator.
+	breaker struct {
+		policy   BreakerPolicy
+		now      func() time.Time
+		lock     sync.Mutex
+		circuits map[string]*circuit
+	}
+
+	// circuit is the state of the circuit of a host.
+	circuit struct {
+		failures int
+		open     bool
+		openedAt time.Time
+		probing  bool
+	}
+
+	// hedgeResult is the result of a hedged request.
+	hedgeResult struct {
+		index int
+		resp  *http.Response
+		err   error
+	}
+
+	// cancelBody cancels the context of a request when its response body is closed.
+	cancelBody struct {
+		io.ReadCloser
+		cancel context.CancelFunc
+	}
+)
+
+// ErrCircuitOpen is the error returned by the CircuitBreaker decorator for requests sent to a host
+// whose circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Decorate applies the decorators to d, the first decorator is the outermost one. Example:
+//
+//	doer := client.Decorate(client.HTTPClientDoer(http.DefaultClient),
+//		client.Retry(client.RetryPolicy{MaxAttempts: 5}),
+//		client.CircuitBreaker(client.BreakerPolicy{}),
+//	)
+func Decorate(d Doer, decorators ...Decorator) Doer {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		d = decorators[i](d)
+	}
+	return d
+}
+
+// WithContext returns a decorator that attaches the context to the requests and that fails
+// right away when the context is done.
+func WithContext() Decorator {
+	return func(d Doer) Doer {
+		return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return d.Do(ctx, req.WithContext(ctx))
+		})
+	}
+}
+
+// Retry returns a decorator that retries idempotent requests that fail with a network error or
+// that get a response with one of the policy status codes. Requests are idempotent if they use
+// the GET, HEAD, OPTIONS, TRACE, PUT or DELETE method or if they have an Idempotency-Key header.
+// Requests whose body cannot be sent again because GetBody is nil are not retried. The delay
+// between two attempts grows exponentially with random jitter, it is at least the duration
+// given by the Retry-After response header if any.
+func Retry(p RetryPolicy) Decorator {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 100 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 10 * time.Second
+	}
+	if p.StatusCodes == nil {
+		p.StatusCodes = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+	retryStatus := make(map[int]bool, len(p.StatusCodes))
+	for _, code := range p.StatusCodes {
+		retryStatus[code] = true
+	}
+	return func(d Doer) Doer {
+		return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if !idempotent(req) || !rewindable(req) {
+				return d.Do(ctx, req)
+			}
+			for attempt := 1; ; attempt++ {
+				resp, err := d.Do(ctx, req)
+				if attempt == p.MaxAttempts || ctx.Err() != nil || err == ErrCircuitOpen {
+					return resp, err
+				}
+				var delay time.Duration
+				if err == nil {
+					if !retryStatus[resp.StatusCode] {
+						return resp, nil
+					}
+					after, ok := retryAfter(resp)
+					if ok && after > p.MaxDelay {
+						return resp, nil
+					}
+					delay = after
+					drain(resp)
+				}
+				if backoff := jitter(p.BaseDelay, p.MaxDelay, attempt); backoff > delay {
+					delay = backoff
+				}
+				t := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return nil, ctx.Err()
+				case <-t.C:
+				}
+				if req, err = rewind(req); err != nil {
+					return nil, err
+				}
+			}
+		})
+	}
+}
+
+// CircuitBreaker returns a decorator that stops sending requests to a host after a number of
+// consecutive failures. Failures are network errors and responses with a status code of 500 or
+// more. Requests sent to a host whose circuit is open fail with ErrCircuitOpen. Once the policy
+// open timeout has elapsed a single probe request is let through: the circuit closes if it
+// succeeds and opens again otherwise. Each call to CircuitBreaker creates a separate set of
+// circuits.
+func CircuitBreaker(p BreakerPolicy) Decorator {
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = 5
+	}
+	if p.OpenTimeout <= 0 {
+		p.OpenTimeout = 30 * time.Second
+	}
+	b := &breaker{policy: p, now: time.Now, circuits: make(map[string]*circuit)}
+	return func(d Doer) Doer {
+		return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			host := req.URL.Host
+			if !b.allow(host) {
+				return nil, ErrCircuitOpen
+			}
+			resp, err := d.Do(ctx, req)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				b.release(host) // Canceled by the caller, not a failure of the host
+			case err != nil || resp.StatusCode >= 500:
+				b.failure(host)
+			default:
+				b.success(host)
+			}
+			return resp, err
+		})
+	}
+}
+
+// Hedge returns a decorator that sends another copy of idempotent requests when no response is
+// received within the policy delay or when a copy fails with a network error, up to the policy
+// maximum number of copies. The first response is returned and the other copies are canceled.
+// Hedging reduces tail latency at the cost of additional load on the service.
+func Hedge(p HedgePolicy) Decorator {
+	if p.Delay <= 0 {
+		p.Delay = 100 * time.Millisecond
+	}
+	if p.MaxRequests <= 0 {
+		p.MaxRequests = 2
+	}
+	return func(d Doer) Doer {
+		return doFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if p.MaxRequests == 1 || !idempotent(req) || !rewindable(req) {
+				return d.Do(ctx, req)
+			}
+			return hedge(ctx, d, req, p)
+		})
+	}
+}
+
+// hedge sends copies of req until one succeeds.
+func hedge(ctx context.Context, d Doer, req *http.Request, p HedgePolicy) (*http.Response, error) {
+	results := make(chan *hedgeResult, p.MaxRequests)
+	cancels := make([]context.CancelFunc, 0, p.MaxRequests)
+	inflight := 0
+	send := func() error {
+		r, err := rewind(req)
+		if err != nil {
+			return err
+		}
+		actx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		inflight++
+		go func(index int) {
+			resp, err := d.Do(actx, r.WithContext(actx))
+			results <- &hedgeResult{index: index, resp: resp, err: err}
+		}(len(cancels) - 1)
+		return nil
+	}
+	// abandon cancels the requests still in flight and discards their results.
+	abandon := func(n int) {
+		for _, cancel := range cancels {
+			cancel()
+		}
+		go func() {
+			for i := 0; i < n; i++ {
+				if r := <-results; r.err == nil {
+					r.resp.Body.Close()
+				}
+			}
+		}()
+	}
+
+	if err := send(); err != nil {
+		return nil, err
+	}
+	next := time.After(p.Delay)
+	var lastErr error
+	for inflight > 0 {
+		if len(cancels) == p.MaxRequests {
+			next = nil
+		}
+		select {
+		case r := <-results:
+			inflight--
+			if r.err == nil {
+				winner := cancels[r.index]
+				cancels = append(cancels[:r.index:r.index], cancels[r.index+1:]...)
+				abandon(inflight)
+				r.resp.Body = &cancelBody{ReadCloser: r.resp.Body, cancel: winner}
+				return r.resp, nil
+			}
+			cancels[r.index]()
+			lastErr = r.err
+			if len(cancels) < p.MaxRequests && ctx.Err() == nil {
+				if err := send(); err != nil {
+					abandon(inflight)
+					return nil, err
+				}
+				next = time.After(p.Delay)
+			}
+		case <-next:
+			if err := send(); err != nil {
+				abandon(inflight)
+				return nil, err
+			}
+			next = time.After(p.Delay)
+		case <-ctx.Done():
+			abandon(inflight)
+			return nil, ctx.Err()
+		}
+	}
+	return nil, lastErr
+}
+
+// Close closes the body and cancels the request context.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// allow returns true if a request may be sent to host.
+func (b *breaker) allow(host string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	c, ok := b.circuits[host]
+	if !ok || !c.open {
+		return true
+	}
+	if c.probing || b.now().Sub(c.openedAt) < b.policy.OpenTimeout {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+// success closes the circuit of host.
+func (b *breaker) success(host string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.circuits, host)
+}
+
+// failure records a failure and opens the circuit of host if needed.
+func (b *breaker) failure(host string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{}
+		b.circuits[host] = c
+	}
+	c.failures++
+	if c.probing || c.failures >= b.policy.FailureThreshold {
+		c.open = true
+		c.openedAt = b.now()
+		c.probing = false
+	}
+}
+
+// release lets another probe through if the request that completed was one.
+func (b *breaker) release(host string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if c, ok := b.circuits[host]; ok {
+		c.probing = false
+	}
+}
+
+// idempotent returns true if the request may be sent more than once.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// rewindable returns true if the request body can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of req with a fresh body.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.WithContext(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// retryAfter returns the delay given by the Retry-After response header.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(h); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// jitter returns a random delay between 0 and base*2^(attempt-1) capped at max.
+func jitter(base, max time.Duration, attempt int) time.Duration {
+	d := max
+	if attempt < 32 {
+		if exp := base << uint(attempt-1); exp > 0 && exp < max {
+			d = exp
+		}
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// drain reads the rest of the response body and closes it so that the connection can be reused.
+func drain(resp *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+}
@@ -0,0 +1,290 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// doer is a Doer that records the requests and replies using a function.
+type doer struct {
+	lock   sync.Mutex
+	bodies []string
+	reply  func(n int, ctx context.Context) (*http.Response, error)
+}
+
+func (d *doer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+	}
+	d.lock.Lock()
+	d.bodies = append(d.bodies, string(body))
+	n := len(d.bodies)
+	d.lock.Unlock()
+	return d.reply(n, ctx)
+}
+
+func (d *doer) calls() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return len(d.bodies)
+}
+
+func response(status int, header ...string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: make(http.Header), Body: ioutil.NopCloser(bytes.NewBufferString("body"))}
+	for i := 0; i < len(header); i += 2 {
+		resp.Header.Set(header[i], header[i+1])
+	}
+	return resp
+}
+
+var _ = Describe("Decorators", func() {
+	var d *doer
+	var ctx context.Context
+	var req *http.Request
+
+	BeforeEach(func() {
+		d = &doer{reply: func(int, context.Context) (*http.Response, error) { return response(200), nil }}
+		ctx = context.Background()
+		req, _ = http.NewRequest("GET", "http://goa.design/bottles", nil)
+	})
+
+	Describe("Decorate", func() {
+		It("applies the decorators in order", func() {
+			var order []string
+			decorator := func(name string) client.Decorator {
+				return func(next client.Doer) client.Doer {
+					return doerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+						order = append(order, name)
+						return next.Do(ctx, req)
+					})
+				}
+			}
+			_, err := client.Decorate(d, decorator("outer"), decorator("inner")).Do(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(order).To(Equal([]string{"outer", "inner"}))
+		})
+	})
+
+	Describe("WithContext", func() {
+		It("fails when the context is done", func() {
+			cctx, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := client.Decorate(d, client.WithContext()).Do(cctx, req)
+			Expect(err).To(Equal(context.Canceled))
+			Expect(d.calls()).To(Equal(0))
+		})
+	})
+
+	Describe("HTTPClientDoer", func() {
+		It("sends the request with the context", func() {
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-release
+			}))
+			defer server.Close()
+			defer close(release)
+			req, _ = http.NewRequest("GET", server.URL, nil)
+			cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			_, err := client.HTTPClientDoer(http.DefaultClient).Do(cctx, req)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Retry", func() {
+		var policy client.RetryPolicy
+
+		do := func() (*http.Response, error) {
+			return client.Decorate(d, client.Retry(policy)).Do(ctx, req)
+		}
+
+		BeforeEach(func() {
+			policy = client.RetryPolicy{BaseDelay: time.Millisecond}
+			d.reply = func(n int, _ context.Context) (*http.Response, error) {
+				if n < 3 {
+					return response(503), nil
+				}
+				return response(200), nil
+			}
+		})
+
+		It("retries idempotent requests", func() {
+			resp, err := do()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(d.calls()).To(Equal(3))
+		})
+
+		It("retries network errors", func() {
+			d.reply = func(n int, _ context.Context) (*http.Response, error) {
+				if n == 1 {
+					return nil, errors.New("connection reset")
+				}
+				return response(200), nil
+			}
+			resp, err := do()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(d.calls()).To(Equal(2))
+		})
+
+		It("stops after the maximum number of attempts", func() {
+			policy.MaxAttempts = 2
+			resp, err := do()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(503))
+			Expect(d.calls()).To(Equal(2))
+		})
+
+		It("does not retry other status codes", func() {
+			policy.StatusCodes = []int{429}
+			resp, _ := do()
+			Expect(resp.StatusCode).To(Equal(503))
+			Expect(d.calls()).To(Equal(1))
+		})
+
+		It("honors Retry-After", func() {
+			d.reply = func(n int, _ context.Context) (*http.Response, error) {
+				if n == 1 {
+					return response(429, "Retry-After", "1"), nil
+				}
+				return response(200), nil
+			}
+			policy.MaxDelay = 10 * time.Millisecond
+			resp, _ := do()
+			Expect(resp.StatusCode).To(Equal(429))
+			Expect(d.calls()).To(Equal(1))
+
+			policy.MaxDelay = 2 * time.Second
+			d.bodies = nil
+			start := time.Now()
+			resp, _ = do()
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		})
+
+		Context("with a POST request", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("POST", "http://goa.design/bottles", bytes.NewBufferString("payload"))
+			})
+
+			It("does not retry", func() {
+				resp, _ := do()
+				Expect(resp.StatusCode).To(Equal(503))
+				Expect(d.calls()).To(Equal(1))
+			})
+
+			Context("with an idempotency key", func() {
+				BeforeEach(func() {
+					req.Header.Set(client.IdempotencyKeyHeader, "key")
+				})
+
+				It("retries with the same body", func() {
+					resp, _ := do()
+					Expect(resp.StatusCode).To(Equal(200))
+					Expect(d.bodies).To(Equal([]string{"payload", "payload", "payload"}))
+				})
+			})
+		})
+	})
+
+	Describe("CircuitBreaker", func() {
+		var decorated client.Doer
+		var fail bool
+
+		BeforeEach(func() {
+			fail = true
+			d.reply = func(int, context.Context) (*http.Response, error) {
+				if fail {
+					return response(500), nil
+				}
+				return response(200), nil
+			}
+			decorated = client.Decorate(d, client.CircuitBreaker(client.BreakerPolicy{FailureThreshold: 2, OpenTimeout: 20 * time.Millisecond}))
+		})
+
+		It("opens the circuit after consecutive failures", func() {
+			decorated.Do(ctx, req)
+			decorated.Do(ctx, req)
+			_, err := decorated.Do(ctx, req)
+			Expect(err).To(Equal(client.ErrCircuitOpen))
+			Expect(d.calls()).To(Equal(2))
+
+			other, _ := http.NewRequest("GET", "http://example.com", nil)
+			_, err = decorated.Do(ctx, other)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("probes the host once the timeout has elapsed", func() {
+			decorated.Do(ctx, req)
+			decorated.Do(ctx, req)
+			time.Sleep(25 * time.Millisecond)
+			resp, err := decorated.Do(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(500))
+			_, err = decorated.Do(ctx, req)
+			Expect(err).To(Equal(client.ErrCircuitOpen))
+
+			fail = false
+			time.Sleep(25 * time.Millisecond)
+			resp, err = decorated.Do(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			_, err = decorated.Do(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("Hedge", func() {
+		var canceled chan bool
+
+		BeforeEach(func() {
+			canceled = make(chan bool, 1)
+			d.reply = func(n int, ctx context.Context) (*http.Response, error) {
+				if n == 1 {
+					<-ctx.Done()
+					canceled <- true
+					return nil, ctx.Err()
+				}
+				return response(200), nil
+			}
+		})
+
+		It("sends another request when the first one is slow", func() {
+			resp, err := client.Decorate(d, client.Hedge(client.HedgePolicy{Delay: 5 * time.Millisecond})).Do(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(resp.Body.Close()).To(Succeed())
+			Expect(d.calls()).To(Equal(2))
+			Eventually(canceled).Should(Receive())
+		})
+
+		It("does not hedge non idempotent requests", func() {
+			req.Method = "POST"
+			cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			_, err := client.Decorate(d, client.Hedge(client.HedgePolicy{Delay: 5 * time.Millisecond})).Do(cctx, req)
+			Expect(err).To(Equal(context.DeadlineExceeded))
+			Expect(d.calls()).To(Equal(1))
+		})
+	})
+})
+
+// doerFunc is a function that implements client.Doer.
+type doerFunc func(context.Context, *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return f(ctx, req)
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Link returns the target URL of the link with the given relation type (e.g. "next") listed in
+// the response Link header as described in RFC 5988. It returns nil if there is no such link.
+func Link(resp *http.Response, rel string) *url.URL {
+	for _, header := range resp.Header["Link"] {
+		for _, link := range strings.Split(header, ",") {
+			if target, ok := parseLink(link, rel); ok {
+				u, err := url.Parse(target)
+				if err != nil {
+					continue
+				}
+				if resp.Request != nil && resp.Request.URL != nil {
+					u = resp.Request.URL.ResolveReference(u)
+				}
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+// LinkParam returns the value of the query string parameter with the given name in the target
+// URL of the link with the given relation type. It returns the empty string if there is no such
+// link or if the link URL does not have the parameter. Generated clients use it to retrieve the
+// cursor or offset of the next page of paginated actions.
+func LinkParam(resp *http.Response, rel, param string) string {
+	u := Link(resp, rel)
+	if u == nil {
+		return ""
+	}
+	return u.Query().Get(param)
+}
+
+// parseLink returns the target of the given link value if its rel parameter lists rel.
+func parseLink(link, rel string) (string, bool) {
+	parts := strings.Split(link, ";")
+	target := strings.TrimSpace(parts[0])
+	if len(target) < 2 || target[0] != '<' || target[len(target)-1] != '>' {
+		return "", false
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 || !strings.EqualFold(strings.TrimSpace(kv[0]), "rel") {
+			continue
+		}
+		for _, r := range strings.Fields(strings.Trim(strings.TrimSpace(kv[1]), `"`)) {
+			if strings.EqualFold(r, rel) {
+				return target[1 : len(target)-1], true
+			}
+		}
+	}
+	return "", false
+}
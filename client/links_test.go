@@ -0,0 +1,74 @@
+package client_test
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Link", func() {
+	var resp *http.Response
+	var rel string
+
+	var link *url.URL
+
+	BeforeEach(func() {
+		req, err := http.NewRequest("GET", "http://goa.design/bottles?limit=10", nil)
+		Expect(err).ToNot(HaveOccurred())
+		resp = &http.Response{Header: make(http.Header), Request: req}
+		rel = "next"
+	})
+
+	JustBeforeEach(func() {
+		link = client.Link(resp, rel)
+	})
+
+	Context("with no Link header", func() {
+		It("returns nil", func() {
+			Expect(link).To(BeNil())
+		})
+	})
+
+	Context("with links in a single header", func() {
+		BeforeEach(func() {
+			resp.Header.Set("Link", `</bottles?cursor=prev>; rel="prev", </bottles?cursor=next&limit=10>; rel="next"`)
+		})
+
+		It("returns the link URL resolved against the request URL", func() {
+			Expect(link).ToNot(BeNil())
+			Expect(link.String()).To(Equal("http://goa.design/bottles?cursor=next&limit=10"))
+		})
+
+		It("returns the link parameters", func() {
+			Expect(client.LinkParam(resp, "next", "cursor")).To(Equal("next"))
+			Expect(client.LinkParam(resp, "prev", "cursor")).To(Equal("prev"))
+			Expect(client.LinkParam(resp, "next", "offset")).To(BeEmpty())
+		})
+	})
+
+	Context("with links in multiple headers", func() {
+		BeforeEach(func() {
+			resp.Header.Add("Link", `</bottles?offset=0>; rel="prev"`)
+			resp.Header.Add("Link", `</bottles?offset=20>; rel="next last"`)
+		})
+
+		It("returns the link with the relation type", func() {
+			Expect(client.LinkParam(resp, "next", "offset")).To(Equal("20"))
+		})
+	})
+
+	Context("with no link with the relation type", func() {
+		BeforeEach(func() {
+			resp.Header.Set("Link", `</bottles?offset=0>; rel="prev"`)
+		})
+
+		It("returns nil", func() {
+			Expect(link).To(BeNil())
+			Expect(client.LinkParam(resp, "next", "offset")).To(BeEmpty())
+		})
+	})
+})
@@ -45,6 +45,9 @@ type (
 		Status int
 		// Length is the response body length.
 		Length int
+
+		// stream is the server-sent events stream opened on the response if any.
+		stream *EventStream
 	}
 
 	// key is the type used to store internal values in the context.
@@ -168,3 +171,10 @@ func (r *ResponseData) Write(b []byte) (int, error) {
 	r.Length += len(b)
 	return r.ResponseWriter.Write(b)
 }
+
+// Flush sends any buffered data to the client if the underlying writer supports it.
+func (r *ResponseData) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
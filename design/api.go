@@ -54,6 +54,7 @@ const (
 	ExpectationFailed            = "ExpectationFailed"
 	Teapot                       = "Teapot"
 	UnprocessableEntity          = "UnprocessableEntity"
+	TooManyRequests              = "TooManyRequests"
 
 	InternalServerError     = "InternalServerError"
 	NotImplemented          = "NotImplemented"
@@ -108,6 +108,7 @@ func Action(name string, dsl func()) {
 				Name:     name,
 				Metadata: make(dslengine.MetadataDefinition),
 			}
+			dslengine.RecordLocation(action)
 		}
 		if !dslengine.Execute(dsl, action) {
 			return
@@ -489,6 +490,84 @@ func MultipartForm() {
 	}
 }
 
+// Idempotent can be used in: Action
+//
+// Idempotent indicates that clients may send an Idempotency-Key header with the action requests so
+// that retries do not perform the action twice. The generated code mounts the middleware
+// implemented by the github.com/goadesign/goa/middleware/idempotency package: the response to the
+// first request made with a given key is stored and sent back to the requests that reuse the key.
+// The generated client sets the header automatically. Idempotent cannot be used on actions that
+// only use safe HTTP methods such as GET. Example:
+//
+//	Action("create", func() {
+//		Routing(POST(""))
+//		Payload(CreatePayload)
+//		Idempotent()
+//		Response(Created)
+//	})
+//
+func Idempotent() {
+	if a, ok := actionDefinition(); ok {
+		a.Idempotent = true
+	}
+}
+
+// InboundMessage can be used in: Action
+//
+// InboundMessage sets the type of the messages sent by clients on the websocket connection of the
+// action. The action must use the "ws" or "wss" scheme. The argument is a type, a media type or
+// the name of a type. The generated action context exposes an Upgrade method that gives the
+// handler a connection whose Receive method returns messages of this type validated against
+// the type definition. Example:
+//
+//	Action("chat", func() {
+//		Routing(GET("/chat"))
+//		Scheme("ws")
+//		InboundMessage(ChatMessage)
+//		OutboundMessage(ChatEvent)
+//		Response(SwitchingProtocols)
+//	})
+//
+func InboundMessage(t interface{}) {
+	if a, ok := actionDefinition(); ok {
+		a.InboundMessage = messageType("InboundMessage", t)
+	}
+}
+
+// OutboundMessage can be used in: Action
+//
+// OutboundMessage sets the type of the messages sent by the server on the websocket connection of
+// the action. It accepts the same arguments as InboundMessage. The messages are validated before
+// being sent. See InboundMessage for an example.
+//
+func OutboundMessage(t interface{}) {
+	if a, ok := actionDefinition(); ok {
+		a.OutboundMessage = messageType("OutboundMessage", t)
+	}
+}
+
+// messageType returns the data type of a websocket message given the argument of InboundMessage or
+// OutboundMessage.
+func messageType(dsl string, t interface{}) design.DataType {
+	switch actual := t.(type) {
+	case *design.UserTypeDefinition:
+		return actual
+	case *design.MediaTypeDefinition:
+		return actual
+	case string:
+		if ut, ok := design.Design.Types[actual]; ok {
+			return ut
+		}
+		if mt := design.Design.MediaTypeWithIdentifier(actual); mt != nil {
+			return mt
+		}
+		dslengine.ReportError("unknown %s type %s", dsl, actual)
+	default:
+		dslengine.ReportError("invalid %s argument, must be a type, a media type or a type name", dsl)
+	}
+	return nil
+}
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
@@ -364,3 +364,104 @@ var _ = Describe("Payload", func() {
 	})
 
 })
+
+var _ = Describe("WebSocket messages", func() {
+	var scheme string
+	var inbound, outbound interface{}
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		scheme = "ws"
+		inbound = Type("Message", func() {
+			Attribute("body")
+		})
+		outbound = "Message"
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("bar", func() {
+				Routing(GET(""))
+				Scheme(scheme)
+				InboundMessage(inbound)
+				OutboundMessage(outbound)
+				Response(SwitchingProtocols)
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the message types", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		a := Design.Resources["foo"].Actions["bar"]
+		Ω(a.HasMessages()).Should(BeTrue())
+		Ω(a.InboundMessage).Should(Equal(Design.Types["Message"]))
+		Ω(a.OutboundMessage).Should(Equal(Design.Types["Message"]))
+	})
+
+	Context("with an unknown type name", func() {
+		BeforeEach(func() {
+			outbound = "Unknown"
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a type that is not an object", func() {
+		BeforeEach(func() {
+			inbound = String
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an action that does not use websockets", func() {
+		BeforeEach(func() {
+			scheme = "http"
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("websocket messages can only be defined"))
+		})
+	})
+})
+
+var _ = Describe("Idempotent", func() {
+	var route *RouteDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		route = POST("")
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("bar", func() {
+				Routing(route)
+				Idempotent()
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("marks the action as idempotent", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["foo"].Actions["bar"].Idempotent).Should(BeTrue())
+	})
+
+	Context("on an action using a safe method", func() {
+		BeforeEach(func() {
+			route = GET("")
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("safe HTTP methods"))
+		})
+	})
+})
@@ -87,6 +87,7 @@ func API(name string, dsl func()) *design.APIDefinition {
 	}
 	design.Design.Name = name
 	design.Design.DSLFunc = dsl
+	dslengine.RecordLocation(design.Design)
 	return design.Design
 }
 
@@ -21,6 +21,8 @@ import (
 //
 // * The primitive types Boolean, Integer, Number, DateTime, UUID or String.
 //
+// * The sized or encoded primitive types Int64, UInt64, Float32, Bytes, Date or Duration.
+//
 // * A type defined via the Type function.
 //
 // * A media type defined via the MediaType function.
@@ -128,6 +130,7 @@ func Attribute(name string, args ...interface{}) {
 			}
 		}
 		baseAttr.Reference = parent.Reference
+		dslengine.RecordLocation(baseAttr)
 		if dsl != nil {
 			dslengine.Execute(dsl, baseAttr)
 		}
@@ -329,6 +332,16 @@ func ReadOnly() {
 	}
 }
 
+// Sensitive can be used in: Attribute
+// Sensitive marks the attribute as holding sensitive data such as a password or a token. The
+// generated payload types implement a Redact method that hides the values of sensitive attributes
+// which the LogRequest middleware uses when logging payloads.
+func Sensitive() {
+	if a, ok := attributeDefinition(); ok {
+		a.SetSensitive()
+	}
+}
+
 // NoExample can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // NoExample sets the example of an attribute to be blank for the documentation. It is used when
@@ -350,6 +363,13 @@ func NoExample() {
 // See http://json-schema.org/latest/json-schema-validation.html#anchor76.
 func Enum(val ...interface{}) {
 	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil {
+			switch a.Type.Kind() {
+			case design.BytesKind, design.DateKind, design.DurationKind:
+				incompatibleAttributeType("enum", qualifiedTypeName(a.Type), "comparable")
+				return
+			}
+		}
 		ok := true
 		for i, v := range val {
 			// When can a.Type be nil? glad you asked
@@ -477,7 +497,7 @@ func Pattern(p string) {
 // See http://json-schema.org/latest/json-schema-validation.html#anchor21.
 func Minimum(val interface{}) {
 	if a, ok := attributeDefinition(); ok {
-		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
+		if a.Type != nil && !isNumeric(a.Type) {
 			incompatibleAttributeType("minimum", a.Type.Name(), "an integer or a number")
 		} else {
 			var f float64
@@ -509,7 +529,7 @@ func Minimum(val interface{}) {
 // See http://json-schema.org/latest/json-schema-validation.html#anchor17.
 func Maximum(val interface{}) {
 	if a, ok := attributeDefinition(); ok {
-		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
+		if a.Type != nil && !isNumeric(a.Type) {
 			incompatibleAttributeType("maximum", a.Type.Name(), "an integer or a number")
 		} else {
 			var f float64
@@ -596,6 +616,15 @@ func Required(names ...string) {
 	}
 }
 
+// isNumeric returns true if t is one of the integer or number primitive types.
+func isNumeric(t design.DataType) bool {
+	switch t.Kind() {
+	case design.IntegerKind, design.NumberKind, design.Int64Kind, design.UInt64Kind, design.Float32Kind:
+		return true
+	}
+	return false
+}
+
 // incompatibleAttributeType reports an error for validations defined on
 // incompatible attributes (e.g. max value on string).
 func incompatibleAttributeType(validation, actual, expected string) {
@@ -610,6 +639,18 @@ func qualifiedTypeName(t design.DataType) string {
 	switch t.Kind() {
 	case design.DateTimeKind:
 		return "datetime"
+	case design.Int64Kind:
+		return "int64"
+	case design.UInt64Kind:
+		return "uint64"
+	case design.Float32Kind:
+		return "float32"
+	case design.BytesKind:
+		return "bytes"
+	case design.DateKind:
+		return "date"
+	case design.DurationKind:
+		return "duration"
 	case design.ArrayKind:
 		return fmt.Sprintf("%s<%s>", t.Name(), qualifiedTypeName(t.ToArray().ElemType.Type))
 	case design.HashKind:
@@ -212,6 +212,19 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name and a DSL defining a sensitive attribute", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { Sensitive() }
+		})
+
+		It("produces a sensitive attribute", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].IsSensitive()).Should(BeTrue())
+		})
+	})
+
 	Context("with a name and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -271,6 +284,35 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name, type int64 and a DSL defining a range validation", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = Int64
+			dsl = func() { Minimum(1); Maximum(10) }
+		})
+
+		It("produces an attribute of type int64 with a validation", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o[name].Type).Should(Equal(Int64))
+			Ω(*o[name].Validation.Minimum).Should(Equal(1.0))
+			Ω(*o[name].Validation.Maximum).Should(Equal(10.0))
+		})
+	})
+
+	Context("with a name, type date and a DSL defining an enum validation", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = Date
+			dsl = func() { Enum("2017-05-29") }
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid enum validation definition"))
+		})
+	})
+
 	Context("with a name, type integer, a description and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -131,3 +131,23 @@ func responseDefinition() (*design.ResponseDefinition, bool) {
 	}
 	return r, ok
 }
+
+// rateLimitDefinition returns true and current context if it is a RateLimitDefinition,
+// nil and false otherwise.
+func rateLimitDefinition() (*design.RateLimitDefinition, bool) {
+	r, ok := dslengine.CurrentDefinition().(*design.RateLimitDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return r, ok
+}
+
+// paginationDefinition returns true and current context if it is a PaginationDefinition,
+// nil and false otherwise.
+func paginationDefinition() (*design.PaginationDefinition, bool) {
+	p, ok := dslengine.CurrentDefinition().(*design.PaginationDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return p, ok
+}
@@ -111,6 +111,7 @@ func MediaType(identifier string, apidsl func()) *design.MediaTypeDefinition {
 	}
 	// Now save the type in the API media types map
 	mt := design.NewMediaTypeDefinition(typeName, identifier, apidsl)
+	dslengine.RecordLocation(mt)
 	design.Design.MediaTypes[canonicalID] = mt
 	return mt
 }
@@ -453,6 +454,7 @@ func CollectionOf(v interface{}, paramAndDSL ...interface{}) *design.MediaTypeDe
 			}
 		}
 	})
+	dslengine.RecordLocation(mt)
 	// Do not execute the apidsl right away, will be done last to make sure the element apidsl has run
 	// first.
 	design.GeneratedMediaTypes[canonical] = mt
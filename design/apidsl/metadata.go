@@ -67,6 +67,41 @@ import (
 //
 //        Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
 //
+// `client:retry`: makes the generated client retry the action requests using the Retry decorator
+// of the github.com/goadesign/goa/client package. The optional value is the maximum number of
+// attempts. `client:retry:status` lists the response status codes that cause a retry.
+// Applicable to actions.
+//
+//        Metadata("client:retry", "5")
+//        Metadata("client:retry:status", "429", "503")
+//
+// `client:breaker`: makes the generated client use a circuit breaker for the action requests. The
+// optional values are the number of consecutive failures that open the circuit and the duration
+// it stays open.
+// Applicable to actions.
+//
+//        Metadata("client:breaker", "5", "30s")
+//
+// `client:hedge`: makes the generated client send another copy of the action requests when no
+// response is received in time. The optional values are the delay and the maximum number of
+// copies.
+// Applicable to actions.
+//
+//        Metadata("client:hedge", "50ms", "2")
+//
+// `log:sensitive`: hides the attribute value from the logs, the generated payload types implement
+// goa.Redactor accordingly. This is the key set by the Sensitive DSL.
+// Applicable to attributes.
+//
+//        Metadata("log:sensitive")
+//
+// `lint:disable`: disables the "goagen lint" rules listed in the values, all rules if there is no
+// value. Disables the rules for the whole design when set on the API and for the definition and
+// the definitions it contains otherwise.
+// Applicable to API, resources, actions, responses, media types and attributes.
+//
+//        Metadata("lint:disable", "snake-case", "paginated-collections")
+//
 // The special key names listed above may be used as follows:
 //
 //        var Account = Type("Account", func() {
@@ -0,0 +1,103 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Paginated can be used in: Action
+//
+// Paginated indicates that the action returns its results one page at a time. The action OK
+// response must be a collection, typically defined with CollectionOf. The kind of pagination is
+// either CursorPagination or OffsetPagination.
+//
+// Paginated adds the "limit" integer parameter that contains the page size as well as the
+// "cursor" string parameter or the "offset" integer parameter that identifies the page. The
+// parameters already defined by the action are left untouched so that their descriptions or
+// validations may be customized.
+//
+// The generated action context has a SetPageLinks method that writes the RFC 5988 Link header
+// with the URLs of the next and previous pages. The generated client has a method that iterates
+// through the pages by following the "next" links.
+//
+// The optional DSL may use PageSize to set the default and maximum page sizes which default to
+// 20 and 100 respectively. Examples:
+//
+//    Action("list", func() {
+//        Routing(GET(""))
+//        Paginated(CursorPagination)
+//        Response(OK, CollectionOf(BottleMedia))
+//    })
+//
+//    Action("list", func() {
+//        Routing(GET(""))
+//        Paginated(OffsetPagination, func() {
+//            PageSize(50, 500)
+//        })
+//        Response(OK, CollectionOf(BottleMedia))
+//    })
+//
+func Paginated(kind design.PaginationKind, dsl ...func()) {
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments")
+		return
+	}
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	def := &design.PaginationDefinition{
+		Parent:      a,
+		Kind:        kind,
+		DefaultSize: design.DefaultPageSize,
+		MaxSize:     design.DefaultMaxPageSize,
+	}
+	if len(dsl) == 1 {
+		if !dslengine.Execute(dsl[0], def) {
+			return
+		}
+	}
+	a.Pagination = def
+	Params(func() {
+		if !hasParam(a, design.LimitParam) {
+			Param(design.LimitParam, design.Integer, "Maximum number of items in the page", func() {
+				Minimum(1)
+				Maximum(def.MaxSize)
+				Default(def.DefaultSize)
+			})
+		}
+		switch kind {
+		case design.CursorPagination:
+			if !hasParam(a, design.CursorParam) {
+				Param(design.CursorParam, design.String, "Cursor of the page returned in the Link header of the previous page")
+			}
+		case design.OffsetPagination:
+			if !hasParam(a, design.OffsetParam) {
+				Param(design.OffsetParam, design.Integer, "Index of the first item in the page", func() {
+					Minimum(0)
+					Default(0)
+				})
+			}
+		}
+	})
+}
+
+// PageSize can be used in: Paginated
+//
+// PageSize sets the number of items returned when the request does not specify a page size and
+// the maximum number of items per page.
+func PageSize(def, max int) {
+	if p, ok := paginationDefinition(); ok {
+		p.DefaultSize = def
+		p.MaxSize = max
+	}
+}
+
+// hasParam returns true if the action defines a parameter with the given name.
+func hasParam(a *design.ActionDefinition, name string) bool {
+	if a.Params == nil {
+		return false
+	}
+	_, ok := a.Params.Type.ToObject()[name]
+	return ok
+}
@@ -0,0 +1,140 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Paginated", func() {
+	var dsl func()
+	var single bool
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+		single = false
+	})
+
+	JustBeforeEach(func() {
+		thing := MediaType("application/vnd.thing", func() {
+			Attributes(func() {
+				Attribute("name", String)
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("things", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				if dsl != nil {
+					dsl()
+				}
+				if single {
+					Response(OK, thing)
+				} else {
+					Response(OK, CollectionOf(thing))
+				}
+			})
+		})
+		dslengine.Run()
+	})
+
+	Context("with cursor pagination", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Paginated(CursorPagination)
+			}
+		})
+
+		It("adds the limit and cursor parameters", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["things"].Actions["list"]
+			Ω(a.Pagination).ShouldNot(BeNil())
+			Ω(a.Pagination.Kind).Should(Equal(CursorPagination))
+			params := a.Params.Type.ToObject()
+			Ω(params).Should(HaveLen(2))
+			Ω(params[CursorParam].Type).Should(Equal(String))
+			limit := params[LimitParam]
+			Ω(limit.Type).Should(Equal(Integer))
+			Ω(limit.DefaultValue).Should(Equal(DefaultPageSize))
+			Ω(*limit.Validation.Minimum).Should(Equal(1.0))
+			Ω(*limit.Validation.Maximum).Should(Equal(float64(DefaultMaxPageSize)))
+		})
+
+		Context("on an action that does not return a collection", func() {
+			BeforeEach(func() {
+				single = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`pagination of resource "things" action "list"`))
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("must define an OK response whose media type is a collection"))
+			})
+		})
+	})
+
+	Context("with offset pagination and custom page sizes", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Paginated(OffsetPagination, func() {
+					PageSize(50, 500)
+				})
+			}
+		})
+
+		It("adds the limit and offset parameters", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["things"].Actions["list"]
+			params := a.Params.Type.ToObject()
+			Ω(params).Should(HaveLen(2))
+			offset := params[OffsetParam]
+			Ω(offset.Type).Should(Equal(Integer))
+			Ω(offset.DefaultValue).Should(Equal(0))
+			Ω(*offset.Validation.Minimum).Should(Equal(0.0))
+			limit := params[LimitParam]
+			Ω(limit.DefaultValue).Should(Equal(50))
+			Ω(*limit.Validation.Maximum).Should(Equal(500.0))
+			Ω(a.QueryParams.Type.ToObject()).Should(HaveKey(OffsetParam))
+		})
+	})
+
+	Context("with existing parameters", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Params(func() {
+					Param(LimitParam, Integer, "Custom", func() {
+						Default(10)
+					})
+				})
+				Paginated(OffsetPagination)
+			}
+		})
+
+		It("keeps them", func() {
+			limit := Design.Resources["things"].Actions["list"].Params.Type.ToObject()[LimitParam]
+			Ω(limit.Description).Should(Equal("Custom"))
+			Ω(limit.DefaultValue).Should(Equal(10))
+		})
+	})
+
+	Context("with a parameter of the wrong type", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Params(func() {
+					Param(OffsetParam, String)
+				})
+				Paginated(OffsetPagination)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("offset parameter must be of type integer, got string"))
+		})
+	})
+})
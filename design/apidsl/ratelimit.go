@@ -0,0 +1,69 @@
+package apidsl
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// RateLimit can be used in: Action, Resource
+//
+// RateLimit limits the rate of requests accepted by an action to limit requests per period and
+// per client. When defined on a Resource, it applies to all the resource actions that don't define
+// their own rate limit, each action gets a separate quota. The generated code mounts the limiting
+// middleware implemented by the github.com/goadesign/goa/middleware/ratelimit package. Requests
+// that exceed the limit get a response with status 429 Too Many Requests.
+//
+// The optional DSL may use Burst to set the maximum number of requests allowed at once (defaults
+// to limit) and KeyBy to set how clients are identified (defaults to RateLimitByIP). Examples:
+//
+//    RateLimit(100, time.Minute)
+//
+//    RateLimit(10, time.Second, func() {
+//        Burst(50)
+//        KeyBy(RateLimitByJWTSubject)
+//    })
+//
+func RateLimit(limit int, period time.Duration, dsl ...func()) {
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments")
+		return
+	}
+	parent := dslengine.CurrentDefinition()
+	def := &design.RateLimitDefinition{Parent: parent, Limit: limit, Period: period}
+	if len(dsl) == 1 {
+		if !dslengine.Execute(dsl[0], def) {
+			return
+		}
+	}
+	switch p := parent.(type) {
+	case *design.ActionDefinition:
+		p.RateLimit = def
+	case *design.ResourceDefinition:
+		p.RateLimit = def
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Burst can be used in: RateLimit
+//
+// Burst sets the maximum number of requests a client may make at once. The quota of a client
+// grows back to Burst requests at the rate defined by RateLimit.
+func Burst(n int) {
+	if r, ok := rateLimitDefinition(); ok {
+		r.Burst = n
+	}
+}
+
+// KeyBy can be used in: RateLimit
+//
+// KeyBy sets the request property used to identify clients, one of RateLimitByIP,
+// RateLimitByAPIKey or RateLimitByJWTSubject. The API key and JWT subject are the ones validated
+// by the action security scheme.
+func KeyBy(key design.RateLimitKey) {
+	if r, ok := rateLimitDefinition(); ok {
+		r.Key = key
+	}
+}
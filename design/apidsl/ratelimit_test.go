@@ -0,0 +1,98 @@
+package apidsl_test
+
+import (
+	"time"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimit", func() {
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		Resource("things", func() {
+			if dsl != nil {
+				dsl()
+			}
+			Action("list", func() {
+				Routing(GET(""))
+				Response(OK)
+			})
+			Action("show", func() {
+				Routing(GET("/:id"))
+				RateLimit(10, time.Second, func() {
+					Burst(20)
+					KeyBy(RateLimitByAPIKey)
+				})
+				Response(OK)
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the action rate limit", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		rl := Design.Resources["things"].Actions["show"].RateLimit
+		Ω(rl).ShouldNot(BeNil())
+		Ω(rl.Limit).Should(Equal(10))
+		Ω(rl.Period).Should(Equal(time.Second))
+		Ω(rl.Burst).Should(Equal(20))
+		Ω(rl.EffectiveKey()).Should(Equal(RateLimitByAPIKey))
+		Ω(Design.Resources["things"].Actions["list"].RateLimit).Should(BeNil())
+	})
+
+	Context("on the resource", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				RateLimit(100, time.Minute)
+			}
+		})
+
+		It("applies to the actions that don't define one", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			rl := Design.Resources["things"].Actions["list"].RateLimit
+			Ω(rl).ShouldNot(BeNil())
+			Ω(rl.Limit).Should(Equal(100))
+			Ω(rl.EffectiveBurst()).Should(Equal(100))
+			Ω(rl.EffectiveKey()).Should(Equal(RateLimitByIP))
+			Ω(Design.Resources["things"].Actions["show"].RateLimit.Limit).Should(Equal(10))
+		})
+	})
+
+	Context("with an invalid limit", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				RateLimit(0, time.Minute, func() {
+					KeyBy("cookie")
+				})
+			}
+		})
+
+		It("produces errors", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("rate limit of resource \"things\": limit must be greater than 0"))
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid rate limit key "cookie"`))
+		})
+	})
+
+	Context("used in an incompatible DSL", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Burst(10)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
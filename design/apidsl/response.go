@@ -1,6 +1,8 @@
 package apidsl
 
 import (
+	"time"
+
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
@@ -88,6 +90,7 @@ func Response(name string, paramsAndDSL ...interface{}) {
 				resp.ViewName = def.Parent.DefaultViewName
 			}
 			resp.Parent = def
+			dslengine.RecordLocation(resp)
 			def.Responses[name] = resp
 		}
 
@@ -105,6 +108,7 @@ func Response(name string, paramsAndDSL ...interface{}) {
 				resp.ViewName = def.DefaultViewName
 			}
 			resp.Parent = def
+			dslengine.RecordLocation(resp)
 			def.Responses[name] = resp
 		}
 
@@ -122,6 +126,46 @@ func Status(status int) {
 	}
 }
 
+// ServerSentEvents can be used in: Response, ResponseTemplate
+//
+// ServerSentEvents makes the response a stream of server-sent events (text/event-stream). The
+// generated action context exposes a method that opens the stream and returns a value whose Send
+// method sends an event rendered using the response media type. The generated client exposes an
+// iterator over the events. The optional argument sets the interval between two heartbeats sent on
+// idle streams, it defaults to 15 seconds and a negative value disables heartbeats:
+//
+//        Response(OK, func() {
+//                Media(BottleMedia)
+//                ServerSentEvents(30 * time.Second)
+//        })
+//
+func ServerSentEvents(heartbeat ...time.Duration) {
+	if len(heartbeat) > 1 {
+		dslengine.ReportError("too many arguments given to ServerSentEvents")
+		return
+	}
+	if r, ok := responseDefinition(); ok {
+		r.Stream = &design.StreamDefinition{}
+		if len(heartbeat) == 1 {
+			r.Stream.Heartbeat = heartbeat[0]
+		}
+	}
+}
+
+// Stream can be used in: Response, ResponseTemplate
+//
+// Stream sets the media type used to render the events of a server-sent events response. It is a
+// shorthand for using Media and ServerSentEvents:
+//
+//        Response(OK, func() {
+//                Stream(BottleMedia, "tiny")
+//        })
+//
+func Stream(val interface{}, viewName ...string) {
+	Media(val, viewName...)
+	ServerSentEvents()
+}
+
 func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.ResponseDefinition {
 	var params []string
 	var dsl func()
@@ -1,6 +1,8 @@
 package apidsl_test
 
 import (
+	"time"
+
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -197,4 +199,56 @@ var _ = Describe("Response", func() {
 		})
 	})
 
+	Context("with server-sent events", func() {
+		const mediaType = "application/json"
+
+		BeforeEach(func() {
+			name = "OK"
+			dsl = func() {
+				Media(mediaType)
+				ServerSentEvents(time.Minute)
+			}
+		})
+
+		It("sets the stream and heartbeat", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Validate()).ShouldNot(HaveOccurred())
+			Ω(res.MediaType).Should(Equal(mediaType))
+			Ω(res.Stream).Should(Equal(&StreamDefinition{Heartbeat: time.Minute}))
+		})
+	})
+
+	Context("with a stream media type", func() {
+		const mediaType = "application/json"
+
+		BeforeEach(func() {
+			name = "OK"
+			dsl = func() {
+				Stream(mediaType, "tiny")
+			}
+		})
+
+		It("sets the media type and the stream", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.MediaType).Should(Equal(mediaType))
+			Ω(res.ViewName).Should(Equal("tiny"))
+			Ω(res.Stream).ShouldNot(BeNil())
+			Ω(res.Stream.Heartbeat).Should(BeZero())
+		})
+	})
+
+	Context("with server-sent events and a non 2xx status", func() {
+		BeforeEach(func() {
+			name = "NotFound"
+			dsl = func() {
+				ServerSentEvents()
+			}
+		})
+
+		It("produces an invalid response definition", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Validate()).Should(HaveOccurred())
+		})
+	})
+
 })
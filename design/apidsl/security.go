@@ -1,6 +1,8 @@
 package apidsl
 
 import (
+	"time"
+
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
@@ -401,3 +403,76 @@ func TokenURL(tokenURL string) {
 	}
 	dslengine.IncompatibleDSL()
 }
+
+// Issuer can be used in: JWTSecurity
+//
+// Issuer defines the accepted values of the JWT "iss" claim. The JWT middleware rejects tokens
+// issued by other issuers. Issuer may be called multiple times.
+//
+// Example:
+//
+//    JWTSecurity("jwt", func() {
+//        Issuer("https://accounts.example.com")
+//        Audience("my-api")
+//        Leeway(30 * time.Second)
+//        RequiredClaims("sub")
+//        SigningAlgorithms("RS256", "ES256")
+//    })
+//
+func Issuer(issuers ...string) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.Issuers = append(parent.Issuers, issuers...)
+	}
+}
+
+// Audience can be used in: JWTSecurity
+//
+// Audience defines the accepted values of the JWT "aud" claim. The JWT middleware rejects tokens
+// that do not list at least one of the audiences. Audience may be called multiple times.
+func Audience(audiences ...string) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.Audiences = append(parent.Audiences, audiences...)
+	}
+}
+
+// Leeway can be used in: JWTSecurity
+//
+// Leeway defines the clock skew tolerated by the JWT middleware when validating the "exp", "nbf"
+// and "iat" claims.
+func Leeway(d time.Duration) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.Leeway = d
+	}
+}
+
+// RequiredClaims can be used in: JWTSecurity
+//
+// RequiredClaims lists the claims that must be present in the JWT for the JWT middleware to accept
+// it.
+func RequiredClaims(names ...string) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.RequiredClaims = append(parent.RequiredClaims, names...)
+	}
+}
+
+// SigningAlgorithms can be used in: JWTSecurity
+//
+// SigningAlgorithms lists the JWT signing algorithms accepted by the JWT middleware, e.g. "RS256"
+// or "ES256".
+func SigningAlgorithms(algs ...string) {
+	if parent, ok := jwtSecurityDefinition(); ok {
+		parent.SigningAlgorithms = append(parent.SigningAlgorithms, algs...)
+	}
+}
+
+// jwtSecurityDefinition returns the current JWT security scheme definition if any, it reports an
+// incompatible DSL error otherwise.
+func jwtSecurityDefinition() (*design.SecuritySchemeDefinition, bool) {
+	if parent, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
+		if parent.Kind == design.JWTSecurityKind {
+			return parent, true
+		}
+	}
+	dslengine.IncompatibleDSL()
+	return nil, false
+}
@@ -1,6 +1,8 @@
 package apidsl_test
 
 import (
+	"time"
+
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -71,6 +73,50 @@ var _ = Describe("Security", func() {
 		Ω(Design.SecuritySchemes[3].Scopes).Should(HaveLen(2))
 	})
 
+	Context("with JWT claim validations", func() {
+		It("should record the claim validation settings", func() {
+			API("secure", func() {
+				JWTSecurity("jwt", func() {
+					Header("Authorization")
+					Issuer("https://issuer.example.com")
+					Audience("api", "admin")
+					Leeway(30 * time.Second)
+					RequiredClaims("sub")
+					SigningAlgorithms("RS256")
+				})
+			})
+			dslengine.Run()
+
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			scheme := Design.SecuritySchemes[0]
+			Ω(scheme.Issuers).Should(Equal([]string{"https://issuer.example.com"}))
+			Ω(scheme.Audiences).Should(Equal([]string{"api", "admin"}))
+			Ω(scheme.Leeway).Should(Equal(30 * time.Second))
+			Ω(scheme.RequiredClaims).Should(Equal([]string{"sub"}))
+			Ω(scheme.SigningAlgorithms).Should(Equal([]string{"RS256"}))
+		})
+
+		It("should fail with an unknown signing algorithm", func() {
+			API("secure", func() {
+				JWTSecurity("jwt", func() {
+					SigningAlgorithms("none")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+
+		It("should fail when used outside of JWTSecurity", func() {
+			API("secure", func() {
+				APIKeySecurity("key", func() {
+					Issuer("https://issuer.example.com")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
 	Context("with basic security", func() {
 		It("should fail because of duplicate In declaration", func() {
 			API("", func() {
@@ -229,11 +275,14 @@ var _ = Describe("Security", func() {
 			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
 			Ω(Design.SecuritySchemes).Should(HaveLen(2))
 			Ω(Design.Resources["one"].Actions["first"].Security).Should(BeNil())
+			Ω(Design.Resources["one"].Actions["first"].NoSecurity).Should(BeTrue())
+			Ω(Design.Resources["one"].Actions["second"].NoSecurity).Should(BeFalse())
 			Ω(Design.Resources["one"].Actions["second"].Security.Scheme.SchemeName).Should(Equal("jwt"))
 			Ω(Design.Resources["two"].Actions["third"].Security.Scheme.SchemeName).Should(Equal("password"))
 			Ω(Design.Resources["two"].Actions["fourth"].Security.Scheme.SchemeName).Should(Equal("jwt"))
 			Ω(Design.Resources["three"].Actions["fifth"].Security.Scheme.SchemeName).Should(Equal("jwt"))
 			Ω(Design.Resources["auth"].Actions["auth"].Security).Should(BeNil())
+			Ω(Design.Resources["auth"].Actions["auth"].NoSecurity).Should(BeTrue())
 			Ω(Design.Resources["auth"].Actions["refresh"].Security.Scheme.SchemeName).Should(Equal("jwt"))
 		})
 	})
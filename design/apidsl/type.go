@@ -57,6 +57,7 @@ func Type(name string, dsl func()) *design.UserTypeDefinition {
 	} else {
 		t.Type = make(design.Object)
 	}
+	dslengine.RecordLocation(t)
 	design.Design.Types[name] = t
 	return t
 }
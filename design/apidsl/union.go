@@ -0,0 +1,80 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// OneOf can be used in: Type
+//
+// OneOf defines the type as a union: the values of the type are exactly one of the given user
+// types. The variants are given as user types or as the names of user types. Example:
+//
+//	var Pet = Type("Pet", func() {
+//		Description("Pet is either a cat or a dog")
+//		OneOf(Cat, Dog)
+//		Discriminator("kind")
+//	})
+//
+// The code generated for a union type is a struct with one field per variant, exactly one of the
+// fields is set. The struct implements the json.Marshaler and json.Unmarshaler interfaces so that
+// the values are encoded and decoded as the variant they hold. See Discriminator for how the
+// variant is identified when decoding a value.
+func OneOf(variants ...interface{}) {
+	at, ok := attributeDefinition()
+	if !ok {
+		return
+	}
+	if o, isObj := at.Type.(design.Object); at.Type != nil && (!isObj || len(o) > 0) {
+		dslengine.ReportError("OneOf cannot be used on an attribute that already has a type or child attributes")
+		return
+	}
+	u := &design.Union{}
+	for _, v := range variants {
+		t := resolveType(v)
+		if t == nil {
+			dslengine.ReportError("invalid OneOf argument: not a type and not a known user type name")
+			continue
+		}
+		u.Variants = append(u.Variants, &design.AttributeDefinition{Type: t})
+	}
+	at.Type = u
+}
+
+// Discriminator can be used in: Type, after OneOf
+//
+// Discriminator sets the name of the attribute whose value identifies the variant of a union.
+// Each variant must define the attribute as a string. The value that identifies a variant is the
+// name of the variant type unless the variant attribute defines a single value with Enum:
+//
+//	var Cat = Type("Cat", func() {
+//		Attribute("kind", String, func() {
+//			Enum("cat")
+//		})
+//		Attribute("lives", Integer)
+//		Required("kind")
+//	})
+//
+//	var Pet = Type("Pet", func() {
+//		OneOf(Cat, Dog)
+//		Discriminator("kind")
+//	})
+//
+// Swagger requires the discriminator value of a variant to be the name of its definition, that is
+// the name of the variant type: values set with Enum are only honored by the generated code and
+// the JSON schema.
+//
+// Values of unions that are not discriminated are decoded by trying each variant: the value must
+// match exactly one variant, that is decode without unknown fields and pass its validations.
+func Discriminator(name string) {
+	at, ok := attributeDefinition()
+	if !ok {
+		return
+	}
+	u, ok := at.Type.(*design.Union)
+	if !ok {
+		dslengine.ReportError("Discriminator must be used after OneOf")
+		return
+	}
+	u.Discriminator = name
+}
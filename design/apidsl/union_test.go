@@ -0,0 +1,109 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OneOf", func() {
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		Type("Cat", func() {
+			Attribute("kind", String, func() {
+				Enum("cat")
+			})
+			Attribute("lives", Integer)
+		})
+		Type("Dog", func() {
+			Attribute("kind", String)
+			Attribute("bark", String)
+		})
+		Type("Pet", dsl)
+		dslengine.Run()
+	})
+
+	Context("with a discriminator", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf("Cat", "Dog")
+				Discriminator("kind")
+			}
+		})
+
+		It("defines a discriminated union", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			pet := Design.Types["Pet"]
+			Ω(pet.IsUnion()).Should(BeTrue())
+			u := pet.ToUnion()
+			Ω(u.Variants).Should(HaveLen(2))
+			Ω(u.Variants[0].Type).Should(Equal(Design.Types["Cat"]))
+			Ω(u.Variants[1].Type).Should(Equal(Design.Types["Dog"]))
+			Ω(u.Discriminator).Should(Equal("kind"))
+			Ω(u.DiscriminatorValue(u.Variants[0])).Should(Equal("cat"))
+			Ω(u.DiscriminatorValue(u.Variants[1])).Should(Equal("Dog"))
+		})
+	})
+
+	Context("with a discriminator missing from a variant", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf("Cat", "Dog")
+				Discriminator("lives")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`variant Cat must define the discriminator attribute "lives" as a string`))
+		})
+	})
+
+	Context("with a single variant", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf("Cat")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("union must define at least two variants"))
+		})
+	})
+
+	Context("with attributes", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Attribute("name", String)
+				OneOf("Cat", "Dog")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with Discriminator used before OneOf", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Discriminator("kind")
+				OneOf("Cat", "Dog")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("Discriminator must be used after OneOf"))
+		})
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dimfeld/httppath"
 	"github.com/goadesign/goa/dslengine"
@@ -144,6 +145,9 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+		// RateLimit defines the rate limit of the Resource actions
+		// that don't define one themselves.
+		RateLimit *RateLimitDefinition
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -204,6 +208,16 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Standard is true if the response definition comes from the goa default responses
 		Standard bool
+		// Stream is set if the response is a stream of server-sent events
+		Stream *StreamDefinition
+	}
+
+	// StreamDefinition defines a response that streams server-sent events. Each event data is
+	// rendered using the response media type.
+	StreamDefinition struct {
+		// Heartbeat is the interval between two heartbeats sent on idle streams, zero means
+		// the goa default and a negative value disables heartbeats.
+		Heartbeat time.Duration
 	}
 
 	// ResponseTemplateDefinition defines a response template.
@@ -253,6 +267,21 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// NoSecurity is true if the action or its resource removes the security
+		// requirements with NoSecurity, Security is nil in this case
+		NoSecurity bool
+		// RateLimit defines the maximum rate of requests accepted by the action
+		RateLimit *RateLimitDefinition
+		// Pagination defines how the action results are split into pages if any
+		Pagination *PaginationDefinition
+		// InboundMessage is the type of the messages sent by clients on the action websocket
+		// connection if any
+		InboundMessage DataType
+		// OutboundMessage is the type of the messages sent by the server on the action
+		// websocket connection if any
+		OutboundMessage DataType
+		// Idempotent is true if the action supports the Idempotency-Key request header
+		Idempotent bool
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -427,6 +456,7 @@ func NewAPIDefinition() *APIDefinition {
 		{417, ExpectationFailed},
 		{418, Teapot},
 		{422, UnprocessableEntity},
+		{429, TooManyRequests},
 		{500, InternalServerError},
 		{501, NotImplemented},
 		{502, BadGateway},
@@ -657,7 +687,8 @@ func (a *APIDefinition) DSL() func() {
 }
 
 // Finalize sets the Consumes and Produces fields to the defaults if empty.
-// Also it records built-in media types that are used by the user design.
+// Also it records built-in media types that are used by the user design, including the error
+// media type returned by rate limited and idempotent actions.
 func (a *APIDefinition) Finalize() {
 	if len(a.Consumes) == 0 {
 		a.Consumes = DefaultDecoders
@@ -666,16 +697,24 @@ func (a *APIDefinition) Finalize() {
 		a.Produces = DefaultEncoders
 	}
 	a.IterateResources(func(r *ResourceDefinition) error {
+		registerErrorMedia := func() {
+			if a.MediaTypes == nil {
+				a.MediaTypes = make(map[string]*MediaTypeDefinition)
+			}
+			a.MediaTypes[CanonicalIdentifier(ErrorMediaIdentifier)] = ErrorMedia
+		}
 		returnsError := func(resp *ResponseDefinition) bool {
 			if resp.MediaType == ErrorMediaIdentifier {
-				if a.MediaTypes == nil {
-					a.MediaTypes = make(map[string]*MediaTypeDefinition)
-				}
-				a.MediaTypes[CanonicalIdentifier(ErrorMediaIdentifier)] = ErrorMedia
+				registerErrorMedia()
 				return true
 			}
 			return false
 		}
+		// Rate limited actions may return a TooManyRequests error response.
+		if r.RateLimit != nil {
+			registerErrorMedia()
+			return errors.New("done")
+		}
 		for _, resp := range a.Responses {
 			if returnsError(resp) {
 				return errors.New("done")
@@ -687,6 +726,11 @@ func (a *APIDefinition) Finalize() {
 			}
 		}
 		return r.IterateActions(func(action *ActionDefinition) error {
+			// Idempotent actions may return Conflict and UnprocessableEntity error responses.
+			if action.RateLimit != nil || action.Idempotent {
+				registerErrorMedia()
+				return errors.New("done")
+			}
 			for _, resp := range action.Responses {
 				if returnsError(resp) {
 					return errors.New("done")
@@ -1164,6 +1208,65 @@ func (a *AttributeDefinition) IsReadOnly() bool {
 	return false
 }
 
+// SetSensitive marks the attribute as holding sensitive data that must not be logged.
+func (a *AttributeDefinition) SetSensitive() {
+	if a.Metadata == nil {
+		a.Metadata = map[string][]string{}
+	}
+	a.Metadata["log:sensitive"] = nil
+}
+
+// IsSensitive returns true if attribute is sensitive (set using SetSensitive() method)
+func (a *AttributeDefinition) IsSensitive() bool {
+	_, ok := a.Metadata["log:sensitive"]
+	return ok
+}
+
+// SensitivePaths returns the paths to the sensitive attributes of the JSON representation of
+// the attribute value. The paths use the syntax accepted by goa.RedactJSON: the keys of nested
+// objects are separated with dots and "[*]" denotes all the elements of an array or all the
+// values of a hash. Recursive types are only traversed once. The paths are sorted.
+func (a *AttributeDefinition) SensitivePaths() []string {
+	var paths []string
+	a.sensitivePaths("", &paths, nil)
+	sort.Strings(paths)
+	return paths
+}
+
+func (a *AttributeDefinition) sensitivePaths(prefix string, paths *[]string, seen []string) {
+	if a.IsSensitive() && prefix != "" {
+		*paths = append(*paths, prefix)
+		return
+	}
+	var ut *UserTypeDefinition
+	switch t := a.Type.(type) {
+	case *UserTypeDefinition:
+		ut = t
+	case *MediaTypeDefinition:
+		ut = t.UserTypeDefinition
+	case Object:
+		for n, att := range t {
+			p := n
+			if prefix != "" {
+				p = prefix + "." + n
+			}
+			att.sensitivePaths(p, paths, seen)
+		}
+	case *Array:
+		t.ElemType.sensitivePaths(prefix+"[*]", paths, seen)
+	case *Hash:
+		t.ElemType.sensitivePaths(prefix+"[*]", paths, seen)
+	}
+	if ut != nil {
+		for _, n := range seen {
+			if n == ut.TypeName {
+				return
+			}
+		}
+		ut.AttributeDefinition.sensitivePaths(prefix, paths, append(seen, ut.TypeName))
+	}
+}
+
 func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string) interface{} {
 	ary := a.Type.ToArray()
 	ln := newExampleGenerator(a, rand).ExampleLength()
@@ -1428,6 +1531,10 @@ func (r *ResponseDefinition) Dup() *ResponseDefinition {
 	if r.Headers != nil {
 		res.Headers = DupAtt(r.Headers)
 	}
+	if r.Stream != nil {
+		stream := *r.Stream
+		res.Stream = &stream
+	}
 	return &res
 }
 
@@ -1449,6 +1556,9 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 		r.MediaType = other.MediaType
 		r.ViewName = other.ViewName
 	}
+	if r.Stream == nil {
+		r.Stream = other.Stream
+	}
 	if other.Headers != nil {
 		otherHeaders := other.Headers.Type.ToObject()
 		if len(otherHeaders) > 0 {
@@ -1584,7 +1694,26 @@ func (a *ActionDefinition) WebSocket() bool {
 	return true
 }
 
-// Finalize inherits security scheme and action responses from parent and top level design.
+// HasMessages returns true if the action defines the types of the messages exchanged on its
+// websocket connection.
+func (a *ActionDefinition) HasMessages() bool {
+	return a.InboundMessage != nil || a.OutboundMessage != nil
+}
+
+// Safe returns true if all the action routes use a safe HTTP method (GET, HEAD, OPTIONS or TRACE).
+func (a *ActionDefinition) Safe() bool {
+	for _, r := range a.Routes {
+		switch r.Verb {
+		case "GET", "HEAD", "OPTIONS", "TRACE":
+		default:
+			return false
+		}
+	}
+	return len(a.Routes) > 0
+}
+
+// Finalize inherits security scheme, rate limit and action responses from parent and top level
+// design.
 func (a *ActionDefinition) Finalize() {
 	// Inherit security scheme
 	if a.Security == nil {
@@ -1596,6 +1725,12 @@ func (a *ActionDefinition) Finalize() {
 
 	if a.Security != nil && a.Security.Scheme.Kind == NoSecurityKind {
 		a.Security = nil
+		a.NoSecurity = true
+	}
+
+	// Inherit rate limit
+	if a.RateLimit == nil {
+		a.RateLimit = a.Parent.RateLimit
 	}
 
 	if a.Payload != nil {
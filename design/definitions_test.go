@@ -125,6 +125,44 @@ var _ = Describe("IsRequired", func() {
 	})
 })
 
+var _ = Describe("SensitivePaths", func() {
+	sensitive := func(t design.DataType) *design.AttributeDefinition {
+		att := &design.AttributeDefinition{Type: t}
+		att.SetSensitive()
+		return att
+	}
+
+	It("lists the paths to the sensitive attributes", func() {
+		token := &design.UserTypeDefinition{
+			TypeName: "Token",
+			AttributeDefinition: &design.AttributeDefinition{Type: design.Object{
+				"value":  sensitive(design.String),
+				"expiry": &design.AttributeDefinition{Type: design.DateTime},
+			}},
+		}
+		node := &design.UserTypeDefinition{TypeName: "Node"}
+		node.AttributeDefinition = &design.AttributeDefinition{Type: design.Object{
+			"secret": sensitive(design.Integer),
+			"next":   &design.AttributeDefinition{Type: node},
+		}}
+		att := &design.AttributeDefinition{Type: design.Object{
+			"name":     &design.AttributeDefinition{Type: design.String},
+			"password": sensitive(design.String),
+			"keys":     sensitive(&design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}),
+			"tokens":   &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: token}}},
+			"byName":   &design.AttributeDefinition{Type: &design.Hash{KeyType: &design.AttributeDefinition{Type: design.String}, ElemType: &design.AttributeDefinition{Type: token}}},
+			"node":     &design.AttributeDefinition{Type: node},
+		}}
+		Ω(att.SensitivePaths()).Should(Equal([]string{
+			"byName[*].value",
+			"keys",
+			"node.secret",
+			"password",
+			"tokens[*].value",
+		}))
+	})
+})
+
 var _ = Describe("IterateHeaders", func() {
 	It("works when Parent.Headers is nil", func() {
 		// create a Resource with no headers, Action with one header
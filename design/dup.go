@@ -72,6 +72,12 @@ func (d *dupper) DupType(t DataType) DataType {
 			KeyType:  d.DupAttribute(actual.KeyType),
 			ElemType: d.DupAttribute(actual.ElemType),
 		}
+	case *Union:
+		variants := make([]*AttributeDefinition, len(actual.Variants))
+		for i, v := range actual.Variants {
+			variants[i] = d.DupAttribute(v)
+		}
+		return &Union{Variants: variants, Discriminator: actual.Discriminator}
 	case *UserTypeDefinition:
 		if u, ok := d.dts[actual.TypeName]; ok {
 			return u
@@ -214,6 +214,15 @@ func (eg *exampleGenerator) hasMinMaxValidation() bool {
 	return eg.a.Validation.Minimum != nil || eg.a.Validation.Maximum != nil
 }
 
+// isInteger returns true if the attribute type is one of the integer primitive types.
+func (eg *exampleGenerator) isInteger() bool {
+	switch eg.a.Type.Kind() {
+	case IntegerKind, Int64Kind, UInt64Kind:
+		return true
+	}
+	return false
+}
+
 func (eg *exampleGenerator) checkMinMaxValueValidation(example interface{}) bool {
 	if !eg.hasMinMaxValidation() {
 		return true
@@ -251,7 +260,7 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 		max = *eg.a.Validation.Maximum
 	}
 	if math.IsInf(min, 1) {
-		if eg.a.Type.Kind() == IntegerKind {
+		if eg.isInteger() {
 			if max == 0 {
 				return int(max) - eg.r.Int()%3
 			}
@@ -259,7 +268,7 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 		}
 		return eg.r.Float64() * max
 	} else if math.IsInf(max, -1) {
-		if eg.a.Type.Kind() == IntegerKind {
+		if eg.isInteger() {
 			if min == 0 {
 				return int(min) + eg.r.Int()%3
 			}
@@ -267,12 +276,12 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 		}
 		return min + eg.r.Float64()*min
 	} else if min < max {
-		if eg.a.Type.Kind() == IntegerKind {
+		if eg.isInteger() {
 			return int(min) + eg.r.Int()%int(max-min)
 		}
 		return min + eg.r.Float64()*(max-min)
 	} else if min == max {
-		if eg.a.Type.Kind() == IntegerKind {
+		if eg.isInteger() {
 			return int(min)
 		}
 		return min
@@ -0,0 +1,131 @@
+package design
+
+import (
+	"fmt"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+// PaginationKind identifies the pagination scheme used by an action.
+type PaginationKind string
+
+const (
+	// CursorPagination uses an opaque cursor returned by the previous page to retrieve the
+	// next one.
+	CursorPagination PaginationKind = "cursor"
+
+	// OffsetPagination uses the index of the first item of the page.
+	OffsetPagination PaginationKind = "offset"
+)
+
+const (
+	// LimitParam is the name of the query string parameter that contains the page size.
+	LimitParam = "limit"
+
+	// CursorParam is the name of the query string parameter that contains the page cursor.
+	CursorParam = "cursor"
+
+	// OffsetParam is the name of the query string parameter that contains the page offset.
+	OffsetParam = "offset"
+
+	// DefaultPageSize is the default number of items per page.
+	DefaultPageSize = 20
+
+	// DefaultMaxPageSize is the default maximum number of items per page.
+	DefaultMaxPageSize = 100
+)
+
+// PaginationDefinition describes how the results of an action are split into pages.
+type PaginationDefinition struct {
+	// Parent is the paginated action.
+	Parent *ActionDefinition
+	// Kind is the pagination scheme.
+	Kind PaginationKind
+	// DefaultSize is the number of items returned when the request does not specify one.
+	DefaultSize int
+	// MaxSize is the maximum number of items per page.
+	MaxSize int
+}
+
+// Context returns the generic definition name used in error messages.
+func (p *PaginationDefinition) Context() string {
+	if p.Parent == nil {
+		return "pagination"
+	}
+	return fmt.Sprintf("pagination of %s", p.Parent.Context())
+}
+
+// PageParam returns the name of the parameter that identifies the page, "cursor" or "offset".
+func (p *PaginationDefinition) PageParam() string {
+	if p.Kind == OffsetPagination {
+		return OffsetParam
+	}
+	return CursorParam
+}
+
+// Validate checks that the pagination definition is consistent: the page sizes are valid, the
+// action returns a collection and the pagination parameters have the expected types.
+func (p *PaginationDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	switch p.Kind {
+	case CursorPagination, OffsetPagination:
+	default:
+		verr.Add(p, "invalid pagination kind %q, must be %q or %q", p.Kind, CursorPagination, OffsetPagination)
+	}
+	if p.DefaultSize <= 0 {
+		verr.Add(p, "default page size must be greater than 0, got %d", p.DefaultSize)
+	}
+	if p.MaxSize < p.DefaultSize {
+		verr.Add(p, "maximum page size must be greater than or equal to the default page size %d, got %d", p.DefaultSize, p.MaxSize)
+	}
+	if p.Parent == nil {
+		return verr.AsError()
+	}
+	if !p.returnsCollection() {
+		verr.Add(p, "paginated actions must define an OK response whose media type is a collection")
+	}
+	var params Object
+	if p.Parent.Params != nil {
+		params = p.Parent.Params.Type.ToObject()
+	}
+	p.validateParam(verr, params, LimitParam, Integer)
+	if p.Kind == OffsetPagination {
+		p.validateParam(verr, params, OffsetParam, Integer)
+	} else {
+		p.validateParam(verr, params, CursorParam, String)
+	}
+	return verr.AsError()
+}
+
+// returnsCollection returns true if the OK response of the paginated action is a collection.
+func (p *PaginationDefinition) returnsCollection() bool {
+	resp, ok := p.Parent.Responses[OK]
+	if !ok && p.Parent.Parent != nil {
+		resp, ok = p.Parent.Parent.Responses[OK]
+	}
+	if !ok {
+		return false
+	}
+	if mt := Design.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+		return mt.IsArray()
+	}
+	return resp.Type != nil && resp.Type.IsArray()
+}
+
+// validateParam checks that the action defines the pagination parameter with the given name
+// and type. Integer parameters must also define a default value so that the generated code
+// always knows the current page.
+func (p *PaginationDefinition) validateParam(verr *dslengine.ValidationErrors, params Object, name string, t Primitive) {
+	att, ok := params[name]
+	if !ok {
+		verr.Add(p, "missing %s parameter", name)
+		return
+	}
+	if att.Type != t {
+		verr.Add(p, "%s parameter must be of type %s, got %s", name, t.Name(), att.Type.Name())
+		return
+	}
+	if t == Integer && att.DefaultValue == nil {
+		verr.Add(p, "%s parameter must define a default value", name)
+	}
+}
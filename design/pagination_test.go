@@ -0,0 +1,39 @@
+package design_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PaginationDefinition", func() {
+	var def *PaginationDefinition
+
+	BeforeEach(func() {
+		def = &PaginationDefinition{Kind: OffsetPagination, DefaultSize: 20, MaxSize: 100}
+	})
+
+	It("validates", func() {
+		Ω(def.Validate()).ShouldNot(HaveOccurred())
+	})
+
+	It("returns the page parameter name", func() {
+		Ω(def.PageParam()).Should(Equal(OffsetParam))
+		def.Kind = CursorPagination
+		Ω(def.PageParam()).Should(Equal(CursorParam))
+	})
+
+	Context("with an invalid kind and page sizes", func() {
+		BeforeEach(func() {
+			def.Kind = "page"
+			def.MaxSize = 10
+		})
+
+		It("does not validate", func() {
+			err := def.Validate()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`invalid pagination kind "page"`))
+			Ω(err.Error()).Should(ContainSubstring("maximum page size must be greater than or equal to the default page size"))
+		})
+	})
+})
@@ -0,0 +1,85 @@
+package design
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+// RateLimitKey identifies the request property used to group the requests when enforcing
+// rate limits.
+type RateLimitKey string
+
+const (
+	// RateLimitByIP groups the requests by client IP address.
+	RateLimitByIP RateLimitKey = "ip"
+
+	// RateLimitByAPIKey groups the requests by API key. The action must be secured with an
+	// API key security scheme.
+	RateLimitByAPIKey RateLimitKey = "apikey"
+
+	// RateLimitByJWTSubject groups the requests by JWT subject. The action must be secured
+	// with a JWT security scheme.
+	RateLimitByJWTSubject RateLimitKey = "jwt"
+)
+
+// RateLimitDefinition defines the maximum rate of requests accepted by an action.
+type RateLimitDefinition struct {
+	// Parent is the action or resource the rate limit applies to.
+	Parent dslengine.Definition
+	// Limit is the number of requests allowed per period.
+	Limit int
+	// Period is the duration over which Limit applies.
+	Period time.Duration
+	// Burst is the maximum number of requests allowed at once, defaults to Limit.
+	Burst int
+	// Key identifies the request property used to group requests, defaults to
+	// RateLimitByIP.
+	Key RateLimitKey
+}
+
+// Context returns the generic definition name used in error messages.
+func (r *RateLimitDefinition) Context() string {
+	if r.Parent == nil {
+		return "rate limit"
+	}
+	return fmt.Sprintf("rate limit of %s", r.Parent.Context())
+}
+
+// Validate checks that the rate limit definition is consistent.
+func (r *RateLimitDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Limit <= 0 {
+		verr.Add(r, "limit must be greater than 0, got %d", r.Limit)
+	}
+	if r.Period <= 0 {
+		verr.Add(r, "period must be greater than 0, got %s", r.Period)
+	}
+	if r.Burst < 0 {
+		verr.Add(r, "burst cannot be negative, got %d", r.Burst)
+	}
+	switch r.Key {
+	case "", RateLimitByIP, RateLimitByAPIKey, RateLimitByJWTSubject:
+	default:
+		verr.Add(r, "invalid rate limit key %q, must be one of %q, %q or %q",
+			r.Key, RateLimitByIP, RateLimitByAPIKey, RateLimitByJWTSubject)
+	}
+	return verr.AsError()
+}
+
+// EffectiveBurst returns the maximum number of requests allowed at once.
+func (r *RateLimitDefinition) EffectiveBurst() int {
+	if r.Burst == 0 {
+		return r.Limit
+	}
+	return r.Burst
+}
+
+// EffectiveKey returns the request property used to group requests.
+func (r *RateLimitDefinition) EffectiveKey() RateLimitKey {
+	if r.Key == "" {
+		return RateLimitByIP
+	}
+	return r.Key
+}
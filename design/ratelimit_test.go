@@ -0,0 +1,40 @@
+package design_test
+
+import (
+	"time"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimitDefinition", func() {
+	var def *RateLimitDefinition
+
+	BeforeEach(func() {
+		def = &RateLimitDefinition{Limit: 10, Period: time.Second}
+	})
+
+	It("validates", func() {
+		Ω(def.Validate()).ShouldNot(HaveOccurred())
+	})
+
+	It("defaults the burst and key", func() {
+		Ω(def.EffectiveBurst()).Should(Equal(10))
+		Ω(def.EffectiveKey()).Should(Equal(RateLimitByIP))
+	})
+
+	Context("with a negative burst and a zero period", func() {
+		BeforeEach(func() {
+			def.Burst = -1
+			def.Period = 0
+		})
+
+		It("does not validate", func() {
+			err := def.Validate()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("period must be greater than 0"))
+			Ω(err.Error()).Should(ContainSubstring("burst cannot be negative"))
+		})
+	})
+})
@@ -3,6 +3,7 @@ package design
 import (
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/goadesign/goa/dslengine"
 )
@@ -24,6 +25,15 @@ const (
 	NoSecurityKind
 )
 
+// jwtSigningAlgorithms lists the JWT signing algorithms supported by the JWT
+// middleware.
+var jwtSigningAlgorithms = map[string]bool{
+	"HS256": true, "HS384": true, "HS512": true,
+	"RS256": true, "RS384": true, "RS512": true,
+	"ES256": true, "ES384": true, "ES512": true,
+	"PS256": true, "PS384": true, "PS512": true,
+}
+
 // SecurityDefinition defines security requirements for an Action
 type SecurityDefinition struct {
 	// Scheme defines the Security Scheme used for this action.
@@ -69,6 +79,17 @@ type SecuritySchemeDefinition struct {
 	TokenURL string `json:"token_url,omitempty"`
 	// AuthorizationURL holds URL for retrieving authorization codes with oauth2
 	AuthorizationURL string `json:"authorization_url,omitempty"`
+	// Issuers lists the accepted values of the JWT "iss" claim.
+	Issuers []string `json:"issuers,omitempty"`
+	// Audiences lists the accepted values of the JWT "aud" claim.
+	Audiences []string `json:"audiences,omitempty"`
+	// Leeway is the clock skew tolerated when validating the JWT "exp", "nbf"
+	// and "iat" claims.
+	Leeway time.Duration `json:"leeway,omitempty"`
+	// RequiredClaims lists the claims that must be present in the JWT.
+	RequiredClaims []string `json:"required_claims,omitempty"`
+	// SigningAlgorithms lists the accepted JWT signing algorithms.
+	SigningAlgorithms []string `json:"signing_algorithms,omitempty"`
 	// Metadata is a list of key/value pairs
 	Metadata dslengine.MetadataDefinition
 }
@@ -94,7 +115,8 @@ func (s *SecuritySchemeDefinition) Context() string {
 	return dslFunc
 }
 
-// Validate ensures that TokenURL and AuthorizationURL are valid URLs.
+// Validate ensures that TokenURL and AuthorizationURL are valid URLs and that
+// the JWT claim validation settings are valid.
 func (s *SecuritySchemeDefinition) Validate() error {
 	_, err := url.Parse(s.TokenURL)
 	if err != nil {
@@ -104,6 +126,14 @@ func (s *SecuritySchemeDefinition) Validate() error {
 	if err != nil {
 		return fmt.Errorf("invalid authorization URL %#v: %s", s.AuthorizationURL, err)
 	}
+	if s.Leeway < 0 {
+		return fmt.Errorf("invalid leeway %s, leeway cannot be negative", s.Leeway)
+	}
+	for _, alg := range s.SigningAlgorithms {
+		if !jwtSigningAlgorithms[alg] {
+			return fmt.Errorf("invalid signing algorithm %#v", alg)
+		}
+	}
 	return nil
 }
 
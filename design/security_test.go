@@ -54,6 +54,23 @@ var _ = Describe("SecuritySchemeDefinition", func() {
 		})
 	})
 
+	Context("with JWT claim validation settings", func() {
+		It("validates known signing algorithms", func() {
+			def.SigningAlgorithms = []string{"RS256", "ES512"}
+			Ω(def.Validate()).ShouldNot(HaveOccurred())
+		})
+
+		It("does not validate unknown signing algorithms", func() {
+			def.SigningAlgorithms = []string{"none"}
+			Ω(def.Validate()).Should(MatchError(ContainSubstring(`"none"`)))
+		})
+
+		It("does not validate a negative leeway", func() {
+			def.Leeway = -1
+			Ω(def.Validate()).Should(HaveOccurred())
+		})
+	})
+
 	Context("with an absolute token URL", func() {
 		BeforeEach(func() {
 			tokenURL = "http://valid.com/auth"
@@ -49,6 +49,9 @@ type (
 		// IsHash returns true if the underlying type is a hash map, a user type which
 		// is a hash map or a media type whose type is a hash map.
 		IsHash() bool
+		// IsUnion returns true if the underlying type is a union or a user type which is a
+		// union.
+		IsUnion() bool
 		// ToObject returns the underlying object if any (i.e. if IsObject returns true),
 		// nil otherwise.
 		ToObject() Object
@@ -58,6 +61,9 @@ type (
 		// ToHash returns the underlying hash map if any (i.e. if IsHash returns true),
 		// nil otherwise.
 		ToHash() *Hash
+		// ToUnion returns the underlying union if any (i.e. if IsUnion returns true), nil
+		// otherwise.
+		ToUnion() *Union
 		// CanHaveDefault returns whether the data type can have a default value.
 		CanHaveDefault() bool
 		// IsCompatible checks whether val has a Go type that is
@@ -106,6 +112,16 @@ type (
 	// HashVal is the value of a hash used to specify the default value.
 	HashVal map[interface{}]interface{}
 
+	// Union is the type for a value that is exactly one of a set of user types.
+	Union struct {
+		// Variants lists the attributes describing the possible values, the type of each
+		// variant is a user type.
+		Variants []*AttributeDefinition
+		// Discriminator is the name of the variant attribute whose value identifies the
+		// variant, empty if the union is not discriminated.
+		Discriminator string
+	}
+
 	// UserTypeDefinition is the type for user defined types that are not media types
 	// (e.g. payload types).
 	UserTypeDefinition struct {
@@ -165,6 +181,20 @@ const (
 	MediaTypeKind
 	// FileKind represents a file.
 	FileKind
+	// UnionKind represents a value that is one of a set of user types.
+	UnionKind
+	// Int64Kind represents a JSON integer that is parsed as a Go int64.
+	Int64Kind
+	// UInt64Kind represents a JSON integer that is parsed as a Go uint64.
+	UInt64Kind
+	// Float32Kind represents a JSON number that is parsed as a Go float32.
+	Float32Kind
+	// BytesKind represents a JSON string that is parsed as a base64 encoded Go []byte.
+	BytesKind
+	// DateKind represents a JSON string that is parsed as a goa.Date.
+	DateKind
+	// DurationKind represents a JSON string that is parsed as a goa.Duration.
+	DurationKind
 )
 
 const (
@@ -193,6 +223,27 @@ const (
 
 	// File is the type for a file. This type can only be used in a multipart definition.
 	File = Primitive(FileKind)
+
+	// Int64 is the type for a JSON integer parsed as a Go int64.
+	Int64 = Primitive(Int64Kind)
+
+	// UInt64 is the type for a non-negative JSON integer parsed as a Go uint64.
+	UInt64 = Primitive(UInt64Kind)
+
+	// Float32 is the type for a JSON number parsed as a Go float32.
+	Float32 = Primitive(Float32Kind)
+
+	// Bytes is the type for a JSON string parsed as a Go []byte.
+	// Bytes expects a base64 encoded value.
+	Bytes = Primitive(BytesKind)
+
+	// Date is the type for a JSON string parsed as a goa.Date.
+	// Date expects a RFC3339 full-date formatted value (e.g. "2017-05-29").
+	Date = Primitive(DateKind)
+
+	// Duration is the type for a JSON string parsed as a goa.Duration.
+	// Duration expects a value that time.ParseDuration accepts (e.g. "1h30m").
+	Duration = Primitive(DurationKind)
 )
 
 // DataType implementation
@@ -205,11 +256,11 @@ func (p Primitive) Name() string {
 	switch p {
 	case Boolean:
 		return "boolean"
-	case Integer:
+	case Integer, Int64, UInt64:
 		return "integer"
-	case Number:
+	case Number, Float32:
 		return "number"
-	case String, DateTime, UUID:
+	case String, DateTime, UUID, Bytes, Date, Duration:
 		return "string"
 	case Any:
 		return "any"
@@ -235,6 +286,9 @@ func (p Primitive) IsArray() bool { return false }
 // IsHash returns false.
 func (p Primitive) IsHash() bool { return false }
 
+// IsUnion returns false.
+func (p Primitive) IsUnion() bool { return false }
+
 // ToObject returns nil.
 func (p Primitive) ToObject() Object { return nil }
 
@@ -244,10 +298,13 @@ func (p Primitive) ToArray() *Array { return nil }
 // ToHash returns nil.
 func (p Primitive) ToHash() *Hash { return nil }
 
+// ToUnion returns nil.
+func (p Primitive) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns whether the primitive can have a default value.
 func (p Primitive) CanHaveDefault() (ok bool) {
 	switch p {
-	case Boolean, Integer, Number, String, DateTime:
+	case Boolean, Integer, Number, String, DateTime, Int64, UInt64, Float32, Date, Duration:
 		ok = true
 	}
 	return
@@ -255,7 +312,9 @@ func (p Primitive) CanHaveDefault() (ok bool) {
 
 // IsCompatible returns true if val is compatible with p.
 func (p Primitive) IsCompatible(val interface{}) bool {
-	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any {
+	switch p {
+	case Boolean, Integer, Number, String, DateTime, UUID, Any, Int64, UInt64, Float32, Bytes, Date, Duration:
+	default:
 		panic("unknown primitive type") // bug
 	}
 	if p == Any {
@@ -264,14 +323,29 @@ func (p Primitive) IsCompatible(val interface{}) bool {
 	switch val.(type) {
 	case bool:
 		return p == Boolean
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-		return p == Integer || p == Number
+	case int, int8, int16, int32, int64:
+		if p == UInt64 {
+			return reflect.ValueOf(val).Int() >= 0
+		}
+		return p == Integer || p == Number || p == Int64 || p == Float32
+	case uint, uint8, uint16, uint32, uint64:
+		return p == Integer || p == Number || p == Int64 || p == UInt64 || p == Float32
 	case float32, float64:
-		return p == Number
+		return p == Number || p == Float32
+	case []byte:
+		return p == Bytes
 	case string:
-		if p == String {
+		if p == String || p == Bytes {
 			return true
 		}
+		if p == Date {
+			_, err := time.Parse("2006-01-02", val.(string))
+			return err == nil
+		}
+		if p == Duration {
+			_, err := time.ParseDuration(val.(string))
+			return err == nil
+		}
 		if p == DateTime {
 			_, err := time.Parse(time.RFC3339, val.(string))
 			return err == nil
@@ -306,6 +380,16 @@ func (p Primitive) GenerateExample(r *RandomGenerator, seen []string) interface{
 		return anyPrimitive[r.Int()%len(anyPrimitive)].GenerateExample(r, seen)
 	case File:
 		return r.File()
+	case Int64, UInt64:
+		return r.Int()
+	case Float32:
+		return float32(r.Float64())
+	case Bytes:
+		return []byte(r.String())
+	case Date:
+		return r.DateTime().Format("2006-01-02")
+	case Duration:
+		return (time.Duration(r.Int()%86400) * time.Second).String()
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -336,6 +420,9 @@ func (a *Array) IsArray() bool { return true }
 // IsHash returns false.
 func (a *Array) IsHash() bool { return false }
 
+// IsUnion returns false.
+func (a *Array) IsUnion() bool { return false }
+
 // ToObject returns nil.
 func (a *Array) ToObject() Object { return nil }
 
@@ -345,6 +432,9 @@ func (a *Array) ToArray() *Array { return a }
 // ToHash returns nil.
 func (a *Array) ToHash() *Hash { return nil }
 
+// ToUnion returns nil.
+func (a *Array) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns true if the array type can have a default value.
 // The array type can have a default value only if the element type can
 // have a default value.
@@ -409,6 +499,9 @@ func (o Object) IsArray() bool { return false }
 // IsHash returns false.
 func (o Object) IsHash() bool { return false }
 
+// IsUnion returns false.
+func (o Object) IsUnion() bool { return false }
+
 // ToObject returns the underlying object.
 func (o Object) ToObject() Object { return o }
 
@@ -418,6 +511,9 @@ func (o Object) ToArray() *Array { return nil }
 // ToHash returns nil.
 func (o Object) ToHash() *Hash { return nil }
 
+// ToUnion returns nil.
+func (o Object) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns false.
 func (o Object) CanHaveDefault() bool { return false }
 
@@ -475,6 +571,9 @@ func (h *Hash) IsArray() bool { return false }
 // IsHash returns true.
 func (h *Hash) IsHash() bool { return true }
 
+// IsUnion returns false.
+func (h *Hash) IsUnion() bool { return false }
+
 // ToObject returns nil.
 func (h *Hash) ToObject() Object { return nil }
 
@@ -484,6 +583,9 @@ func (h *Hash) ToArray() *Array { return nil }
 // ToHash returns the underlying hash map.
 func (h *Hash) ToHash() *Hash { return h }
 
+// ToUnion returns nil.
+func (h *Hash) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns true if the hash type can have a default value.
 // The hash type can have a default value only if both the key type and
 // the element type can have a default value.
@@ -528,6 +630,94 @@ func (h *Hash) MakeMap(m map[interface{}]interface{}) interface{} {
 	return hash.Interface()
 }
 
+// Kind implements DataKind.
+func (u *Union) Kind() Kind { return UnionKind }
+
+// Name returns the type name.
+func (u *Union) Name() string { return "union" }
+
+// IsPrimitive returns false.
+func (u *Union) IsPrimitive() bool { return false }
+
+// HasAttributes returns true.
+func (u *Union) HasAttributes() bool { return true }
+
+// IsObject returns false.
+func (u *Union) IsObject() bool { return false }
+
+// IsArray returns false.
+func (u *Union) IsArray() bool { return false }
+
+// IsHash returns false.
+func (u *Union) IsHash() bool { return false }
+
+// IsUnion returns true.
+func (u *Union) IsUnion() bool { return true }
+
+// ToObject returns nil.
+func (u *Union) ToObject() Object { return nil }
+
+// ToArray returns nil.
+func (u *Union) ToArray() *Array { return nil }
+
+// ToHash returns nil.
+func (u *Union) ToHash() *Hash { return nil }
+
+// ToUnion returns the underlying union.
+func (u *Union) ToUnion() *Union { return u }
+
+// CanHaveDefault returns false.
+func (u *Union) CanHaveDefault() bool { return false }
+
+// IsCompatible returns true if val is compatible with one of the union variants.
+func (u *Union) IsCompatible(val interface{}) bool {
+	for _, v := range u.Variants {
+		if v.Type != nil && v.Type.IsCompatible(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateExample returns a random value of one of the union variants. The discriminator
+// attribute of the example is set to the value that identifies the variant.
+func (u *Union) GenerateExample(r *RandomGenerator, seen []string) interface{} {
+	if len(u.Variants) == 0 {
+		return nil
+	}
+	v := u.Variants[r.Int()%len(u.Variants)]
+	ex := v.Type.GenerateExample(r, seen)
+	if m, ok := ex.(map[string]interface{}); ok && u.Discriminator != "" {
+		res := make(map[string]interface{}, len(m))
+		for k, val := range m {
+			res[k] = val
+		}
+		res[u.Discriminator] = u.DiscriminatorValue(v)
+		ex = res
+	}
+	return ex
+}
+
+// VariantName returns the name of the given variant, that is the name of its user type.
+func (u *Union) VariantName(v *AttributeDefinition) string {
+	if ut, ok := v.Type.(*UserTypeDefinition); ok {
+		return ut.TypeName
+	}
+	return v.Type.Name()
+}
+
+// DiscriminatorValue returns the value of the discriminator attribute that identifies the given
+// variant. This is the only value listed by the Enum validation of the variant discriminator
+// attribute if there is one, the name of the variant otherwise.
+func (u *Union) DiscriminatorValue(v *AttributeDefinition) string {
+	if o := v.Type.ToObject(); o != nil {
+		if att, ok := o[u.Discriminator]; ok && att.Validation != nil && len(att.Validation.Values) == 1 {
+			return fmt.Sprintf("%v", att.Validation.Values[0])
+		}
+	}
+	return u.VariantName(v)
+}
+
 // AttributeIterator is the type of the function given to IterateAttributes.
 type AttributeIterator func(string, *AttributeDefinition) error
 
@@ -578,6 +768,15 @@ func UserTypes(dt DataType) map[string]*UserTypeDefinition {
 			vtypes[n] = ut
 		}
 		return vtypes
+	case *Union:
+		types := make(map[string]*UserTypeDefinition)
+		for _, v := range actual.Variants {
+			v.Walk(collect(types))
+		}
+		if len(types) == 0 {
+			return nil
+		}
+		return types
 	case Object:
 		types := make(map[string]*UserTypeDefinition)
 		for _, att := range actual {
@@ -623,6 +822,12 @@ func hasFile(dt DataType, seen map[string]struct{}) bool {
 			return true
 		}
 		return hasFile(dt.ToHash().ElemType.Type, seen)
+	case dt.IsUnion():
+		for _, v := range dt.ToUnion().Variants {
+			if hasFile(v.Type, seen) {
+				return true
+			}
+		}
 	case dt.IsObject():
 		if _, ok := seen[dt.Name()]; ok {
 			return false
@@ -704,6 +909,9 @@ func (u *UserTypeDefinition) IsArray() bool { return u.Type != nil && u.Type.IsA
 // IsHash calls IsHash on the user type underlying data type.
 func (u *UserTypeDefinition) IsHash() bool { return u.Type != nil && u.Type.IsHash() }
 
+// IsUnion calls IsUnion on the user type underlying data type.
+func (u *UserTypeDefinition) IsUnion() bool { return u.Type != nil && u.Type.IsUnion() }
+
 // ToObject calls ToObject on the user type underlying data type.
 func (u *UserTypeDefinition) ToObject() Object { return u.Type.ToObject() }
 
@@ -713,6 +921,9 @@ func (u *UserTypeDefinition) ToArray() *Array { return u.Type.ToArray() }
 // ToHash calls ToHash on the user type underlying data type.
 func (u *UserTypeDefinition) ToHash() *Hash { return u.Type.ToHash() }
 
+// ToUnion calls ToUnion on the user type underlying data type.
+func (u *UserTypeDefinition) ToUnion() *Union { return u.Type.ToUnion() }
+
 // CanHaveDefault calls CanHaveDefault on the user type underlying data type.
 func (u *UserTypeDefinition) CanHaveDefault() bool { return u.Type.CanHaveDefault() }
 
@@ -1065,6 +1276,12 @@ func walk(at *AttributeDefinition, walker func(*AttributeDefinition) error, seen
 		return walkUt(actual)
 	case *MediaTypeDefinition:
 		return walkUt(actual.UserTypeDefinition)
+	case *Union:
+		for _, v := range actual.Variants {
+			if err := walk(v, walker, seen); err != nil {
+				return err
+			}
+		}
 	default:
 		panic("unknown attribute type") // bug
 	}
@@ -1080,7 +1297,7 @@ func toReflectType(dtype DataType) reflect.Type {
 		return reflect.TypeOf(int(0))
 	case NumberKind:
 		return reflect.TypeOf(float64(0))
-	case UUIDKind, StringKind:
+	case UUIDKind, StringKind, DateKind, DurationKind:
 		return reflect.TypeOf("")
 	case DateTimeKind:
 		return reflect.TypeOf(time.Time{})
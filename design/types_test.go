@@ -603,3 +603,140 @@ var _ = Describe("GenerateExample", func() {
 		})
 	})
 })
+
+var _ = Describe("Sized and encoded primitives", func() {
+	It("map to the JSON types", func() {
+		Ω(Int64.Name()).Should(Equal("integer"))
+		Ω(UInt64.Name()).Should(Equal("integer"))
+		Ω(Float32.Name()).Should(Equal("number"))
+		Ω(Bytes.Name()).Should(Equal("string"))
+		Ω(Date.Name()).Should(Equal("string"))
+		Ω(Duration.Name()).Should(Equal("string"))
+	})
+
+	It("check the compatibility of values", func() {
+		Ω(Int64.IsCompatible(-42)).Should(BeTrue())
+		Ω(Int64.IsCompatible(4.2)).Should(BeFalse())
+		Ω(UInt64.IsCompatible(42)).Should(BeTrue())
+		Ω(UInt64.IsCompatible(-42)).Should(BeFalse())
+		Ω(Float32.IsCompatible(4.2)).Should(BeTrue())
+		Ω(Float32.IsCompatible("4.2")).Should(BeFalse())
+		Ω(Bytes.IsCompatible([]byte("foo"))).Should(BeTrue())
+		Ω(Date.IsCompatible("2017-05-29")).Should(BeTrue())
+		Ω(Date.IsCompatible("2017-05-29T10:00:00Z")).Should(BeFalse())
+		Ω(Duration.IsCompatible("1h30m")).Should(BeTrue())
+		Ω(Duration.IsCompatible("forever")).Should(BeFalse())
+	})
+
+	It("generate compatible examples", func() {
+		rand := NewRandomGenerator("foo")
+		for _, p := range []Primitive{Int64, UInt64, Float32, Bytes, Date, Duration} {
+			Ω(p.IsCompatible(p.GenerateExample(rand, nil))).Should(BeTrue(), p.Name())
+		}
+	})
+
+	It("cannot have a bytes default value", func() {
+		Ω(Date.CanHaveDefault()).Should(BeTrue())
+		Ω(Bytes.CanHaveDefault()).Should(BeFalse())
+	})
+})
+
+var _ = Describe("Union", func() {
+	var cat, dog *UserTypeDefinition
+	var u *Union
+
+	BeforeEach(func() {
+		cat = &UserTypeDefinition{
+			TypeName: "Cat",
+			AttributeDefinition: &AttributeDefinition{Type: Object{
+				"kind": &AttributeDefinition{
+					Type:       String,
+					Validation: &dslengine.ValidationDefinition{Values: []interface{}{"cat"}},
+				},
+			}},
+		}
+		dog = &UserTypeDefinition{
+			TypeName:            "Dog",
+			AttributeDefinition: &AttributeDefinition{Type: Object{"kind": &AttributeDefinition{Type: String}}},
+		}
+		u = &Union{
+			Variants:      []*AttributeDefinition{{Type: cat}, {Type: dog}},
+			Discriminator: "kind",
+		}
+	})
+
+	It("is a union", func() {
+		ut := &UserTypeDefinition{TypeName: "Pet", AttributeDefinition: &AttributeDefinition{Type: u}}
+		Ω(ut.IsUnion()).Should(BeTrue())
+		Ω(ut.ToUnion()).Should(Equal(u))
+		Ω(ut.IsObject()).Should(BeFalse())
+		Ω(u.Kind()).Should(Equal(UnionKind))
+	})
+
+	It("computes the discriminator values", func() {
+		Ω(u.DiscriminatorValue(u.Variants[0])).Should(Equal("cat"))
+		Ω(u.DiscriminatorValue(u.Variants[1])).Should(Equal("Dog"))
+	})
+
+	It("generates examples that set the discriminator", func() {
+		ex := u.GenerateExample(NewRandomGenerator("union"), nil)
+		Ω(ex).Should(BeAssignableToTypeOf(map[string]interface{}{}))
+		kind := ex.(map[string]interface{})["kind"]
+		Ω([]interface{}{"cat", "Dog"}).Should(ContainElement(kind))
+	})
+
+	It("lists the variant user types", func() {
+		types := UserTypes(u)
+		Ω(types).Should(HaveLen(2))
+		Ω(types["Cat"]).Should(Equal(cat))
+		Ω(types["Dog"]).Should(Equal(dog))
+	})
+
+	Context("validation", func() {
+		var ut *UserTypeDefinition
+
+		JustBeforeEach(func() {
+			ut = &UserTypeDefinition{TypeName: "Pet", AttributeDefinition: &AttributeDefinition{Type: u}}
+		})
+
+		It("accepts variants that define the discriminator", func() {
+			Ω(ut.Validate("", nil)).ShouldNot(HaveOccurred())
+		})
+
+		Context("with variants using the same discriminator value", func() {
+			BeforeEach(func() {
+				dog.Type.ToObject()["kind"].Validation = &dslengine.ValidationDefinition{Values: []interface{}{"cat"}}
+			})
+
+			It("returns an error", func() {
+				err := ut.Validate("", nil)
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring(`variants Cat and Dog use the same discriminator value "cat"`))
+			})
+		})
+
+		Context("with a variant that is not a user type", func() {
+			BeforeEach(func() {
+				u.Variants[1] = &AttributeDefinition{Type: Object{}}
+			})
+
+			It("returns an error", func() {
+				err := ut.Validate("", nil)
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("union variant of type object must be a user type"))
+			})
+		})
+
+		Context("used to define an attribute", func() {
+			It("returns an error", func() {
+				parent := &UserTypeDefinition{
+					TypeName:            "Owner",
+					AttributeDefinition: &AttributeDefinition{Type: Object{"pet": &AttributeDefinition{Type: u}}},
+				}
+				err := parent.Validate("", nil)
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("OneOf can only be used to define user types"))
+			})
+		})
+	})
+})
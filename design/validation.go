@@ -228,6 +228,9 @@ func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
 	for _, origin := range r.Origins {
 		verr.Merge(origin.Validate())
 	}
+	if r.RateLimit != nil {
+		verr.Merge(r.RateLimit.Validate())
+	}
 	return verr.AsError()
 }
 
@@ -350,6 +353,25 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
+	if a.RateLimit != nil {
+		verr.Merge(a.RateLimit.Validate())
+	}
+	if a.Pagination != nil {
+		verr.Merge(a.Pagination.Validate())
+	}
+	if a.HasMessages() && !a.WebSocket() {
+		verr.Add(a, "websocket messages can only be defined on actions using the ws or wss schemes")
+	}
+	verr.Merge(a.validateMessage("inbound", a.InboundMessage))
+	if a.Idempotent {
+		if a.Safe() {
+			verr.Add(a, "Idempotent cannot be used on actions that only use safe HTTP methods")
+		}
+		if a.WebSocket() {
+			verr.Add(a, "Idempotent cannot be used on websocket actions")
+		}
+	}
+	verr.Merge(a.validateMessage("outbound", a.OutboundMessage))
 	if a.Params != nil {
 		for n, p := range a.Params.Type.ToObject() {
 			if p.Type.IsPrimitive() {
@@ -373,6 +395,21 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	return verr.AsError()
 }
 
+// validateMessage checks the type of the websocket messages of the action is an object with no file.
+func (a *ActionDefinition) validateMessage(name string, m DataType) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if m == nil {
+		return nil
+	}
+	if !m.IsObject() {
+		verr.Add(a, "%s message type must be an object, got %s", name, m.Name())
+	}
+	if HasFile(m) {
+		verr.Add(a, "%s message type cannot contain a file", name)
+	}
+	return verr.AsError()
+}
+
 // Validate checks the file server is properly initialized.
 func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -505,7 +542,60 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			verr.Merge(elemType.Validate(ctx, a))
 		}
 	}
+	if u, ok := a.Type.(*Union); ok {
+		if ut, ok := parent.(*UserTypeDefinition); !ok || ut.AttributeDefinition != a {
+			verr.Add(parent, "%sOneOf can only be used to define user types", ctx)
+		}
+		verr.Merge(u.Validate(ctx, parent))
+	}
+
+	return verr.AsError()
+}
 
+// Validate checks that the union variants are distinct user types and that each variant defines
+// the discriminator attribute as a string with a distinct value if the union is discriminated.
+func (u *Union) Validate(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if len(u.Variants) < 2 {
+		verr.Add(parent, "%sunion must define at least two variants", ctx)
+	}
+	names := make(map[string]bool)
+	values := make(map[string]string)
+	for _, v := range u.Variants {
+		ut, ok := v.Type.(*UserTypeDefinition)
+		if !ok {
+			verr.Add(parent, "%sunion variant of type %s must be a user type", ctx, v.Type.Name())
+			continue
+		}
+		if names[ut.TypeName] {
+			verr.Add(parent, "%sunion variant %s is listed more than once", ctx, ut.TypeName)
+			continue
+		}
+		names[ut.TypeName] = true
+		if ut.Type != nil && (ut.IsPrimitive() || ut.IsUnion()) {
+			verr.Add(parent, "%sunion variant %s must be an object, an array or a hash", ctx, ut.TypeName)
+			continue
+		}
+		if u.Discriminator == "" {
+			continue
+		}
+		o := ut.ToObject()
+		if o == nil {
+			verr.Add(parent, "%svariant %s of union discriminated by %#v must be an object", ctx, ut.TypeName, u.Discriminator)
+			continue
+		}
+		att, ok := o[u.Discriminator]
+		if !ok || att.Type.Kind() != StringKind {
+			verr.Add(parent, "%svariant %s must define the discriminator attribute %#v as a string", ctx, ut.TypeName, u.Discriminator)
+			continue
+		}
+		val := u.DiscriminatorValue(v)
+		if other, ok := values[val]; ok {
+			verr.Add(parent, "%svariants %s and %s use the same discriminator value %#v", ctx, other, ut.TypeName, val)
+			continue
+		}
+		values[val] = ut.TypeName
+	}
 	return verr.AsError()
 }
 
@@ -519,6 +609,9 @@ func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Status == 0 {
 		verr.Add(r, "response status not defined")
 	}
+	if r.Stream != nil && r.Status != 0 && (r.Status < 200 || r.Status > 299) {
+		verr.Add(r, "event stream responses must use a 2xx status, got %d", r.Status)
+	}
 	return verr.AsError()
 }
 
@@ -21,6 +21,9 @@ var (
 
 	// DSL package paths used to compute error locations (skip the frames in these packages)
 	dslPackages map[string]bool
+
+	// Locations of the DSL that created the definitions recorded with RecordLocation
+	locations map[Definition]location
 )
 
 type (
@@ -38,6 +41,12 @@ type (
 
 	// DSL evaluation contexts stack
 	contextStack []Definition
+
+	// location is the file and line of the DSL that created a definition.
+	location struct {
+		file string
+		line int
+	}
 )
 
 func init() {
@@ -72,6 +81,7 @@ func Reset() {
 		r.Reset()
 	}
 	Errors = nil
+	locations = nil
 }
 
 // Run runs the given root definitions. It iterates over the definition sets
@@ -244,29 +254,67 @@ func (s contextStack) Current() Definition {
 	return s[len(s)-1]
 }
 
+// RecordLocation records the location of the user code that creates the given definition. DSL
+// functions call RecordLocation when they create definitions so that tools such as linters can
+// report problems with file and line information. The location is computed the same way as the
+// location of DSL errors.
+func RecordLocation(def Definition) {
+	file, line := computeErrorLocation()
+	if file == "" {
+		return
+	}
+	if locations == nil {
+		locations = make(map[Definition]location)
+	}
+	locations[def] = location{file: file, line: line}
+}
+
+// Location returns the file and line of the user code that created the given definition as
+// recorded by RecordLocation, the empty string and 0 if not recorded.
+func Location(def Definition) (file string, line int) {
+	loc := locations[def]
+	return loc.file, loc.line
+}
+
 // computeErrorLocation implements a heuristic to find the location in the user
-// code where the error occurred. It walks back the callstack until the file
-// doesn't match "/goa/design/*.go" or one of the DSL package paths.
+// code where the error occurred. It walks back the callstack until the function
+// doesn't belong to one of the DSL packages. Packages are identified using the
+// function names rather than the file paths so that the DSL frames are skipped
+// regardless of where the packages are located (GOPATH, vendor or module cache).
 // When successful it returns the file name and line number, empty string and
 // 0 otherwise.
 func computeErrorLocation() (file string, line int) {
-	skipFunc := func(file string) bool {
-		if strings.HasSuffix(file, "_test.go") { // Be nice with tests
+	skipFrame := func(frame runtime.Frame) bool {
+		if strings.HasSuffix(frame.File, "_test.go") { // Be nice with tests
 			return false
 		}
-		file = filepath.ToSlash(file)
-		for pkg := range dslPackages {
-			if strings.Contains(file, pkg) {
+		pkg := funcPackage(frame.Function)
+		if i := strings.LastIndex(pkg, "/vendor/"); i >= 0 {
+			pkg = pkg[i+len("/vendor/"):]
+		}
+		pkg += "/"
+		for p := range dslPackages {
+			if strings.HasPrefix(pkg, strings.TrimSuffix(p, "/")+"/") {
 				return true
 			}
 		}
 		return false
 	}
-	depth := 2
-	_, file, line, _ = runtime.Caller(depth)
-	for skipFunc(file) {
-		depth++
-		_, file, line, _ = runtime.Caller(depth)
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !skipFrame(frame) {
+			file, line = frame.File, frame.Line
+			break
+		}
+		if !more {
+			return
+		}
+	}
+	if file == "" {
+		return
 	}
 	wd, err := os.Getwd()
 	if err != nil {
@@ -284,6 +332,17 @@ func computeErrorLocation() (file string, line int) {
 	return
 }
 
+// funcPackage returns the import path of the package of the function with the given fully
+// qualified name, e.g. "github.com/goadesign/goa/design/apidsl" for
+// "github.com/goadesign/goa/design/apidsl.Attribute.func1".
+func funcPackage(name string) string {
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
+
 // runSet executes the DSL for all definitions in the given set. The definition DSLs may append to
 // the set as they execute.
 func runSet(set DefinitionSet) error {
@@ -165,3 +165,31 @@ var _ = Describe("DSL errors", func() {
 		})
 	})
 })
+
+var _ = Describe("Definition locations", func() {
+	const lineNumber = 178
+
+	var api *APIDefinition
+	var res *ResourceDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		api = API("foo", func() {})
+		res = Resource("bar", func() {})
+	})
+
+	It("records the location of the DSL that creates the definitions", func() {
+		file, line := dslengine.Location(res)
+		Ω(file).Should(HaveSuffix("runner_test.go"))
+		Ω(line).Should(Equal(lineNumber))
+		file, _ = dslengine.Location(api)
+		Ω(file).Should(HaveSuffix("runner_test.go"))
+	})
+
+	It("forgets the locations on reset", func() {
+		dslengine.Reset()
+		file, line := dslengine.Location(res)
+		Ω(file).Should(BeEmpty())
+		Ω(line).Should(Equal(0))
+	})
+})
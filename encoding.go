@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"mime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -65,9 +67,22 @@ type (
 	// HTTPEncoder is a Encoder that encodes HTTP request or response bodies given a set of
 	// known Content-Type to encoder mapping.
 	HTTPEncoder struct {
+		// Strict causes the encoder to fail with ErrNotAcceptable when the Accept header
+		// does not match any registered content type instead of falling back to the default
+		// encoder.
+		Strict bool
+
 		pools        map[string]*encoderPool // Registered encoders
 		contentTypes []string                // List of content types for type negotiation
 	}
+
+	// mediaRange is a single media range of an Accept header as defined in RFC 7231 section
+	// 5.3.2.
+	mediaRange struct {
+		typ, sub string  // Media type and subtype, either may be "*"
+		q        float64 // Quality value
+		pos      int     // Position in Accept header, used to break ties
+	}
 )
 
 // NewJSONEncoder is an adapter for the encoding package JSON encoder.
@@ -182,20 +197,66 @@ func (p *decoderPool) Put(d Decoder) {
 	p.pool.Put(d)
 }
 
-// Encode uses the registered encoders and given content type to marshal and write the given value
-// using the given writer.
+// Encode uses the registered encoders and given Accept header value to marshal and write the
+// given value using the given writer. See Negotiate for a description of how the encoder is
+// selected. If no registered content type matches the Accept header then Encode uses the default
+// encoder unless the HTTPEncoder is strict in which case it returns an ErrNotAcceptable error.
 func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string) error {
-	now := time.Now()
-	if accept == "" {
-		accept = "*/*"
-	}
-	var contentType string
-	for _, t := range encoder.contentTypes {
-		if accept == "*/*" || accept == t {
-			contentType = accept
-			break
+	contentType, ok := encoder.Negotiate(accept)
+	if !ok {
+		if encoder.Strict {
+			return ErrNotAcceptable("no encoder matches the request Accept header", "accept", accept)
+		}
+		contentType = "*/*"
+	}
+	return encoder.encode(v, resp, contentType)
+}
+
+// Negotiate returns the registered content type that best matches the given Accept header value
+// following the content negotiation rules of RFC 7231 section 5.3.2. Media ranges are ordered by
+// quality value, then by specificity (application/json is more specific than application/* which
+// is more specific than */*) and finally by position in the header. A media range that uses a
+// structured syntax suffix such as application/vnd.goa.error+json also matches the corresponding
+// content type (application/json here).
+// Negotiate returns "*/*" when the best match is the "*/*" media range and a default encoder is
+// registered. An empty Accept header is equivalent to "*/*". The second return value is false if
+// no registered content type is acceptable.
+func (encoder *HTTPEncoder) Negotiate(accept string) (string, bool) {
+	ranges := parseAccept(accept)
+	var (
+		best              string
+		bestQ             float64
+		bestSpec, bestPos int
+	)
+	for _, ct := range encoder.contentTypes {
+		if ct == "*/*" {
+			continue
+		}
+		q, spec, pos := matchRanges(ranges, ct)
+		if spec == 0 || q <= 0 {
+			continue
+		}
+		if best == "" || q > bestQ || q == bestQ && (spec > bestSpec || spec == bestSpec && pos < bestPos) {
+			best, bestQ, bestSpec, bestPos = ct, q, spec, pos
 		}
 	}
+	if best != "" && bestSpec > 1 {
+		return best, true
+	}
+	if _, ok := encoder.pools["*/*"]; ok {
+		for _, r := range ranges {
+			if r.typ == "*" && r.sub == "*" && r.q > 0 {
+				return "*/*", true
+			}
+		}
+	}
+	return best, best != ""
+}
+
+// encode marshals and writes v using the encoder registered for contentType or the default
+// encoder if there is none.
+func (encoder *HTTPEncoder) encode(v interface{}, resp io.Writer, contentType string) error {
+	now := time.Now()
 	defer MeasureSince([]string{"goa", "encode", contentType}, now)
 	p := encoder.pools[contentType]
 	if p == nil && contentType != "*/*" {
@@ -224,14 +285,12 @@ func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
 		if err != nil {
 			mediaType = contentType
 		}
+		// Keep track of registration order so that negotiation is deterministic
+		if _, ok := encoder.pools[mediaType]; !ok {
+			encoder.contentTypes = append(encoder.contentTypes, mediaType)
+		}
 		encoder.pools[mediaType] = p
 	}
-
-	// Rebuild a unique index of registered content encoders to be used in EncodeResponse
-	encoder.contentTypes = make([]string, 0, len(encoder.pools))
-	for contentType := range encoder.pools {
-		encoder.contentTypes = append(encoder.contentTypes, contentType)
-	}
 }
 
 // newEncodePool checks to see if the EncoderFactory returns reusable encoders and if so, creates
@@ -272,3 +331,76 @@ func (p *encoderPool) Put(e Encoder) {
 	}
 	p.pool.Put(e)
 }
+
+// parseAccept parses the media ranges listed in the given Accept header value. Malformed media
+// ranges are ignored. An empty value is equivalent to "*/*".
+func parseAccept(accept string) []*mediaRange {
+	if strings.TrimSpace(accept) == "" {
+		return []*mediaRange{{typ: "*", sub: "*", q: 1}}
+	}
+	var ranges []*mediaRange
+	for _, elem := range strings.Split(accept, ",") {
+		elem = strings.TrimSpace(elem)
+		if elem == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(elem)
+		if err != nil {
+			continue
+		}
+		if mediaType == "*" {
+			mediaType = "*/*"
+		}
+		idx := strings.Index(mediaType, "/")
+		if idx < 1 || idx == len(mediaType)-1 {
+			continue
+		}
+		r := &mediaRange{typ: mediaType[:idx], sub: mediaType[idx+1:], q: 1, pos: len(ranges)}
+		if r.typ == "*" && r.sub != "*" {
+			continue
+		}
+		if qv, ok := params["q"]; ok {
+			q, err := strconv.ParseFloat(qv, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+			r.q = q
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// matchRanges returns the quality value, specificity and position of the most specific media
+// range that matches the given content type. The specificity is 0 if no range matches.
+func matchRanges(ranges []*mediaRange, contentType string) (q float64, spec, pos int) {
+	for _, r := range ranges {
+		if s := r.match(contentType); s > spec {
+			q, spec, pos = r.q, s, r.pos
+		}
+	}
+	return
+}
+
+// match returns the specificity of the match between the media range and the given content type:
+// 4 for an exact match, 3 for a structured syntax suffix match, 2 for a subtype wildcard match, 1
+// for a full wildcard match and 0 if the range does not match.
+func (r *mediaRange) match(contentType string) int {
+	if r.typ == "*" {
+		return 1
+	}
+	idx := strings.Index(contentType, "/")
+	if idx < 0 || r.typ != contentType[:idx] {
+		return 0
+	}
+	sub := contentType[idx+1:]
+	switch {
+	case r.sub == "*":
+		return 2
+	case r.sub == sub:
+		return 4
+	case strings.HasSuffix(r.sub, "+"+sub):
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,241 @@
+package goa_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPEncoder", func() {
+	var encoder *goa.HTTPEncoder
+
+	BeforeEach(func() {
+		encoder = goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, "application/json")
+		encoder.Register(goa.NewXMLEncoder, "application/xml", "text/xml")
+	})
+
+	Describe("Negotiate", func() {
+		var accept string
+		var contentType string
+		var ok bool
+
+		JustBeforeEach(func() {
+			contentType, ok = encoder.Negotiate(accept)
+		})
+
+		Context("with an exact match", func() {
+			BeforeEach(func() {
+				accept = "application/xml"
+			})
+
+			It("selects the matching content type", func() {
+				Ω(ok).Should(BeTrue())
+				Ω(contentType).Should(Equal("application/xml"))
+			})
+		})
+
+		Context("with multiple media ranges and quality values", func() {
+			BeforeEach(func() {
+				accept = "application/json;q=0.9, application/xml"
+			})
+
+			It("selects the content type with the highest quality", func() {
+				Ω(ok).Should(BeTrue())
+				Ω(contentType).Should(Equal("application/xml"))
+			})
+		})
+
+		Context("with equal quality values", func() {
+			BeforeEach(func() {
+				accept = "text/*, application/json"
+			})
+
+			It("selects the most specific media range", func() {
+				Ω(ok).Should(BeTrue())
+				Ω(contentType).Should(Equal("application/json"))
+			})
+		})
+
+		Context("with a subtype wildcard", func() {
+			BeforeEach(func() {
+				accept = "application/msgpack, text/*;q=0.5"
+			})
+
+			It("selects a matching content type", func() {
+				Ω(ok).Should(BeTrue())
+				Ω(contentType).Should(Equal("text/xml"))
+			})
+		})
+
+		Context("with a structured syntax suffix", func() {
+			BeforeEach(func() {
+				accept = "application/vnd.goa.example+json"
+			})
+
+			It("selects the suffix content type", func() {
+				Ω(ok).Should(BeTrue())
+				Ω(contentType).Should(Equal("application/json"))
+			})
+		})
+
+		Context("with a zero quality value", func() {
+			BeforeEach(func() {
+				accept = "application/json;q=0, application/*;q=0.5"
+			})
+
+			It("excludes the content type", func() {
+				Ω(ok).Should(BeTrue())
+				Ω(contentType).Should(Equal("application/xml"))
+			})
+		})
+
+		Context("with no match", func() {
+			BeforeEach(func() {
+				accept = "application/msgpack"
+			})
+
+			It("returns false", func() {
+				Ω(ok).Should(BeFalse())
+			})
+		})
+
+		Context("with an empty Accept header", func() {
+			BeforeEach(func() {
+				accept = ""
+			})
+
+			It("selects the first registered content type", func() {
+				Ω(ok).Should(BeTrue())
+				Ω(contentType).Should(Equal("application/json"))
+			})
+
+			Context("and a default encoder", func() {
+				BeforeEach(func() {
+					encoder.Register(goa.NewJSONEncoder, "*/*")
+				})
+
+				It("selects the default encoder", func() {
+					Ω(ok).Should(BeTrue())
+					Ω(contentType).Should(Equal("*/*"))
+				})
+			})
+		})
+	})
+
+	Describe("Encode", func() {
+		var accept string
+		var buf *bytes.Buffer
+		var err error
+
+		BeforeEach(func() {
+			buf = new(bytes.Buffer)
+			encoder.Register(goa.NewJSONEncoder, "*/*")
+		})
+
+		JustBeforeEach(func() {
+			err = encoder.Encode(map[string]int{"a": 1}, buf, accept)
+		})
+
+		Context("with an unknown content type", func() {
+			BeforeEach(func() {
+				accept = "application/msgpack"
+			})
+
+			It("uses the default encoder", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(buf.String()).Should(Equal(`{"a":1}` + "\n"))
+			})
+
+			Context("and a strict encoder", func() {
+				BeforeEach(func() {
+					encoder.Strict = true
+				})
+
+				It("returns a not acceptable error", func() {
+					Ω(err).Should(HaveOccurred())
+					Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(406))
+					Ω(buf.Len()).Should(Equal(0))
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("Send", func() {
+	var s *goa.Service
+	var rw *TestResponseWriter
+	var req *http.Request
+	var ctx context.Context
+	var status int
+	var err error
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.Encoder.Register(goa.NewJSONEncoder, "application/json")
+		s.Encoder.Register(goa.NewXMLEncoder, "application/xml")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		req, _ = http.NewRequest("GET", "/", nil)
+		status = 200
+	})
+
+	JustBeforeEach(func() {
+		ctx = goa.NewContext(context.Background(), rw, req, nil)
+		err = s.Send(ctx, status, "ok")
+	})
+
+	Context("with a matching Accept header", func() {
+		BeforeEach(func() {
+			req.Header.Set("Accept", "application/json;q=0.5, application/xml")
+		})
+
+		It("sets the Content-Type header", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/xml"))
+			Ω(string(rw.Body)).Should(Equal("<string>ok</string>"))
+		})
+
+		Context("and a compatible Content-Type header", func() {
+			BeforeEach(func() {
+				req.Header.Set("Accept", "application/json")
+				rw.ParentHeader.Set("Content-Type", "application/vnd.goa.example+json")
+			})
+
+			It("keeps the Content-Type header", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/vnd.goa.example+json"))
+			})
+		})
+	})
+
+	Context("with a strict encoder and no match", func() {
+		BeforeEach(func() {
+			s.Encoder.Strict = true
+			req.Header.Set("Accept", "application/msgpack")
+		})
+
+		It("does not write the response", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(406))
+			Ω(rw.Status).Should(Equal(0))
+		})
+
+		Context("sending an error response", func() {
+			BeforeEach(func() {
+				status = 406
+			})
+
+			It("uses the default encoder", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(406))
+				Ω(string(rw.Body)).Should(Equal(`"ok"` + "\n"))
+			})
+		})
+	})
+})
@@ -73,6 +73,23 @@ var (
 	// handler but not the HTTP method.
 	ErrMethodNotAllowed = NewErrorClass("method_not_allowed", 405)
 
+	// ErrNotAcceptable is the error produced by strict encoders when the request Accept header
+	// does not match any registered encoder.
+	ErrNotAcceptable = NewErrorClass("not_acceptable", 406)
+
+	// ErrConflict is the error produced when a request conflicts with the current state of the
+	// target resource, for example when it reuses the idempotency key of a request still in
+	// progress.
+	ErrConflict = NewErrorClass("conflict", 409)
+
+	// ErrUnprocessableEntity is the error produced when a request is well formed but cannot be
+	// processed, for example when it reuses an idempotency key with a different payload.
+	ErrUnprocessableEntity = NewErrorClass("unprocessable_entity", 422)
+
+	// ErrTooManyRequests is the error produced by rate limiting middlewares when a client
+	// exceeds its allowed request rate.
+	ErrTooManyRequests = NewErrorClass("too_many_requests", 429)
+
 	// ErrInternal is the class of error used for uncaught errors.
 	ErrInternal = NewErrorClass("internal", 500)
 )
@@ -227,7 +244,12 @@ func InvalidRangeError(ctx string, target interface{}, value interface{}, min bo
 	if !min {
 		comp = "less than or equal to"
 	}
-	msg := fmt.Sprintf("%s must be %s %v but got value %#v", ctx, comp, value, target)
+	format := "%s must be %s %v but got value %#v"
+	switch target.(type) {
+	case uint, uint8, uint16, uint32, uint64:
+		format = "%s must be %s %v but got value %d" // %#v prints unsigned integers in hexadecimal
+	}
+	msg := fmt.Sprintf(format, ctx, comp, value, target)
 	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
 }
 
@@ -242,6 +264,15 @@ func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool)
 	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
 }
 
+// InvalidUnionError is the error produced when a value does not hold exactly one of the variants
+// of the union defined in the design. count is the number of variants the value holds or
+// matches.
+func InvalidUnionError(ctx string, variants []string, count int) error {
+	expected := strings.Join(variants, ", ")
+	msg := fmt.Sprintf("%s must be exactly one of %s but matches %d of them", ctx, expected, count)
+	return ErrInvalidRequest(msg, "attribute", ctx, "expected", expected, "matches", count)
+}
+
 // NoAuthMiddleware is the error produced when goa is unable to lookup a auth middleware for a
 // security scheme defined in the design.
 func NoAuthMiddleware(schemeName string) error {
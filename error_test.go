@@ -267,6 +267,34 @@ var _ = Describe("InvalidRangeError", func() {
 			Ω(err.Detail).Should(ContainSubstring(target))
 		})
 	})
+
+	Context("with an unsigned integer target", func() {
+		It("prints the target in decimal", func() {
+			err := InvalidRangeError(ctx, uint64(20), 10, false).(*ErrorResponse)
+			Ω(err.Detail).Should(HaveSuffix("but got value 20"))
+		})
+	})
+})
+
+var _ = Describe("InvalidUnionError", func() {
+	const ctx = "ctx"
+	var variants = []string{"Cat", "Dog"}
+	const count = 2
+
+	var valErr error
+
+	BeforeEach(func() {
+		valErr = InvalidUnionError(ctx, variants, count)
+	})
+
+	It("creates a http error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(ContainSubstring(ctx))
+		Ω(err.Detail).Should(ContainSubstring("Cat, Dog"))
+		Ω(err.Detail).Should(ContainSubstring("matches 2"))
+	})
 })
 
 var _ = Describe("InvalidLengthError", func() {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"text/template"
+	"time"
 
 	"github.com/goadesign/goa/design"
 )
@@ -81,7 +82,7 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 			}
 			a := f.recurse(root, catt, fmt.Sprintf("%s.%s", target, Goify(n, true)), depth+1).String()
 			if a != "" {
-				if catt.Type.IsObject() {
+				if catt.Type.IsObject() || catt.Type.IsUnion() {
 					a = fmt.Sprintf("%sif %s.%s != nil {\n%s\n%s}",
 						Tabs(depth), target, Goify(n, true), a, Tabs(depth))
 				}
@@ -103,6 +104,20 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 		if as := RunTemplate(f.arrayAssignmentT, data); as != "" {
 			buf.WriteString(as)
 		}
+	} else if u := att.Type.ToUnion(); u != nil {
+		for _, v := range u.Variants {
+			field := fmt.Sprintf("%s.%s", target, Goify(u.VariantName(v), true))
+			a := f.recurse(root, v, field, depth+1).String()
+			if a != "" {
+				a = fmt.Sprintf("%sif %s != nil {\n%s\n%s}", Tabs(depth), field, a, Tabs(depth))
+				if !first {
+					buf.WriteByte('\n')
+				} else {
+					first = false
+				}
+				buf.WriteString(a)
+			}
+		}
 	}
 	return buf
 }
@@ -123,6 +138,20 @@ func PrintVal(t design.DataType, val interface{}) string {
 			s = fmt.Sprintf("%f", v)
 		case design.DateTime:
 			s = fmt.Sprintf("time.Parse(time.RFC3339, %s)", s)
+		case design.Int64, design.UInt64:
+			s = fmt.Sprintf("%s(%s)", GoNativeType(t), s)
+		case design.Float32:
+			v := val
+			if i, ok := val.(int); ok {
+				v = float64(i)
+			}
+			s = fmt.Sprintf("float32(%f)", v)
+		case design.Date:
+			d, _ := time.Parse("2006-01-02", val.(string))
+			s = fmt.Sprintf("goa.Date{Time: time.Date(%d, %d, %d, 0, 0, 0, 0, time.UTC)}", d.Year(), d.Month(), d.Day())
+		case design.Duration:
+			d, _ := time.ParseDuration(val.(string))
+			s = fmt.Sprintf("goa.Duration(%d)", d)
 		}
 		return s
 	case t.IsHash():
@@ -137,6 +137,32 @@ var _ = Describe("Struct finalize code generation", func() {
 		})
 	})
 
+	Context("given sized and encoded primitive fields", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{
+				Type: &design.Object{
+					"day": &design.AttributeDefinition{
+						Type:         design.Date,
+						DefaultValue: "2017-05-29",
+					},
+					"size": &design.AttributeDefinition{
+						Type:         design.Int64,
+						DefaultValue: 42,
+					},
+					"ttl": &design.AttributeDefinition{
+						Type:         design.Duration,
+						DefaultValue: "1h30m",
+					},
+				},
+			}
+			target = "ut"
+		})
+		It("finalizes the fields", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(Equal(sizedAssignmentCode))
+		})
+	})
+
 	Context("given a recursive user type", func() {
 		BeforeEach(func() {
 			var (
@@ -213,6 +239,19 @@ if ut.Foo == nil {
 	ut.Foo = &defaultFoo
 }`
 
+	sizedAssignmentCode = `var defaultDay = goa.Date{Time: time.Date(2017, 5, 29, 0, 0, 0, 0, time.UTC)}
+if ut.Day == nil {
+	ut.Day = &defaultDay
+}
+var defaultSize = int64(42)
+if ut.Size == nil {
+	ut.Size = &defaultSize
+}
+var defaultTTL = goa.Duration(5400000000000)
+if ut.TTL == nil {
+	ut.TTL = &defaultTTL
+}`
+
 	recursiveAssignmentCodeA = `if ut.Child != nil {
 	var defaultOther = "foo"
 	if ut.Child.Other == nil {
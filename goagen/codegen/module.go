@@ -0,0 +1,258 @@
+package codegen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Module represents a Go module as described by a go.mod file.
+	Module struct {
+		// Path is the module import path as declared by the "module" directive.
+		Path string
+		// Dir is the absolute path to the directory containing the go.mod file.
+		Dir string
+		// Replace lists the module replace directives.
+		Replace []*ModuleReplace
+	}
+
+	// ModuleReplace represents a single go.mod replace directive.
+	ModuleReplace struct {
+		// Old is the path of the replaced module.
+		Old string
+		// New is the replacement module path or, if the replacement is a
+		// local directory, the absolute path to that directory.
+		New string
+		// Local is true if the replacement is a local directory.
+		Local bool
+	}
+)
+
+// ModulesEnabled returns true unless module support is explicitly turned off
+// via the GO111MODULE environment variable.
+func ModulesEnabled() bool {
+	return os.Getenv("GO111MODULE") != "off"
+}
+
+// ModuleFor returns the Go module enclosing the given path. It returns nil if
+// the path does not live under a directory containing a go.mod file or if
+// module support is disabled.
+func ModuleFor(path string) (*Module, error) {
+	if !ModulesEnabled() {
+		return nil, nil
+	}
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		dir = path
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		gomod := filepath.Join(dir, "go.mod")
+		if fi, err := os.Stat(gomod); err == nil && !fi.IsDir() {
+			return ParseModule(gomod)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// ParseModule reads the module path and replace directives of the given go.mod
+// file.
+func ParseModule(gomod string) (*Module, error) {
+	content, err := ioutil.ReadFile(gomod)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(filepath.Dir(gomod))
+	if err != nil {
+		dir = filepath.Dir(gomod)
+	}
+	m := &Module{Dir: dir}
+	var block string
+	s := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		if i := strings.Index(text, "//"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("%s:%d: invalid module directive", gomod, line)
+			}
+			m.Path = unquote(fields[1])
+		case "replace":
+			r, err := parseReplace(dir, fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", gomod, line, err)
+			}
+			m.Replace = append(m.Replace, r)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if m.Path == "" {
+		return nil, fmt.Errorf("%s: missing module directive", gomod)
+	}
+	return m, nil
+}
+
+// PackagePath returns the Go import path of the package in the given directory.
+// The directory must live under the module root directory.
+func (m *Module) PackagePath(dir string) (string, error) {
+	rel, err := filepath.Rel(m.Dir, dir)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s is not in module %s (%s)", dir, m.Path, m.Dir)
+	}
+	if rel == "." {
+		return m.Path, nil
+	}
+	return path.Join(m.Path, rel), nil
+}
+
+// PackageDir returns the absolute path to the source directory of the package
+// with the given import path. It resolves packages that belong to the module
+// itself, to a local replace directive or to the module vendor directory and
+// falls back to "go list" for packages stored in the module cache.
+func (m *Module) PackageDir(pkg string) (string, error) {
+	if dir, ok := subdir(m.Path, pkg, m.Dir); ok {
+		return existingDir(dir, pkg)
+	}
+	var best *ModuleReplace
+	for _, r := range m.Replace {
+		if !r.Local || !hasPathPrefix(pkg, r.Old) {
+			continue
+		}
+		if best == nil || len(r.Old) > len(best.Old) {
+			best = r
+		}
+	}
+	if best != nil {
+		dir, _ := subdir(best.Old, pkg, best.New)
+		return existingDir(dir, pkg)
+	}
+	vendor := filepath.Join(m.Dir, "vendor", filepath.FromSlash(pkg))
+	if fi, err := os.Stat(vendor); err == nil && fi.IsDir() {
+		return vendor, nil
+	}
+	return m.listDir(pkg)
+}
+
+// listDir runs "go list" in the module root directory to locate the package.
+func (m *Module) listDir(pkg string) (string, error) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		return "", fmt.Errorf(`failed to find a go compiler, looked in "%s"`, os.Getenv("PATH"))
+	}
+	c := exec.Cmd{
+		Path: gobin,
+		Args: []string{gobin, "list", "-f", "{{.Dir}}", pkg},
+		Dir:  m.Dir,
+	}
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("cannot find package %q in module %s: %s", pkg, m.Path, msg)
+		}
+		return "", fmt.Errorf("cannot find package %q in module %s: %s", pkg, m.Path, err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if dir == "" {
+		return "", fmt.Errorf("cannot find package %q in module %s", pkg, m.Path)
+	}
+	return dir, nil
+}
+
+// parseReplace parses the arguments of a replace directive, relative paths are
+// resolved against the module directory.
+func parseReplace(dir string, args []string) (*ModuleReplace, error) {
+	arrow := -1
+	for i, a := range args {
+		if a == "=>" {
+			arrow = i
+			break
+		}
+	}
+	if arrow < 1 || arrow > 2 || len(args)-arrow-1 < 1 || len(args)-arrow-1 > 2 {
+		return nil, fmt.Errorf("invalid replace directive")
+	}
+	r := &ModuleReplace{Old: unquote(args[0]), New: unquote(args[arrow+1])}
+	if len(args)-arrow-1 == 1 && isLocalPath(r.New) {
+		r.Local = true
+		if !filepath.IsAbs(r.New) {
+			r.New = filepath.Join(dir, filepath.FromSlash(r.New))
+		}
+	}
+	return r, nil
+}
+
+// isLocalPath returns true if the replacement path denotes a directory.
+func isLocalPath(p string) bool {
+	return filepath.IsAbs(p) || p == "." || p == ".." ||
+		strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") ||
+		strings.HasPrefix(p, `.\`) || strings.HasPrefix(p, `..\`)
+}
+
+// hasPathPrefix returns true if pkg is prefix or a sub-package of prefix.
+func hasPathPrefix(pkg, prefix string) bool {
+	return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+}
+
+// subdir returns the directory of pkg given the directory root of the package
+// with import path prefix.
+func subdir(prefix, pkg, root string) (string, bool) {
+	if !hasPathPrefix(pkg, prefix) {
+		return "", false
+	}
+	return filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(pkg, prefix))), true
+}
+
+// existingDir returns dir if it exists, an error otherwise.
+func existingDir(dir, pkg string) (string, error) {
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return "", fmt.Errorf("cannot find package %q in %s", pkg, dir)
+	}
+	return dir, nil
+}
+
+// unquote removes the quotes surrounding go.mod string literals if any.
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
@@ -0,0 +1,187 @@
+package codegen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Module", func() {
+	var root string
+	var gomod string
+	var mode string
+
+	BeforeEach(func() {
+		mode = os.Getenv("GO111MODULE")
+		os.Setenv("GO111MODULE", "on")
+		var err error
+		root, err = ioutil.TempDir("", "module")
+		Ω(err).ShouldNot(HaveOccurred())
+		root, err = filepath.EvalSymlinks(root)
+		Ω(err).ShouldNot(HaveOccurred())
+		gomod = `module example.com/app // the app
+
+go 1.12
+
+require (
+	github.com/goadesign/goa v1.4.0
+	example.com/lib v1.0.0
+)
+
+replace example.com/lib => ../lib
+
+replace (
+	example.com/remote v1.0.0 => example.com/fork v1.1.0
+	"example.com/other" => ./other
+)
+`
+	})
+
+	JustBeforeEach(func() {
+		mkdir(root, "app", "design")
+		mkdir(root, "app", "other", "pkg")
+		mkdir(root, "app", "vendor", "github.com", "goadesign", "goa", "design")
+		mkdir(root, "lib", "sub")
+		err := ioutil.WriteFile(filepath.Join(root, "app", "go.mod"), []byte(gomod), 0644)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.Setenv("GO111MODULE", mode)
+		os.RemoveAll(root)
+	})
+
+	Describe("ModuleFor", func() {
+		It("finds the enclosing module", func() {
+			m, err := codegen.ModuleFor(filepath.Join(root, "app", "design", "design.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m).ShouldNot(BeNil())
+			Ω(m.Path).Should(Equal("example.com/app"))
+			Ω(m.Dir).Should(Equal(filepath.Join(root, "app")))
+		})
+
+		It("parses the replace directives", func() {
+			m, err := codegen.ModuleFor(filepath.Join(root, "app"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m.Replace).Should(Equal([]*codegen.ModuleReplace{
+				{Old: "example.com/lib", New: filepath.Join(root, "lib"), Local: true},
+				{Old: "example.com/remote", New: "example.com/fork"},
+				{Old: "example.com/other", New: filepath.Join(root, "app", "other"), Local: true},
+			}))
+		})
+
+		It("returns nil outside of a module", func() {
+			m, err := codegen.ModuleFor(filepath.Join(root, "lib", "sub"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m).Should(BeNil())
+		})
+
+		Context("with modules disabled", func() {
+			var mode string
+
+			BeforeEach(func() {
+				mode = os.Getenv("GO111MODULE")
+				os.Setenv("GO111MODULE", "off")
+			})
+
+			AfterEach(func() {
+				os.Setenv("GO111MODULE", mode)
+			})
+
+			It("returns nil", func() {
+				m, err := codegen.ModuleFor(filepath.Join(root, "app"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(m).Should(BeNil())
+			})
+		})
+
+		Context("with a go.mod file missing the module directive", func() {
+			BeforeEach(func() {
+				gomod = "go 1.12\n"
+			})
+
+			It("returns an error", func() {
+				_, err := codegen.ModuleFor(filepath.Join(root, "app"))
+				Ω(err).Should(MatchError(ContainSubstring("missing module directive")))
+			})
+		})
+	})
+
+	Describe("PackagePath", func() {
+		It("computes the import path from the module path", func() {
+			p, err := codegen.PackagePath(filepath.Join(root, "app", "design"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p).Should(Equal("example.com/app/design"))
+		})
+
+		It("returns the module path for the module root", func() {
+			p, err := codegen.PackagePath(filepath.Join(root, "app"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p).Should(Equal("example.com/app"))
+		})
+	})
+
+	Describe("PackageDir", func() {
+		var m *codegen.Module
+
+		JustBeforeEach(func() {
+			var err error
+			m, err = codegen.ModuleFor(filepath.Join(root, "app"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("resolves packages of the module", func() {
+			dir, err := m.PackageDir("example.com/app/design")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(dir).Should(Equal(filepath.Join(root, "app", "design")))
+		})
+
+		It("resolves packages of replaced modules", func() {
+			dir, err := m.PackageDir("example.com/lib/sub")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(dir).Should(Equal(filepath.Join(root, "lib", "sub")))
+		})
+
+		It("prefers the longest matching replace directive", func() {
+			m.Replace = append(m.Replace, &codegen.ModuleReplace{
+				Old:   "example.com/app/other",
+				New:   filepath.Join(root, "app", "other"),
+				Local: true,
+			})
+			dir, err := m.PackageDir("example.com/app/other/pkg")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(dir).Should(Equal(filepath.Join(root, "app", "other", "pkg")))
+		})
+
+		It("resolves vendored packages", func() {
+			dir, err := m.PackageDir("github.com/goadesign/goa/design")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(dir).Should(Equal(filepath.Join(root, "app", "vendor", "github.com", "goadesign", "goa", "design")))
+		})
+
+		It("fails for missing packages of the module", func() {
+			_, err := m.PackageDir("example.com/app/missing")
+			Ω(err).Should(MatchError(HavePrefix(`cannot find package "example.com/app/missing"`)))
+		})
+	})
+
+	Describe("PackageFor", func() {
+		It("returns a package rooted in the module", func() {
+			source := filepath.Join(root, "app", "design", "design.go")
+			p, err := codegen.PackageFor(source)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p.Path).Should(Equal("example.com/app/design"))
+			Ω(p.Workspace.Module).ShouldNot(BeNil())
+			Ω(p.Abs()).Should(Equal(filepath.Join(root, "app", "design")))
+		})
+	})
+})
+
+func mkdir(elems ...string) {
+	err := os.MkdirAll(filepath.Join(elems...), 0755)
+	Ω(err).ShouldNot(HaveOccurred())
+}
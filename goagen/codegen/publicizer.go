@@ -66,6 +66,21 @@ func RecursivePublicizer(att *design.AttributeDefinition, source, target string,
 			publications = append(publications, publication)
 			return nil
 		})
+	} else if u := att.Type.ToUnion(); u != nil {
+		for _, v := range u.Variants {
+			field := Goify(u.VariantName(v), true)
+			publication := Publicizer(
+				v,
+				fmt.Sprintf("%s.%s", source, field),
+				fmt.Sprintf("%s.%s", target, field),
+				false,
+				depth+1,
+				false,
+			)
+			publication = fmt.Sprintf("%sif %s.%s != nil {\n%s\n%s}",
+				Tabs(depth), source, field, publication, Tabs(depth))
+			publications = append(publications, publication)
+		}
 	}
 	return strings.Join(publications, "\n")
 }
@@ -92,6 +107,8 @@ func Publicizer(att *design.AttributeDefinition, sourceField, targetField string
 		} else {
 			publication = RunTemplate(objectPublicizeT, data)
 		}
+	case att.Type.IsUnion():
+		publication = RunTemplate(recursivePublicizeT, data)
 	case att.Type.IsArray():
 		// If the array element is primitive type, we can simply copy the elements over (i.e) []string
 		if att.Type.HasAttributes() {
@@ -131,11 +148,11 @@ const (
 */}}{{ $k := printf "%s%d" "k" .depth }}{{ $v := printf "%s%d" "v" .depth }}
 {{ tabs .depth }}for {{ $k }}, {{ $v }} := range {{ .sourceField }} {
 {{ $pubk := printf "%s%s" "pub" $k }}{{ $pubv := printf "%s%s" "pub" $v }}{{/*
-*/}}{{ tabs (add .depth 1) }}{{ if .keyType.Type.IsObject }}var {{ $pubk }} {{ gotyperef .keyType.Type .AllRequired .depth false}}
+*/}}{{ tabs (add .depth 1) }}{{ if or .keyType.Type.IsObject .keyType.Type.IsUnion }}var {{ $pubk }} {{ gotyperef .keyType.Type .AllRequired .depth false}}
 {{ tabs (add .depth 1) }}if {{ $k }} != nil {
 {{ tabs (add .depth 1) }}{{ publicizer .keyType $k $pubk .dereference (add .depth 1) false }}
 {{ tabs (add .depth 1) }}}{{ else }}{{ publicizer .keyType $k $pubk .dereference (add .depth 1) true }}{{ end }}
-{{ tabs (add .depth 1) }}{{if .elemType.Type.IsObject }}var {{ $pubv }} {{ gotyperef .elemType.Type .AllRequired .depth false }}
+{{ tabs (add .depth 1) }}{{if or .elemType.Type.IsObject .elemType.Type.IsUnion }}var {{ $pubv }} {{ gotyperef .elemType.Type .AllRequired .depth false }}
 {{ tabs (add .depth 1) }}if {{ $v }} != nil {
 {{ tabs (add .depth 1) }}{{ publicizer .elemType $v $pubv .dereference (add .depth 1) false }}
 {{ tabs (add .depth 1) }}}{{ else }}{{ publicizer .elemType $v $pubv .dereference (add .depth 1) true }}{{ end }}
@@ -76,22 +76,24 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 		return GoTypeName(t, nil, tabs, private)
 	case *design.Array:
 		d := GoTypeDef(actual.ElemType, tabs, jsonTags, private)
-		if actual.ElemType.Type.IsObject() {
+		if actual.ElemType.Type.IsObject() || actual.ElemType.Type.IsUnion() {
 			d = "*" + d
 		}
 		return "[]" + d
 	case *design.Hash:
 		keyDef := GoTypeDef(actual.KeyType, tabs, jsonTags, private)
-		if actual.KeyType.Type.IsObject() {
+		if actual.KeyType.Type.IsObject() || actual.KeyType.Type.IsUnion() {
 			keyDef = "*" + keyDef
 		}
 		elemDef := GoTypeDef(actual.ElemType, tabs, jsonTags, private)
-		if actual.ElemType.Type.IsObject() {
+		if actual.ElemType.Type.IsObject() || actual.ElemType.Type.IsUnion() {
 			elemDef = "*" + elemDef
 		}
 		return fmt.Sprintf("map[%s]%s", keyDef, elemDef)
 	case design.Object:
 		return goTypeDefObject(actual, def, tabs, jsonTags, private)
+	case *design.Union:
+		return goTypeDefUnion(actual, tabs, private)
 	case *design.UserTypeDefinition:
 		return GoTypeName(actual, actual.AllRequired(), tabs, private)
 	case *design.MediaTypeDefinition:
@@ -116,7 +118,7 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 		WriteTabs(&buffer, tabs+1)
 		field := obj[name]
 		typedef := GoTypeDef(field, tabs+1, jsonTags, private)
-		if (private && field.Type.IsPrimitive() && !def.IsInterface(name)) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
+		if (private && field.Type.IsPrimitive() && !def.IsInterface(name)) || field.Type.IsObject() || field.Type.IsUnion() || def.IsPrimitivePointer(name) {
 			typedef = "*" + typedef
 		}
 		fname := GoifyAtt(field, name, true)
@@ -136,6 +138,21 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 	return buffer.String()
 }
 
+// goTypeDefUnion returns the Go code that defines the struct used to represent a union. The
+// struct has one field per variant, only the field corresponding to the actual variant is set.
+func goTypeDefUnion(u *design.Union, tabs int, private bool) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("struct {\n")
+	for _, v := range u.Variants {
+		WriteTabs(&buffer, tabs+1)
+		typedef := GoTypeRef(v.Type, v.AllRequired(), tabs+1, private)
+		buffer.WriteString(fmt.Sprintf("%s %s\n", Goify(u.VariantName(v), true), typedef))
+	}
+	WriteTabs(&buffer, tabs)
+	buffer.WriteString("}")
+	return buffer.String()
+}
+
 // attributeTags computes the struct field tags.
 func attributeTags(parent, att *design.AttributeDefinition, name string, private bool) string {
 	var elems []string
@@ -180,7 +197,7 @@ func GoTypeRef(t design.DataType, required []string, tabs int, private bool) str
 			return "error"
 		}
 	}
-	if t.IsObject() {
+	if t.IsObject() || t.IsUnion() {
 		return "*" + tname
 	}
 	return tname
@@ -205,6 +222,8 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 			att.Validation.Merge(requiredVal)
 		}
 		return GoTypeDef(att, tabs, false, private)
+	case *design.Union:
+		return GoTypeDef(&design.AttributeDefinition{Type: actual}, tabs, false, private)
 	case *design.Hash:
 		return fmt.Sprintf(
 			"map[%s]%s",
@@ -244,6 +263,18 @@ func GoNativeType(t design.DataType) string {
 			return "interface{}"
 		case design.FileKind:
 			return "multipart.FileHeader"
+		case design.Int64Kind:
+			return "int64"
+		case design.UInt64Kind:
+			return "uint64"
+		case design.Float32Kind:
+			return "float32"
+		case design.BytesKind:
+			return "[]byte"
+		case design.DateKind:
+			return "goa.Date"
+		case design.DurationKind:
+			return "goa.Duration"
 		default:
 			panic(fmt.Sprintf("goa bug: unknown primitive type %#v", actual))
 		}
@@ -251,6 +282,8 @@ func GoNativeType(t design.DataType) string {
 		return "[]" + GoNativeType(actual.ElemType.Type)
 	case design.Object:
 		return "map[string]interface{}"
+	case *design.Union:
+		return "interface{}"
 	case *design.Hash:
 		return fmt.Sprintf("map[%s]%s", GoNativeType(actual.KeyType.Type), GoNativeType(actual.ElemType.Type))
 	case *design.MediaTypeDefinition:
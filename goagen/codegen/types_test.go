@@ -562,6 +562,17 @@ var _ = Describe("code generation", func() {
 	})
 })
 
+var _ = Describe("GoNativeType", func() {
+	It("maps the sized and encoded primitives", func() {
+		Ω(codegen.GoNativeType(Int64)).Should(Equal("int64"))
+		Ω(codegen.GoNativeType(UInt64)).Should(Equal("uint64"))
+		Ω(codegen.GoNativeType(Float32)).Should(Equal("float32"))
+		Ω(codegen.GoNativeType(Bytes)).Should(Equal("[]byte"))
+		Ω(codegen.GoNativeType(Date)).Should(Equal("goa.Date"))
+		Ω(codegen.GoNativeType(Duration)).Should(Equal("goa.Duration"))
+	})
+})
+
 var _ = Describe("GoTypeTransform", func() {
 	var source, target *UserTypeDefinition
 	var targetPkg, funcName string
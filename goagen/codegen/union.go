@@ -0,0 +1,103 @@
+package codegen
+
+import (
+	"fmt"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+)
+
+var unionMarshalersT *template.Template
+
+func init() {
+	var err error
+	if unionMarshalersT, err = template.New("unionMarshalers").Parse(unionMarshalersTmpl); err != nil {
+		panic(err)
+	}
+}
+
+// UnionMarshalers produces the MarshalJSON and UnmarshalJSON methods of the Go struct generated
+// for the given union user type. The marshaler encodes the variant that is set. The unmarshaler
+// uses the value of the discriminator attribute to select the variant if the union defines one
+// and otherwise decodes the variant which is the only one to match the JSON document.
+// private controls whether the methods apply to the private or public struct.
+func UnionMarshalers(ut *design.UserTypeDefinition, private bool) string {
+	u := ut.ToUnion()
+	if u == nil {
+		return ""
+	}
+	var (
+		variants = make([]map[string]interface{}, len(u.Variants))
+		names    = make([]string, len(u.Variants))
+		values   = make([]interface{}, len(u.Variants))
+	)
+	for i, v := range u.Variants {
+		names[i] = u.VariantName(v)
+		values[i] = u.DiscriminatorValue(v)
+		variants[i] = map[string]interface{}{
+			"field":   Goify(names[i], true),
+			"type":    GoTypeName(v.Type, v.AllRequired(), 0, private),
+			"pointer": v.Type.IsObject(),
+			"value":   values[i],
+		}
+	}
+	data := map[string]interface{}{
+		"typeName":      GoTypeName(ut, ut.AllRequired(), 0, private),
+		"name":          ut.TypeName,
+		"discriminator": u.Discriminator,
+		"variants":      variants,
+		"names":         fmt.Sprintf("%#v", names),
+		"values":        toSlice(values),
+	}
+	return RunTemplate(unionMarshalersT, data)
+}
+
+const unionMarshalersTmpl = `// MarshalJSON encodes the {{ .typeName }} variant that is set.
+func (ut {{ .typeName }}) MarshalJSON() ([]byte, error) {
+	switch {
+{{ range .variants }}	case ut.{{ .field }} != nil:
+		return json.Marshal(ut.{{ .field }})
+{{ end }}	}
+	return []byte("null"), nil
+}
+
+{{ if .discriminator }}// UnmarshalJSON decodes the {{ .typeName }} variant identified by the "{{ .discriminator }}" attribute.
+func (ut *{{ .typeName }}) UnmarshalJSON(data []byte) error {
+	var d struct {
+		Value *string ` + "`" + `json:"{{ .discriminator }}"` + "`" + `
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	if d.Value == nil {
+		return goa.MissingAttributeError(` + "`" + `{{ .name }}` + "`" + `, "{{ .discriminator }}")
+	}
+	var res {{ .typeName }}
+	switch *d.Value {
+{{ range .variants }}	case {{ printf "%q" .value }}:
+		res.{{ .field }} = &{{ .type }}{}
+		if err := json.Unmarshal(data, res.{{ .field }}); err != nil {
+			return err
+		}
+{{ end }}	default:
+		return goa.InvalidEnumValueError(` + "`" + `{{ .name }}.{{ .discriminator }}` + "`" + `, *d.Value, {{ .values }})
+	}
+	*ut = res
+	return nil
+}
+{{ else }}// UnmarshalJSON decodes the {{ .typeName }} variant that matches the JSON document, exactly one
+// variant must match.
+func (ut *{{ .typeName }}) UnmarshalJSON(data []byte) error {
+	var res {{ .typeName }}
+	matches := 0
+{{ range .variants }}	if v := new({{ .type }}); goa.UnmarshalVariant(data, v) {
+		res.{{ .field }} = {{ if not .pointer }}*{{ end }}v
+		matches++
+	}
+{{ end }}	if matches != 1 {
+		return goa.InvalidUnionError(` + "`" + `{{ .name }}` + "`" + `, {{ .names }}, matches)
+	}
+	*ut = res
+	return nil
+}
+{{ end }}`
@@ -0,0 +1,132 @@
+package codegen_test
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("union code generation", func() {
+	var union *design.Union
+	var pet *design.UserTypeDefinition
+
+	BeforeEach(func() {
+		cat := &design.UserTypeDefinition{
+			TypeName: "Cat",
+			AttributeDefinition: &design.AttributeDefinition{
+				Type: design.Object{"kind": &design.AttributeDefinition{
+					Type:       design.String,
+					Validation: &dslengine.ValidationDefinition{Values: []interface{}{"cat"}},
+				}},
+			},
+		}
+		tags := &design.UserTypeDefinition{
+			TypeName: "Tags",
+			AttributeDefinition: &design.AttributeDefinition{
+				Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}},
+			},
+		}
+		union = &design.Union{Variants: []*design.AttributeDefinition{{Type: cat}, {Type: tags}}}
+		pet = &design.UserTypeDefinition{
+			TypeName:            "Pet",
+			AttributeDefinition: &design.AttributeDefinition{Type: union},
+		}
+	})
+
+	Describe("GoTypeDef", func() {
+		It("produces a struct with one field per variant", func() {
+			Ω(codegen.GoTypeDef(pet.AttributeDefinition, 0, true, false)).Should(Equal("struct {\n\tCat *Cat\n\tTags Tags\n}"))
+			Ω(codegen.GoTypeDef(pet.AttributeDefinition, 0, true, true)).Should(Equal("struct {\n\tCat *cat\n\tTags tags\n}"))
+		})
+
+		It("refers to unions with pointers", func() {
+			Ω(codegen.GoTypeRef(pet, nil, 0, false)).Should(Equal("*Pet"))
+			array := &design.Array{ElemType: &design.AttributeDefinition{Type: pet}}
+			Ω(codegen.GoTypeDef(&design.AttributeDefinition{Type: array}, 0, true, false)).Should(Equal("[]*Pet"))
+		})
+	})
+
+	Describe("UnionMarshalers", func() {
+		var code string
+
+		JustBeforeEach(func() {
+			code = codegen.UnionMarshalers(pet, false)
+		})
+
+		It("produces a marshaler that encodes the variant that is set", func() {
+			Ω(code).Should(ContainSubstring(unionMarshalCode))
+		})
+
+		It("produces an unmarshaler that decodes the variant that matches", func() {
+			Ω(code).Should(ContainSubstring(unionUnmarshalCode))
+		})
+
+		Context("with a discriminator", func() {
+			BeforeEach(func() {
+				dog := &design.UserTypeDefinition{
+					TypeName: "Dog",
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"kind": &design.AttributeDefinition{Type: design.String}},
+					},
+				}
+				union.Variants[1] = &design.AttributeDefinition{Type: dog}
+				union.Discriminator = "kind"
+			})
+
+			It("produces an unmarshaler that uses the discriminator", func() {
+				Ω(code).Should(ContainSubstring(unionDiscriminatorCode))
+			})
+		})
+
+		It("does not produce code for types that are not unions", func() {
+			Ω(codegen.UnionMarshalers(union.Variants[0].Type.(*design.UserTypeDefinition), false)).Should(BeEmpty())
+		})
+	})
+})
+
+const (
+	unionMarshalCode = `func (ut Pet) MarshalJSON() ([]byte, error) {
+	switch {
+	case ut.Cat != nil:
+		return json.Marshal(ut.Cat)
+	case ut.Tags != nil:
+		return json.Marshal(ut.Tags)
+	}
+	return []byte("null"), nil
+}`
+
+	unionUnmarshalCode = `func (ut *Pet) UnmarshalJSON(data []byte) error {
+	var res Pet
+	matches := 0
+	if v := new(Cat); goa.UnmarshalVariant(data, v) {
+		res.Cat = v
+		matches++
+	}
+	if v := new(Tags); goa.UnmarshalVariant(data, v) {
+		res.Tags = *v
+		matches++
+	}
+	if matches != 1 {
+		return goa.InvalidUnionError(` + "`Pet`" + `, []string{"Cat", "Tags"}, matches)
+	}
+	*ut = res
+	return nil
+}`
+
+	unionDiscriminatorCode = `	switch *d.Value {
+	case "cat":
+		res.Cat = &Cat{}
+		if err := json.Unmarshal(data, res.Cat); err != nil {
+			return err
+		}
+	case "Dog":
+		res.Dog = &Dog{}
+		if err := json.Unmarshal(data, res.Dog); err != nil {
+			return err
+		}
+	default:
+		return goa.InvalidEnumValueError(` + "`Pet.kind`" + `, *d.Value, []interface{}{"cat", "Dog"})
+	}`
+)
@@ -56,6 +56,7 @@ type Validator struct {
 	arrayValT *template.Template
 	hashValT  *template.Template
 	userValT  *template.Template
+	unionValT *template.Template
 	seen      map[string]*bytes.Buffer
 }
 
@@ -86,6 +87,10 @@ func NewValidator() *Validator {
 	if err != nil {
 		panic(err)
 	}
+	v.unionValT, err = template.New("union").Funcs(fm).Parse(unionValTmpl)
+	if err != nil {
+		panic(err)
+	}
 	return v
 }
 
@@ -201,6 +206,33 @@ func (v *Validator) hashValCode(att *design.AttributeDefinition, nonzero, requir
 	return buf.Bytes()
 }
 
+func (v *Validator) unionValCode(u *design.Union, target, context string, depth int, private bool) []byte {
+	variants := make([]map[string]interface{}, len(u.Variants))
+	names := make([]string, len(u.Variants))
+	for i, va := range u.Variants {
+		names[i] = u.VariantName(va)
+		field := fmt.Sprintf("%s.%s", target, Goify(names[i], true))
+		var validation string
+		if ds, ok := va.Type.(design.DataStructure); ok && hasValidations(ds, private) {
+			validation = RunTemplate(v.userValT, map[string]interface{}{
+				"depth":  depth + 1,
+				"target": field,
+			})
+		}
+		variants[i] = map[string]interface{}{
+			"field":      field,
+			"validation": validation,
+		}
+	}
+	data := map[string]interface{}{
+		"variants": variants,
+		"names":    names,
+		"context":  context,
+		"depth":    depth,
+	}
+	return []byte(RunTemplate(v.unionValT, data))
+}
+
 func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required, hasDefault bool, target, context string, depth int, private bool) *bytes.Buffer {
 	var (
 		buf   = new(bytes.Buffer)
@@ -246,6 +278,8 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 		buf.Write(v.arrayValCode(att, nonzero, required, hasDefault, target, context, depth, private))
 	} else if h := att.Type.ToHash(); h != nil {
 		buf.Write(v.hashValCode(att, nonzero, required, hasDefault, target, context, depth, private))
+	} else if u := att.Type.ToUnion(); u != nil {
+		buf.Write(v.unionValCode(u, target, context, depth, private))
 	} else {
 		validation := ValidationChecker(att, nonzero, required, hasDefault, target, context, depth, private)
 		if validation != "" {
@@ -258,38 +292,7 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, target, context string, depth int, private bool) string {
 	var validation string
 	if ds, ok := catt.Type.(design.DataStructure); ok {
-		// We need to check empirically whether there are validations to be
-		// generated, we can't just generate and check whether something was
-		// generated to avoid infinite recursions.
-		hasValidations := false
-		done := errors.New("done")
-		ds.Walk(func(a *design.AttributeDefinition) error {
-			if a.Validation != nil {
-				if private {
-					hasValidations = true
-					return done
-				}
-				// For public data structures there is a case where
-				// there is validation but no actual validation
-				// code: if the validation is a required validation
-				// that applies to attributes that cannot be nil or
-				// empty string i.e. primitive types other than
-				// string.
-				if !a.Validation.HasRequiredOnly() {
-					hasValidations = true
-					return done
-				}
-				for _, name := range a.Validation.Required {
-					att := a.Type.ToObject()[name]
-					if att != nil && (!att.Type.IsPrimitive() || att.Type.Kind() == design.StringKind) {
-						hasValidations = true
-						return done
-					}
-				}
-			}
-			return nil
-		})
-		if hasValidations {
+		if hasValidations(ds, private) {
 			validation = RunTemplate(v.userValT, map[string]interface{}{
 				"depth":  depth,
 				"target": fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
@@ -297,7 +300,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 		}
 	} else {
 		dp := depth
-		if catt.Type.IsObject() {
+		if catt.Type.IsObject() || catt.Type.IsUnion() {
 			dp++
 		}
 		validation = v.recurse(
@@ -312,7 +315,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 		).String()
 	}
 	if validation != "" {
-		if catt.Type.IsObject() {
+		if catt.Type.IsObject() || catt.Type.IsUnion() {
 			validation = fmt.Sprintf("%sif %s.%s != nil {\n%s\n%s}",
 				Tabs(depth), target, GoifyAtt(catt, n, true), validation, Tabs(depth))
 		}
@@ -320,6 +323,46 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 	return validation
 }
 
+// hasValidations returns true if validation code must be generated for the given data
+// structure. We need to check empirically whether there are validations to be generated, we
+// can't just generate and check whether something was generated to avoid infinite recursions.
+func hasValidations(ds design.DataStructure, private bool) bool {
+	hasValidations := false
+	done := errors.New("done")
+	ds.Walk(func(a *design.AttributeDefinition) error {
+		if a.Type.IsUnion() {
+			// Unions always validate that exactly one variant is set.
+			hasValidations = true
+			return done
+		}
+		if a.Validation != nil {
+			if private {
+				hasValidations = true
+				return done
+			}
+			// For public data structures there is a case where
+			// there is validation but no actual validation
+			// code: if the validation is a required validation
+			// that applies to attributes that cannot be nil or
+			// empty string i.e. primitive types other than
+			// string.
+			if !a.Validation.HasRequiredOnly() {
+				hasValidations = true
+				return done
+			}
+			for _, name := range a.Validation.Required {
+				att := a.Type.ToObject()[name]
+				if att != nil && (!att.Type.IsPrimitive() || att.Type.Kind() == design.StringKind) {
+					hasValidations = true
+					return done
+				}
+			}
+		}
+		return nil
+	})
+	return hasValidations
+}
+
 // ValidationChecker produces Go code that runs the validation defined in the given attribute
 // definition against the content of the variable named target recursively.
 // context is used to keep track of recursion to produce helpful error messages in case of type
@@ -374,9 +417,12 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 		}
 	}
 	if min := validation.Minimum; min != nil {
-		if att.Type == design.Integer {
+		switch att.Type {
+		case design.Integer, design.Int64:
 			data["min"] = renderInteger(*min)
-		} else {
+		case design.UInt64:
+			data["min"] = renderInteger(math.Max(*min, 0))
+		default:
 			data["min"] = fmt.Sprintf("%f", *min)
 		}
 		data["isMin"] = true
@@ -386,9 +432,12 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 		}
 	}
 	if max := validation.Maximum; max != nil {
-		if att.Type == design.Integer {
+		switch att.Type {
+		case design.Integer, design.Int64:
 			data["max"] = renderInteger(*max)
-		} else {
+		case design.UInt64:
+			data["max"] = renderInteger(math.Max(*max, 0))
+		default:
 			data["max"] = fmt.Sprintf("%f", *max)
 		}
 		data["isMin"] = false
@@ -493,6 +542,15 @@ const (
 
 	userValTmpl = `{{ tabs .depth }}if err2 := {{ .target }}.Validate(); err2 != nil {
 {{ tabs .depth }}	err = goa.MergeErrors(err, err2)
+{{ tabs .depth }}}`
+
+	unionValTmpl = `{{ tabs .depth }}variants := 0
+{{ range .variants }}{{ tabs $.depth }}if {{ .field }} != nil {
+{{ tabs $.depth }}	variants++
+{{ if .validation }}{{ .validation }}
+{{ end }}{{ tabs $.depth }}}
+{{ end }}{{ tabs .depth }}if variants != 1 {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.InvalidUnionError(` + "`" + `{{ .context }}` + "`" + `, {{ printf "%#v" .names }}, variants))
 {{ tabs .depth }}}`
 
 	enumValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
@@ -217,6 +217,33 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of union", func() {
+				BeforeEach(func() {
+					min := 1.0
+					cat := &design.UserTypeDefinition{
+						TypeName: "Cat",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{"lives": &design.AttributeDefinition{
+								Type:       design.Integer,
+								Validation: &dslengine.ValidationDefinition{Minimum: &min},
+							}},
+						},
+					}
+					dog := &design.UserTypeDefinition{
+						TypeName: "Dog",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{"bark": &design.AttributeDefinition{Type: design.String}},
+						},
+					}
+					attType = &design.Union{Variants: []*design.AttributeDefinition{{Type: cat}, {Type: dog}}}
+					validation = nil
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(unionValCode))
+				})
+			})
+
 			Context("of embedded object", func() {
 				var catt, ccatt *design.AttributeDefinition
 
@@ -423,6 +450,20 @@ const (
 		}
 	}`
 
+	unionValCode = `	variants := 0
+	if val.Cat != nil {
+		variants++
+		if err2 := val.Cat.Validate(); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}
+	}
+	if val.Dog != nil {
+		variants++
+	}
+	if variants != 1 {
+		err = goa.MergeErrors(err, goa.InvalidUnionError(` + "`context`" + `, []string{"Cat", "Dog"}, variants))
+	}`
+
 	embeddedValCode = `	if val.Foo != nil {
 		if val.Foo.Bar != nil {
 			if !(*val.Foo.Bar == 1 || *val.Foo.Bar == 2 || *val.Foo.Bar == 3) {
@@ -23,10 +23,13 @@ import (
 )
 
 type (
-	// Workspace represents a temporary Go workspace
+	// Workspace represents a temporary Go workspace or the root of a Go
+	// module.
 	Workspace struct {
 		// Path is the absolute path to the workspace directory.
 		Path string
+		// Module is the Go module rooted at Path if any.
+		Module *Module
 		// gopath is the original GOPATH
 		gopath string
 	}
@@ -72,6 +75,7 @@ var (
 		"tempvar":             Tempvar,
 		"title":               strings.Title,
 		"toLower":             strings.ToLower,
+		"unionMarshalers":     UnionMarshalers,
 		"validationChecker":   ValidationChecker,
 	}
 )
@@ -96,7 +100,9 @@ func NewWorkspace(prefix string) (*Workspace, error) {
 	return &Workspace{Path: dir, gopath: gopath}, nil
 }
 
-// WorkspaceFor returns the Go workspace for the given Go source file.
+// WorkspaceFor returns the Go workspace for the given Go source file. If the
+// file lives in a Go module then the returned workspace is rooted at the
+// module directory.
 func WorkspaceFor(source string) (*Workspace, error) {
 	gopaths := os.Getenv("GOPATH")
 	// We use absolute paths so that in particular on Windows the case gets normalized
@@ -104,6 +110,13 @@ func WorkspaceFor(source string) (*Workspace, error) {
 	if err != nil {
 		sourcePath = source
 	}
+	m, err := ModuleFor(filepath.Dir(sourcePath))
+	if err != nil {
+		return nil, err
+	}
+	if m != nil {
+		return &Workspace{Path: m.Dir, Module: m}, nil
+	}
 	for _, gp := range filepath.SplitList(gopaths) {
 		gopath, err := filepath.Abs(gp)
 		if err != nil {
@@ -116,7 +129,7 @@ func WorkspaceFor(source string) (*Workspace, error) {
 			}, nil
 		}
 	}
-	return nil, fmt.Errorf(`Go source file "%s" not in Go workspace, adjust GOPATH %s`, source, gopaths)
+	return nil, fmt.Errorf(`Go source file "%s" not in Go workspace or module, adjust GOPATH %s`, source, gopaths)
 }
 
 // Delete deletes the workspace temporary directory.
@@ -164,6 +177,13 @@ func PackageFor(source string) (*Package, error) {
 	if err != nil {
 		return nil, err
 	}
+	if w.Module != nil {
+		path, err := w.Module.PackagePath(filepath.Dir(source))
+		if err != nil {
+			return nil, err
+		}
+		return &Package{Workspace: w, Path: path}, nil
+	}
 	path, err := filepath.Rel(filepath.Join(w.Path, "src"), filepath.Dir(source))
 	if err != nil {
 		return nil, err
@@ -173,6 +193,10 @@ func PackageFor(source string) (*Package, error) {
 
 // Abs returns the absolute path to the package source directory
 func (p *Package) Abs() string {
+	if m := p.Workspace.Module; m != nil {
+		dir, _ := subdir(m.Path, p.Path, p.Workspace.Path)
+		return dir
+	}
 	return filepath.Join(p.Workspace.Path, "src", p.Path)
 }
 
@@ -209,6 +233,14 @@ func (p *Package) Compile(bin string) (string, error) {
 		Args: []string{gobin, "build", "-o", bin},
 		Dir:  p.Abs(),
 	}
+	if os.Getenv("GO111MODULE") == "" {
+		// Make sure the go tool builds in the same mode as the workspace.
+		mode := "off"
+		if p.Workspace.Module != nil {
+			mode = "on"
+		}
+		c.Env = append(os.Environ(), "GO111MODULE="+mode)
+	}
 	out, err := c.CombinedOutput()
 	if err != nil {
 		if len(out) > 0 {
@@ -310,12 +342,19 @@ func (f *SourceFile) ExecuteTemplate(name, source string, funcMap template.FuncM
 }
 
 // PackagePath returns the Go package path for the directory that lives under the given absolute
-// file path.
+// file path. The path is computed from the enclosing Go module if any, from GOPATH otherwise.
 func PackagePath(path string) (string, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		absPath = path
 	}
+	m, err := ModuleFor(absPath)
+	if err != nil {
+		return "", err
+	}
+	if m != nil {
+		return m.PackagePath(absPath)
+	}
 	gopaths := filepath.SplitList(os.Getenv("GOPATH"))
 	for _, gopath := range gopaths {
 		if gp, err := filepath.Abs(gopath); err == nil {
@@ -331,13 +370,22 @@ func PackagePath(path string) (string, error) {
 }
 
 // PackageSourcePath returns the absolute path to the given package source.
+// If the current working directory is in a Go module then the package is
+// resolved using the module replace directives and vendor directory.
 func PackageSourcePath(pkg string) (string, error) {
-	buildCtx := build.Default
-	buildCtx.GOPATH = os.Getenv("GOPATH") // Reevaluate each time to be nice to tests
 	wd, err := os.Getwd()
 	if err != nil {
 		wd = "."
 	}
+	m, err := ModuleFor(wd)
+	if err != nil {
+		return "", err
+	}
+	if m != nil {
+		return m.PackageDir(pkg)
+	}
+	buildCtx := build.Default
+	buildCtx.GOPATH = os.Getenv("GOPATH") // Reevaluate each time to be nice to tests
 	p, err := buildCtx.Import(pkg, wd, 0)
 	if err != nil {
 		return "", err
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_diff"
+	"github.com/goadesign/goa/goagen/meta"
+)
+
+// runDiff compares the base design with the new design, writes the report to stdout and returns
+// an error if there are breaking changes. The base design is the package basePkg - or designPkg if
+// empty - at git revision from - or as found on disk if empty. The new design is the package
+// designPkg at git revision to - or as found on disk if empty.
+func runDiff(designPkg, basePkg, from, to string, jsonOutput, debug bool) error {
+	if designPkg == "" {
+		return fmt.Errorf("missing design package flag")
+	}
+	if basePkg == "" {
+		basePkg = designPkg
+	}
+	if basePkg == designPkg && from == to {
+		return fmt.Errorf("nothing to compare, specify a base design package with --base or a git revision with --from")
+	}
+	base, err := snapshot(basePkg, from, debug)
+	if err != nil {
+		return err
+	}
+	api, err := snapshot(designPkg, to, debug)
+	if err != nil {
+		return err
+	}
+
+	report := gendiff.Compare(base, api)
+	if jsonOutput {
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	} else if err := report.WriteText(os.Stdout); err != nil {
+		return err
+	}
+	if report.Breaking {
+		return fmt.Errorf("breaking changes detected")
+	}
+	return nil
+}
+
+// snapshot runs the gendiff generator against the design package at the given git revision and
+// loads the resulting design summary. The package is loaded from disk if rev is empty.
+func snapshot(pkg, rev string, debug bool) (*gendiff.Snapshot, error) {
+	if rev != "" {
+		restore, err := checkout(pkg, rev)
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+	}
+	out, err := ioutil.TempDir("", "goagen-diff")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(out)
+
+	gen, err := meta.NewGenerator(
+		"gendiff.Generate",
+		[]*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa/goagen/gen_diff")},
+		map[string]string{"out": out, "design": pkg, "debug": strconv.FormatBool(debug)},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gen.Generate(); err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filepath.Join(out, gendiff.SnapshotFile))
+	if err != nil {
+		return nil, err
+	}
+	var snap gendiff.Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, fmt.Errorf("invalid design summary: %s", err)
+	}
+	return &snap, nil
+}
+
+// checkout extracts the git repository containing the design package at the given revision in a
+// temporary GOPATH workspace. It then changes the working directory to the corresponding
+// directory in the extracted tree (so that Go modules also resolve the design package to the
+// extracted code) and prepends the workspace to GOPATH. The returned function reverts these
+// changes and deletes the workspace.
+func checkout(pkg, rev string) (func(), error) {
+	dir, err := codegen.PackageSourcePath(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid design package import path: %s", err)
+	}
+	top, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return nil, err
+	}
+	if top, err = filepath.EvalSymlinks(top); err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(top, dir)
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSuffix(pkg, "/"+filepath.ToSlash(rel))
+	if rel == "." {
+		root = pkg
+	}
+
+	tmp, err := ioutil.TempDir("", "goagen-rev")
+	if err != nil {
+		return nil, err
+	}
+	tree := filepath.Join(tmp, "src", filepath.FromSlash(root))
+	if err := extract(top, rev, tree); err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+	gopath, modcache := os.Getenv("GOPATH"), os.Getenv("GOMODCACHE")
+	paths := gopath
+	if paths == "" {
+		paths = build.Default.GOPATH
+	}
+	os.Setenv("GOPATH", tmp+string(filepath.ListSeparator)+paths)
+	if modcache == "" {
+		// Keep using the module cache of the original GOPATH.
+		os.Setenv("GOMODCACHE", filepath.Join(filepath.SplitList(paths)[0], "pkg", "mod"))
+	}
+	if ewd, err := filepath.EvalSymlinks(wd); err == nil {
+		if r, err := filepath.Rel(top, ewd); err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			target := filepath.Join(tree, r)
+			if err := os.MkdirAll(target, 0755); err == nil {
+				os.Chdir(target)
+			}
+		}
+	}
+
+	return func() {
+		os.Chdir(wd)
+		os.Setenv("GOPATH", gopath)
+		os.Setenv("GOMODCACHE", modcache)
+		os.RemoveAll(tmp)
+	}, nil
+}
+
+// extract writes the content of the git repository rooted at top at the given revision to dst.
+func extract(top, rev, dst string) error {
+	cmd := exec.Command("git", "archive", "--format=tar", rev)
+	cmd.Dir = top
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	tr := tar.NewReader(out)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cmd.Wait()
+			return fmt.Errorf("failed to extract revision %s: %s%s", rev, err, stderr.String())
+		}
+		target := filepath.Join(dst, filepath.FromSlash(hdr.Name))
+		mode := hdr.FileInfo().Mode()
+		switch {
+		case mode.IsDir():
+			err = os.MkdirAll(target, 0755)
+		case mode&os.ModeSymlink != 0:
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err == nil {
+				err = os.Symlink(hdr.Linkname, target)
+			}
+		case mode.IsRegular():
+			err = writeFile(target, tr, mode.Perm())
+		}
+		if err != nil {
+			cmd.Wait()
+			return err
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git archive %s failed: %s\n%s", rev, err, stderr.String())
+	}
+	return nil
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// gitOutput runs git in dir and returns its trimmed standard output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
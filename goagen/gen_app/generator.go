@@ -144,6 +144,8 @@ func (g *Generator) generateContexts() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/base64"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("strconv"),
@@ -153,6 +155,7 @@ func (g *Generator) generateContexts() (err error) {
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 		codegen.SimpleImport("context"),
+		codegen.SimpleImport("golang.org/x/net/websocket"),
 	}
 	g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		return r.IterateActions(func(a *design.ActionDefinition) error {
@@ -207,6 +210,9 @@ func (g *Generator) generateContexts() (err error) {
 				API:          g.API,
 				DefaultPkg:   g.Target,
 				Security:     a.Security,
+				Pagination:   a.Pagination,
+				Inbound:      a.InboundMessage,
+				Outbound:     a.OutboundMessage,
 			}
 			return ctxWr.Execute(&ctxData)
 		})
@@ -236,11 +242,14 @@ func (g *Generator) generateControllers() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application Controllers", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/base64"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/goadesign/goa/cors"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/idempotency"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/ratelimit"),
 		codegen.SimpleImport("regexp"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("time"),
@@ -317,6 +326,11 @@ func (g *Generator) generateControllers() (err error) {
 				"PayloadOptional":  a.PayloadOptional,
 				"PayloadMultipart": a.PayloadMultipart,
 				"Security":         a.Security,
+				"Idempotent":       a.Idempotent,
+			}
+			if a.RateLimit != nil {
+				action["RateLimit"] = rateLimitCode(a.RateLimit)
+				action["RateLimitAuthenticated"] = a.RateLimit.EffectiveKey() != design.RateLimitByIP
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
@@ -491,6 +505,7 @@ func (g *Generator) generateUserTypes() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("time"),
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -245,6 +246,60 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("with a rate limit", func() {
+			BeforeEach(func() {
+				design.Design.Resources["Widget"].Actions["get"].RateLimit = &design.RateLimitDefinition{
+					Limit:  100,
+					Period: 90 * time.Second,
+					Burst:  20,
+				}
+			})
+
+			It("mounts the rate limiting middleware", func() {
+				Ω(genErr).Should(BeNil())
+
+				controllersContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(controllersContent)).Should(ContainSubstring(`"github.com/goadesign/goa/middleware/ratelimit"`))
+				Ω(string(controllersContent)).Should(ContainSubstring(controllersRateLimitCode))
+			})
+
+			Context("keyed by JWT subject", func() {
+				BeforeEach(func() {
+					design.Design.Resources["Widget"].Actions["get"].RateLimit.Key = design.RateLimitByJWTSubject
+					design.Design.Resources["Widget"].Actions["get"].Security = &design.SecurityDefinition{
+						Scheme: &design.SecuritySchemeDefinition{SchemeName: "jwt", Kind: design.JWTSecurityKind},
+					}
+				})
+
+				It("mounts the rate limiting middleware after the security middleware", func() {
+					Ω(genErr).Should(BeNil())
+
+					controllersContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(controllersContent)).Should(ContainSubstring(controllersRateLimitJWTCode))
+				})
+			})
+		})
+
+		Context("with an idempotent action", func() {
+			BeforeEach(func() {
+				design.Design.Resources["Widget"].Actions["get"].Idempotent = true
+				design.Design.Resources["Widget"].Actions["get"].Security = &design.SecurityDefinition{
+					Scheme: &design.SecuritySchemeDefinition{SchemeName: "jwt", Kind: design.JWTSecurityKind},
+				}
+			})
+
+			It("mounts the idempotency middleware before the security middleware", func() {
+				Ω(genErr).Should(BeNil())
+
+				controllersContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(controllersContent)).Should(ContainSubstring(`"github.com/goadesign/goa/middleware/idempotency"`))
+				Ω(string(controllersContent)).Should(ContainSubstring(controllersIdempotentCode))
+			})
+		})
+
 		Context("with a multipart payload", func() {
 			BeforeEach(func() {
 				elemTypeInt := &design.AttributeDefinition{Type: design.Integer}
@@ -412,7 +467,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, nil))
+	service.Mount("GET", "/:id", ctrl, "get", h, nil)
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 `
@@ -478,7 +533,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
+	service.Mount("GET", "/:id", ctrl, "get", h, unmarshalGetWidgetPayload)
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 
@@ -493,6 +548,29 @@ func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *h
 }
 `
 
+const controllersRateLimitCode = `
+		return ctrl.Get(rctx)
+	}
+	h = ratelimit.New(100, 90*time.Second, ratelimit.Burst(20))(h)
+	service.Mount("GET", "/:id", ctrl, "get", h, nil)
+`
+
+const controllersRateLimitJWTCode = `
+		return ctrl.Get(rctx)
+	}
+	h = ratelimit.New(100, 90*time.Second, ratelimit.Burst(20), ratelimit.KeyBy(ratelimit.JWTSubject))(h)
+	h = handleSecurity("jwt", h)
+	service.Mount("GET", "/:id", ctrl, "get", h, nil)
+`
+
+const controllersIdempotentCode = `
+		return ctrl.Get(rctx)
+	}
+	h = idempotency.New()(h)
+	h = handleSecurity("jwt", h)
+	service.Mount("GET", "/:id", ctrl, "get", h, nil)
+`
+
 const controllersOptionalPayloadCode = `
 // MountWidgetController "mounts" a Widget resource controller on the given service.
 func MountWidgetController(service *goa.Service, ctrl WidgetController) {
@@ -515,7 +593,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
+	service.Mount("GET", "/:id", ctrl, "get", h, unmarshalGetWidgetPayload)
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 
@@ -554,7 +632,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		}
 		return ctrl.Get(rctx)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
+	service.Mount("GET", "/:id", ctrl, "get", h, unmarshalGetWidgetPayload)
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 
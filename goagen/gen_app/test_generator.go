@@ -44,6 +44,10 @@ type TestMethod struct {
 	QueryParams       []*ObjectType
 	Headers           []*ObjectType
 	Payload           *ObjectType
+	ConnName          string
+	DefineConn        bool
+	Inbound           *ObjectType
+	Outbound          *ObjectType
 	reservedNames     map[string]bool
 }
 
@@ -73,6 +77,7 @@ func (g *Generator) generateResourceTest() error {
 		"isSlice": isSlice,
 	}
 	testTmpl := template.Must(template.New("test").Funcs(funcs).Parse(testTmpl))
+	testWSTmpl := template.Must(template.New("testws").Funcs(funcs).Parse(testWSTmpl))
 	outDir, err := makeTestDir(g, g.API.Name)
 	if err != nil {
 		return err
@@ -83,6 +88,7 @@ func (g *Generator) generateResourceTest() error {
 	}
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("encoding/base64"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("io"),
 		codegen.SimpleImport("log"),
@@ -97,6 +103,7 @@ func (g *Generator) generateResourceTest() error {
 		codegen.SimpleImport("github.com/goadesign/goa/goatest"),
 		codegen.SimpleImport("context"),
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+		codegen.SimpleImport("golang.org/x/net/websocket"),
 	}
 
 	return g.API.IterateResources(func(res *design.ResourceDefinition) (err error) {
@@ -117,16 +124,21 @@ func (g *Generator) generateResourceTest() error {
 			return err
 		}
 
-		var methods []*TestMethod
+		var methods, wsMethods []*TestMethod
 
 		if err = res.IterateActions(func(action *design.ActionDefinition) error {
+			if action.HasMessages() {
+				for routeIndex, route := range action.Routes {
+					wsMethods = append(wsMethods, g.createWSTestMethod(res, action, route, routeIndex))
+				}
+			}
 			if err := action.IterateResponses(func(response *design.ResponseDefinition) error {
 				if response.Status == 101 { // SwitchingProtocols, Don't currently handle WebSocket endpoints
 					return nil
 				}
 				for routeIndex, route := range action.Routes {
 					mediaType := design.Design.MediaTypeWithIdentifier(response.MediaType)
-					if mediaType == nil {
+					if mediaType == nil || response.Stream != nil { // Event streams are not decoded
 						methods = append(methods, g.createTestMethod(res, action, response, route, routeIndex, nil, nil))
 					} else {
 						if err := mediaType.IterateViews(func(view *design.ViewDefinition) error {
@@ -146,11 +158,56 @@ func (g *Generator) generateResourceTest() error {
 			return err
 		}
 		g.genfiles = append(g.genfiles, filename)
-		err = testTmpl.Execute(file, methods)
+		if err = testTmpl.Execute(file, methods); err != nil {
+			return
+		}
+		err = testWSTmpl.Execute(file, wsMethods)
 		return
 	})
 }
 
+// createWSTestMethod returns the data needed to render the helper that runs the given websocket
+// action on an in-process connection.
+func (g *Generator) createWSTestMethod(resource *design.ResourceDefinition, action *design.ActionDefinition,
+	route *design.RouteDefinition, routeIndex int) *TestMethod {
+
+	actionName := codegen.Goify(action.Name, true)
+	ctrlName := codegen.Goify(resource.Name, true)
+	varName := codegen.Goify(action.Name, false)
+	path := pathParams(action, route)
+	query := queryParams(action)
+	header := headers(action, resource.Headers)
+	message := func(dt design.DataType) *ObjectType {
+		if dt == nil {
+			return nil
+		}
+		return &ObjectType{
+			Name:    "msg",
+			Type:    fmt.Sprintf("%s.%s", g.Target, codegen.GoTypeName(dt, nil, 0, false)),
+			Pointer: "*",
+		}
+	}
+
+	return &TestMethod{
+		Name:           fmt.Sprintf("%s%sWS%s", actionName, ctrlName, suffixRoute(action.Routes, routeIndex)),
+		ActionName:     actionName,
+		ResourceName:   ctrlName,
+		Comment:        "runs the method " + actionName + " of the given controller on an in-process websocket connection.",
+		Params:         path,
+		QueryParams:    query,
+		Headers:        header,
+		ConnName:       fmt.Sprintf("%s%sTestConn", actionName, ctrlName),
+		DefineConn:     routeIndex == 0,
+		Inbound:        message(action.InboundMessage),
+		Outbound:       message(action.OutboundMessage),
+		ControllerName: fmt.Sprintf("%s.%sController", g.Target, ctrlName),
+		ContextVarName: fmt.Sprintf("%sCtx", varName),
+		ContextType:    fmt.Sprintf("%s.New%s%sContext", g.Target, actionName, ctrlName),
+		FullPath:       goPathFormat(route.FullPath()),
+		reservedNames:  reservedNames(path, query, header, nil, nil),
+	}
+}
+
 func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action *design.ActionDefinition,
 	response *design.ResponseDefinition, route *design.RouteDefinition, routeIndex int,
 	mediaType *design.MediaTypeDefinition, view *design.ViewDefinition) *TestMethod {
@@ -348,6 +405,7 @@ func isSlice(typeName string) bool {
 var convertParamTmpl = `{{ if eq .Type "string" }}		sliceVal := []string{ {{ if .Pointer }}*{{ end }}{{ .Name }}}{{/*
 */}}{{ else if eq .Type "int" }}		sliceVal := []string{strconv.Itoa({{ if .Pointer }}*{{ end }}{{ .Name }})}{{/*
 */}}{{ else if eq .Type "[]string" }}		sliceVal := {{ .Name }}{{/*
+*/}}{{ else if eq .Type "[]byte" }}		sliceVal := []string{base64.StdEncoding.EncodeToString({{ if .Pointer }}*{{ end }}{{ .Name }})}{{/*
 */}}{{ else if (isSlice .Type) }}		sliceVal := make([]string, len({{ .Name }}))
 		for i, v := range {{ .Name }} {
 			sliceVal[i] = fmt.Sprintf("%v", v)
@@ -461,3 +519,108 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 	return {{ $rw }}{{ if $test.ReturnType }}, mt{{ end }}
 }
 {{ end }}`
+
+var testWSTmpl = `{{ define "convertParam" }}` + convertParamTmpl + `{{ end }}` + `
+{{ range $test := . }}{{ if $test.DefineConn }}
+// {{ $test.ConnName }} is the client side of the in-process websocket connections created by
+// {{ $test.Name }}.
+type {{ $test.ConnName }} struct {
+	*goa.WebSocketConn
+	server *httptest.Server
+}
+{{ if $test.Inbound }}
+// Send validates and sends a message to the controller.
+func (c *{{ $test.ConnName }}) Send(msg {{ $test.Inbound.Pointer }}{{ $test.Inbound.Type }}) error {
+	return c.WebSocketConn.Send(msg)
+}
+{{ end }}{{ if $test.Outbound }}
+// Receive reads and validates the next message sent by the controller. It returns io.EOF once
+// the controller closed the connection.
+func (c *{{ $test.ConnName }}) Receive() ({{ $test.Outbound.Pointer }}{{ $test.Outbound.Type }}, error) {
+	var msg {{ $test.Outbound.Type }}
+	if err := c.WebSocketConn.Receive(&msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+{{ end }}
+// Close closes the connection and stops the test server.
+func (c *{{ $test.ConnName }}) Close() error {
+	err := c.WebSocketConn.Close()
+	c.server.Close()
+	return err
+}
+{{ end }}
+// {{ $test.Name }} {{ $test.Comment }}
+// It returns the client side of the connection, closing it stops the test server.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl {{ $test.ControllerName}}{{/*
+*/}}{{ range $param := $test.Params }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
+*/}}{{ range $param := $test.QueryParams }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
+*/}}{{ range $header := $test.Headers }}, {{ $header.Name }} {{ $header.Pointer }}{{ $header.Type }}{{ end }}) *{{ $test.ConnName }} {
+	// Setup service
+	var (
+		{{ $logBuf := $test.Escape "logBuf" }}{{ $logBuf }} bytes.Buffer
+
+		{{ $respSetter := $test.Escape "respSetter" }}{{ $respSetter }} goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&{{ $logBuf }}, {{ $respSetter }})
+	} else {
+		{{ $logger := $test.Escape "logger" }}{{ $logger }} := log.New(&{{ $logBuf }}, "", log.Ltime)
+		service.WithLogger(goa.NewLogger({{ $logger }}))
+	}
+
+	// Setup request context
+{{ $query := $test.Escape "query" }}{{ if $test.QueryParams}}	{{ $query }} := url.Values{}
+{{ range $param := $test.QueryParams }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
+{{ template "convertParam" $param }}
+		{{ $query }}[{{ printf "%q" $param.Label }}] = sliceVal
+	}
+{{ end }}{{ end }}	{{ $u := $test.Escape "u" }}{{ $u }}:= &url.URL{
+		Path: fmt.Sprintf({{ printf "%q" $test.FullPath }}{{ range $param := $test.Params }}, {{ $param.Name }}{{ end }}),
+{{ if $test.QueryParams }}		RawQuery: {{ $query }}.Encode(),
+{{ end }}	}
+	{{ $prms := $test.Escape "prms" }}{{ $prms }} := url.Values{}
+{{ range $param := $test.Params }}	{{ $prms }}["{{ $param.Label }}"] = []string{fmt.Sprintf("%v",{{ $param.Name}})}
+{{ end }}{{ range $param := $test.QueryParams }}{{ if $param.Pointer }} if {{ $param.Name }} != nil {{ end }} {
+{{ template "convertParam" $param }}
+		{{ $prms }}[{{ printf "%q" $param.Label }}] = sliceVal
+	}
+{{ end }}	if ctx == nil {
+		ctx = context.Background()
+	}
+	{{ $rw := $test.Escape "rw" }}{{ $req := $test.Escape "req" }}{{ $server := $test.Escape "server" }}{{ $server }} := httptest.NewServer(http.HandlerFunc(func({{ $rw }} http.ResponseWriter, {{ $req }} *http.Request) {
+		{{ $goaCtx := $test.Escape "goaCtx" }}{{ $goaCtx }} := goa.NewContext(goa.WithAction(ctx, "{{ $test.ResourceName }}Test"), {{ $rw }}, {{ $req }}, {{ $prms }})
+		{{ $test.ContextVarName }}, {{ $err := $test.Escape "err" }}{{ $err }} := {{ $test.ContextType }}({{ $goaCtx }}, {{ $req }}, service)
+		if {{ $err }} != nil {
+			t.Errorf("unexpected parameter validation error: %+v", {{ $err }})
+			return
+		}
+
+		// Perform action
+		if {{ $err }} := ctrl.{{ $test.ActionName}}({{ $test.ContextVarName }}); {{ $err }} != nil {
+			t.Errorf("controller returned %+v", {{ $err }})
+		}
+	}))
+
+	// Connect
+	{{ $config := $test.Escape "config" }}{{ $config }}, {{ $err }} := websocket.NewConfig("ws"+strings.TrimPrefix({{ $server }}.URL, "http")+{{ $u }}.String(), {{ $server }}.URL)
+	if {{ $err }} != nil {
+		panic("invalid test " + {{ $err }}.Error()) // bug
+	}
+{{ range $header := $test.Headers }}{{ if $header.Pointer }}	if {{ $header.Name }} != nil {{ end }}{
+{{ template "convertParam" $header }}
+		{{ $config }}.Header[{{ printf "%q" $header.Label }}] = sliceVal
+	}
+{{ end }}	{{ $ws := $test.Escape "ws" }}{{ $ws }}, {{ $err }} := websocket.DialConfig({{ $config }})
+	if {{ $err }} != nil {
+		{{ $server }}.Close()
+		t.Fatalf("failed to connect: %s, logs:\n%s", {{ $err }}, {{ $logBuf }}.String())
+		return nil
+	}
+
+	return &{{ $test.ConnName }}{WebSocketConn: goa.NewWebSocketConn({{ $ws }}, 0), server: {{ $server }}}
+}
+{{ end }}`
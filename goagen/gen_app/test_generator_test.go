@@ -263,4 +263,65 @@ var _ = Describe("Generate", func() {
 			Ω(strings.Split(string(content), "\n")).Should(ContainElement(MatchRegexp(`^// Code generated .* DO NOT EDIT\.$`)))
 		})
 	})
+
+	Context("with a websocket action defining messages", func() {
+		BeforeEach(func() {
+			message := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type:       design.Object{"body": &design.AttributeDefinition{Type: design.String}},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"body"}},
+				},
+				TypeName: "Message",
+			}
+			design.Design = &design.APIDefinition{
+				Name:  "testapi",
+				Types: map[string]*design.UserTypeDefinition{"Message": message},
+				Resources: map[string]*design.ResourceDefinition{
+					"room": {
+						Name: "room",
+						Actions: map[string]*design.ActionDefinition{
+							"chat": {
+								Name:    "chat",
+								Schemes: []string{"ws"},
+								Params: &design.AttributeDefinition{
+									Type:       design.Object{"id": &design.AttributeDefinition{Type: design.Integer}},
+									Validation: &dslengine.ValidationDefinition{Required: []string{"id"}},
+								},
+								Routes:          []*design.RouteDefinition{{Verb: "GET", Path: "/rooms/:id/chat"}},
+								Responses:       map[string]*design.ResponseDefinition{"SwitchingProtocols": {Name: "SwitchingProtocols", Status: 101}},
+								InboundMessage:  message,
+								OutboundMessage: message,
+							},
+						},
+					},
+				},
+			}
+			res := design.Design.Resources["room"]
+			for _, a := range res.Actions {
+				a.Parent = res
+				a.Routes[0].Parent = a
+			}
+		})
+
+		It("generates a helper running the action on an in-process connection", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "room_testing.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("func ChatRoomWS(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.RoomController, id int) *ChatRoomTestConn {"))
+			Ω(content).Should(ContainSubstring("func (c *ChatRoomTestConn) Send(msg *app.Message) error {"))
+			Ω(content).Should(ContainSubstring("func (c *ChatRoomTestConn) Receive() (*app.Message, error) {"))
+			Ω(content).Should(ContainSubstring("websocket.DialConfig(config)"))
+			Ω(content).Should(ContainSubstring("ctrl.Chat(chatCtx)"))
+		})
+
+		It("generates the typed connection of the action context", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("func (ctx *ChatRoomContext) Upgrade(handler func(*ChatRoomConn)) error {"))
+			Ω(content).Should(ContainSubstring("func (c *ChatRoomConn) Send(msg *Message) error {"))
+			Ω(content).Should(ContainSubstring("func (c *ChatRoomConn) Receive() (*Message, error) {"))
+		})
+	})
 })
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"sort"
 
@@ -85,6 +87,9 @@ type (
 		API          *design.APIDefinition
 		DefaultPkg   string
 		Security     *design.SecurityDefinition
+		Pagination   *design.PaginationDefinition
+		Inbound      design.DataType // Type of the websocket messages sent by clients if any
+		Outbound     design.DataType // Type of the websocket messages sent by the server if any
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
@@ -219,6 +224,16 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
 		return err
 	}
+	if data.Pagination != nil {
+		fn := template.FuncMap{
+			"paramField": func(name string) string {
+				return codegen.GoifyAtt(data.Params.Type.ToObject()[name], name, true)
+			},
+		}
+		if err := w.ExecuteTemplate("page", ctxPageT, fn, data); err != nil {
+			return err
+		}
+	}
 	if data.Payload != nil {
 		found := false
 		for _, t := range design.Design.Types {
@@ -231,12 +246,29 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			fn := template.FuncMap{
 				"finalizeCode":   w.Finalizer.Code,
 				"validationCode": w.Validator.Code,
+				"sensitivePaths": sensitivePaths,
 			}
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
 				return err
 			}
 		}
 	}
+	if data.Inbound != nil || data.Outbound != nil {
+		connData := map[string]interface{}{
+			"Context":  data,
+			"ConnName": strings.TrimSuffix(data.Name, "Context") + "Conn",
+		}
+		if data.Inbound != nil {
+			connData["Inbound"] = codegen.GoTypeRef(data.Inbound, nil, 0, false)
+			connData["InboundName"] = codegen.GoTypeName(data.Inbound, nil, 0, false)
+		}
+		if data.Outbound != nil {
+			connData["Outbound"] = codegen.GoTypeRef(data.Outbound, nil, 0, false)
+		}
+		if err := w.ExecuteTemplate("conn", ctxConnT, nil, connData); err != nil {
+			return err
+		}
+	}
 	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
 		respData := map[string]interface{}{
 			"Context":  data,
@@ -248,6 +280,9 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			if mt, ok = resp.Type.(*design.MediaTypeDefinition); !ok {
 				respData["Type"] = resp.Type
 				respData["ContentType"] = resp.MediaType
+				if resp.Stream != nil {
+					return w.writeStream(data, resp, codegen.Goify(resp.Name, true), codegen.GoTypeRef(resp.Type, nil, 0, false))
+				}
 				return w.ExecuteTemplate("response", ctxTRespT, nil, respData)
 			}
 		} else {
@@ -281,16 +316,59 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 					base := fmt.Sprintf("%s%s", resp.Name, strings.Title(view))
 					respData["RespName"] = codegen.Goify(base, true)
 				}
+				if resp.Stream != nil {
+					ref := codegen.GoTypeRef(projected, projected.AllRequired(), 0, false)
+					if err := w.writeStream(data, resp, respData["RespName"].(string), ref); err != nil {
+						return err
+					}
+					continue
+				}
 				if err := w.ExecuteTemplate("response", ctxMTRespT, fn, respData); err != nil {
 					return err
 				}
 			}
 			return nil
 		}
+		if resp.Stream != nil {
+			return w.writeStream(data, resp, codegen.Goify(resp.Name, true), "[]byte")
+		}
 		return w.ExecuteTemplate("response", ctxNoMTRespT, nil, respData)
 	})
 }
 
+// writeStream writes the helper that opens the server-sent events stream of the given response
+// and the type used to send events rendered with the given type.
+func (w *ContextsWriter) writeStream(data *ContextTemplateData, resp *design.ResponseDefinition, respName, typeRef string) error {
+	streamData := map[string]interface{}{
+		"Context":    data,
+		"Response":   resp,
+		"RespName":   respName,
+		"StreamName": strings.TrimSuffix(data.Name, "Context") + respName + "Stream",
+		"TypeRef":    typeRef,
+		"Heartbeat":  heartbeatCode(resp.Stream.Heartbeat),
+	}
+	return w.ExecuteTemplate("stream", ctxStreamRespT, nil, streamData)
+}
+
+// heartbeatCode returns the Go code for the given event stream heartbeat interval.
+func heartbeatCode(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "goa.DefaultHeartbeat"
+	case d < 0:
+		return "0"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d * time.Hour", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%d * time.Minute", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%d * time.Second", d/time.Second)
+	case d%time.Millisecond == 0:
+		return fmt.Sprintf("%d * time.Millisecond", d/time.Millisecond)
+	}
+	return fmt.Sprintf("time.Duration(%d)", d)
+}
+
 // NewControllersWriter returns a handlers code writer.
 // Handlers provide the glue between the underlying request data and the user controller.
 func NewControllersWriter(filename string) (*ControllersWriter, error) {
@@ -430,10 +508,22 @@ func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 	fn := template.FuncMap{
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
+		"sensitivePaths": sensitivePaths,
 	}
 	return w.ExecuteTemplate("types", userTypeT, fn, t)
 }
 
+// sensitivePaths returns the code listing the paths to the sensitive attributes of att given to
+// goa.RedactJSON, the empty string if att has no sensitive attribute.
+func sensitivePaths(att *design.AttributeDefinition) string {
+	paths := att.SensitivePaths()
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = strconv.Quote(p)
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // newCoerceData is a helper function that creates a map that can be given to the "Coerce" template.
 func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, pkg string, depth int) map[string]interface{} {
 	return map[string]interface{}{
@@ -451,6 +541,46 @@ func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
 	return a.Type.(*design.Array).ElemType
 }
 
+// rateLimitCode returns the code that creates the rate limiting middleware for the given
+// definition.
+func rateLimitCode(rl *design.RateLimitDefinition) string {
+	args := []string{strconv.Itoa(rl.Limit), DurationCode(rl.Period)}
+	if rl.Burst > 0 {
+		args = append(args, fmt.Sprintf("ratelimit.Burst(%d)", rl.Burst))
+	}
+	switch rl.EffectiveKey() {
+	case design.RateLimitByAPIKey:
+		args = append(args, "ratelimit.KeyBy(ratelimit.APIKey)")
+	case design.RateLimitByJWTSubject:
+		args = append(args, "ratelimit.KeyBy(ratelimit.JWTSubject)")
+	}
+	return fmt.Sprintf("ratelimit.New(%s)", strings.Join(args, ", "))
+}
+
+// DurationCode returns the Go expression that represents d using the largest time unit that
+// divides it, e.g. "time.Minute" or "30*time.Second".
+func DurationCode(d time.Duration) string {
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.d == 0 {
+			if d == u.d {
+				return u.name
+			}
+			return fmt.Sprintf("%d*%s", d/u.d, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", d)
+}
+
 const (
 	// ctxT generates the code for the context data type.
 	// template input: *ContextTemplateData
@@ -531,7 +661,68 @@ type {{ .Name }} struct {
 */}}{{ if .Pointer }}{{ $tmp := tempvar }}{{ tabs .Depth }}{{ $tmp }} := interface{}(raw{{ goify .Name true }})
 {{ tabs .Depth }}{{ .Pkg }} = &{{ $tmp }}
 {{ else }}{{ tabs .Depth }}{{ .Pkg }} = raw{{ goify .Name true }}
-{{ end }}{{ end }}{{ if eq .Attribute.Type.Kind 13 }}{{/*
+{{ end }}{{ end }}{{ if eq .Attribute.Type.Kind 15 }}{{/*
+
+*/}}{{/* Int64Type */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseInt(raw{{ goify .Name true }}, 10, 64); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "int64"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 16 }}{{/*
+
+*/}}{{/* UInt64Type */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseUint(raw{{ goify .Name true }}, 10, 64); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "uint64"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 17 }}{{/*
+
+*/}}{{/* Float32Type */}}{{/*
+*/}}{{ $tmp := tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseFloat(raw{{ goify .Name true }}, 32); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $tmp }} := float32({{ .VarName }})
+{{ tabs .Depth }}	{{ .Pkg }} = &{{ $tmp }}
+{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = float32({{ .VarName }})
+{{ end }}{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "float32"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 18 }}{{/*
+
+*/}}{{/* BytesType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := base64.StdEncoding.DecodeString(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "bytes"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 19 }}{{/*
+
+*/}}{{/* DateType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := goa.ParseDate(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "date"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 20 }}{{/*
+
+*/}}{{/* DurationType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := goa.ParseDuration(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "duration"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 13 }}{{/*
 
 */}}{{/* FileType */}}{{/*
 */}}{{ tabs .Depth }}if err2 == nil {
@@ -602,6 +793,37 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 }
 `
 
+	// ctxPageT generates the helper that sets the links to the next and previous pages.
+	// template input: *ContextTemplateData
+	ctxPageT = `{{ if eq .Pagination.Kind "offset" }}{{ $offset := paramField "offset" }}{{ $limit := paramField "limit" }}{{/*
+*/}}// SetPageLinks sets the Link response header with the URLs of the next and previous pages of the
+// {{ .ResourceName }} {{ .ActionName }} action results. hasNext indicates whether there are items after the
+// current page.
+func (ctx *{{ .Name }}) SetPageLinks(hasNext bool) {
+	if hasNext {
+		next := strconv.Itoa(ctx.{{ $offset }} + ctx.{{ $limit }})
+		ctx.ResponseData.AddLink(goa.PageURL(ctx.RequestData.Request, map[string]string{"offset": next}), "next")
+	}
+	if ctx.{{ $offset }} > 0 {
+		prev := ctx.{{ $offset }} - ctx.{{ $limit }}
+		if prev < 0 {
+			prev = 0
+		}
+		ctx.ResponseData.AddLink(goa.PageURL(ctx.RequestData.Request, map[string]string{"offset": strconv.Itoa(prev)}), "prev")
+	}
+}
+{{ else }}// SetPageLinks sets the Link response header with the URLs of the next and previous pages of the
+// {{ .ResourceName }} {{ .ActionName }} action results. Empty cursors are omitted.
+func (ctx *{{ .Name }}) SetPageLinks(next, prev string) {
+	if next != "" {
+		ctx.ResponseData.AddLink(goa.PageURL(ctx.RequestData.Request, map[string]string{"cursor": next}), "next")
+	}
+	if prev != "" {
+		ctx.ResponseData.AddLink(goa.PageURL(ctx.RequestData.Request, map[string]string{"cursor": prev}), "prev")
+	}
+}
+{{ end }}`
+
 	// ctxMTRespT generates the response helpers for responses with media types.
 	// template input: map[string]interface{}
 	ctxMTRespT = `// {{ goify .RespName true }} sends a HTTP response with status code {{ .Response.Status }}.
@@ -627,6 +849,61 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .
 }
 `
 
+	// ctxStreamRespT generates the helpers for server-sent events responses.
+	// template input: map[string]interface{}
+	ctxStreamRespT = `
+// {{ .RespName }}Stream opens the server-sent events stream of the response with status code {{ .Response.Status }}.
+// The stream is closed when the action returns.
+func (ctx *{{ .Context.Name }}) {{ .RespName }}Stream() *{{ .StreamName }} {
+	return &{{ .StreamName }}{EventStream: goa.NewEventStream(ctx.Context, {{ .Response.Status }}, {{ .Heartbeat }})}
+}
+
+// {{ .StreamName }} sends the events of the {{ .Response.Name }} response of the {{ .Context.ActionName }} action.
+type {{ .StreamName }} struct {
+	*goa.EventStream
+}
+
+// Send sends an event with the given data.
+func (s *{{ .StreamName }}) Send(r {{ .TypeRef }}, opts ...goa.EventOption) error {
+	return s.EventStream.Send(r, opts...)
+}
+`
+
+	// ctxConnT generates the websocket connection type of actions that define messages.
+	// template input: map[string]interface{}
+	ctxConnT = `
+// Upgrade upgrades the connection to the websocket protocol and calls handler with the websocket
+// connection. The connection is closed when handler returns.
+func (ctx *{{ .Context.Name }}) Upgrade(handler func(*{{ .ConnName }})) error {
+	websocket.Handler(func(ws *websocket.Conn) {
+		conn := &{{ .ConnName }}{WebSocketConn: goa.NewWebSocketConn(ws, goa.DefaultPingInterval)}
+		defer conn.Close()
+		handler(conn)
+	}).ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	return nil
+}
+
+// {{ .ConnName }} is the websocket connection of the {{ .Context.ActionName }} action.
+type {{ .ConnName }} struct {
+	*goa.WebSocketConn
+}
+{{ if .Outbound }}
+// Send validates and sends a message to the client.
+func (c *{{ .ConnName }}) Send(msg {{ .Outbound }}) error {
+	return c.WebSocketConn.Send(msg)
+}
+{{ end }}{{ if .Inbound }}
+// Receive reads and validates the next message sent by the client. It returns io.EOF once the
+// client closed the connection.
+func (c *{{ .ConnName }}) Receive() ({{ .Inbound }}, error) {
+	var msg {{ .InboundName }}
+	if err := c.WebSocketConn.Receive(&msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+{{ end }}`
+
 	// ctxNoMTRespT generates the response helpers for responses with no known media type.
 	// template input: *ContextTemplateData
 	ctxNoMTRespT = `
@@ -644,9 +921,11 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.
 
 	// payloadT generates the payload type definition GoGenerator
 	// template input: *ContextTemplateData
-	payloadT = `{{ $payload := .Payload }}{{ if .Payload.IsObject }}// {{ gotypename .Payload nil 0 true }} is the {{ .ResourceName }} {{ .ActionName }} action payload.{{/*
+	payloadT = `{{ $payload := .Payload }}{{ if or .Payload.IsObject .Payload.IsUnion }}// {{ gotypename .Payload nil 0 true }} is the {{ .ResourceName }} {{ .ActionName }} action payload.{{/*
 */}}{{ $privateTypeName := gotypename .Payload nil 1 true }}
 type {{ $privateTypeName }} {{ gotypedef .Payload 0 true true }}
+{{ if .Payload.IsUnion }}
+{{ unionMarshalers .Payload true }}{{ end }}
 
 {{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}// Finalize sets the default values defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Finalize() {
@@ -658,7 +937,11 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Validate() (
 {{ $validation }}
 	return
 }{{ end }}
-{{ $typeName := gotypename .Payload .Payload.AllRequired 1 false }}
+{{ $paths := sensitivePaths .Payload.AttributeDefinition }}{{ if $paths }}// Redact returns a copy of the payload where the sensitive attributes are hidden.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Redact() interface{} {
+	return goa.RedactJSON(payload, {{ $paths }})
+}
+{{ end }}{{ $typeName := gotypename .Payload .Payload.AllRequired 1 false }}
 // Publicize creates {{ $typeName }} from {{ $privateTypeName }}
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Publicize() {{ gotyperef .Payload .Payload.AllRequired 0 false }} {
 	var pub {{ $typeName }}
@@ -668,13 +951,19 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Publicize()
 
 // {{ gotypename .Payload nil 0 false }} is the {{ .ResourceName }} {{ .ActionName }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
+{{ if .Payload.IsUnion }}
+{{ unionMarshalers .Payload false }}{{ end }}
 
 {{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
-`
+{{ $paths := sensitivePaths .Payload.AttributeDefinition }}{{ if $paths }}// Redact returns a copy of the payload where the sensitive attributes are hidden.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Redact() interface{} {
+	return goa.RedactJSON(payload, {{ $paths }})
+}
+{{ end }}`
 	// ctrlT generates the controller interface for a given resource.
 	// template input: *ControllerTemplateData
 	ctrlT = `// {{ .Resource }}Controller is the controller interface for the {{ .Resource }} actions.
@@ -713,7 +1002,7 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 	initService(service)
 	var h goa.Handler
 {{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
-*/}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
+*/}}	service.Mount("OPTIONS", {{ printf "%q" . }}, ctrl, "preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil)
 {{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
@@ -733,15 +1022,18 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}		}
 {{ end }}		return ctrl.{{ .Name }}(rctx)
 	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ if .Idempotent }}	h = idempotency.New()(h)
+{{ end }}{{ if .RateLimitAuthenticated }}	h = {{ .RateLimit }}(h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ if and .RateLimit (not .RateLimitAuthenticated) }}	h = {{ .RateLimit }}(h)
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+{{ end }}{{ range .Routes }}	service.Mount("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl, {{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }})
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
 {{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
+{{ end }}	service.Mount("GET", "{{ .RequestPath }}", ctrl, "serve", h, nil)
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}}
 `
@@ -791,7 +1083,7 @@ func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Reque
 {{ template "Coerce" (newCoerceData $name $att true (printf "payload.%s" (goifyatt $att $name true)) 1) }}{{ end }}{{/*
 */}}	if err != nil {
 		return err
-	}{{ else if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
+	}{{ else if or .Payload.IsObject .Payload.IsUnion }}payload := &{{ gotypename .Payload nil 1 true }}{}
 	if err := service.DecodeRequest(req, payload); err != nil {
 		return err
 	}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
@@ -804,7 +1096,7 @@ func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Reque
 		goa.ContextRequest(ctx).Payload = payload
 		return err
 	}{{ end }}
-	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}
+	goa.ContextRequest(ctx).Payload = payload{{ if or .Payload.IsObject .Payload.IsUnion }}.Publicize(){{ end }}
 	return nil
 }
 {{ end }}
@@ -850,7 +1142,8 @@ func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 	// template input: UserTypeTemplateData
 	userTypeT = `// {{ gotypedesc . false }}{{ $privateTypeName := gotypename . .AllRequired 0 true }}
 type {{ $privateTypeName }} {{ gotypedef . 0 true true }}
-{{ $assignment := finalizeCode .AttributeDefinition "ut" 1 }}{{ if $assignment }}// Finalize sets the default values for {{$privateTypeName}} type instance.
+{{ if .IsUnion }}
+{{ unionMarshalers . true }}{{ end }}{{ $assignment := finalizeCode .AttributeDefinition "ut" 1 }}{{ if $assignment }}// Finalize sets the default values for {{$privateTypeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 true }}) Finalize() {
 {{ $assignment }}
 }{{ end }}
@@ -859,7 +1152,11 @@ func (ut {{ gotyperef . .AllRequired 0 true }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
-{{ $typeName := gotypename . .AllRequired 0 false }}
+{{ $paths := sensitivePaths .AttributeDefinition }}{{ if $paths }}// Redact returns a copy of the {{$privateTypeName}} type instance where the sensitive attributes are hidden.
+func (ut {{ gotyperef . .AllRequired 0 true }}) Redact() interface{} {
+	return goa.RedactJSON(ut, {{ $paths }})
+}
+{{ end }}{{ $typeName := gotypename . .AllRequired 0 false }}
 // Publicize creates {{ $typeName }} from {{ $privateTypeName }}
 func (ut {{ gotyperef . .AllRequired 0 true }}) Publicize() {{ gotyperef . .AllRequired 0 false }} {
 	var pub {{ gotypename . .AllRequired 0 false }}
@@ -869,11 +1166,17 @@ func (ut {{ gotyperef . .AllRequired 0 true }}) Publicize() {{ gotyperef . .AllR
 
 // {{ gotypedesc . true }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
+{{ if .IsUnion }}
+{{ unionMarshalers . false }}{{ end }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
-}{{ end }}
+}{{ end }}{{ if $paths }}
+// Redact returns a copy of the {{$typeName}} type instance where the sensitive attributes are hidden.
+func (ut {{ gotyperef . .AllRequired 0 false }}) Redact() interface{} {
+	return goa.RedactJSON(ut, {{ $paths }})
+}
+{{ end }}
 `
 
 	// securitySchemesT generates the code for the security module.
@@ -912,7 +1215,12 @@ func {{ $funcName }}() *goa.{{ .Context }} {
 		Scopes: map[string]string{
 {{ range $k, $v := . }}			{{ printf "%q" $k }}: {{ printf "%q" $v }},
 {{ end }}{{/*
-*/}}		},{{ end }}
+*/}}		},{{ end }}{{ with .Issuers }}
+		Issuers: {{ printf "%#v" . }},{{ end }}{{ with .Audiences }}
+		Audiences: {{ printf "%#v" . }},{{ end }}{{ with .Leeway }}
+		Leeway: {{ printf "%d" . }}, // {{ . }}{{ end }}{{ with .RequiredClaims }}
+		RequiredClaims: {{ printf "%#v" . }},{{ end }}{{ with .SigningAlgorithms }}
+		SigningAlgorithms: {{ printf "%#v" . }},{{ end }}
 {{ end }}{{/*
 */}}	}
 {{ if .Description }} def.Description = {{ printf "%q" .Description }}
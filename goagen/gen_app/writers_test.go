@@ -3,6 +3,7 @@ package genapp_test
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/design/apidsl"
@@ -54,6 +55,7 @@ var _ = Describe("ContextsWriter", func() {
 			var payload *design.UserTypeDefinition
 			var responses map[string]*design.ResponseDefinition
 			var routes []*design.RouteDefinition
+			var pagination *design.PaginationDefinition
 
 			var data *genapp.ContextTemplateData
 
@@ -63,6 +65,7 @@ var _ = Describe("ContextsWriter", func() {
 				payload = nil
 				responses = nil
 				routes = nil
+				pagination = nil
 				data = nil
 			})
 
@@ -78,6 +81,7 @@ var _ = Describe("ContextsWriter", func() {
 					Routes:       routes,
 					API:          design.Design,
 					DefaultPkg:   "",
+					Pagination:   pagination,
 				}
 			})
 
@@ -180,6 +184,47 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a server-sent events response", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"foo": {Type: design.String}},
+							},
+							TypeName: "Bottle",
+						},
+						Identifier: "application/vnd.goa.bottle",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: mediaType.Identifier,
+						Stream:    &design.StreamDefinition{Heartbeat: 30 * time.Second},
+					}}
+				})
+
+				It("the generated code opens a typed event stream", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(streamResponse))
+					Ω(written).ShouldNot(ContainSubstring("func (ctx *ListBottleContext) OK("))
+				})
+			})
+
 			Context("with an integer param", func() {
 				var (
 					intParam   *design.AttributeDefinition
@@ -426,6 +471,28 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with sized and encoded params", func() {
+				BeforeEach(func() {
+					params = &design.AttributeDefinition{
+						Type: design.Object{
+							"day":   &design.AttributeDefinition{Type: design.Date},
+							"ratio": &design.AttributeDefinition{Type: design.Float32},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"day"}},
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(sizedContext))
+					Ω(written).Should(ContainSubstring(sizedContextFactory))
+				})
+			})
+
 			Context("with a boolean param", func() {
 				var (
 					boolParam  *design.AttributeDefinition
@@ -767,6 +834,48 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with offset pagination", func() {
+				BeforeEach(func() {
+					limit := &design.AttributeDefinition{Type: design.Integer}
+					limit.SetDefault(20)
+					offset := &design.AttributeDefinition{Type: design.Integer}
+					offset.SetDefault(0)
+					params = &design.AttributeDefinition{
+						Type: design.Object{"limit": limit, "offset": offset},
+					}
+					pagination = &design.PaginationDefinition{Kind: design.OffsetPagination}
+				})
+
+				It("writes the page links helper", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(offsetPageLinks))
+				})
+			})
+
+			Context("with cursor pagination", func() {
+				BeforeEach(func() {
+					limit := &design.AttributeDefinition{Type: design.Integer}
+					limit.SetDefault(20)
+					params = &design.AttributeDefinition{
+						Type: design.Object{"limit": limit, "cursor": {Type: design.String}},
+					}
+					pagination = &design.PaginationDefinition{Kind: design.CursorPagination}
+				})
+
+				It("writes the page links helper", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(cursorPageLinks))
+				})
+			})
+
 			Context("with a string header", func() {
 				BeforeEach(func() {
 					strHeader := &design.AttributeDefinition{Type: design.String}
@@ -1522,6 +1631,29 @@ var _ = Describe("UserTypesWriter", func() {
 				})
 			})
 
+			Context("with a sensitive attribute", func() {
+				BeforeEach(func() {
+					password := &design.AttributeDefinition{Type: design.String}
+					password.SetSensitive()
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"name":     &design.AttributeDefinition{Type: design.String},
+							"password": password,
+						},
+					}
+					typeName = "SensitivePayload"
+				})
+				It("writes the redaction methods", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(sensitiveUserTypeRedact))
+					Ω(written).Should(ContainSubstring(sensitivePrivateUserTypeRedact))
+				})
+			})
+
 			Context("with a user type including hash", func() {
 				BeforeEach(func() {
 					attDef = &design.AttributeDefinition{
@@ -1563,6 +1695,102 @@ var _ = Describe("UserTypesWriter", func() {
 					Ω(written).Should(ContainSubstring(userTypeIncludingHash))
 				})
 			})
+
+			Context("with a union user type", func() {
+				BeforeEach(func() {
+					cat := &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{"kind": &design.AttributeDefinition{Type: design.String}},
+						},
+						TypeName: "Cat",
+					}
+					dog := &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{"kind": &design.AttributeDefinition{Type: design.String}},
+						},
+						TypeName: "Dog",
+					}
+					attDef = &design.AttributeDefinition{
+						Type: &design.Union{
+							Variants:      []*design.AttributeDefinition{{Type: cat}, {Type: dog}},
+							Discriminator: "kind",
+						},
+					}
+					typeName = "Pet"
+				})
+				It("writes the union struct and its JSON methods", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("type pet struct {\n\tCat *cat\n\tDog *dog\n}"))
+					Ω(written).Should(ContainSubstring("type Pet struct {\n\tCat *Cat\n\tDog *Dog\n}"))
+					Ω(written).Should(ContainSubstring("func (ut *pet) UnmarshalJSON(data []byte) error {"))
+					Ω(written).Should(ContainSubstring("func (ut Pet) MarshalJSON() ([]byte, error) {"))
+					Ω(written).Should(ContainSubstring("func (ut *pet) Publicize() *Pet {"))
+					Ω(written).Should(ContainSubstring("goa.InvalidUnionError(`type`, []string{\"Cat\", \"Dog\"}, variants)"))
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("SecurityWriter", func() {
+	var writer *genapp.SecurityWriter
+	var workspace *codegen.Workspace
+	var filename string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("controllers")
+		Ω(err).ShouldNot(HaveOccurred())
+		src, err := pkg.CreateSourceFile("test.go")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer src.Close()
+		filename = src.Abs()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		writer, err = genapp.NewSecurityWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a JWT security scheme validating claims", func() {
+		var schemes []*design.SecuritySchemeDefinition
+
+		BeforeEach(func() {
+			schemes = []*design.SecuritySchemeDefinition{{
+				SchemeName:        "jwt",
+				Kind:              design.JWTSecurityKind,
+				In:                "header",
+				Name:              "Authorization",
+				Issuers:           []string{"https://issuer.example.com"},
+				Audiences:         []string{"api"},
+				Leeway:            30 * time.Second,
+				RequiredClaims:    []string{"sub"},
+				SigningAlgorithms: []string{"RS256", "ES256"},
+			}}
+		})
+
+		It("writes the claim validation settings", func() {
+			err := writer.Execute(schemes)
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(b)
+			Ω(written).Should(ContainSubstring(`Issuers: []string{"https://issuer.example.com"},`))
+			Ω(written).Should(ContainSubstring(`Audiences: []string{"api"},`))
+			Ω(written).Should(ContainSubstring(`Leeway: 30000000000, // 30s`))
+			Ω(written).Should(ContainSubstring(`RequiredClaims: []string{"sub"},`))
+			Ω(written).Should(ContainSubstring(`SigningAlgorithms: []string{"RS256", "ES256"},`))
 		})
 	})
 })
@@ -1869,6 +2097,41 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 }
 `
 
+	sizedContext = `
+type ListBottleContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Day goa.Date
+	Ratio *float32
+}
+`
+
+	sizedContextFactory = `
+	paramDay := req.Params["day"]
+	if len(paramDay) == 0 {
+		err = goa.MergeErrors(err, goa.MissingParamError("day"))
+	} else {
+		rawDay := paramDay[0]
+		if day, err2 := goa.ParseDate(rawDay); err2 == nil {
+			rctx.Day = day
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("day", rawDay, "date"))
+		}
+	}
+	paramRatio := req.Params["ratio"]
+	if len(paramRatio) > 0 {
+		rawRatio := paramRatio[0]
+		if ratio, err2 := strconv.ParseFloat(rawRatio, 32); err2 == nil {
+			tmp1 := float32(ratio)
+			rctx.Ratio = &tmp1
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("ratio", rawRatio, "float32"))
+		}
+	}
+	return &rctx, err
+`
+
 	numNonOptionalContext = `
 type ListBottleContext struct {
 	context.Context
@@ -2360,7 +2623,7 @@ type PublicController interface {
 }
 `
 
-	fileServerOptionsHandler = `service.Mux.Handle("OPTIONS", "/public/star\\*star/*filepath", ctrl.MuxHandler("preflight", handlePublicOrigin(cors.HandlePreflight()), nil))`
+	fileServerOptionsHandler = `service.Mount("OPTIONS", "/public/star\\*star/*filepath", ctrl, "preflight", handlePublicOrigin(cors.HandlePreflight()), nil)`
 
 	simpleController = `// BottlesController is the controller interface for the Bottles actions.
 type BottlesController interface {
@@ -2371,7 +2634,7 @@ type BottlesController interface {
 
 	originsIntegration = `}
 	h = handleBottlesOrigin(h)
-	service.Mux.Handle`
+	service.Mount`
 
 	originsHandler = `// handleBottlesOrigin applies the CORS response headers corresponding to the origin.
 func handleBottlesOrigin(h goa.Handler) goa.Handler {
@@ -2472,7 +2735,7 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 		}
 		return ctrl.List(rctx)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.Mount("GET", "/accounts/:accountID/bottles", ctrl, "list", h, nil)
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 }
 `
@@ -2493,7 +2756,7 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 		}
 		return ctrl.List(rctx)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.Mount("GET", "/accounts/:accountID/bottles", ctrl, "list", h, nil)
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 }
 `
@@ -2522,7 +2785,7 @@ type BottlesController interface {
 		}
 		return ctrl.List(rctx)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.Mount("GET", "/accounts/:accountID/bottles", ctrl, "list", h, nil)
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -2537,7 +2800,7 @@ type BottlesController interface {
 		}
 		return ctrl.Show(rctx)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles/:id", ctrl.MuxHandler("show", h, nil))
+	service.Mount("GET", "/accounts/:accountID/bottles/:id", ctrl, "show", h, nil)
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "Show", "route", "GET /accounts/:accountID/bottles/:id")
 }
 `
@@ -2572,6 +2835,18 @@ func (ut *simplePayload) Publicize() *SimplePayload {
 type SimplePayload struct {
 	Name *string ` + "`" + `form:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"` + "`" + `
 }
+`
+
+	sensitivePrivateUserTypeRedact = `// Redact returns a copy of the sensitivePayload type instance where the sensitive attributes are hidden.
+func (ut *sensitivePayload) Redact() interface{} {
+	return goa.RedactJSON(ut, "password")
+}
+`
+
+	sensitiveUserTypeRedact = `// Redact returns a copy of the SensitivePayload type instance where the sensitive attributes are hidden.
+func (ut *SensitivePayload) Redact() interface{} {
+	return goa.RedactJSON(ut, "password")
+}
 `
 
 	userTypeIncludingHash = `// complexPayload user type.
@@ -2607,5 +2882,52 @@ type ComplexPayload struct {
 	Misc map[int]*MiscPayload ` + "`" + `form:"misc,omitempty" json:"misc,omitempty" yaml:"misc,omitempty" xml:"misc,omitempty"` + "`" + `
 	Name *string ` + "`" + `form:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"` + "`" + `
 }
+`
+
+	offsetPageLinks = `// SetPageLinks sets the Link response header with the URLs of the next and previous pages of the
+// bottles list action results. hasNext indicates whether there are items after the
+// current page.
+func (ctx *ListBottleContext) SetPageLinks(hasNext bool) {
+	if hasNext {
+		next := strconv.Itoa(ctx.Offset + ctx.Limit)
+		ctx.ResponseData.AddLink(goa.PageURL(ctx.RequestData.Request, map[string]string{"offset": next}), "next")
+	}
+	if ctx.Offset > 0 {
+		prev := ctx.Offset - ctx.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		ctx.ResponseData.AddLink(goa.PageURL(ctx.RequestData.Request, map[string]string{"offset": strconv.Itoa(prev)}), "prev")
+	}
+}
+`
+
+	cursorPageLinks = `// SetPageLinks sets the Link response header with the URLs of the next and previous pages of the
+// bottles list action results. Empty cursors are omitted.
+func (ctx *ListBottleContext) SetPageLinks(next, prev string) {
+	if next != "" {
+		ctx.ResponseData.AddLink(goa.PageURL(ctx.RequestData.Request, map[string]string{"cursor": next}), "next")
+	}
+	if prev != "" {
+		ctx.ResponseData.AddLink(goa.PageURL(ctx.RequestData.Request, map[string]string{"cursor": prev}), "prev")
+	}
+}
+`
+
+	streamResponse = `// OKStream opens the server-sent events stream of the response with status code 200.
+// The stream is closed when the action returns.
+func (ctx *ListBottleContext) OKStream() *ListBottleOKStream {
+	return &ListBottleOKStream{EventStream: goa.NewEventStream(ctx.Context, 200, 30 * time.Second)}
+}
+
+// ListBottleOKStream sends the events of the OK response of the list action.
+type ListBottleOKStream struct {
+	*goa.EventStream
+}
+
+// Send sends an event with the given data.
+func (s *ListBottleOKStream) Send(r *Bottle, opts ...goa.EventOption) error {
+	return s.EventStream.Send(r, opts...)
+}
 `
 )
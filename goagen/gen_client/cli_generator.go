@@ -126,6 +126,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	registerTmpl := template.Must(template.New("register").Funcs(funcs).Parse(registerTmpl))
 
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/base64"),
 		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("log"),
@@ -352,7 +353,8 @@ func flagTypeVal(a *design.AttributeDefinition, key string, field string) string
 		return `intFlagVal("` + key + `", ` + field + ")"
 	case design.String:
 		return `stringFlagVal("` + key + `", ` + field + ")"
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any,
+		design.Int64, design.UInt64, design.Float32, design.Bytes, design.Date, design.Duration:
 		return "%s"
 	default:
 		return "&" + field
@@ -365,7 +367,8 @@ func flagTypeVal(a *design.AttributeDefinition, key string, field string) string
 // %s maps to specialTypeResult.Temps
 func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
 	switch a.Type {
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any,
+		design.Int64, design.UInt64, design.Float32, design.Bytes, design.Date, design.Duration:
 		return "*%s"
 	default:
 		return field
@@ -377,7 +380,8 @@ func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
 // %s maps to specialTypeResult.Temps
 func flagTypeArrayVal(a *design.AttributeDefinition, field string) string {
 	switch a.Type.ToArray().ElemType.Type {
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any,
+		design.Int64, design.UInt64, design.Float32, design.Bytes, design.Date, design.Duration:
 		return "%s"
 	}
 	return field
@@ -443,6 +447,18 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 					typeHandler = "timeVal"
 				case design.Any:
 					typeHandler = "jsonVal"
+				case design.Int64:
+					typeHandler = "int64Val"
+				case design.UInt64:
+					typeHandler = "uint64Val"
+				case design.Float32:
+					typeHandler = "float32Val"
+				case design.Bytes:
+					typeHandler = "bytesVal"
+				case design.Date:
+					typeHandler = "dateVal"
+				case design.Duration:
+					typeHandler = "durationVal"
 				}
 
 			} else if a.Type.IsArray() {
@@ -458,6 +474,18 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 					typeHandler = "timeArray"
 				case design.Any:
 					typeHandler = "jsonArray"
+				case design.Int64:
+					typeHandler = "int64Array"
+				case design.UInt64:
+					typeHandler = "uint64Array"
+				case design.Float32:
+					typeHandler = "float32Array"
+				case design.Bytes:
+					typeHandler = "bytesArray"
+				case design.Date:
+					typeHandler = "dateArray"
+				case design.Duration:
+					typeHandler = "durationArray"
 				}
 			}
 			if typeHandler != "" {
@@ -571,12 +599,16 @@ func flagType(att *design.AttributeDefinition) string {
 		return "String"
 	case design.AnyKind:
 		return "String"
+	case design.Int64Kind, design.UInt64Kind, design.Float32Kind, design.BytesKind, design.DateKind, design.DurationKind:
+		return "String"
 	case design.ArrayKind:
 		switch att.Type.ToArray().ElemType.Type.Kind() {
 		case design.NumberKind:
 			return "StringSlice"
 		case design.BooleanKind:
 			return "StringSlice"
+		case design.Int64Kind, design.UInt64Kind, design.Float32Kind, design.BytesKind, design.DateKind, design.DurationKind:
+			return "StringSlice"
 		default:
 			return flagType(att.Type.(*design.Array).ElemType) + "Slice"
 		}
@@ -831,7 +863,7 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
 	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
 	resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
-	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
+	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsUnion .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
 	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
 	*/}}{{ if and .Action.Payload .HasMultiContent }}, cmd.ContentType{{ end }})
 	if err != nil {
@@ -1032,4 +1064,143 @@ func boolArray(ins []string) ([]bool, error) {
 		vals = append(vals, *val)
 	}
 	return vals, nil
+}
+
+func int64Val(val string) (*int64, error) {
+	t, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func int64Array(ins []string) ([]int64, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals []int64
+	for _, id := range ins {
+		val, err := int64Val(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
+}
+
+func uint64Val(val string) (*uint64, error) {
+	t, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func uint64Array(ins []string) ([]uint64, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals []uint64
+	for _, id := range ins {
+		val, err := uint64Val(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
+}
+
+func float32Val(val string) (*float32, error) {
+	t, err := strconv.ParseFloat(val, 32)
+	if err != nil {
+		return nil, err
+	}
+	f := float32(t)
+	return &f, nil
+}
+
+func float32Array(ins []string) ([]float32, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals []float32
+	for _, id := range ins {
+		val, err := float32Val(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
+}
+
+func bytesVal(val string) (*[]byte, error) {
+	t, err := base64.StdEncoding.DecodeString(val)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func bytesArray(ins []string) ([][]byte, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals [][]byte
+	for _, id := range ins {
+		val, err := bytesVal(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
+}
+
+func dateVal(val string) (*goa.Date, error) {
+	t, err := goa.ParseDate(val)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func dateArray(ins []string) ([]goa.Date, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals []goa.Date
+	for _, id := range ins {
+		val, err := dateVal(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
+}
+
+func durationVal(val string) (*goa.Duration, error) {
+	t, err := goa.ParseDuration(val)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func durationArray(ins []string) ([]goa.Duration, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals []goa.Duration
+	for _, id := range ins {
+		val, err := durationVal(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
 }`
@@ -7,8 +7,10 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -161,6 +163,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			"title":              strings.Title,
 			"toString":           toString,
 			"typeName":           typeName,
+			"unionMarshalers":    codegen.UnionMarshalers,
 			"format":             format,
 			"handleSpecialTypes": handleSpecialTypes,
 		}
@@ -266,6 +269,7 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 	// Setup codegen
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("time"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
@@ -279,15 +283,36 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 	}
 	g.genfiles = append(g.genfiles, clientFile)
 
+	// Compute the doers of the actions that define client policies
+	var policies []*policyData
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(action *design.ActionDefinition) error {
+			if action.WebSocket() {
+				return nil
+			}
+			decorators, err := clientPolicies(action)
+			if err != nil || len(decorators) == 0 {
+				return err
+			}
+			policies = append(policies, &policyData{Field: policyField(action), Decorators: decorators})
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
 	// Generate
 	data := struct {
 		API      *design.APIDefinition
 		Encoders []*genapp.EncoderTemplateData
 		Decoders []*genapp.EncoderTemplateData
+		Policies []*policyData
 	}{
 		API:      g.API,
 		Encoders: encoders,
 		Decoders: decoders,
+		Policies: policies,
 	}
 	err = clientTmpl.Execute(file, data)
 	return
@@ -331,6 +356,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 	}()
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("encoding/base64"),
 		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("io"),
@@ -346,7 +372,9 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 	}
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
 	if err = file.WriteHeader(title, g.Target, imports); err != nil {
@@ -506,6 +534,8 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		ParamNames         string
 		CanonicalScheme    string
 		Signer             string
+		Idempotent         bool
+		Doer               string
 		QueryParams        []*paramData
 		Headers            []*paramData
 	}{
@@ -522,18 +552,141 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		ParamNames:         strings.Join(names, ", "),
 		CanonicalScheme:    action.CanonicalScheme(),
 		Signer:             signer,
+		Idempotent:         action.Idempotent,
+		Doer:               "Client",
 		QueryParams:        queryParams,
 		Headers:            headers,
 	}
+	if policies, err := clientPolicies(action); err != nil {
+		return err
+	} else if len(policies) > 0 {
+		data.Doer = policyField(action)
+	}
 	if action.WebSocket() {
-		return clientsWSTmpl.Execute(file, data)
+		if err := clientsWSTmpl.Execute(file, data); err != nil {
+			return err
+		}
+		return g.generateConnClient(action, data.Params, data.ParamNames, file, funcs)
 	}
 	if err := clientsTmpl.Execute(file, data); err != nil {
 		return err
 	}
+	if action.Pagination != nil {
+		if err := g.generatePagesClient(action, data.Params, data.ParamNames, queryParams, file, funcs); err != nil {
+			return err
+		}
+	}
+	if err := g.generateEventsClient(action, data.Params, data.ParamNames, file, funcs); err != nil {
+		return err
+	}
 	return requestsTmpl.Execute(file, data)
 }
 
+// generateConnClient generates the client method and connection type that exchange the websocket
+// messages of an action if any.
+func (g *Generator) generateConnClient(action *design.ActionDefinition, params, names string, file *codegen.SourceFile, funcs template.FuncMap) error {
+	if !action.HasMessages() {
+		return nil
+	}
+	data := map[string]interface{}{
+		"Name":         action.Name,
+		"ResourceName": action.Parent.Name,
+		"Params":       params,
+		"ParamNames":   names,
+	}
+	if t := action.InboundMessage; t != nil {
+		data["Inbound"] = decodeGoTypeRef(t, nil, 0, false)
+	}
+	if t := action.OutboundMessage; t != nil {
+		data["Outbound"] = decodeGoTypeRef(t, nil, 0, false)
+		data["OutboundName"] = decodeGoTypeName(t, nil, 0, false)
+	}
+	connTmpl := template.Must(template.New("conn").Funcs(funcs).Parse(connTmpl))
+	return connTmpl.Execute(file, data)
+}
+
+// generateEventsClient generates the client method and iterator type that read the server-sent
+// events returned by an action if any.
+func (g *Generator) generateEventsClient(action *design.ActionDefinition, params, names string, file *codegen.SourceFile, funcs template.FuncMap) error {
+	var resp *design.ResponseDefinition
+	action.IterateResponses(func(r *design.ResponseDefinition) error {
+		if resp == nil && r.Stream != nil {
+			resp = r
+		}
+		return nil
+	})
+	if resp == nil {
+		return nil
+	}
+	data := map[string]interface{}{
+		"Name":         action.Name,
+		"ResourceName": action.Parent.Name,
+		"Params":       params,
+		"ParamNames":   names,
+		"Status":       resp.Status,
+		"TypeRef":      "[]byte",
+	}
+	if mt := g.API.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		projected, _, err := mt.Project(view)
+		if err != nil {
+			return err
+		}
+		data["Type"] = projected
+		data["TypeRef"] = decodeGoTypeRef(projected, projected.AllRequired(), 0, false)
+	}
+	funcs["decodegotypename"] = decodeGoTypeName
+	eventsTmpl := template.Must(template.New("events").Funcs(funcs).Parse(eventsTmpl))
+	return eventsTmpl.Execute(file, data)
+}
+
+// generatePagesClient generates the client method that iterates through the pages returned by a
+// paginated action.
+func (g *Generator) generatePagesClient(action *design.ActionDefinition, params, names string, queryParams []*paramData, file *codegen.SourceFile, funcs template.FuncMap) error {
+	resp, ok := action.Responses[design.OK]
+	if !ok {
+		return nil
+	}
+	mt := g.API.MediaTypeWithIdentifier(resp.MediaType)
+	if mt == nil {
+		return nil // Response type is not a media type, no decoder to use
+	}
+	view := resp.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	projected, _, err := mt.Project(view)
+	if err != nil {
+		return err
+	}
+	var page *paramData
+	for _, p := range queryParams {
+		if p.Name == action.Pagination.PageParam() {
+			page = p
+			break
+		}
+	}
+	if page == nil {
+		return nil
+	}
+	funcs["decodegotyperef"] = decodeGoTypeRef
+	pagesTmpl := template.Must(template.New("pages").Funcs(funcs).Parse(pagesTmpl))
+	data := map[string]interface{}{
+		"Name":         action.Name,
+		"ResourceName": action.Parent.Name,
+		"Params":       params,
+		"ParamNames":   names,
+		"Kind":         string(action.Pagination.Kind),
+		"Page":         page,
+		"Type":         projected,
+		"TypeName":     typeName(projected),
+	}
+	return pagesTmpl.Execute(file, data)
+}
+
 // fileServerMethod returns the name of the client method for downloading assets served by the given
 // file server.
 // Note: the implementation opts for generating good names rather than names that are guaranteed to
@@ -643,6 +796,7 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
@@ -760,9 +914,9 @@ func cmdFieldTypeString(t design.DataType, point bool) string {
 	if point && !t.IsArray() {
 		pointer = "*"
 	}
-	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind {
+	if isStringFlag(t) {
 		suffix = "string"
-	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind) {
+	} else if t.IsArray() && isStringFlag(t.ToArray().ElemType.Type) {
 		suffix = "[]string"
 	} else {
 		suffix = codegen.GoNativeType(t)
@@ -770,15 +924,13 @@ func cmdFieldTypeString(t design.DataType, point bool) string {
 	return pointer + suffix
 }
 
-func isArrayOfType(array design.DataType, kinds ...design.Kind) bool {
-	if !array.IsArray() {
-		return false
-	}
-	kind := array.ToArray().ElemType.Type.Kind()
-	for _, t := range kinds {
-		if t == kind {
-			return true
-		}
+// isStringFlag returns true if command flags of the given type are stored as strings that must be
+// parsed prior to making the request.
+func isStringFlag(t design.DataType) bool {
+	switch t.Kind() {
+	case design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind,
+		design.Int64Kind, design.UInt64Kind, design.Float32Kind, design.BytesKind, design.DateKind, design.DurationKind:
+		return true
 	}
 	return false
 }
@@ -808,6 +960,16 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 			return fmt.Sprintf("%s := fmt.Sprintf(\"%%v\", %s)", target, name)
 		case design.FileKind:
 			return fmt.Sprintf("%s := fmt.Sprintf(\"%%v\", %s)", target, name)
+		case design.Int64Kind:
+			return fmt.Sprintf("%s := strconv.FormatInt(%s, 10)", target, name)
+		case design.UInt64Kind:
+			return fmt.Sprintf("%s := strconv.FormatUint(%s, 10)", target, name)
+		case design.Float32Kind:
+			return fmt.Sprintf("%s := strconv.FormatFloat(float64(%s), 'f', -1, 32)", target, name)
+		case design.BytesKind:
+			return fmt.Sprintf("%s := base64.StdEncoding.EncodeToString(%s)", target, name)
+		case design.DateKind, design.DurationKind:
+			return fmt.Sprintf("%s := %s.String()", target, strings.Replace(name, "*", "", -1)) // remove pointer if present
 		default:
 			panic("unknown primitive type")
 		}
@@ -835,6 +997,77 @@ func defaultPath(action *design.ActionDefinition) string {
 	return ""
 }
 
+// clientPolicies returns the code that creates the client decorators configured with the
+// client:retry, client:breaker and client:hedge action metadata.
+func clientPolicies(action *design.ActionDefinition) ([]string, error) {
+	var decorators []string
+	invalid := func(key string, err error) error {
+		return fmt.Errorf("action %s of resource %s: invalid %s metadata: %s", action.Name, action.Parent.Name, key, err)
+	}
+	if vals, ok := action.Metadata["client:retry"]; ok {
+		var fields []string
+		if len(vals) > 0 {
+			n, err := strconv.Atoi(vals[0])
+			if err != nil {
+				return nil, invalid("client:retry", err)
+			}
+			fields = append(fields, fmt.Sprintf("MaxAttempts: %d", n))
+		}
+		if codes, ok := action.Metadata["client:retry:status"]; ok {
+			for _, c := range codes {
+				if _, err := strconv.Atoi(c); err != nil {
+					return nil, invalid("client:retry:status", err)
+				}
+			}
+			fields = append(fields, fmt.Sprintf("StatusCodes: []int{%s}", strings.Join(codes, ", ")))
+		}
+		decorators = append(decorators, fmt.Sprintf("goaclient.Retry(goaclient.RetryPolicy{%s})", strings.Join(fields, ", ")))
+	}
+	if vals, ok := action.Metadata["client:breaker"]; ok {
+		var fields []string
+		if len(vals) > 0 {
+			n, err := strconv.Atoi(vals[0])
+			if err != nil {
+				return nil, invalid("client:breaker", err)
+			}
+			fields = append(fields, fmt.Sprintf("FailureThreshold: %d", n))
+		}
+		if len(vals) > 1 {
+			d, err := time.ParseDuration(vals[1])
+			if err != nil {
+				return nil, invalid("client:breaker", err)
+			}
+			fields = append(fields, "OpenTimeout: "+genapp.DurationCode(d))
+		}
+		decorators = append(decorators, fmt.Sprintf("goaclient.CircuitBreaker(goaclient.BreakerPolicy{%s})", strings.Join(fields, ", ")))
+	}
+	if vals, ok := action.Metadata["client:hedge"]; ok {
+		var fields []string
+		if len(vals) > 0 {
+			d, err := time.ParseDuration(vals[0])
+			if err != nil {
+				return nil, invalid("client:hedge", err)
+			}
+			fields = append(fields, "Delay: "+genapp.DurationCode(d))
+		}
+		if len(vals) > 1 {
+			n, err := strconv.Atoi(vals[1])
+			if err != nil {
+				return nil, invalid("client:hedge", err)
+			}
+			fields = append(fields, fmt.Sprintf("MaxRequests: %d", n))
+		}
+		decorators = append(decorators, fmt.Sprintf("goaclient.Hedge(goaclient.HedgePolicy{%s})", strings.Join(fields, ", ")))
+	}
+	return decorators, nil
+}
+
+// policyField returns the name of the client struct field holding the doer used by the action
+// requests.
+func policyField(action *design.ActionDefinition) string {
+	return codegen.Goify(fmt.Sprintf("%s_%s_doer", action.Name, action.Parent.Name), false)
+}
+
 // signerType returns the name of the client signer used for the defined security model on the Action
 func signerType(scheme *design.SecuritySchemeDefinition) string {
 	switch scheme.Kind {
@@ -930,6 +1163,13 @@ type paramData struct {
 	CheckNil      bool
 }
 
+// policyData is the data structure holding the information needed to generate the doer of an
+// action that defines client policies.
+type policyData struct {
+	Field      string
+	Decorators []string
+}
+
 type byParamName []*paramData
 
 func (b byParamName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
@@ -946,13 +1186,14 @@ const (
 
 	payloadTmpl = `// {{ gotypename .Payload nil 0 false }} is the {{ .Parent.Name }} {{ .Name }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
-`
+{{ if .Payload.IsUnion }}
+{{ unionMarshalers .Payload false }}{{ end }}`
 
 	typeDecodeTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
 func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .AllRequired 0 false }}, error) {
 	var decoded {{ decodegotypename . .AllRequired 0 false }}
 	err := c.Decoder.Decode(&decoded, resp.Body, resp.Header.Get("Content-Type"))
-	return {{ if .IsObject }}&{{ end }}decoded, err
+	return {{ if or .IsObject .IsUnion }}&{{ end }}decoded, err
 }
 `
 
@@ -974,8 +1215,122 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	if err != nil {
 		return nil, err
 	}
-	return c.Client.Do(ctx, req)
+	return c.{{ .Doer }}.Do(ctx, req)
 }
+`
+
+	pagesTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Pages iterates through the pages returned by the {{ .Name }} action endpoint of the
+// {{ .ResourceName }} resource. It calls fn with the content of each page until fn returns false or there
+// are no more pages. The {{ .Page.Name }} of the next page is read from the response Link header.
+func (c *Client) {{ $funcName }}Pages(ctx context.Context, path string, {{ .Params }}, fn func({{ decodegotyperef .Type .Type.AllRequired 0 false }}) bool) error {
+	for {
+		resp, err := c.{{ $funcName }}(ctx, path, {{ .ParamNames }})
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected response status: %s", resp.Status)
+		}
+		page, err := c.Decode{{ .TypeName }}(resp)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if !fn(page) {
+			return nil
+		}
+		next := goaclient.LinkParam(resp, "next", "{{ .Page.Name }}")
+		if next == "" {
+			return nil
+		}
+{{ if eq .Kind "offset" }}		n, err := strconv.Atoi(next)
+		if err != nil {
+			return fmt.Errorf("invalid next page offset %q", next)
+		}
+		{{ .Page.VarName }} = {{ if .Page.CheckNil }}&{{ end }}n
+{{ else }}		{{ .Page.VarName }} = {{ if .Page.CheckNil }}&{{ end }}next
+{{ end }}	}
+}
+`
+
+	eventsTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}EventStream iterates over the server-sent events returned by the {{ .Name }} action
+// endpoint of the {{ .ResourceName }} resource.
+type {{ $funcName }}EventStream struct {
+	c    *Client
+	resp *http.Response
+	dec  *goa.EventDecoder
+}
+
+// {{ $funcName }}Events opens the server-sent events stream returned by the {{ .Name }} action endpoint
+// of the {{ .ResourceName }} resource. The stream must be closed once done.
+func (c *Client) {{ $funcName }}Events(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}) (*{{ $funcName }}EventStream, error) {
+	resp, err := c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != {{ .Status }} {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return &{{ $funcName }}EventStream{c: c, resp: resp, dec: goa.NewEventDecoder(resp.Body)}, nil
+}
+
+// Next reads the next event and returns its decoded data. It returns io.EOF once the stream ends.
+func (s *{{ $funcName }}EventStream) Next() ({{ .TypeRef }}, *goa.Event, error) {
+	e, err := s.dec.Decode()
+	if err != nil {
+		return nil, nil, err
+	}
+{{ if .Type }}	var decoded {{ decodegotypename .Type .Type.AllRequired 0 false }}
+	if err := s.c.Decoder.Decode(&decoded, bytes.NewReader(e.Data), "application/json"); err != nil {
+		return nil, e, err
+	}
+	return {{ if or .Type.IsObject .Type.IsUnion }}&{{ end }}decoded, e, nil
+{{ else }}	return e.Data, e, nil
+{{ end }}}
+
+// Close closes the stream.
+func (s *{{ $funcName }}EventStream) Close() error {
+	return s.resp.Body.Close()
+}
+
+`
+
+	connTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Conn is a websocket connection to the {{ .Name }} action endpoint of the {{ .ResourceName }}
+// resource.
+type {{ $funcName }}Conn struct {
+	*goa.WebSocketConn
+}
+
+// Dial{{ $funcName }} establishes a websocket connection to the {{ .Name }} action endpoint of the
+// {{ .ResourceName }} resource. The connection sends pings periodically and must be closed once done.
+func (c *Client) Dial{{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}) (*{{ $funcName }}Conn, error) {
+	ws, err := c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }})
+	if err != nil {
+		return nil, err
+	}
+	return &{{ $funcName }}Conn{WebSocketConn: goa.NewWebSocketConn(ws, goa.DefaultPingInterval)}, nil
+}
+{{ if .Inbound }}
+// Send validates and sends a message to the server.
+func (c *{{ $funcName }}Conn) Send(msg {{ .Inbound }}) error {
+	return c.WebSocketConn.Send(msg)
+}
+{{ end }}{{ if .Outbound }}
+// Receive reads and validates the next message sent by the server. It returns io.EOF once the
+// server closed the connection.
+func (c *{{ $funcName }}Conn) Receive() ({{ .Outbound }}, error) {
+	var msg {{ .OutboundName }}
+	if err := c.WebSocketConn.Receive(&msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+{{ end }}
 `
 
 	clientsWSTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $desc := .Description }}{{/*
@@ -1140,7 +1495,10 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	header.Set("{{ .Name }}", {{ $tmp }}){{ else }}
 	header.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}{{ end }}
-{{ end }}{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
+{{ end }}{{ end }}{{ if .Idempotent }}	if req.Header.Get(goaclient.IdempotencyKeyHeader) == "" {
+		req.Header.Set(goaclient.IdempotencyKeyHeader, goaclient.IdempotencyKey(ctx))
+	}
+{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
 		if err := c.{{ .Signer }}Signer.Sign(req); err != nil {
 			return nil, err
 		}
@@ -1155,7 +1513,8 @@ type Client struct {
 	{{ goify $security.SchemeName true }}Signer goaclient.Signer{{ end }}{{ end }}
 	Encoder *goa.HTTPEncoder
 	Decoder *goa.HTTPDecoder
-}
+{{ range .Policies }}	{{ .Field }} goaclient.Doer
+{{ end }}}
 
 // New instantiates the client.
 func New(c goaclient.Doer) *Client {
@@ -1178,6 +1537,9 @@ func New(c goaclient.Doer) *Client {
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	client.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}
+{{ end }}{{ if .Policies }}	// Setup the action policies
+{{ range .Policies }}	client.{{ .Field }} = goaclient.Decorate(client.Client, {{ joinStrings .Decorators ", " }})
+{{ end }}
 {{ end }}	return client
 }
 
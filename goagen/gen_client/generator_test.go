@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_client"
@@ -375,6 +376,133 @@ var _ = Describe("Generate", func() {
 				Ω(files).Should(HaveLen(5)) // 9, minus 4 entries for tool paths
 			})
 		})
+
+		Context("with messages", func() {
+			BeforeEach(func() {
+				message := &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"body": &design.AttributeDefinition{Type: design.String}},
+					},
+					TypeName: "Message",
+				}
+				design.Design.Types = map[string]*design.UserTypeDefinition{"Message": message}
+				showAct := design.Design.Resources["foo"].Actions["show"]
+				showAct.InboundMessage = message
+				showAct.OutboundMessage = message
+			})
+
+			It("generates a typed connection", func() {
+				Ω(genErr).Should(BeNil())
+				c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				content := string(c)
+				Ω(content).Should(ContainSubstring("func (c *Client) DialShowFoo(ctx context.Context, path string, fieldsBar []string, fieldsBat *time.Time, fieldsBaz []int, fieldsFoo *string) (*ShowFooConn, error) {"))
+				Ω(content).Should(ContainSubstring("ws, err := c.ShowFoo(ctx, path, fieldsBar, fieldsBat, fieldsBaz, fieldsFoo)"))
+				Ω(content).Should(ContainSubstring("func (c *ShowFooConn) Send(msg *Message) error {"))
+				Ω(content).Should(ContainSubstring("func (c *ShowFooConn) Receive() (*Message, error) {"))
+			})
+		})
+	})
+
+	Context("with an idempotent action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name: "create",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "POST",
+										Path: "",
+									},
+								},
+								Idempotent: true,
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createAct := fooRes.Actions["create"]
+			createAct.Parent = fooRes
+			createAct.Routes[0].Parent = createAct
+		})
+
+		It("sets the idempotency key header", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(c)).Should(ContainSubstring(`	if req.Header.Get(goaclient.IdempotencyKeyHeader) == "" {
+		req.Header.Set(goaclient.IdempotencyKeyHeader, goaclient.IdempotencyKey(ctx))
+	}
+`))
+		})
+	})
+
+	Context("with client policies", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Metadata: dslengine.MetadataDefinition{
+									"client:retry":        {"5"},
+									"client:retry:status": {"409", "503"},
+									"client:breaker":      {"3", "1m"},
+									"client:hedge":        {"50ms"},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("decorates the action doer", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("	showFooDoer goaclient.Doer\n"))
+			Ω(content).Should(ContainSubstring("	client.showFooDoer = goaclient.Decorate(client.Client, " +
+				"goaclient.Retry(goaclient.RetryPolicy{MaxAttempts: 5, StatusCodes: []int{409, 503}}), " +
+				"goaclient.CircuitBreaker(goaclient.BreakerPolicy{FailureThreshold: 3, OpenTimeout: time.Minute}), " +
+				"goaclient.Hedge(goaclient.HedgePolicy{Delay: 50 * time.Millisecond}))\n"))
+			c, err = ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(c)).Should(ContainSubstring("	return c.showFooDoer.Do(ctx, req)\n"))
+		})
+
+		Context("with invalid metadata", func() {
+			BeforeEach(func() {
+				design.Design.Resources["foo"].Actions["show"].Metadata = dslengine.MetadataDefinition{"client:hedge": {"soon"}}
+			})
+
+			It("fails", func() {
+				Ω(genErr).Should(HaveOccurred())
+				Ω(genErr.Error()).Should(ContainSubstring("invalid client:hedge metadata"))
+			})
+		})
 	})
 
 	Context("with an action with multiple routes", func() {
@@ -565,6 +693,96 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring("uuid \"github.com/goadesign/goa/uuid\""))
 		})
 	})
+
+	Context("with paginated actions", func() {
+		// Other tests replace design.Design, keep the definition registered with the DSL engine.
+		root := design.Design
+
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = root
+			dslengine.Reset()
+			API("testapi", func() {})
+			item := MediaType("application/vnd.item", func() {
+				Attributes(func() {
+					Attribute("name", design.String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("items", func() {
+				Action("list", func() {
+					Routing(GET("/items"))
+					Paginated(design.CursorPagination)
+					Response(design.OK, CollectionOf(item))
+				})
+				Action("search", func() {
+					Routing(GET("/search"))
+					Params(func() {
+						Param("q", design.String)
+						Required("q")
+					})
+					Paginated(design.OffsetPagination)
+					Response(design.OK, CollectionOf(item))
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the page iterators", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "items.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring(cursorPagesCode))
+			Ω(content).Should(ContainSubstring(offsetPagesCode))
+		})
+	})
+
+	Context("with server-sent events actions", func() {
+		// Other tests replace design.Design, keep the definition registered with the DSL engine.
+		root := design.Design
+
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = root
+			dslengine.Reset()
+			API("testapi", func() {})
+			item := MediaType("application/vnd.item", func() {
+				Attributes(func() {
+					Attribute("name", design.String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("items", func() {
+				Action("watch", func() {
+					Routing(GET("/items/events"))
+					Response(design.OK, func() {
+						Stream(item)
+					})
+				})
+				Action("logs", func() {
+					Routing(GET("/logs"))
+					Response(design.OK, "text/plain", func() {
+						ServerSentEvents()
+					})
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the event iterators", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "items.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring(eventsCode))
+			Ω(content).Should(ContainSubstring("func (s *LogsItemsEventStream) Next() ([]byte, *goa.Event, error) {"))
+		})
+	})
 })
 
 var _ = Describe("NewGenerator", func() {
@@ -623,3 +841,89 @@ const clientHeaderTmpl = `// Code generated by goagen {{ .version }}, DO NOT EDI
 // --design={{.design}}
 // --version={{.version}}
 `
+
+const (
+	cursorPagesCode = `// ListItemsPages iterates through the pages returned by the list action endpoint of the
+// items resource. It calls fn with the content of each page until fn returns false or there
+// are no more pages. The cursor of the next page is read from the response Link header.
+func (c *Client) ListItemsPages(ctx context.Context, path string, cursor *string, limit *int, fn func(ItemCollection) bool) error {
+	for {
+		resp, err := c.ListItems(ctx, path, cursor, limit)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected response status: %s", resp.Status)
+		}
+		page, err := c.DecodeItemCollection(resp)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if !fn(page) {
+			return nil
+		}
+		next := goaclient.LinkParam(resp, "next", "cursor")
+		if next == "" {
+			return nil
+		}
+		cursor = &next
+	}
+}
+`
+
+	eventsCode = `// WatchItemsEventStream iterates over the server-sent events returned by the watch action
+// endpoint of the items resource.
+type WatchItemsEventStream struct {
+	c    *Client
+	resp *http.Response
+	dec  *goa.EventDecoder
+}
+
+// WatchItemsEvents opens the server-sent events stream returned by the watch action endpoint
+// of the items resource. The stream must be closed once done.
+func (c *Client) WatchItemsEvents(ctx context.Context, path string) (*WatchItemsEventStream, error) {
+	resp, err := c.WatchItems(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return &WatchItemsEventStream{c: c, resp: resp, dec: goa.NewEventDecoder(resp.Body)}, nil
+}
+
+// Next reads the next event and returns its decoded data. It returns io.EOF once the stream ends.
+func (s *WatchItemsEventStream) Next() (*Item, *goa.Event, error) {
+	e, err := s.dec.Decode()
+	if err != nil {
+		return nil, nil, err
+	}
+	var decoded Item
+	if err := s.c.Decoder.Decode(&decoded, bytes.NewReader(e.Data), "application/json"); err != nil {
+		return nil, e, err
+	}
+	return &decoded, e, nil
+}
+
+// Close closes the stream.
+func (s *WatchItemsEventStream) Close() error {
+	return s.resp.Body.Close()
+}
+`
+
+	offsetPagesCode = `		next := goaclient.LinkParam(resp, "next", "offset")
+		if next == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(next)
+		if err != nil {
+			return fmt.Errorf("invalid next page offset %q", next)
+		}
+		offset = &n
+	}
+}
+`
+)
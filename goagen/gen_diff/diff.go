@@ -0,0 +1,445 @@
+package gendiff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// Added indicates an element that only exists in the new design.
+	Added ChangeKind = "added"
+	// Removed indicates an element that only exists in the base design.
+	Removed ChangeKind = "removed"
+	// Changed indicates an element that exists in both designs but differs.
+	Changed ChangeKind = "changed"
+)
+
+type (
+	// ChangeKind describes the nature of a change.
+	ChangeKind string
+
+	// Change describes a single difference between two designs.
+	Change struct {
+		// Kind is the nature of the change.
+		Kind ChangeKind `json:"kind"`
+		// Path identifies the element that changed, e.g. "bottle.show.params.id".
+		// Media type elements are prefixed with the media type identifier in brackets.
+		Path string `json:"path"`
+		// Description is a human readable description of the change.
+		Description string `json:"description"`
+		// Breaking is true if the change may break existing clients.
+		Breaking bool `json:"breaking"`
+	}
+
+	// Report lists the differences between two designs.
+	Report struct {
+		// Breaking is true if at least one change is breaking.
+		Breaking bool `json:"breaking"`
+		// Changes lists the differences.
+		Changes []*Change `json:"changes"`
+	}
+)
+
+// Compare computes the differences between the base design and the new design. Changes are
+// classified from the point of view of existing clients: changes that may cause requests that
+// used to be accepted to be rejected or responses that used to be understood to be rejected are
+// breaking. The attributes of requests (parameters, headers and payloads) and responses (media
+// types) are thus compared with opposite rules: for example making a request attribute required
+// is breaking while making a response attribute required is not.
+func Compare(base, api *Snapshot) *Report {
+	r := &Report{Changes: []*Change{}}
+	for _, name := range keys(base.Resources, api.Resources) {
+		r.compareResource(name, base.Resources[name], api.Resources[name])
+	}
+	for _, id := range keys(base.MediaTypes, api.MediaTypes) {
+		r.compareMediaType(id, base.MediaTypes[id], api.MediaTypes[id])
+	}
+	return r
+}
+
+// WriteText writes a human readable version of the report to w.
+func (r *Report) WriteText(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+	breaking := 0
+	for _, c := range r.Changes {
+		label := ""
+		if c.Breaking {
+			label = "BREAKING"
+			breaking++
+		}
+		if _, err := fmt.Fprintf(w, "%-8s  %-7s  %s: %s\n", label, c.Kind, c.Path, c.Description); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d change(s), %d breaking\n", len(r.Changes), breaking)
+	return err
+}
+
+func (r *Report) add(kind ChangeKind, breaking bool, path, format string, args ...interface{}) {
+	r.Changes = append(r.Changes, &Change{
+		Kind:        kind,
+		Path:        path,
+		Description: fmt.Sprintf(format, args...),
+		Breaking:    breaking,
+	})
+	if breaking {
+		r.Breaking = true
+	}
+}
+
+func (r *Report) compareResource(name string, base, res *Resource) {
+	switch {
+	case base == nil:
+		r.add(Added, false, name, "resource added")
+		return
+	case res == nil:
+		r.add(Removed, true, name, "resource removed")
+		return
+	}
+	for _, a := range keys(base.Actions, res.Actions) {
+		r.compareAction(name+"."+a, base.Actions[a], res.Actions[a])
+	}
+}
+
+func (r *Report) compareAction(path string, base, a *Action) {
+	switch {
+	case base == nil:
+		r.add(Added, false, path, "action added")
+		return
+	case a == nil:
+		r.add(Removed, true, path, "action removed")
+		return
+	}
+
+	// Routes
+	routes := make(map[string]bool)
+	for _, route := range a.Routes {
+		routes[route] = true
+	}
+	for _, route := range base.Routes {
+		if !routes[route] {
+			r.add(Removed, true, path, "route %s removed", route)
+		}
+		delete(routes, route)
+	}
+	for _, route := range a.Routes {
+		if routes[route] {
+			r.add(Added, false, path, "route %s added", route)
+		}
+	}
+
+	// Request
+	r.compareAttribute(path+".params", orEmpty(base.Params), orEmpty(a.Params), true)
+	r.compareAttribute(path+".headers", orEmpty(base.Headers), orEmpty(a.Headers), true)
+	switch {
+	case base.Payload == nil && a.Payload != nil:
+		r.add(Added, !a.PayloadOptional, path+".payload", "payload added")
+	case base.Payload != nil && a.Payload == nil:
+		r.add(Removed, true, path+".payload", "payload removed")
+	case base.Payload != nil:
+		if base.PayloadOptional && !a.PayloadOptional {
+			r.add(Changed, true, path+".payload", "payload is now required")
+		} else if !base.PayloadOptional && a.PayloadOptional {
+			r.add(Changed, false, path+".payload", "payload is now optional")
+		}
+		r.compareAttribute(path+".payload", base.Payload, a.Payload, true)
+	}
+
+	// WebSocket messages
+	r.compareMessage(path+".inbound", base.Inbound, a.Inbound, true)
+	r.compareMessage(path+".outbound", base.Outbound, a.Outbound, false)
+
+	// Responses
+	for _, code := range keys(base.Responses, a.Responses) {
+		bresp, resp := base.Responses[code], a.Responses[code]
+		rpath := path + ".responses." + code
+		switch {
+		case bresp == nil:
+			r.add(Added, false, rpath, "response %s added", resp.Name)
+		case resp == nil:
+			r.add(Removed, true, rpath, "response %s removed", bresp.Name)
+		case bresp.MediaType != resp.MediaType:
+			r.add(Changed, true, rpath, "media type changed from %q to %q", bresp.MediaType, resp.MediaType)
+		case bresp.View != resp.View:
+			r.add(Changed, true, rpath, "view changed from %q to %q", bresp.View, resp.View)
+		case bresp.Stream != resp.Stream:
+			if resp.Stream {
+				r.add(Changed, true, rpath, "response is now a stream of server-sent events")
+			} else {
+				r.add(Changed, true, rpath, "response is no longer a stream of server-sent events")
+			}
+		}
+	}
+}
+
+func (r *Report) compareMessage(path string, base, msg *Attribute, request bool) {
+	switch {
+	case base == nil && msg != nil:
+		r.add(Added, false, path, "websocket message type added")
+	case base != nil && msg == nil:
+		r.add(Removed, true, path, "websocket message type removed")
+	default:
+		r.compareAttribute(path, base, msg, request)
+	}
+}
+
+func (r *Report) compareMediaType(id string, base, mt *MediaType) {
+	path := "[" + id + "]"
+	switch {
+	case base == nil:
+		r.add(Added, false, path, "media type added")
+		return
+	case mt == nil:
+		r.add(Removed, true, path, "media type removed")
+		return
+	}
+	r.compareAttribute(path, base.Attribute, mt.Attribute, false)
+	for _, v := range keys(base.Views, mt.Views) {
+		bview, view := base.Views[v], mt.Views[v]
+		vpath := path + ".views." + v
+		switch {
+		case bview == nil:
+			r.add(Added, false, vpath, "view added")
+		case view == nil:
+			r.add(Removed, true, vpath, "view removed")
+		default:
+			for _, n := range difference(bview, view) {
+				r.add(Removed, true, vpath, "attribute %s removed from view", n)
+			}
+			for _, n := range difference(view, bview) {
+				r.add(Added, false, vpath, "attribute %s added to view", n)
+			}
+		}
+	}
+}
+
+// compareAttribute compares two attributes. request is true if the attributes describe request
+// data and false if they describe response data.
+func (r *Report) compareAttribute(path string, base, att *Attribute, request bool) {
+	if base == nil || att == nil {
+		return
+	}
+	if base.Type != att.Type {
+		r.add(Changed, true, path, "type changed from %s to %s", base.Type, att.Type)
+		return
+	}
+	if base.Ref != "" || att.Ref != "" {
+		if base.Ref != att.Ref {
+			r.add(Changed, true, path, "type changed from %s to %s", typeName(base), typeName(att))
+		}
+		return
+	}
+	r.compareValidation(path, base.Validation, att.Validation, request)
+
+	required := func(a *Attribute, n string) bool {
+		for _, req := range a.Required {
+			if req == n {
+				return true
+			}
+		}
+		return false
+	}
+	for _, n := range keys(base.Attributes, att.Attributes) {
+		child := path + "." + n
+		bchild, achild := base.Attributes[n], att.Attributes[n]
+		breq, areq := required(base, n), required(att, n)
+		switch {
+		case bchild == nil:
+			r.add(Added, request && areq, child, "attribute added%s", requiredSuffix(areq))
+			continue
+		case achild == nil:
+			r.add(Removed, true, child, "attribute removed")
+			continue
+		case !breq && areq:
+			r.add(Changed, request, child, "attribute is now required")
+		case breq && !areq:
+			r.add(Changed, !request, child, "attribute is no longer required")
+		}
+		r.compareAttribute(child, bchild, achild, request)
+	}
+	r.compareAttribute(path+"{key}", base.Key, att.Key, request)
+	r.compareAttribute(path+"[]", base.Elem, att.Elem, request)
+}
+
+// compareValidation compares two sets of validations. A validation that rejects values that
+// used to be valid breaks requests while a validation that accepts values that used to be
+// invalid breaks responses.
+func (r *Report) compareValidation(path string, base, v *Validation, request bool) {
+	if base == nil {
+		base = &Validation{}
+	}
+	if v == nil {
+		v = &Validation{}
+	}
+	change := func(narrowed, widened bool, format string, args ...interface{}) {
+		breaking := (request && narrowed) || (!request && widened)
+		r.add(Changed, breaking, path, format, args...)
+	}
+
+	// Enum
+	bvals, vals := toStrings(base.Values), toStrings(v.Values)
+	removed, added := difference(bvals, vals), difference(vals, bvals)
+	switch {
+	case len(bvals) == 0 && len(vals) > 0:
+		change(true, false, "enum validation added: %s", strings.Join(vals, ", "))
+	case len(bvals) > 0 && len(vals) == 0:
+		change(false, true, "enum validation removed")
+	case len(removed) > 0 || len(added) > 0:
+		var desc []string
+		if len(removed) > 0 {
+			desc = append(desc, "removed "+strings.Join(removed, ", "))
+		}
+		if len(added) > 0 {
+			desc = append(desc, "added "+strings.Join(added, ", "))
+		}
+		change(len(removed) > 0, len(added) > 0, "enum values changed: %s", strings.Join(desc, "; "))
+	}
+
+	// Format and pattern
+	if base.Format != v.Format {
+		change(v.Format != "", base.Format != "", "format changed from %q to %q", base.Format, v.Format)
+	}
+	if base.Pattern != v.Pattern {
+		change(v.Pattern != "", base.Pattern != "", "pattern changed from %q to %q", base.Pattern, v.Pattern)
+	}
+
+	// Bounds
+	if narrowed, widened, changed := compareBound(base.Minimum, v.Minimum, false); changed {
+		change(narrowed, widened, "minimum changed from %s to %s", bound(base.Minimum), bound(v.Minimum))
+	}
+	if narrowed, widened, changed := compareBound(base.Maximum, v.Maximum, true); changed {
+		change(narrowed, widened, "maximum changed from %s to %s", bound(base.Maximum), bound(v.Maximum))
+	}
+	if narrowed, widened, changed := compareBound(intPtr(base.MinLength), intPtr(v.MinLength), false); changed {
+		change(narrowed, widened, "min length changed from %s to %s", bound(intPtr(base.MinLength)), bound(intPtr(v.MinLength)))
+	}
+	if narrowed, widened, changed := compareBound(intPtr(base.MaxLength), intPtr(v.MaxLength), true); changed {
+		change(narrowed, widened, "max length changed from %s to %s", bound(intPtr(base.MaxLength)), bound(intPtr(v.MaxLength)))
+	}
+}
+
+// compareBound compares a lower bound (upper is false) or an upper bound (upper is true) and
+// returns whether the range of valid values was narrowed, widened or changed at all.
+func compareBound(base, b *float64, upper bool) (narrowed, widened, changed bool) {
+	switch {
+	case base == nil && b == nil:
+		return false, false, false
+	case base == nil:
+		return true, false, true
+	case b == nil:
+		return false, true, true
+	case *base == *b:
+		return false, false, false
+	}
+	tighter := *b > *base
+	if upper {
+		tighter = !tighter
+	}
+	return tighter, !tighter, true
+}
+
+// keys returns the sorted union of the keys of the two maps which must be maps indexed by
+// strings.
+func keys(m1, m2 interface{}) []string {
+	set := make(map[string]bool)
+	for _, m := range []interface{}{m1, m2} {
+		switch actual := m.(type) {
+		case map[string]*Resource:
+			for k := range actual {
+				set[k] = true
+			}
+		case map[string]*Action:
+			for k := range actual {
+				set[k] = true
+			}
+		case map[string]*Response:
+			for k := range actual {
+				set[k] = true
+			}
+		case map[string]*MediaType:
+			for k := range actual {
+				set[k] = true
+			}
+		case map[string]*Attribute:
+			for k := range actual {
+				set[k] = true
+			}
+		case map[string][]string:
+			for k := range actual {
+				set[k] = true
+			}
+		default:
+			panic(fmt.Sprintf("unexpected map type %T", m)) // bug
+		}
+	}
+	res := make([]string, 0, len(set))
+	for k := range set {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// difference returns the elements of s1 that are not in s2.
+func difference(s1, s2 []string) []string {
+	in := make(map[string]bool, len(s2))
+	for _, s := range s2 {
+		in[s] = true
+	}
+	var res []string
+	for _, s := range s1 {
+		if !in[s] {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func orEmpty(att *Attribute) *Attribute {
+	if att == nil {
+		return &Attribute{Type: "object"}
+	}
+	return att
+}
+
+func typeName(att *Attribute) string {
+	if att.Ref != "" {
+		return att.Ref
+	}
+	return att.Type
+}
+
+func requiredSuffix(required bool) string {
+	if required {
+		return " (required)"
+	}
+	return ""
+}
+
+func toStrings(vals []interface{}) []string {
+	res := make([]string, len(vals))
+	for i, v := range vals {
+		res[i] = fmt.Sprintf("%v", v)
+	}
+	return res
+}
+
+func intPtr(i *int) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+	return &f
+}
+
+func bound(f *float64) string {
+	if f == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%v", *f)
+}
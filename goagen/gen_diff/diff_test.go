@@ -0,0 +1,290 @@
+package gendiff_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// bottleDesign defines a simple API, the arguments make it possible to vary the design.
+type bottleDesign struct {
+	maxName   int
+	required  []string
+	extraView bool
+	delete    bool
+	listRoute string
+	colors    []interface{}
+	stream    bool
+	untyped   bool
+}
+
+func (d bottleDesign) snapshot() *gendiff.Snapshot {
+	dslengine.Reset()
+	API("cellar", func() {})
+	bottle := MediaType("application/vnd.bottle+json", func() {
+		Attributes(func() {
+			Attribute("id", Integer)
+			Attribute("name", String, func() {
+				MaxLength(d.maxName)
+			})
+			Attribute("color", String, func() {
+				Enum(d.colors...)
+			})
+			Required("id")
+		})
+		View("default", func() {
+			Attribute("id")
+			Attribute("name")
+			if d.extraView {
+				Attribute("color")
+			}
+		})
+	})
+	Resource("bottle", func() {
+		Action("list", func() {
+			Routing(GET(d.listRoute))
+			Params(func() {
+				Param("filter", String)
+				Param("limit", Integer)
+				Required(d.required...)
+			})
+			Response(OK, CollectionOf(bottle), func() {
+				if d.stream {
+					ServerSentEvents()
+				}
+			})
+		})
+		Action("create", func() {
+			Routing(POST(""))
+			Payload(func() {
+				Attribute("name", String, func() {
+					MaxLength(d.maxName)
+				})
+				Attribute("color", String, func() {
+					Enum(d.colors...)
+				})
+			})
+			Response(Created)
+			Response(BadRequest)
+		})
+		Action("watch", func() {
+			Routing(GET("/watch"))
+			Scheme("ws")
+			if !d.untyped {
+				OutboundMessage(bottle)
+			}
+			Response(SwitchingProtocols)
+		})
+		if d.delete {
+			Action("delete", func() {
+				Routing(DELETE("/:id"))
+				Response(NoContent)
+			})
+		}
+	})
+	Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	return gendiff.NewSnapshot(Design)
+}
+
+var base = bottleDesign{
+	maxName:   10,
+	delete:    true,
+	listRoute: "",
+	colors:    []interface{}{"red", "white"},
+}
+
+var _ = Describe("Compare", func() {
+	var old, api bottleDesign
+	var report *gendiff.Report
+
+	BeforeEach(func() {
+		old, api = base, base
+	})
+
+	JustBeforeEach(func() {
+		report = gendiff.Compare(old.snapshot(), api.snapshot())
+	})
+
+	findChange := func(path string) *gendiff.Change {
+		for _, c := range report.Changes {
+			if c.Path == path {
+				return c
+			}
+		}
+		return nil
+	}
+
+	It("reports no changes for identical designs", func() {
+		Ω(report.Changes).Should(BeEmpty())
+		Ω(report.Breaking).Should(BeFalse())
+	})
+
+	Context("with a removed action", func() {
+		BeforeEach(func() {
+			api.delete = false
+		})
+
+		It("reports a breaking change", func() {
+			Ω(report.Changes).Should(HaveLen(1))
+			Ω(*report.Changes[0]).Should(Equal(gendiff.Change{
+				Kind:        gendiff.Removed,
+				Path:        "bottle.delete",
+				Description: "action removed",
+				Breaking:    true,
+			}))
+			Ω(report.Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with an added action", func() {
+		BeforeEach(func() {
+			old.delete = false
+		})
+
+		It("reports a non breaking change", func() {
+			Ω(report.Changes).Should(HaveLen(1))
+			Ω(report.Changes[0].Kind).Should(Equal(gendiff.Added))
+			Ω(report.Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a changed route", func() {
+		BeforeEach(func() {
+			api.listRoute = "/all"
+		})
+
+		It("reports the removed route as breaking", func() {
+			Ω(report.Changes).Should(HaveLen(2))
+			Ω(report.Changes[0].Description).Should(Equal("route GET / removed"))
+			Ω(report.Changes[0].Breaking).Should(BeTrue())
+			Ω(report.Changes[1].Description).Should(Equal("route GET /all added"))
+			Ω(report.Changes[1].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a new required parameter", func() {
+		BeforeEach(func() {
+			api.required = []string{"limit"}
+		})
+
+		It("reports a breaking change", func() {
+			c := findChange("bottle.list.params.limit")
+			Ω(c).ShouldNot(BeNil())
+			Ω(c.Description).Should(Equal("attribute is now required"))
+			Ω(c.Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a parameter that is no longer required", func() {
+		BeforeEach(func() {
+			old.required = []string{"limit"}
+		})
+
+		It("reports a non breaking change", func() {
+			Ω(report.Changes).Should(HaveLen(1))
+			Ω(report.Changes[0].Description).Should(Equal("attribute is no longer required"))
+			Ω(report.Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a tighter validation", func() {
+		BeforeEach(func() {
+			api.maxName = 5
+		})
+
+		It("reports a breaking change for requests only", func() {
+			c := findChange("bottle.create.payload.name")
+			Ω(c).ShouldNot(BeNil())
+			Ω(c.Description).Should(Equal("max length changed from 10 to 5"))
+			Ω(c.Breaking).Should(BeTrue())
+			c = findChange("[application/vnd.bottle+json].name")
+			Ω(c).ShouldNot(BeNil())
+			Ω(c.Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with additional enum values", func() {
+		BeforeEach(func() {
+			api.colors = []interface{}{"red", "white", "rose"}
+		})
+
+		It("reports a breaking change for responses only", func() {
+			c := findChange("bottle.create.payload.color")
+			Ω(c).ShouldNot(BeNil())
+			Ω(c.Description).Should(Equal("enum values changed: added rose"))
+			Ω(c.Breaking).Should(BeFalse())
+			c = findChange("[application/vnd.bottle+json].color")
+			Ω(c).ShouldNot(BeNil())
+			Ω(c.Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with an attribute added to a view", func() {
+		BeforeEach(func() {
+			api.extraView = true
+		})
+
+		It("reports a non breaking change", func() {
+			c := findChange("[application/vnd.bottle+json].views.default")
+			Ω(c).ShouldNot(BeNil())
+			Ω(c.Description).Should(Equal("attribute color added to view"))
+			Ω(c.Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a response turned into an event stream", func() {
+		BeforeEach(func() {
+			api.stream = true
+		})
+
+		It("reports a breaking change", func() {
+			Ω(report.Changes).Should(HaveLen(1))
+			Ω(report.Changes[0].Path).Should(Equal("bottle.list.responses.200"))
+			Ω(report.Changes[0].Description).Should(Equal("response is now a stream of server-sent events"))
+			Ω(report.Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a websocket action whose message type is removed", func() {
+		BeforeEach(func() {
+			api.untyped = true
+		})
+
+		It("reports a breaking change", func() {
+			Ω(report.Changes).Should(HaveLen(1))
+			Ω(*report.Changes[0]).Should(Equal(gendiff.Change{
+				Kind:        gendiff.Removed,
+				Path:        "bottle.watch.outbound",
+				Description: "websocket message type removed",
+				Breaking:    true,
+			}))
+		})
+	})
+
+	It("produces reports that serialize to JSON and text", func() {
+		report = gendiff.Compare(bottleDesign{maxName: 10, colors: base.colors}.snapshot(), base.snapshot())
+		b, err := json.Marshal(report)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"breaking":false,"changes":[{"kind":"added","path":"bottle.delete","description":"action added","breaking":false}]}`))
+		var buf bytes.Buffer
+		Ω(report.WriteText(&buf)).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal("          added    bottle.delete: action added\n\n1 change(s), 0 breaking\n"))
+	})
+})
+
+var _ = Describe("NewSnapshot", func() {
+	It("survives a JSON round trip", func() {
+		api := base.snapshot()
+		b, err := json.Marshal(api)
+		Ω(err).ShouldNot(HaveOccurred())
+		var decoded gendiff.Snapshot
+		Ω(json.Unmarshal(b, &decoded)).ShouldNot(HaveOccurred())
+		Ω(gendiff.Compare(api, &decoded).Changes).Should(BeEmpty())
+	})
+})
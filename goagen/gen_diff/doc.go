@@ -0,0 +1,9 @@
+/*
+Package gendiff computes the differences between two versions of an API design.
+The generator writes a JSON summary of the design that the "goagen diff" command compares with
+the summary of another design. Each difference is classified as breaking or non-breaking from
+the point of view of existing clients: removing resources, actions, routes, response codes, media
+type views or attributes, adding required request attributes and tightening request validations
+are examples of breaking changes.
+*/
+package gendiff
@@ -0,0 +1,259 @@
+package gendiff
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+type (
+	// Snapshot is a serializable summary of an API definition. It contains the elements of the
+	// design that make up the contract between the API and its clients.
+	Snapshot struct {
+		// Name of API
+		Name string `json:"name"`
+		// Resources indexed by name
+		Resources map[string]*Resource `json:"resources,omitempty"`
+		// MediaTypes indexed by identifier
+		MediaTypes map[string]*MediaType `json:"media_types,omitempty"`
+	}
+
+	// Resource summarizes a resource definition.
+	Resource struct {
+		// Name of resource
+		Name string `json:"name"`
+		// Actions indexed by name
+		Actions map[string]*Action `json:"actions,omitempty"`
+	}
+
+	// Action summarizes an action definition.
+	Action struct {
+		// Name of action
+		Name string `json:"name"`
+		// Routes lists the action routes as "VERB path", e.g. "GET /bottles/:id".
+		Routes []string `json:"routes,omitempty"`
+		// Params describes the path and query string parameters.
+		Params *Attribute `json:"params,omitempty"`
+		// Headers describes the request headers.
+		Headers *Attribute `json:"headers,omitempty"`
+		// Payload describes the request body if any.
+		Payload *Attribute `json:"payload,omitempty"`
+		// PayloadOptional is true if the request body may be omitted.
+		PayloadOptional bool `json:"payload_optional,omitempty"`
+		// Responses indexed by HTTP status code
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// Inbound describes the websocket messages sent by clients if any.
+		Inbound *Attribute `json:"inbound,omitempty"`
+		// Outbound describes the websocket messages sent by the server if any.
+		Outbound *Attribute `json:"outbound,omitempty"`
+	}
+
+	// Response summarizes a response definition.
+	Response struct {
+		// Name of response, e.g. "OK"
+		Name string `json:"name"`
+		// MediaType is the identifier of the response media type if any.
+		MediaType string `json:"media_type,omitempty"`
+		// View is the name of the view used to render the response media type if any.
+		View string `json:"view,omitempty"`
+		// Stream is true if the response is a stream of server-sent events.
+		Stream bool `json:"stream,omitempty"`
+	}
+
+	// MediaType summarizes a media type definition.
+	MediaType struct {
+		// Identifier of media type
+		Identifier string `json:"identifier"`
+		// Attribute describes the media type attributes.
+		Attribute *Attribute `json:"attribute,omitempty"`
+		// Views lists the names of the attributes rendered by each view.
+		Views map[string][]string `json:"views,omitempty"`
+	}
+
+	// Attribute summarizes an attribute definition. User types are inlined so that two
+	// designs that describe the same wire format compare equal.
+	Attribute struct {
+		// Type is the name of the attribute type, e.g. "string" or "object".
+		Type string `json:"type"`
+		// Ref is the name of the user type if the attribute refers to a user type that
+		// is being described already (recursive types).
+		Ref string `json:"ref,omitempty"`
+		// Required lists the names of the required child attributes.
+		Required []string `json:"required,omitempty"`
+		// Validation lists the attribute validations.
+		Validation *Validation `json:"validation,omitempty"`
+		// Attributes describes the child attributes of objects.
+		Attributes map[string]*Attribute `json:"attributes,omitempty"`
+		// Key describes the keys of hashes.
+		Key *Attribute `json:"key,omitempty"`
+		// Elem describes the elements of arrays and the values of hashes.
+		Elem *Attribute `json:"elem,omitempty"`
+	}
+
+	// Validation summarizes the validations of an attribute.
+	Validation struct {
+		Values    []interface{} `json:"values,omitempty"`
+		Format    string        `json:"format,omitempty"`
+		Pattern   string        `json:"pattern,omitempty"`
+		Minimum   *float64      `json:"minimum,omitempty"`
+		Maximum   *float64      `json:"maximum,omitempty"`
+		MinLength *int          `json:"min_length,omitempty"`
+		MaxLength *int          `json:"max_length,omitempty"`
+	}
+)
+
+// NewSnapshot builds the summary of the given API definition.
+func NewSnapshot(api *design.APIDefinition) *Snapshot {
+	res := &Snapshot{
+		Name:       api.Name,
+		Resources:  make(map[string]*Resource),
+		MediaTypes: make(map[string]*MediaType),
+	}
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		res.Resources[r.Name] = newResource(r)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		res.MediaTypes[mt.Identifier] = newMediaType(mt)
+		return nil
+	})
+	return res
+}
+
+func newResource(r *design.ResourceDefinition) *Resource {
+	res := &Resource{Name: r.Name, Actions: make(map[string]*Action)}
+	r.IterateActions(func(a *design.ActionDefinition) error {
+		res.Actions[a.Name] = newAction(a)
+		return nil
+	})
+	return res
+}
+
+func newAction(a *design.ActionDefinition) *Action {
+	res := &Action{
+		Name:            a.Name,
+		Params:          newAttribute(a.AllParams(), nil),
+		PayloadOptional: a.PayloadOptional,
+		Responses:       make(map[string]*Response),
+	}
+	for _, r := range a.Routes {
+		res.Routes = append(res.Routes, r.Verb+" "+r.FullPath())
+	}
+	sort.Strings(res.Routes)
+	var headers *design.AttributeDefinition
+	if a.Headers != nil {
+		headers = design.DupAtt(a.Headers)
+	}
+	if headers = headers.Merge(a.Parent.Headers); headers != nil {
+		res.Headers = newAttribute(headers, nil)
+	}
+	if a.Payload != nil {
+		res.Payload = newAttribute(&design.AttributeDefinition{Type: a.Payload}, nil)
+	}
+	if a.InboundMessage != nil {
+		res.Inbound = newAttribute(&design.AttributeDefinition{Type: a.InboundMessage}, nil)
+	}
+	if a.OutboundMessage != nil {
+		res.Outbound = newAttribute(&design.AttributeDefinition{Type: a.OutboundMessage}, nil)
+	}
+	for _, r := range a.Responses {
+		res.Responses[strconv.Itoa(r.Status)] = &Response{
+			Name:      r.Name,
+			MediaType: r.MediaType,
+			View:      r.ViewName,
+			Stream:    r.Stream != nil,
+		}
+	}
+	return res
+}
+
+func newMediaType(mt *design.MediaTypeDefinition) *MediaType {
+	res := &MediaType{
+		Identifier: mt.Identifier,
+		Attribute:  newAttribute(&design.AttributeDefinition{Type: mt}, nil),
+		Views:      make(map[string][]string),
+	}
+	mt.IterateViews(func(v *design.ViewDefinition) error {
+		var names []string
+		if o := v.Type.ToObject(); o != nil {
+			for n := range o {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		res.Views[v.Name] = names
+		return nil
+	})
+	return res
+}
+
+// newAttribute builds the summary of att. seen records the user types being described to
+// avoid infinite recursions.
+func newAttribute(att *design.AttributeDefinition, seen map[string]bool) *Attribute {
+	if att == nil || att.Type == nil {
+		return nil
+	}
+	var ut *design.UserTypeDefinition
+	switch actual := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		ut = actual
+	case *design.MediaTypeDefinition:
+		ut = actual.UserTypeDefinition
+	}
+	if ut != nil {
+		if seen[ut.TypeName] {
+			return &Attribute{Type: ut.Name(), Ref: ut.TypeName}
+		}
+		if seen == nil {
+			seen = make(map[string]bool)
+		}
+		seen[ut.TypeName] = true
+		defer delete(seen, ut.TypeName)
+		res := newAttribute(ut.AttributeDefinition, seen)
+		if v := newValidation(att.Validation); v != nil {
+			res.Validation = v
+		}
+		return res
+	}
+
+	res := &Attribute{Type: att.Type.Name(), Validation: newValidation(att.Validation)}
+	switch actual := att.Type.(type) {
+	case design.Object:
+		res.Attributes = make(map[string]*Attribute, len(actual))
+		for n, child := range actual {
+			res.Attributes[n] = newAttribute(child, seen)
+		}
+		if att.Validation != nil && len(att.Validation.Required) > 0 {
+			res.Required = append([]string{}, att.Validation.Required...)
+			sort.Strings(res.Required)
+		}
+	case *design.Array:
+		res.Elem = newAttribute(actual.ElemType, seen)
+	case *design.Hash:
+		res.Key = newAttribute(actual.KeyType, seen)
+		res.Elem = newAttribute(actual.ElemType, seen)
+	}
+	return res
+}
+
+// newValidation returns nil if v does not define any validation other than required attributes.
+func newValidation(v *dslengine.ValidationDefinition) *Validation {
+	if v == nil {
+		return nil
+	}
+	if len(v.Values) == 0 && v.Format == "" && v.Pattern == "" && v.Minimum == nil &&
+		v.Maximum == nil && v.MinLength == nil && v.MaxLength == nil {
+		return nil
+	}
+	return &Validation{
+		Values:    v.Values,
+		Format:    v.Format,
+		Pattern:   v.Pattern,
+		Minimum:   v.Minimum,
+		Maximum:   v.Maximum,
+		MinLength: v.MinLength,
+		MaxLength: v.MaxLength,
+	}
+}
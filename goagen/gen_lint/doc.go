@@ -0,0 +1,30 @@
+/*
+Package genlint checks a design against API style rules. The "goagen lint" command runs the rules
+and reports each problem together with the file and line of the DSL that created the offending
+definition. The command exits with a non zero status if there is at least one problem.
+
+The built-in rules check that actions have a description, that attribute names are snake_case,
+that actions returning collections are paginated, that error responses use ErrorMedia, that the
+success responses of DELETE actions have no body, that media types define a default view and that
+actions define security requirements when the API defines security schemes.
+
+Rules are disabled for the whole project with the "lint:disable" metadata set on the API, for a
+single definition and the definitions it contains with the same metadata set on that definition,
+or with the --disable flag:
+
+	var _ = API("cellar", func() {
+		Metadata("lint:disable", "paginated-collections")
+	})
+
+Design packages may add rules with Register:
+
+	func init() {
+		genlint.Register(genlint.NewRule("resource-description", func(n *genlint.Node) error {
+			if r, ok := n.Definition.(*design.ResourceDefinition); ok && r.Description == "" {
+				return errors.New("missing description")
+			}
+			return nil
+		}))
+	}
+*/
+package genlint
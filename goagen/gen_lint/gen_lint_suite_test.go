@@ -0,0 +1,13 @@
+package genlint_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenLint(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenLint Suite")
+}
@@ -0,0 +1,68 @@
+package genlint
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// NewGenerator returns an initialized instance of a design linter Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator checks the design against the lint rules. It does not generate any file.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	Rules    []Rule                // Rules to run, defaults to the registered rules
+	Disabled []string              // Names of the rules to skip
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var ver, disable string
+	set := flag.NewFlagSet("lint", flag.PanicOnError)
+	set.String("out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&disable, "disable", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{API: design.Design}
+	for _, d := range strings.Split(disable, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			g.Disabled = append(g.Disabled, d)
+		}
+	}
+
+	return g.Generate()
+}
+
+// Generate runs the rules and returns the problems found as a dslengine.MultiError.
+func (g *Generator) Generate() ([]string, error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	rules := g.Rules
+	if rules == nil {
+		rules = RegisteredRules()
+	}
+	if errs := Lint(g.API, rules, g.Disabled...); len(errs) > 0 {
+		return nil, errs
+	}
+	return nil, nil
+}
@@ -0,0 +1,236 @@
+package genlint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// DisableKey is the metadata key that disables rules. The values list the names of the rules to
+// disable, all rules are disabled if there is no value. Setting the metadata on the API disables
+// the rules for the whole design, setting it on another definition disables the rules for that
+// definition and the definitions it contains.
+const DisableKey = "lint:disable"
+
+type (
+	// Rule checks the definitions of a design.
+	Rule interface {
+		// Name is the name of the rule used in reports and to disable the rule.
+		Name() string
+		// Check is called with each definition of the design and returns an error
+		// describing the problem if the definition breaks the rule, nil otherwise.
+		Check(n *Node) error
+	}
+
+	// Node is a design definition together with the definitions that contain it.
+	Node struct {
+		// Definition is the design definition, one of *design.APIDefinition,
+		// *design.ResourceDefinition, *design.ActionDefinition,
+		// *design.ResponseDefinition, *design.MediaTypeDefinition,
+		// *design.UserTypeDefinition or *design.AttributeDefinition.
+		Definition dslengine.Definition
+		// Name is the name of the attribute if Definition is an attribute.
+		Name string
+		// Parent is the node of the definition that contains Definition, nil for the
+		// API node.
+		Parent *Node
+	}
+
+	// rule is the Rule implementation returned by NewRule.
+	rule struct {
+		name  string
+		check func(*Node) error
+	}
+)
+
+// registered lists the rules registered with Register.
+var registered []Rule
+
+// NewRule returns a rule with the given name that uses the check function to check definitions.
+func NewRule(name string, check func(*Node) error) Rule {
+	return &rule{name: name, check: check}
+}
+
+// Register adds a rule to the rules run by "goagen lint". Design packages may call Register in
+// an init function to add project specific rules. Register panics if a rule with the same name
+// is already registered.
+func Register(r Rule) {
+	for _, o := range registered {
+		if o.Name() == r.Name() {
+			panic(fmt.Sprintf("lint rule %s is registered twice", r.Name())) // bug
+		}
+	}
+	registered = append(registered, r)
+}
+
+// RegisteredRules returns the rules registered with Register, starting with the built-in rules.
+func RegisteredRules() []Rule {
+	return append([]Rule(nil), registered...)
+}
+
+// Lint runs the given rules against the API definitions and returns the problems found, one
+// dslengine.Error per problem. The errors contain the location of the DSL that created the
+// definition. Rules listed in disabled or in the DisableKey metadata of the definitions are
+// skipped.
+func Lint(api *design.APIDefinition, rules []Rule, disabled ...string) dslengine.MultiError {
+	off := make(map[string]bool, len(disabled))
+	for _, d := range disabled {
+		off[d] = true
+	}
+	var errs dslengine.MultiError
+	walk(api, func(n *Node) {
+		for _, r := range rules {
+			if off[r.Name()] || n.disables(r.Name()) {
+				continue
+			}
+			err := r.Check(n)
+			if err == nil {
+				continue
+			}
+			file, line := n.Location()
+			errs = append(errs, &dslengine.Error{
+				GoError: fmt.Errorf("%s in %s (%s)", err, n.Context(), r.Name()),
+				File:    file,
+				Line:    line,
+			})
+		}
+	})
+	return errs
+}
+
+// API returns the API definition at the root of the node tree.
+func (n *Node) API() *design.APIDefinition {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	api, _ := n.Definition.(*design.APIDefinition)
+	return api
+}
+
+// Context returns the description of the definition used in error messages.
+func (n *Node) Context() string {
+	if _, ok := n.Definition.(*design.AttributeDefinition); ok && n.Parent != nil {
+		return fmt.Sprintf("attribute %#v of %s", n.Name, n.Parent.Context())
+	}
+	return n.Definition.Context()
+}
+
+// Location returns the file and line of the DSL that created the definition or the closest
+// containing definition if not known.
+func (n *Node) Location() (file string, line int) {
+	for ; n != nil; n = n.Parent {
+		if file, line = dslengine.Location(n.Definition); file != "" {
+			return
+		}
+	}
+	return
+}
+
+// disables returns true if the definition or a containing definition disables the rule with
+// the given name.
+func (n *Node) disables(name string) bool {
+	for ; n != nil; n = n.Parent {
+		vals, ok := metadata(n.Definition)[DisableKey]
+		if !ok {
+			continue
+		}
+		if len(vals) == 0 {
+			return true
+		}
+		for _, v := range vals {
+			if v == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Name returns the rule name.
+func (r *rule) Name() string { return r.name }
+
+// Check runs the rule check function.
+func (r *rule) Check(n *Node) error { return r.check(n) }
+
+// walk calls visit with the nodes of the API, its resources, actions, action responses, action
+// parameters and payload attributes, media types, user types and their attributes.
+func walk(api *design.APIDefinition, visit func(*Node)) {
+	root := &Node{Definition: api}
+	visit(root)
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		rn := &Node{Definition: r, Parent: root}
+		visit(rn)
+		walkAttributes(r.Params, rn, visit)
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			an := &Node{Definition: a, Parent: rn}
+			visit(an)
+			walkAttributes(a.Params, an, visit)
+			if a.Payload != nil && api.Types[a.Payload.TypeName] != a.Payload {
+				walkAttributes(a.Payload.AttributeDefinition, an, visit)
+			}
+			return a.IterateResponses(func(resp *design.ResponseDefinition) error {
+				visit(&Node{Definition: resp, Parent: an})
+				return nil
+			})
+		})
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		mn := &Node{Definition: mt, Parent: root}
+		visit(mn)
+		walkAttributes(mt.AttributeDefinition, mn, visit)
+		return nil
+	})
+	api.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		tn := &Node{Definition: t, Parent: root}
+		visit(tn)
+		walkAttributes(t.AttributeDefinition, tn, visit)
+		return nil
+	})
+}
+
+// walkAttributes calls visit with the child attributes of att recursively. It does not recurse
+// into user types and media types as these are visited separately.
+func walkAttributes(att *design.AttributeDefinition, parent *Node, visit func(*Node)) {
+	if att == nil {
+		return
+	}
+	switch t := att.Type.(type) {
+	case design.Object:
+		names := make([]string, 0, len(t))
+		for n := range t {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := t[name]
+			n := &Node{Definition: child, Name: name, Parent: parent}
+			visit(n)
+			walkAttributes(child, n, visit)
+		}
+	case *design.Array:
+		walkAttributes(t.ElemType, parent, visit)
+	}
+}
+
+// metadata returns the metadata of the given definition.
+func metadata(def dslengine.Definition) dslengine.MetadataDefinition {
+	switch d := def.(type) {
+	case *design.APIDefinition:
+		return d.Metadata
+	case *design.ResourceDefinition:
+		return d.Metadata
+	case *design.ActionDefinition:
+		return d.Metadata
+	case *design.ResponseDefinition:
+		return d.Metadata
+	case *design.MediaTypeDefinition:
+		return d.Metadata
+	case *design.UserTypeDefinition:
+		return d.Metadata
+	case *design.AttributeDefinition:
+		return d.Metadata
+	}
+	return nil
+}
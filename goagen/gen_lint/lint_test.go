@@ -0,0 +1,215 @@
+package genlint_test
+
+import (
+	"errors"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_lint"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// messages returns the messages of the given errors without the locations.
+func messages(errs dslengine.MultiError) []string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.GoError.Error()
+	}
+	return msgs
+}
+
+var _ = Describe("Lint", func() {
+	var rules []genlint.Rule
+	var disabled []string
+	var errs dslengine.MultiError
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		rules = genlint.RegisteredRules()
+		disabled = nil
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		errs = genlint.Lint(Design, rules, disabled...)
+	})
+
+	Context("with a design that follows the rules", func() {
+		BeforeEach(func() {
+			API("cellar", func() {
+				BasicAuthSecurity("password")
+				Security("password")
+			})
+			bottle := MediaType("application/vnd.bottle+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("vintage_year", Integer)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("vintage_year")
+				})
+			})
+			Resource("bottle", func() {
+				Action("list", func() {
+					Description("Lists the bottles")
+					Routing(GET("/bottles"))
+					Paginated(CursorPagination)
+					Response(OK, CollectionOf(bottle))
+					Response(BadRequest, ErrorMedia)
+				})
+				Action("delete", func() {
+					Description("Deletes a bottle")
+					Routing(DELETE("/bottles/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Response(NoContent)
+					Response(NotFound, ErrorMedia)
+				})
+			})
+		})
+
+		It("does not report problems", func() {
+			Ω(errs).Should(BeEmpty())
+		})
+	})
+
+	Context("with a design that breaks the rules", func() {
+		var metadata func()
+
+		BeforeEach(func() {
+			metadata = func() {}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("cellar", func() {
+				BasicAuthSecurity("password")
+				metadata()
+			})
+			bottle := MediaType("application/vnd.bottle+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("vintageYear", Integer)
+				})
+				View("default", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottle", func() {
+				Action("list", func() {
+					Routing(GET("/bottles"))
+					Response(OK, CollectionOf(bottle))
+					Response(BadRequest, bottle)
+				})
+				Action("delete", func() {
+					Description("Deletes a bottle")
+					Routing(DELETE("/bottles/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					NoSecurity()
+					Response(OK, bottle)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			errs = genlint.Lint(Design, rules, disabled...)
+		})
+
+		It("reports the problems", func() {
+			Ω(messages(errs)).Should(Equal([]string{
+				`response to DELETE request has a body in response "OK" of resource "bottle" action "delete" (delete-no-body)`,
+				`missing description in resource "bottle" action "list" (action-description)`,
+				`response "OK" returns a collection without pagination in resource "bottle" action "list" (paginated-collections)`,
+				`missing security requirements, use Security or NoSecurity in resource "bottle" action "list" (action-security)`,
+				`error response body does not use ErrorMedia in response "BadRequest" of resource "bottle" action "list" (error-media)`,
+				`attribute name is not snake_case in attribute "vintageYear" of type "Bottle" (snake-case)`,
+			}))
+		})
+
+		It("reports the location of the definitions", func() {
+			Ω(errs).ShouldNot(BeEmpty())
+			for _, e := range errs {
+				Ω(e.File).Should(HaveSuffix("lint_test.go"))
+				Ω(e.Line).Should(BeNumerically(">", 0))
+			}
+		})
+
+		Context("with disabled rules", func() {
+			BeforeEach(func() {
+				disabled = []string{genlint.SnakeCaseRule, genlint.ErrorMediaRule}
+			})
+
+			It("skips the rules", func() {
+				Ω(errs).Should(HaveLen(4))
+			})
+		})
+
+		Context("with rules disabled in the API metadata", func() {
+			BeforeEach(func() {
+				metadata = func() {
+					Metadata(genlint.DisableKey, genlint.ActionDescriptionRule, genlint.PaginatedCollectionsRule)
+				}
+			})
+
+			It("skips the rules", func() {
+				Ω(errs).Should(HaveLen(4))
+			})
+		})
+
+		Context("with all rules disabled in the API metadata", func() {
+			BeforeEach(func() {
+				metadata = func() {
+					Metadata(genlint.DisableKey)
+				}
+			})
+
+			It("does not report problems", func() {
+				Ω(errs).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("with a custom rule", func() {
+		BeforeEach(func() {
+			rules = []genlint.Rule{genlint.NewRule("resource-description", func(n *genlint.Node) error {
+				if r, ok := n.Definition.(*ResourceDefinition); ok && r.Description == "" {
+					return errors.New("missing description")
+				}
+				return nil
+			})}
+			API("cellar", func() {})
+			Resource("bottle", func() {
+				Description("A wine bottle")
+			})
+			Resource("account", func() {
+				Metadata(genlint.DisableKey, "resource-description")
+			})
+			Resource("region", func() {})
+		})
+
+		It("runs the rule", func() {
+			Ω(messages(errs)).Should(Equal([]string{
+				`missing description in resource "region" (resource-description)`,
+			}))
+		})
+	})
+})
+
+var _ = Describe("DefaultViewRule", func() {
+	It("reports media types without default view", func() {
+		mt := &MediaTypeDefinition{
+			UserTypeDefinition: &UserTypeDefinition{
+				AttributeDefinition: &AttributeDefinition{Type: Object{}},
+				TypeName:            "Bottle",
+			},
+			Identifier: "application/vnd.bottle",
+		}
+		api := &APIDefinition{MediaTypes: map[string]*MediaTypeDefinition{mt.Identifier: mt}}
+		errs := genlint.Lint(api, genlint.RegisteredRules())
+		Ω(messages(errs)).Should(Equal([]string{`missing default view in type "Bottle" (default-view)`}))
+	})
+})
@@ -0,0 +1,27 @@
+package genlint
+
+import "github.com/goadesign/goa/design"
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+// Rules The rules run against the design
+func Rules(rules ...Rule) Option {
+	return func(g *Generator) {
+		g.Rules = rules
+	}
+}
+
+// Disabled Names of the rules to skip
+func Disabled(names ...string) Option {
+	return func(g *Generator) {
+		g.Disabled = names
+	}
+}
@@ -0,0 +1,147 @@
+package genlint
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"regexp"
+
+	"github.com/goadesign/goa/design"
+)
+
+// Names of the built-in rules.
+const (
+	// ActionDescriptionRule checks that actions have a description.
+	ActionDescriptionRule = "action-description"
+	// SnakeCaseRule checks that attribute names are snake_case.
+	SnakeCaseRule = "snake-case"
+	// PaginatedCollectionsRule checks that actions returning collections define pagination.
+	PaginatedCollectionsRule = "paginated-collections"
+	// ErrorMediaRule checks that error responses that have a body use ErrorMedia.
+	ErrorMediaRule = "error-media"
+	// DeleteNoBodyRule checks that the success responses of DELETE actions have no body.
+	DeleteNoBodyRule = "delete-no-body"
+	// DefaultViewRule checks that media types define a default view.
+	DefaultViewRule = "default-view"
+	// ActionSecurityRule checks that actions define security requirements when the API
+	// defines security schemes. Actions may opt out explicitly with NoSecurity.
+	ActionSecurityRule = "action-security"
+)
+
+// snakeCase matches snake_case names.
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+func init() {
+	Register(NewRule(ActionDescriptionRule, checkActionDescription))
+	Register(NewRule(SnakeCaseRule, checkSnakeCase))
+	Register(NewRule(PaginatedCollectionsRule, checkPaginatedCollections))
+	Register(NewRule(ErrorMediaRule, checkErrorMedia))
+	Register(NewRule(DeleteNoBodyRule, checkDeleteNoBody))
+	Register(NewRule(DefaultViewRule, checkDefaultView))
+	Register(NewRule(ActionSecurityRule, checkActionSecurity))
+}
+
+func checkActionDescription(n *Node) error {
+	if a, ok := n.Definition.(*design.ActionDefinition); ok && a.Description == "" {
+		return errors.New("missing description")
+	}
+	return nil
+}
+
+func checkSnakeCase(n *Node) error {
+	if _, ok := n.Definition.(*design.AttributeDefinition); ok && !snakeCase.MatchString(n.Name) {
+		return errors.New("attribute name is not snake_case")
+	}
+	return nil
+}
+
+func checkPaginatedCollections(n *Node) error {
+	a, ok := n.Definition.(*design.ActionDefinition)
+	if !ok || a.Pagination != nil {
+		return nil
+	}
+	api := n.API()
+	return a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if r.Status >= 300 {
+			return nil
+		}
+		if t := responseType(api, r); t != nil && t.IsArray() {
+			return fmt.Errorf("response %#v returns a collection without pagination", r.Name)
+		}
+		return nil
+	})
+}
+
+func checkErrorMedia(n *Node) error {
+	r, ok := n.Definition.(*design.ResponseDefinition)
+	if !ok || r.Status < 400 || !hasBody(r) {
+		return nil
+	}
+	if mt, ok := r.Type.(*design.MediaTypeDefinition); ok && mt.IsError() {
+		return nil
+	}
+	if base, _, err := mime.ParseMediaType(r.MediaType); err == nil &&
+		design.CanonicalIdentifier(base) == design.ErrorMediaIdentifier {
+		return nil
+	}
+	return errors.New("error response body does not use ErrorMedia")
+}
+
+func checkDeleteNoBody(n *Node) error {
+	r, ok := n.Definition.(*design.ResponseDefinition)
+	if !ok || r.Status < 200 || r.Status >= 300 || !hasBody(r) {
+		return nil
+	}
+	a, ok := r.Parent.(*design.ActionDefinition)
+	if !ok {
+		return nil
+	}
+	for _, route := range a.Routes {
+		if route.Verb == "DELETE" {
+			return errors.New("response to DELETE request has a body")
+		}
+	}
+	return nil
+}
+
+func checkDefaultView(n *Node) error {
+	mt, ok := n.Definition.(*design.MediaTypeDefinition)
+	if !ok || mt.IsArray() {
+		return nil
+	}
+	if _, ok := mt.Views["default"]; !ok {
+		return errors.New("missing default view")
+	}
+	return nil
+}
+
+func checkActionSecurity(n *Node) error {
+	a, ok := n.Definition.(*design.ActionDefinition)
+	if !ok || a.Security != nil || a.NoSecurity {
+		return nil
+	}
+	if api := n.API(); api != nil && len(api.SecuritySchemes) > 0 {
+		return errors.New("missing security requirements, use Security or NoSecurity")
+	}
+	return nil
+}
+
+// hasBody returns true if the response defines a body.
+func hasBody(r *design.ResponseDefinition) bool {
+	return r.Type != nil || r.MediaType != ""
+}
+
+// responseType returns the type of the response body, nil if there is none or if it is not
+// defined in the design.
+func responseType(api *design.APIDefinition, r *design.ResponseDefinition) design.DataType {
+	if r.Type != nil {
+		return r.Type
+	}
+	if r.MediaType == "" || api == nil {
+		return nil
+	}
+	if mt := api.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+		return mt
+	}
+	return nil
+}
@@ -5,5 +5,9 @@ bootstrap new applications.
 The generator creates a main.go file and one file per resource listed in the API metadata.
 If a file already exists it skips its creation unless the flag --force is provided on the command
 line in which case it overrides the content of existing files.
+The flag --regen causes the generator to merge the generated controllers with the existing files
+instead: the existing code is kept, stubs are added for new actions and the implementation of the
+actions that were removed from the design are moved to a commented out section at the end of the
+file. Actions whose signature changed are reported as conflicts and left untouched.
 */
 package genmain
@@ -1,17 +1,13 @@
 package genmain
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
+	"io/ioutil"
 	"net"
 	"os"
 	"path"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"text/template"
 
@@ -71,62 +67,15 @@ func Generate() (files []string, err error) {
 	return g.Generate()
 }
 
-func extractControllerBody(filename string) (map[string]string, []*ast.ImportSpec, error) {
-	// First check if a file is there. If not, return empty results to let generation proceed.
-	if _, e := os.Stat(filename); e != nil {
-		return map[string]string{}, []*ast.ImportSpec{}, nil
-	}
-	fset := token.NewFileSet()
-	pfile, err := parser.ParseFile(fset, filename, nil, parser.ImportsOnly)
-	if err != nil {
-		return nil, nil, err
-	}
-	f, err := os.Open(filename)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer f.Close()
-	var (
-		inBlock bool
-		block   []string
-	)
-	actionImpls := map[string]string{}
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		match := linePattern.FindStringSubmatch(line)
-		if len(match) == 3 {
-			switch match[2] {
-			case "start":
-				inBlock = true
-			case "end":
-				inBlock = false
-				actionImpls[match[1]] = strings.Join(block, "\n")
-				block = []string{}
-			}
-			continue
-		}
-		if inBlock {
-			block = append(block, line)
-		}
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, nil, err
-	}
-	return actionImpls, pfile.Imports, nil
-}
-
 // GenerateController generates the controller corresponding to the given
-// resource and returns the generated filename.
+// resource and returns the generated filename. If regen is true and the
+// controller file already exists the generated code is merged with the
+// existing code, see MergeController.
 func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *design.ResourceDefinition) (filename string, err error) {
 	filename = filepath.Join(outDir, codegen.SnakeCase(name)+".go")
-	var (
-		actionImpls      map[string]string
-		extractedImports []*ast.ImportSpec
-	)
+	var existing []byte
 	if regen {
-		actionImpls, extractedImports, err = extractControllerBody(filename)
-		if err != nil {
+		if existing, err = ioutil.ReadFile(filename); err != nil && !os.IsNotExist(err) {
 			return "", err
 		}
 		os.Remove(filename)
@@ -148,9 +97,16 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 	}
 	defer func() {
 		file.Close()
+		if err == nil && existing != nil {
+			err = mergeControllerFile(filename, existing)
+		}
 		if err == nil {
 			err = file.FormatCode()
 		}
+		if err != nil && existing != nil {
+			// Never lose the existing implementation.
+			ioutil.WriteFile(filename, existing, 0644)
+		}
 	}()
 
 	elems := strings.Split(appPkg, "/")
@@ -172,20 +128,7 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		codegen.SimpleImport(imp),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
 	}
-	for _, imp := range extractedImports {
-		// This may introduce duplicate imports of the defaults, but
-		// that'll get worked out by Format later.
-		var cgimp *codegen.ImportSpec
-		path := strings.Trim(imp.Path.Value, `"`)
-		if imp.Name != nil {
-			cgimp = codegen.NewImport(imp.Name.Name, path)
-		} else {
-			cgimp = codegen.SimpleImport(path)
-		}
-		imports = append(imports, cgimp)
-	}
-
-	funcs := funcMap(pkgName, actionImpls)
+	funcs := funcMap(pkgName)
 	if err = file.WriteHeader("", pkg, imports); err != nil {
 		return "", err
 	}
@@ -194,6 +137,9 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 	}
 	err = r.IterateActions(func(a *design.ActionDefinition) error {
 		if a.WebSocket() {
+			if a.HasMessages() {
+				return file.ExecuteTemplate("actionConn", actionConnT, funcs, a)
+			}
 			return file.ExecuteTemplate("actionWS", actionWST, funcs, a)
 		}
 		return file.ExecuteTemplate("action", actionT, funcs, a)
@@ -204,6 +150,26 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 	return
 }
 
+// mergeControllerFile merges the freshly generated controller file with its
+// previous content and reports the changes that require attention.
+func mergeControllerFile(filename string, existing []byte) error {
+	generated, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	merged, report, err := MergeController(existing, generated)
+	if err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+	for _, name := range report.Removed {
+		fmt.Fprintf(os.Stderr, "%s: action %s removed from design, moved implementation to end of file\n", filename, name)
+	}
+	for _, c := range report.Conflicts {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", filename, c)
+	}
+	return ioutil.WriteFile(filename, merged, 0644)
+}
+
 // Generate produces the skeleton main.
 func (g *Generator) Generate() (_ []string, err error) {
 	if g.API == nil {
@@ -234,7 +200,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 		if err = os.MkdirAll(g.OutDir, 0755); err != nil {
 			return nil, err
 		}
-		if err = g.createMainFile(mainFile, funcMap(g.Target, nil)); err != nil {
+		if err = g.createMainFile(mainFile, funcMap(g.Target)); err != nil {
 			return nil, err
 		}
 	}
@@ -373,33 +339,29 @@ func okResp(a *design.ActionDefinition, appPkg string) map[string]interface{} {
 		"Name":    ok.Name + nameSuffix,
 		"GoType":  codegen.GoNativeType(pmt),
 		"TypeRef": typeref,
+		"Stream":  ok.Stream != nil,
 	}
 }
 
+// echoes returns true if the action websocket messages sent by the server and the clients have
+// the same type.
+func echoes(a *design.ActionDefinition) bool {
+	return a.InboundMessage != nil && a.InboundMessage == a.OutboundMessage
+}
+
 // funcMap creates the funcMap used to render the controller code.
-func funcMap(appPkg string, actionImpls map[string]string) template.FuncMap {
+func funcMap(appPkg string) template.FuncMap {
 	return template.FuncMap{
 		"tempvar":   tempvar,
 		"okResp":    okResp,
+		"echoes":    echoes,
 		"targetPkg": func() string { return appPkg },
-		"actionBody": func(name string) string {
-			body, ok := actionImpls[name]
-			if !ok {
-				return defaultActionBody
-			}
-			return body
-		},
-		"printResp": func(name string) bool {
-			_, ok := actionImpls[name]
-			return !ok
+		"message": func(t design.DataType) string {
+			return fmt.Sprintf("&%s.%s{}", appPkg, codegen.GoTypeName(t, nil, 0, false))
 		},
 	}
 }
 
-var linePattern = regexp.MustCompile(`^\s*// ([^:]+): (\w+)_implement\s*$`)
-
-const defaultActionBody = `// Put your logic here`
-
 const ctrlT = `// {{ $ctrlName := printf "%s%s" (goify .Name true) "Controller" }}{{ $ctrlName }} implements the {{ .Name }} resource.
 type {{ $ctrlName }} struct {
 	*goa.Controller
@@ -418,12 +380,12 @@ const actionT = `
 func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) error {
 	// {{ $actionDescr }}: start_implement
 
-	{{ actionBody $actionDescr }}
+	// Put your logic here
 
-{{ if printResp $actionDescr }}
 {{ $ok := okResp . targetPkg }}{{ if $ok }} res := {{ $ok.TypeRef }}
-{{ end }} return {{ if $ok }}ctx.{{ $ok.Name }}(res){{ else }}nil{{ end }}
-{{ end }}	// {{ $actionDescr }}: end_implement
+{{ if $ok.Stream }} stream := ctx.{{ $ok.Name }}Stream()
+ return stream.Send(res){{ else }} return ctx.{{ $ok.Name }}(res){{ end }}{{ else }} return nil{{ end }}
+	// {{ $actionDescr }}: end_implement
 }
 `
 
@@ -441,15 +403,40 @@ func (c *{{ $ctrlName }}) {{ goify .Name true }}WSHandler(ctx *{{ targetPkg }}.{
 	return func(ws *websocket.Conn) {
 		// {{ $actionDescr }}: start_implement
 
-		{{ actionBody $actionDescr }}
-{{ if printResp $actionDescr }}
+		// Put your logic here
+
 		ws.Write([]byte("{{ .Name }} {{ .Parent.Name }}"))
 		// Dummy echo websocket server
 		io.Copy(ws, ws)
-{{ end }}		// {{ $actionDescr }}: end_implement
+		// {{ $actionDescr }}: end_implement
 	}
 }`
 
+const actionConnT = `
+{{- $ctrlName := printf "%s%s" (goify .Parent.Name true) "Controller" -}}
+{{- $actionDescr := printf "%s_%s" $ctrlName (goify .Name true) -}}
+// {{ goify .Name true }} runs the {{ .Name }} action.
+func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) error {
+	return ctx.Upgrade(func(conn *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Conn) {
+		// {{ $actionDescr }}: start_implement
+
+		// Put your logic here
+
+{{ if .InboundMessage }}		for {
+			{{ if echoes . }}msg{{ else }}_{{ end }}, err := conn.Receive()
+			if err != nil {
+				return
+			}
+{{ if .OutboundMessage }}			if err := conn.Send({{ if echoes . }}msg{{ else }}{{ message .OutboundMessage }}{{ end }}); err != nil {
+				return
+			}
+{{ end }}		}
+{{ else }}		conn.Send({{ message .OutboundMessage }})
+{{ end }}		// {{ $actionDescr }}: end_implement
+	})
+}
+`
+
 const mainT = `
 func main() {
 	// Create service
@@ -465,6 +452,14 @@ func main() {
 	{{ $tmp := tempvar }}{{ $tmp }} := New{{ $name }}Controller(service)
 	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }})
 {{ end }}
+	// Release resources once the in-flight requests completed, e.g.:
+	//
+	//	service.OnShutdown(func(ctx context.Context) error {
+	//		return db.Close()
+	//	})
+
+	// Shutdown gracefully on SIGINT and SIGTERM
+	service.ShutdownOnSignal(goa.DefaultShutdownTimeout)
 
 {{ if .TLS }}
 	// Start service
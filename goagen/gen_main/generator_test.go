@@ -122,7 +122,7 @@ var _ = Describe("Generate", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				// First add an import for fmt, to make sure it remains
-				existing = bytes.Replace(existing, []byte("import ("), []byte("import (\n\t\"fmt\")"), 1)
+				existing = bytes.Replace(existing, []byte("import ("), []byte("import (\n\t\"fmt\"\n"), 1)
 
 				// Next add some body that uses fmt
 				existing = bytes.Replace(existing, []byte("// Put your logic here"), []byte("fmt.Println(\"I did it first\")"), 1)
@@ -185,6 +185,27 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("with a websocket action defining messages", func() {
+			BeforeEach(func() {
+				message := &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{Type: design.Object{}},
+					TypeName:            "Message",
+				}
+				alpha := resource.Actions["alpha"]
+				alpha.Schemes = []string{"ws"}
+				alpha.InboundMessage = message
+				alpha.OutboundMessage = message
+			})
+
+			It("generates an echo server using the typed connection", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "first.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("return ctx.Upgrade(func(conn *app_.AlphaFirstConn) {"))
+				Ω(string(content)).Should(MatchRegexp(`msg, err := conn.Receive\(\)\s*if err != nil {\s*return\s*}\s*if err := conn.Send\(msg\); err != nil {`))
+			})
+		})
+
 	})
 })
 
@@ -237,12 +258,20 @@ var _ = Describe("NewGenerator", func() {
 })
 
 const listenAndServeCode = `
+	// Shutdown gracefully on SIGINT and SIGTERM
+	service.ShutdownOnSignal(goa.DefaultShutdownTimeout)
+
+	// Start service
 	if err := service.ListenAndServe(":8080"); err != nil {
 		service.LogError("startup", "err", err)
 	}
 `
 
 const listenAndServeTLSCode = `
+	// Shutdown gracefully on SIGINT and SIGTERM
+	service.ShutdownOnSignal(goa.DefaultShutdownTimeout)
+
+	// Start service
 	if err := service.ListenAndServeTLS(":8080", "cert.pem", "key.pem"); err != nil {
 		service.LogError("startup", "err", err)
 	}
@@ -0,0 +1,348 @@
+package genmain
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// removedActionsMarker is the first line of the comment that introduces the section listing the
+// implementation of the actions that were removed from the design.
+const removedActionsMarker = "// goagen: removed actions"
+
+// removedActionsHeader is the comment written before the code of the removed actions.
+const removedActionsHeader = removedActionsMarker + `
+//
+// The actions below are no longer part of the design. Their implementation was moved here when the
+// controller was regenerated, port the code to the new actions or delete it.
+`
+
+type (
+	// MergeReport describes the changes made to an existing controller when merging it with the
+	// regenerated scaffolding.
+	MergeReport struct {
+		// Added lists the names of the functions and methods added to the controller.
+		Added []string
+		// Removed lists the names of the action methods moved to the removed actions section.
+		Removed []string
+		// Conflicts lists the functions whose signature differs from the generated one.
+		Conflicts []*MergeConflict
+	}
+
+	// MergeConflict describes a function whose existing signature differs from the
+	// signature produced by the generator. The existing code is always kept.
+	MergeConflict struct {
+		// Name is the name of the function or method.
+		Name string
+		// Existing is the signature found in the existing controller.
+		Existing string
+		// Generated is the signature produced by the generator.
+		Generated string
+	}
+
+	// edit describes a change made to the existing controller source.
+	edit struct {
+		start, end int
+		text       string
+	}
+)
+
+// MergeController merges the existing content of a controller file with the content produced by
+// the generator. The code of the existing file is kept as is: imports, helper functions, fields
+// added to the controller struct and comments are not modified. Functions and methods generated
+// for new actions are added to the file and the actions that do not exist in the design anymore
+// are moved - commented out - to a section at the end of the file. Functions that exist in both
+// but whose signature changed are reported as conflicts and left untouched.
+func MergeController(existing, generated []byte) ([]byte, *MergeReport, error) {
+	fset := token.NewFileSet()
+	ef, err := parser.ParseFile(fset, "existing.go", existing, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse existing controller: %s", err)
+	}
+	gf, err := parser.ParseFile(fset, "generated.go", generated, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse generated controller: %s", err)
+	}
+
+	var (
+		report    = &MergeReport{}
+		decls     = make(map[string]ast.Decl)
+		generates = make(map[string]bool)
+		added     bytes.Buffer
+	)
+	for _, d := range ef.Decls {
+		if name := declName(d); name != "" {
+			decls[name] = d
+		}
+	}
+	for _, d := range gf.Decls {
+		name := declName(d)
+		if name == "" {
+			continue
+		}
+		generates[name] = true
+		if e, ok := decls[name]; ok {
+			efn, ok1 := e.(*ast.FuncDecl)
+			gfn, ok2 := d.(*ast.FuncDecl)
+			if ok1 && ok2 {
+				if es, gs := signature(efn), signature(gfn); es != gs {
+					report.Conflicts = append(report.Conflicts,
+						&MergeConflict{Name: efn.Name.Name, Existing: es, Generated: gs})
+				}
+			}
+			continue
+		}
+		start, end := declRange(fset, generated, d)
+		added.WriteString("\n")
+		added.Write(bytes.TrimSpace(generated[start:end]))
+		added.WriteString("\n")
+		report.Added = append(report.Added, shortName(name))
+	}
+
+	// Compute the edits: remove the actions that are not generated anymore, add the new
+	// declarations before the removed actions section and append the removed actions to it.
+	var (
+		edits   []*edit
+		removed bytes.Buffer
+	)
+	for _, d := range ef.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok || !isAction(fn) || generates[declName(fn)] {
+			continue
+		}
+		start, end := declRange(fset, existing, fn)
+		edits = append(edits, &edit{start: start, end: end})
+		removed.WriteString("//\n")
+		removed.WriteString(commentOut(existing[start:end]))
+		report.Removed = append(report.Removed, fn.Name.Name)
+	}
+	marker := removedActionsOffset(fset, ef)
+	header := ""
+	if marker < 0 {
+		marker = len(existing)
+		header = "\n" + removedActionsHeader
+	}
+	if added.Len() > 0 {
+		edits = append(edits, &edit{start: marker, end: marker, text: added.String() + "\n"})
+	}
+	if removed.Len() > 0 {
+		edits = append(edits, &edit{start: len(existing), end: len(existing), text: header + removed.String()})
+	}
+	if len(edits) == 0 {
+		return existing, report, nil
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var buf bytes.Buffer
+	offset := 0
+	for _, e := range edits {
+		buf.Write(existing[offset:e.start])
+		if e.start == len(existing) && len(existing) > 0 && existing[len(existing)-1] != '\n' {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(e.text)
+		offset = e.end
+	}
+	buf.Write(existing[offset:])
+
+	// Add the imports needed by the generated code.
+	mset := token.NewFileSet()
+	mf, err := parser.ParseFile(mset, "merged.go", buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to merge controller: %s", err)
+	}
+	paths := make(map[string]bool)
+	for _, imp := range mf.Imports {
+		paths[importPath(imp)] = true
+	}
+	for _, imp := range gf.Imports {
+		path := importPath(imp)
+		if paths[path] {
+			continue
+		}
+		var name string
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		astutil.AddNamedImport(mset, mf, name, path)
+		if !astutil.UsesImport(mf, path) {
+			astutil.DeleteNamedImport(mset, mf, name, path)
+		}
+	}
+	var res bytes.Buffer
+	if err := format.Node(&res, mset, mf); err != nil {
+		return nil, nil, err
+	}
+	return res.Bytes(), report, nil
+}
+
+// String returns a description of the conflict suitable for reporting to the user.
+func (c *MergeConflict) String() string {
+	return fmt.Sprintf("%s: signature conflict, kept existing %s instead of generated %s",
+		c.Name, c.Existing, c.Generated)
+}
+
+// declName returns a name that uniquely identifies the declaration in its file. Methods are
+// prefixed with the name of the receiver type. declName returns an empty string for imports.
+func declName(d ast.Decl) string {
+	switch actual := d.(type) {
+	case *ast.FuncDecl:
+		if recv := receiverName(actual); recv != "" {
+			return recv + "." + actual.Name.Name
+		}
+		return actual.Name.Name
+	case *ast.GenDecl:
+		if actual.Tok == token.IMPORT || len(actual.Specs) == 0 {
+			return ""
+		}
+		switch s := actual.Specs[0].(type) {
+		case *ast.TypeSpec:
+			return s.Name.Name
+		case *ast.ValueSpec:
+			return s.Names[0].Name
+		}
+	}
+	return ""
+}
+
+// shortName removes the receiver type prefix from the names returned by declName.
+func shortName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// receiverName returns the name of the receiver type of a method, the empty string for functions.
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	t := fn.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// isAction returns true if fn is a method generated for an action, that is a method of a
+// controller whose only parameter is the action context or the corresponding websocket handler.
+func isAction(fn *ast.FuncDecl) bool {
+	recv := receiverName(fn)
+	if !strings.HasSuffix(recv, "Controller") || len(fn.Type.Params.List) != 1 {
+		return false
+	}
+	param := fn.Type.Params.List[0]
+	if len(param.Names) > 1 {
+		return false
+	}
+	star, ok := param.Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	action := strings.TrimSuffix(fn.Name.Name, "WSHandler")
+	return sel.Sel.Name == action+strings.TrimSuffix(recv, "Controller")+"Context"
+}
+
+// signature returns the signature of fn without parameter names.
+func signature(fn *ast.FuncDecl) string {
+	list := func(fields *ast.FieldList) []string {
+		if fields == nil {
+			return nil
+		}
+		var res []string
+		for _, f := range fields.List {
+			n := len(f.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				res = append(res, types.ExprString(f.Type))
+			}
+		}
+		return res
+	}
+	sig := "func(" + strings.Join(list(fn.Type.Params), ", ") + ")"
+	switch results := list(fn.Type.Results); len(results) {
+	case 0:
+	case 1:
+		sig += " " + results[0]
+	default:
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
+
+// declRange returns the offsets of the beginning of the line where the declaration or its
+// documentation starts and of the end of the line where the declaration ends.
+func declRange(fset *token.FileSet, src []byte, d ast.Decl) (int, int) {
+	pos := d.Pos()
+	switch actual := d.(type) {
+	case *ast.FuncDecl:
+		if actual.Doc != nil {
+			pos = actual.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if actual.Doc != nil {
+			pos = actual.Doc.Pos()
+		}
+	}
+	start := fset.Position(pos).Offset
+	end := fset.Position(d.End()).Offset
+	for start > 0 && src[start-1] != '\n' {
+		start--
+	}
+	for end < len(src) && src[end] != '\n' {
+		end++
+	}
+	if end < len(src) {
+		end++
+	}
+	return start, end
+}
+
+// removedActionsOffset returns the offset of the removed actions section in the file or -1 if
+// there isn't one.
+func removedActionsOffset(fset *token.FileSet, f *ast.File) int {
+	for _, cg := range f.Comments {
+		if len(cg.List) > 0 && strings.TrimSpace(cg.List[0].Text) == removedActionsMarker {
+			return fset.Position(cg.Pos()).Offset
+		}
+	}
+	return -1
+}
+
+// commentOut turns the given code into line comments.
+func commentOut(code []byte) string {
+	lines := strings.Split(strings.TrimRight(string(code), "\n"), "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			lines[i] = "//"
+			continue
+		}
+		lines[i] = "// " + l
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// importPath returns the unquoted path of the import.
+func importPath(imp *ast.ImportSpec) string {
+	path, err := strconv.Unquote(imp.Path.Value)
+	if err != nil {
+		return imp.Path.Value
+	}
+	return path
+}
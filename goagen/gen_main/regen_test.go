@@ -0,0 +1,165 @@
+package genmain_test
+
+import (
+	"strings"
+
+	"github.com/goadesign/goa/goagen/gen_main"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MergeController", func() {
+	var existing, generated string
+	var merged string
+	var report *genmain.MergeReport
+	var mergeErr error
+
+	BeforeEach(func() {
+		existing = existingController
+		generated = generatedController
+	})
+
+	JustBeforeEach(func() {
+		var res []byte
+		res, report, mergeErr = genmain.MergeController([]byte(existing), []byte(generated))
+		merged = string(res)
+	})
+
+	It("keeps the existing code", func() {
+		Ω(mergeErr).ShouldNot(HaveOccurred())
+		Ω(merged).Should(ContainSubstring(`"strings"`))
+		Ω(merged).Should(ContainSubstring("\tprefix string"))
+		Ω(merged).Should(ContainSubstring("// Show returns the bottle, see also greet."))
+		Ω(merged).Should(ContainSubstring(`return ctx.OK([]byte(greet(c.prefix)))`))
+		Ω(merged).Should(ContainSubstring("func greet(prefix string) string {"))
+	})
+
+	It("adds the new actions", func() {
+		Ω(mergeErr).ShouldNot(HaveOccurred())
+		Ω(report.Added).Should(Equal([]string{"Create"}))
+		Ω(merged).Should(ContainSubstring("// BottleController_Create: start_implement"))
+		Ω(strings.Index(merged, "func (c *BottleController) Create")).Should(BeNumerically(">", strings.Index(merged, "func greet")))
+	})
+
+	It("moves the removed actions to the end of the file", func() {
+		Ω(mergeErr).ShouldNot(HaveOccurred())
+		Ω(report.Removed).Should(Equal([]string{"Delete"}))
+		Ω(merged).ShouldNot(ContainSubstring("\nfunc (c *BottleController) Delete"))
+		Ω(merged).Should(ContainSubstring("// goagen: removed actions"))
+		Ω(merged).Should(HaveSuffix("//\n// // Delete runs the delete action.\n" +
+			"// func (c *BottleController) Delete(ctx *app.DeleteBottleContext) error {\n" +
+			"// \treturn ctx.NoContent()\n" +
+			"// }\n"))
+		Ω(strings.Index(merged, "// goagen: removed actions")).Should(BeNumerically(">", strings.Index(merged, "func (c *BottleController) Create")))
+	})
+
+	It("produces an idempotent result", func() {
+		Ω(mergeErr).ShouldNot(HaveOccurred())
+		again, rep, err := genmain.MergeController([]byte(merged), []byte(generated))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rep.Added).Should(BeEmpty())
+		Ω(rep.Removed).Should(BeEmpty())
+		Ω(string(again)).Should(Equal(merged))
+	})
+
+	Context("with an action whose signature changed", func() {
+		BeforeEach(func() {
+			generated = strings.Replace(generated, "Show(ctx *app.ShowBottleContext) error", "Show(ctx *app.ShowBottleContext) (int, error)", 1)
+		})
+
+		It("reports a conflict and keeps the existing implementation", func() {
+			Ω(mergeErr).ShouldNot(HaveOccurred())
+			Ω(report.Conflicts).Should(HaveLen(1))
+			Ω(report.Conflicts[0].Name).Should(Equal("Show"))
+			Ω(report.Conflicts[0].Existing).Should(Equal("func(*app.ShowBottleContext) error"))
+			Ω(report.Conflicts[0].Generated).Should(Equal("func(*app.ShowBottleContext) (int, error)"))
+			Ω(merged).Should(ContainSubstring(`return ctx.OK([]byte(greet(c.prefix)))`))
+		})
+	})
+
+	Context("with an invalid existing file", func() {
+		BeforeEach(func() {
+			existing = "package main\n\nfunc {"
+		})
+
+		It("returns an error", func() {
+			Ω(mergeErr).Should(HaveOccurred())
+		})
+	})
+})
+
+const existingController = `package main
+
+import (
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/example/app"
+)
+
+// BottleController implements the bottle resource.
+type BottleController struct {
+	*goa.Controller
+	prefix string
+}
+
+// NewBottleController creates a bottle controller.
+func NewBottleController(service *goa.Service) *BottleController {
+	return &BottleController{Controller: service.NewController("BottleController"), prefix: "hello"}
+}
+
+// Show returns the bottle, see also greet.
+func (c *BottleController) Show(ctx *app.ShowBottleContext) error {
+	// BottleController_Show: start_implement
+
+	return ctx.OK([]byte(greet(c.prefix)))
+	// BottleController_Show: end_implement
+}
+
+// Delete runs the delete action.
+func (c *BottleController) Delete(ctx *app.DeleteBottleContext) error {
+	return ctx.NoContent()
+}
+
+func greet(prefix string) string {
+	return strings.Title(prefix)
+}
+`
+
+const generatedController = `package main
+
+import (
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/example/app"
+)
+
+// BottleController implements the bottle resource.
+type BottleController struct {
+	*goa.Controller
+}
+
+// NewBottleController creates a bottle controller.
+func NewBottleController(service *goa.Service) *BottleController {
+	return &BottleController{Controller: service.NewController("BottleController")}
+}
+
+// Show runs the show action.
+func (c *BottleController) Show(ctx *app.ShowBottleContext) error {
+	// BottleController_Show: start_implement
+
+	// Put your logic here
+
+	return nil
+	// BottleController_Show: end_implement
+}
+
+// Create runs the create action.
+func (c *BottleController) Create(ctx *app.CreateBottleContext) error {
+	// BottleController_Create: start_implement
+
+	// Put your logic here
+
+	return nil
+	// BottleController_Create: end_implement
+}
+`
@@ -0,0 +1,7 @@
+/*
+Package genopenapi provides a generator for the OpenAPI 3.0 specification of an API.
+The specification is generated in both JSON and YAML formats from the same design that the
+swagger generator uses and can be consumed by any tool that supports OpenAPI 3.x.
+See https://github.com/OAI/OpenAPI-Specification for more information.
+*/
+package genopenapi
@@ -0,0 +1,948 @@
+package genopenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_schema"
+)
+
+type (
+	// OpenAPI represents an instance of an OpenAPI 3.0 document.
+	// See https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md
+	OpenAPI struct {
+		OpenAPI      string               `json:"openapi"`
+		Info         *Info                `json:"info"`
+		Servers      []*Server            `json:"servers,omitempty"`
+		Paths        map[string]*PathItem `json:"paths"`
+		Components   *Components          `json:"components,omitempty"`
+		Tags         []*Tag               `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs        `json:"externalDocs,omitempty"`
+	}
+
+	// Info provides metadata about the API. The metadata can be used by the clients if needed,
+	// and can be presented in editing or documentation generation tools for convenience.
+	Info struct {
+		Title          string                    `json:"title"`
+		Description    string                    `json:"description,omitempty"`
+		TermsOfService string                    `json:"termsOfService,omitempty"`
+		Contact        *design.ContactDefinition `json:"contact,omitempty"`
+		License        *design.LicenseDefinition `json:"license,omitempty"`
+		Version        string                    `json:"version"`
+		Extensions     map[string]interface{}    `json:"-"`
+	}
+
+	// Server represents a server hosting the API.
+	Server struct {
+		// URL to the target host. The URL may be relative to indicate that the host
+		// location is relative to the location where the document is being served.
+		URL string `json:"url"`
+		// Description of the host designated by the URL.
+		Description string `json:"description,omitempty"`
+	}
+
+	// PathItem describes the operations available on a single path.
+	PathItem struct {
+		// Get defines a GET operation on this path.
+		Get *Operation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *Operation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *Operation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *Operation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *Operation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *Operation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *Operation `json:"patch,omitempty"`
+		// Trace defines a TRACE operation on this path.
+		Trace *Operation `json:"trace,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Operation describes a single API operation on a path.
+	Operation struct {
+		// Tags is a list of tags for API documentation control. Tags can be used for
+		// logical grouping of operations by resources or any other qualifier.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		// CommonMark syntax can be used for rich text representation.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is a list of parameters that are applicable for this operation.
+		Parameters []*Parameter `json:"parameters,omitempty"`
+		// RequestBody is the request body applicable for this operation.
+		RequestBody *RequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses as they are returned from executing
+		// this operation.
+		Responses map[string]*Response `json:"responses"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security is a declaration of which security schemes are applied for this
+		// operation.
+		Security []map[string][]string `json:"security,omitempty"`
+		// Servers overrides the API servers for this operation.
+		Servers []*Server `json:"servers,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Parameter describes a single operation parameter.
+	Parameter struct {
+		// Name of the parameter. Parameter names are case sensitive.
+		Name string `json:"name"`
+		// In is the location of the parameter.
+		// Possible values are "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required,omitempty"`
+		// Schema defines the type used for the parameter.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// RequestBody describes a single request body.
+	RequestBody struct {
+		// Description is a brief description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content maps media types to the corresponding request body definition.
+		Content map[string]*MediaType `json:"content"`
+		// Required determines if the request body is required in the request.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// MediaType provides schema and examples for the media type identified by its key.
+	MediaType struct {
+		// Schema defines the type used for the request or response body.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Response describes a single response from an API operation.
+	Response struct {
+		// Description of the response. CommonMark syntax can be used for rich text
+		// representation.
+		Description string `json:"description"`
+		// Headers maps header names to their definitions.
+		Headers map[string]*Header `json:"headers,omitempty"`
+		// Content maps media types to the corresponding response body definition.
+		Content map[string]*MediaType `json:"content,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Header describes a single response header.
+	Header struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Schema defines the type used for the header.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Components holds the reusable objects referenced by the rest of the document.
+	Components struct {
+		// Schemas holds the user and media type schemas.
+		Schemas map[string]*genschema.JSONSchema `json:"schemas,omitempty"`
+		// Responses holds the API wide responses.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// Parameters holds the API wide parameters.
+		Parameters map[string]*Parameter `json:"parameters,omitempty"`
+		// SecuritySchemes holds the API security schemes.
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme defines a security scheme that can be used by the operations. Supported
+	// schemes are HTTP authentication (basic and bearer), an API key (either as a header or as
+	// a query parameter) and OAuth2's common flows.
+	SecurityScheme struct {
+		// Type of the security scheme. Valid values are "apiKey", "http" or "oauth2".
+		Type string `json:"type"`
+		// Description for security scheme.
+		Description string `json:"description,omitempty"`
+		// Name of the header or query parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		// Valid values are "query" or "header".
+		In string `json:"in,omitempty"`
+		// Scheme is the name of the HTTP authorization scheme when type is "http".
+		Scheme string `json:"scheme,omitempty"`
+		// BearerFormat is a hint to the client to identify how the bearer token is
+		// formatted.
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		// Flows contains the configuration information for the supported OAuth2 flows.
+		Flows *OAuthFlows `json:"flows,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OAuthFlows lists the supported OAuth2 flows.
+	OAuthFlows struct {
+		// Implicit configures the OAuth2 implicit flow.
+		Implicit *OAuthFlow `json:"implicit,omitempty"`
+		// Password configures the OAuth2 resource owner password flow.
+		Password *OAuthFlow `json:"password,omitempty"`
+		// ClientCredentials configures the OAuth2 client credentials flow ("application"
+		// flow in the design).
+		ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+		// AuthorizationCode configures the OAuth2 authorization code flow ("access code"
+		// flow in the design).
+		AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	}
+
+	// OAuthFlow describes the configuration of a single OAuth2 flow.
+	OAuthFlow struct {
+		// AuthorizationURL is the authorization URL to be used for this flow.
+		AuthorizationURL string `json:"authorizationUrl,omitempty"`
+		// TokenURL is the token URL to be used for this flow.
+		TokenURL string `json:"tokenUrl,omitempty"`
+		// Scopes list the available scopes for the OAuth2 security scheme.
+		Scopes map[string]string `json:"scopes"`
+	}
+
+	// ExternalDocs allows referencing an external resource for extended documentation.
+	ExternalDocs struct {
+		// Description is a short description of the target documentation.
+		Description string `json:"description,omitempty"`
+		// URL for the target documentation.
+		URL string `json:"url"`
+	}
+
+	// Tag allows adding meta data to a single tag that is used by the Operation Object.
+	Tag struct {
+		// Name of the tag.
+		Name string `json:"name"`
+		// Description is a short description of the tag.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs is additional external documentation for this tag.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// These types are used in marshalJSON() to avoid recursive call of json.Marshal().
+	_Info           Info
+	_PathItem       PathItem
+	_Operation      Operation
+	_Parameter      Parameter
+	_Response       Response
+	_SecurityScheme SecurityScheme
+	_Tag            Tag
+)
+
+// Prefix of the JSON schema references produced by genschema and their OpenAPI counterpart.
+const (
+	definitionsRef = "#/definitions/"
+	schemasRef     = "#/components/schemas/"
+)
+
+func marshalJSON(v interface{}, extensions map[string]interface{}) ([]byte, error) {
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(extensions) == 0 {
+		return marshaled, nil
+	}
+	var unmarshaled map[string]interface{}
+	if err := json.Unmarshal(marshaled, &unmarshaled); err != nil {
+		return nil, err
+	}
+	for k, v := range extensions {
+		unmarshaled[k] = v
+	}
+	return json.Marshal(unmarshaled)
+}
+
+// MarshalJSON returns the JSON encoding of i.
+func (i Info) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Info(i), i.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p PathItem) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_PathItem(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Operation(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Parameter(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r Response) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Response(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of s.
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_SecurityScheme(s), s.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of t.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Tag(t), t.Extensions)
+}
+
+// New creates an OpenAPI 3.0 document from an API definition. New honors the same "swagger:"
+// metadata as the Swagger generator (generate, summary, tag and extension).
+func New(api *design.APIDefinition) (*OpenAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	basePath := api.BasePath
+	if hasAbsoluteRoutes(api) || len(design.ExtractWildcards(basePath)) > 0 {
+		basePath = ""
+	}
+	o := &OpenAPI{
+		OpenAPI: "3.0.0",
+		Info: &Info{
+			Title:          api.Title,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Version:        api.Version,
+			Extensions:     extensionsFromDefinition(api.Metadata),
+		},
+		Servers:      serversFromDefinition(api, basePath),
+		Paths:        make(map[string]*PathItem),
+		Components:   &Components{SecuritySchemes: securitySchemesFromDefinition(api.SecuritySchemes)},
+		Tags:         tagsFromDefinition(api.Metadata),
+		ExternalDocs: docsFromDefinition(api.Docs),
+	}
+	if o.Info.Title == "" {
+		o.Info.Title = api.Name
+	}
+	if api.Params != nil {
+		params, err := paramsFromDefinition(api, api.Params, api.BasePath)
+		if err != nil {
+			return nil, err
+		}
+		if len(params) > 0 {
+			o.Components.Parameters = make(map[string]*Parameter, len(params))
+			for _, p := range params {
+				o.Components.Parameters[p.Name] = p
+			}
+		}
+	}
+	err := api.IterateResponses(func(r *design.ResponseDefinition) error {
+		res, err := responseFromDefinition(api, r)
+		if err != nil {
+			return err
+		}
+		if o.Components.Responses == nil {
+			o.Components.Responses = make(map[string]*Response)
+		}
+		o.Components.Responses[r.Name] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			if !mustGenerate(fs.Metadata) {
+				return nil
+			}
+			buildPathFromFileServer(o, api, fs)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			for _, route := range a.Routes {
+				if err := buildPathFromDefinition(o, api, route, basePath); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(genschema.Definitions) > 0 {
+		o.Components.Schemas = make(map[string]*genschema.JSONSchema)
+		for n, d := range genschema.Definitions {
+			o.Components.Schemas[n] = adaptSchema(d)
+		}
+	}
+	return o, nil
+}
+
+// mustGenerate returns true if the metadata indicates that a specification should be generated,
+// false otherwise.
+func mustGenerate(meta dslengine.MetadataDefinition) bool {
+	if m, ok := meta["swagger:generate"]; ok {
+		if len(m) > 0 && m[0] == "false" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAbsoluteRoutes returns true if any action exposed by the API uses an absolute route or if the
+// API has file servers. In this case the server URLs do not include the API base path and all
+// paths are absolute.
+func hasAbsoluteRoutes(api *design.APIDefinition) bool {
+	for _, res := range api.Resources {
+		for _, fs := range res.FileServers {
+			if mustGenerate(fs.Metadata) {
+				return true
+			}
+		}
+		for _, a := range res.Actions {
+			if !mustGenerate(a.Metadata) {
+				continue
+			}
+			for _, ro := range a.Routes {
+				if ro.IsAbsolute() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// serversFromDefinition builds the server URLs from the API host, schemes and base path.
+func serversFromDefinition(api *design.APIDefinition, basePath string) []*Server {
+	if api.Host == "" {
+		if basePath == "" {
+			return nil
+		}
+		return []*Server{{URL: basePath}}
+	}
+	schemes := api.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	servers := make([]*Server, len(schemes))
+	for i, scheme := range schemes {
+		u := url.URL{Scheme: scheme, Host: api.Host, Path: basePath}
+		servers[i] = &Server{URL: u.String()}
+	}
+	return servers
+}
+
+// adaptSchema turns a JSON schema produced by genschema into an OpenAPI schema object: it
+// rewrites the references to point to the components schemas, replaces the Swagger "file" type
+// with binary strings and removes the hyper-schema fields that OpenAPI does not support.
+func adaptSchema(s *genschema.JSONSchema) *genschema.JSONSchema {
+	if s == nil {
+		return nil
+	}
+	s.Schema = ""
+	s.ID = ""
+	s.Media = nil
+	s.Links = nil
+	s.PathStart = ""
+	s.Definitions = nil
+	if strings.HasPrefix(s.Ref, definitionsRef) {
+		s.Ref = schemasRef + strings.TrimPrefix(s.Ref, definitionsRef)
+	}
+	if s.Type == genschema.JSONFile {
+		s.Type = genschema.JSONString
+		s.Format = "binary"
+	}
+	adaptSchema(s.Items)
+	for _, p := range s.Properties {
+		adaptSchema(p)
+	}
+	for _, a := range s.AnyOf {
+		adaptSchema(a)
+	}
+	for _, o := range s.OneOf {
+		adaptSchema(o)
+	}
+	for _, a := range s.AllOf {
+		adaptSchema(a)
+	}
+	// OpenAPI 3 discriminators are objects, the discriminator attribute is required by the
+	// schema of the union and described by its properties.
+	s.Discriminator = ""
+	return s
+}
+
+// refSchema returns a schema that consists of the given reference.
+func refSchema(ref string) *genschema.JSONSchema {
+	return adaptSchema(&genschema.JSONSchema{Ref: ref})
+}
+
+func securitySchemesFromDefinition(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+	defs := make(map[string]*SecurityScheme)
+	for _, scheme := range schemes {
+		def := &SecurityScheme{
+			Description: scheme.Description,
+			Extensions:  extensionsFromDefinition(scheme.Metadata),
+		}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			def.Type = "http"
+			def.Scheme = "basic"
+		case design.APIKeySecurityKind:
+			def.Type = "apiKey"
+			def.Name = scheme.Name
+			def.In = scheme.In
+		case design.JWTSecurityKind:
+			if scheme.In != "" && (scheme.In != "header" || !strings.EqualFold(scheme.Name, "Authorization")) {
+				// Tokens sent in a custom header or in the query string cannot be
+				// described as bearer tokens.
+				def.Type = "apiKey"
+				def.Name = scheme.Name
+				def.In = scheme.In
+			} else {
+				def.Type = "http"
+				def.Scheme = "bearer"
+				def.BearerFormat = "JWT"
+			}
+			if scheme.TokenURL != "" {
+				def.Description += fmt.Sprintf("\n\n**Token URL**: %s", scheme.TokenURL)
+			}
+			if len(scheme.Scopes) != 0 {
+				def.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(scheme.Scopes))
+			}
+		case design.OAuth2SecurityKind:
+			def.Type = "oauth2"
+			scopes := scheme.Scopes
+			if scopes == nil {
+				scopes = make(map[string]string)
+			}
+			flow := &OAuthFlow{
+				AuthorizationURL: scheme.AuthorizationURL,
+				TokenURL:         scheme.TokenURL,
+				Scopes:           scopes,
+			}
+			def.Flows = &OAuthFlows{}
+			switch scheme.Flow {
+			case "implicit":
+				flow.TokenURL = ""
+				def.Flows.Implicit = flow
+			case "password":
+				flow.AuthorizationURL = ""
+				def.Flows.Password = flow
+			case "application":
+				flow.AuthorizationURL = ""
+				def.Flows.ClientCredentials = flow
+			default:
+				def.Flows.AuthorizationCode = flow
+			}
+		default:
+			continue
+		}
+		defs[scheme.SchemeName] = def
+	}
+	return defs
+}
+
+func scopesMapList(scopes map[string]string) string {
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = fmt.Sprintf("  * `%s`: %s", name, scopes[name])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
+	var keys []string
+	for k := range mdata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 || chunks[0] != "swagger" || chunks[1] != "tag" {
+			continue
+		}
+		tag := &Tag{Name: chunks[2]}
+		if desc := mdata[key+":desc"]; len(desc) != 0 {
+			tag.Description = desc[0]
+		}
+		docs := &ExternalDocs{}
+		if u := mdata[key+":url"]; len(u) != 0 {
+			docs.URL = u[0]
+		}
+		if desc := mdata[key+":url:desc"]; len(desc) != 0 {
+			docs.Description = desc[0]
+		}
+		if docs.URL != "" || docs.Description != "" {
+			tag.ExternalDocs = docs
+		}
+		tags = append(tags, tag)
+	}
+	return
+}
+
+func tagNamesFromDefinitions(mdatas ...dslengine.MetadataDefinition) (tagNames []string) {
+	for _, mdata := range mdatas {
+		for _, tag := range tagsFromDefinition(mdata) {
+			tagNames = append(tagNames, tag.Name)
+		}
+	}
+	return
+}
+
+func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) string {
+	if mdata, ok := metadata["swagger:summary"]; ok && len(mdata) > 0 {
+		return mdata[0]
+	}
+	return name
+}
+
+func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
+	extensions := make(map[string]interface{})
+	for key, value := range mdata {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 || chunks[0] != "swagger" || chunks[1] != "extension" {
+			continue
+		}
+		if !strings.HasPrefix(chunks[2], "x-") || len(value) == 0 {
+			continue
+		}
+		var ival interface{}
+		if err := json.Unmarshal([]byte(value[0]), &ival); err != nil {
+			extensions[chunks[2]] = value[0]
+			continue
+		}
+		extensions[chunks[2]] = ival
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+	return extensions
+}
+
+func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
+	if docs == nil {
+		return nil
+	}
+	return &ExternalDocs{
+		Description: docs.Description,
+		URL:         docs.URL,
+	}
+}
+
+func paramsFromDefinition(api *design.APIDefinition, params *design.AttributeDefinition, path string) ([]*Parameter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	obj := params.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid parameters definition, not an object")
+	}
+	var res []*Parameter
+	wildcards := design.ExtractWildcards(path)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		in := "query"
+		required := params.IsRequired(n)
+		for _, w := range wildcards {
+			if n == w {
+				in = "path"
+				required = true
+				break
+			}
+		}
+		res = append(res, paramFor(api, at, n, in, required))
+		return nil
+	})
+	return res, nil
+}
+
+func paramsFromHeaders(api *design.APIDefinition, action *design.ActionDefinition) []*Parameter {
+	var params []*Parameter
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		params = append(params, paramFor(api, header, name, "header", required))
+		return nil
+	})
+	return params
+}
+
+func paramFor(api *design.APIDefinition, at *design.AttributeDefinition, name, in string, required bool) *Parameter {
+	return &Parameter{
+		Name:        name,
+		In:          in,
+		Description: at.Description,
+		Required:    required,
+		Schema:      adaptSchema(genschema.AttributeSchema(api, at)),
+		Extensions:  extensionsFromDefinition(at.Metadata),
+	}
+}
+
+func responseFromDefinition(api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
+	resp := &Response{
+		Description: r.Description,
+		Extensions:  extensionsFromDefinition(r.Metadata),
+	}
+	if resp.Description == "" {
+		// Description is required by OpenAPI
+		resp.Description = http.StatusText(r.Status)
+	}
+	if r.MediaType != "" || r.Stream != nil {
+		var schema *genschema.JSONSchema
+		if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			schema = refSchema(genschema.MediaTypeRef(api, mt, view))
+		}
+		contentType := r.MediaType
+		if r.Stream != nil {
+			// The schema describes the data of each event.
+			contentType = "text/event-stream"
+		}
+		resp.Content = map[string]*MediaType{contentType: {Schema: schema}}
+	}
+	if r.Headers != nil {
+		obj := r.Headers.Type.ToObject()
+		if obj == nil {
+			return nil, fmt.Errorf("invalid headers definition, not an object")
+		}
+		resp.Headers = make(map[string]*Header)
+		obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+			resp.Headers[n] = &Header{
+				Description: at.Description,
+				Schema:      adaptSchema(genschema.AttributeSchema(api, at)),
+			}
+			return nil
+		})
+	}
+	return resp, nil
+}
+
+func requestBodyFromDefinition(api *design.APIDefinition, action *design.ActionDefinition) *RequestBody {
+	if action.Payload == nil {
+		return nil
+	}
+	schema := adaptSchema(genschema.TypeSchema(api, action.Payload))
+	body := &RequestBody{
+		Description: action.Payload.Description,
+		Content:     make(map[string]*MediaType),
+		Required:    !action.PayloadOptional,
+	}
+	if action.PayloadMultipart {
+		body.Content["multipart/form-data"] = &MediaType{Schema: schema}
+		return body
+	}
+	for _, c := range api.Consumes {
+		for _, mt := range c.MIMETypes {
+			body.Content[mt] = &MediaType{Schema: schema}
+		}
+	}
+	if len(body.Content) == 0 {
+		body.Content["application/json"] = &MediaType{Schema: schema}
+	}
+	return body
+}
+
+func buildPathFromFileServer(o *OpenAPI, api *design.APIDefinition, fs *design.FileServerDefinition) {
+	wcs := design.ExtractWildcards(fs.RequestPath)
+	var params []*Parameter
+	if len(wcs) > 0 {
+		params = []*Parameter{{
+			In:          "path",
+			Name:        wcs[0],
+			Description: "Relative file path",
+			Required:    true,
+			Schema:      &genschema.JSONSchema{Type: genschema.JSONString},
+		}}
+	}
+
+	binary := &genschema.JSONSchema{Type: genschema.JSONString, Format: "binary"}
+	responses := map[string]*Response{
+		"200": {
+			Description: "File downloaded",
+			Content:     map[string]*MediaType{"*/*": {Schema: binary}},
+		},
+	}
+	if len(wcs) > 0 {
+		schema := adaptSchema(genschema.TypeSchema(api, design.ErrorMedia))
+		responses["404"] = &Response{
+			Description: "File not found",
+			Content:     map[string]*MediaType{design.ErrorMedia.Identifier: {Schema: schema}},
+		}
+	}
+
+	operation := &Operation{
+		Description:  fs.Description,
+		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
+		ExternalDocs: docsFromDefinition(fs.Docs),
+		OperationID:  fmt.Sprintf("%s#%s", fs.Parent.Name, fs.RequestPath),
+		Parameters:   params,
+		Responses:    responses,
+	}
+	applySecurity(operation, fs.Security)
+
+	item := pathItem(o, openAPIPath(fs.RequestPath, ""))
+	item.Get = operation
+	item.Extensions = extensionsFromDefinition(fs.Metadata)
+}
+
+func buildPathFromDefinition(o *OpenAPI, api *design.APIDefinition, route *design.RouteDefinition, basePath string) error {
+	action := route.Parent
+
+	tagNames := tagNamesFromDefinitions(action.Parent.Metadata, action.Metadata)
+	if len(tagNames) == 0 {
+		// By default tag with resource name
+		tagNames = []string{action.Parent.Name}
+	}
+	params, err := paramsFromDefinition(api, action.AllParams(), route.FullPath())
+	if err != nil {
+		return err
+	}
+	params = append(params, paramsFromHeaders(api, action)...)
+
+	responses := make(map[string]*Response, len(action.Responses))
+	for _, r := range action.Responses {
+		resp, err := responseFromDefinition(api, r)
+		if err != nil {
+			return err
+		}
+		responses[strconv.Itoa(r.Status)] = resp
+	}
+	if len(responses) == 0 {
+		// OpenAPI requires at least one response
+		responses["default"] = &Response{Description: "Unspecified response"}
+	}
+
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	for i, rt := range action.Routes {
+		if rt == route && i > 0 {
+			operationID = fmt.Sprintf("%s#%d", operationID, i)
+			break
+		}
+	}
+
+	operation := &Operation{
+		Tags:         tagNames,
+		Description:  action.Description,
+		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		ExternalDocs: docsFromDefinition(action.Docs),
+		OperationID:  operationID,
+		Parameters:   params,
+		RequestBody:  requestBodyFromDefinition(api, action),
+		Responses:    responses,
+		Extensions:   extensionsFromDefinition(route.Metadata),
+	}
+	if len(action.Schemes) > 0 && api.Host != "" {
+		for _, scheme := range action.Schemes {
+			u := url.URL{Scheme: scheme, Host: api.Host, Path: basePath}
+			operation.Servers = append(operation.Servers, &Server{URL: u.String()})
+		}
+	}
+	applySecurity(operation, action.Security)
+
+	item := pathItem(o, openAPIPath(route.FullPath(), basePath))
+	switch route.Verb {
+	case "GET":
+		item.Get = operation
+	case "PUT":
+		item.Put = operation
+	case "POST":
+		item.Post = operation
+	case "DELETE":
+		item.Delete = operation
+	case "OPTIONS":
+		item.Options = operation
+	case "HEAD":
+		item.Head = operation
+	case "PATCH":
+		item.Patch = operation
+	case "TRACE":
+		item.Trace = operation
+	}
+	item.Extensions = extensionsFromDefinition(action.Metadata)
+	return nil
+}
+
+// openAPIPath converts the given goa route path into an OpenAPI path relative to basePath.
+func openAPIPath(path, basePath string) string {
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		path,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if basePath != "" && basePath != "/" {
+		key = strings.TrimPrefix(key, basePath)
+	}
+	if key == "" {
+		key = "/"
+	}
+	return key
+}
+
+// pathItem returns the path item with the given key, creating it if needed.
+func pathItem(o *OpenAPI, key string) *PathItem {
+	item, ok := o.Paths[key]
+	if !ok {
+		item = new(PathItem)
+		o.Paths[key] = item
+	}
+	return item
+}
+
+func applySecurity(operation *Operation, security *design.SecurityDefinition) {
+	if security == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return
+	}
+	// Only OAuth2 requirements may list scopes, the scopes of other schemes are
+	// documented in the description.
+	scopes := make([]string, 0)
+	if security.Scheme.Kind == design.OAuth2SecurityKind {
+		scopes = append(scopes, security.Scopes...)
+	} else if len(security.Scopes) > 0 {
+		if operation.Description != "" {
+			operation.Description += "\n\n"
+		}
+		operation.Description += fmt.Sprintf("Required security scopes:\n%s", scopesList(security.Scopes))
+	}
+	operation.Security = []map[string][]string{{security.Scheme.SchemeName: scopes}}
+}
+
+func scopesList(scopes []string) string {
+	sorted := make([]string, len(scopes))
+	copy(sorted, scopes)
+	sort.Strings(sorted)
+
+	lines := make([]string, len(sorted))
+	for i, scope := range sorted {
+		lines[i] = fmt.Sprintf("  * `%s`", scope)
+	}
+	return strings.Join(lines, "\n")
+}
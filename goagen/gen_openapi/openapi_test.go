@@ -0,0 +1,270 @@
+package genopenapi_test
+
+import (
+	"encoding/json"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_openapi"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var openapi *genopenapi.OpenAPI
+	var newErr error
+
+	BeforeEach(func() {
+		openapi = nil
+		newErr = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		openapi, newErr = genopenapi.New(Design)
+	})
+
+	Context("with a valid API definition", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Title("title")
+				Description("description")
+				Version("1.0")
+				Host("goa.design")
+				Scheme("http", "https")
+				BasePath("/base")
+			})
+		})
+
+		It("sets the basic fields", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(openapi.OpenAPI).Should(Equal("3.0.0"))
+			Ω(openapi.Info.Title).Should(Equal("title"))
+			Ω(openapi.Info.Description).Should(Equal("description"))
+			Ω(openapi.Info.Version).Should(Equal("1.0"))
+		})
+
+		It("builds the servers from the host, schemes and base path", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(openapi.Servers).Should(Equal([]*genopenapi.Server{
+				{URL: "http://goa.design/base"},
+				{URL: "https://goa.design/base"},
+			}))
+		})
+
+		It("serializes into JSON", func() {
+			b, err := json.Marshal(openapi)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"openapi":"3.0.0"`))
+		})
+	})
+
+	Context("with an action that has a payload and a media type response", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				BasePath("/base")
+				Consumes("application/json")
+				Consumes("application/xml")
+			})
+			p := Type("Payload", func() {
+				Attribute("name", String)
+				Required("name")
+			})
+			mt := MediaType("application/vnd.goa.example", func() {
+				TypeName("Example")
+				Attributes(func() {
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("res", func() {
+				Action("create", func() {
+					Routing(POST("/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Payload(p)
+					Response(Created, mt)
+				})
+			})
+		})
+
+		It("generates the path relative to the base path", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(openapi.Paths).Should(HaveKey("/{id}"))
+			op := openapi.Paths["/{id}"].Post
+			Ω(op).ShouldNot(BeNil())
+			Ω(op.OperationID).Should(Equal("res#create"))
+			Ω(op.Parameters).Should(HaveLen(1))
+			Ω(op.Parameters[0].In).Should(Equal("path"))
+			Ω(op.Parameters[0].Required).Should(BeTrue())
+			Ω(string(op.Parameters[0].Schema.Type)).Should(Equal("integer"))
+		})
+
+		It("generates a request body for each consumed content type", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			body := openapi.Paths["/{id}"].Post.RequestBody
+			Ω(body).ShouldNot(BeNil())
+			Ω(body.Required).Should(BeTrue())
+			Ω(body.Content).Should(HaveLen(2))
+			Ω(body.Content).Should(HaveKey("application/json"))
+			Ω(body.Content).Should(HaveKey("application/xml"))
+			Ω(body.Content["application/json"].Schema.Ref).Should(Equal("#/components/schemas/Payload"))
+		})
+
+		It("references the component schemas", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			resp := openapi.Paths["/{id}"].Post.Responses["201"]
+			Ω(resp).ShouldNot(BeNil())
+			Ω(resp.Description).Should(Equal("Created"))
+			Ω(resp.Content).Should(HaveKey("application/vnd.goa.example"))
+			Ω(resp.Content["application/vnd.goa.example"].Schema.Ref).Should(Equal("#/components/schemas/Example"))
+			Ω(openapi.Components.Schemas).Should(HaveKey("Example"))
+			Ω(openapi.Components.Schemas).Should(HaveKey("Payload"))
+		})
+	})
+
+	Context("with a multipart/form-data payload", func() {
+		BeforeEach(func() {
+			f := Type("MultipartPayload", func() {
+				Attribute("image", File, "Binary image data")
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(PUT("/"))
+					MultipartForm()
+					Payload(f)
+				})
+			})
+		})
+
+		It("generates a multipart request body", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			body := openapi.Paths["/"].Put.RequestBody
+			Ω(body.Content).Should(HaveLen(1))
+			Ω(body.Content).Should(HaveKey("multipart/form-data"))
+		})
+
+		It("describes files as binary strings", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			s := openapi.Components.Schemas["MultipartPayload"]
+			Ω(s).ShouldNot(BeNil())
+			Ω(s.Properties).Should(HaveKey("image"))
+			Ω(string(s.Properties["image"].Type)).Should(Equal("string"))
+			Ω(s.Properties["image"].Format).Should(Equal("binary"))
+		})
+	})
+
+	Context("with a server-sent events response", func() {
+		BeforeEach(func() {
+			mt := MediaType("application/vnd.item", func() {
+				Attributes(func() {
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+					Response(OK, func() {
+						Stream(mt)
+					})
+				})
+			})
+		})
+
+		It("uses the text/event-stream content type", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			resp := openapi.Paths["/"].Get.Responses["200"]
+			Ω(resp).ShouldNot(BeNil())
+			Ω(resp.Content).Should(HaveLen(1))
+			Ω(resp.Content).Should(HaveKey("text/event-stream"))
+			Ω(resp.Content["text/event-stream"].Schema.Ref).Should(Equal("#/components/schemas/Item"))
+		})
+	})
+
+	Context("with security schemes", func() {
+		BeforeEach(func() {
+			basic := BasicAuthSecurity("basic")
+			jwt := JWTSecurity("jwt", func() {
+				TokenURL("http://example.com/token")
+				Scope("api:read", "Read access")
+			})
+			queryJWT := JWTSecurity("query_jwt", func() {
+				Query("token")
+			})
+			oauth2 := OAuth2Security("oauth2", func() {
+				AccessCodeFlow("http://example.com/auth", "http://example.com/token")
+				Scope("api:write", "Write access")
+			})
+			key := APIKeySecurity("key", func() {
+				Query("k")
+			})
+			API("test", func() {
+				Security(basic)
+			})
+			Resource("res", func() {
+				Action("jwt", func() {
+					Routing(GET("/jwt"))
+					Security(jwt, func() {
+						Scope("api:read")
+					})
+				})
+				Action("query_jwt", func() {
+					Routing(GET("/query_jwt"))
+					Security(queryJWT)
+				})
+				Action("oauth2", func() {
+					Routing(GET("/oauth2"))
+					Security(oauth2, func() {
+						Scope("api:write")
+					})
+				})
+				Action("key", func() {
+					Routing(GET("/key"))
+					Security(key)
+				})
+			})
+		})
+
+		It("generates the security schemes", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			schemes := openapi.Components.SecuritySchemes
+			Ω(schemes).Should(HaveLen(5))
+			Ω(schemes["basic"].Type).Should(Equal("http"))
+			Ω(schemes["basic"].Scheme).Should(Equal("basic"))
+			Ω(schemes["jwt"].Type).Should(Equal("http"))
+			Ω(schemes["jwt"].Scheme).Should(Equal("bearer"))
+			Ω(schemes["jwt"].BearerFormat).Should(Equal("JWT"))
+			Ω(schemes["query_jwt"].Type).Should(Equal("apiKey"))
+			Ω(schemes["query_jwt"].In).Should(Equal("query"))
+			Ω(schemes["query_jwt"].Name).Should(Equal("token"))
+			Ω(schemes["query_jwt"].Scheme).Should(BeEmpty())
+			Ω(schemes["oauth2"].Type).Should(Equal("oauth2"))
+			Ω(schemes["oauth2"].Flows.AuthorizationCode).Should(Equal(&genopenapi.OAuthFlow{
+				AuthorizationURL: "http://example.com/auth",
+				TokenURL:         "http://example.com/token",
+				Scopes:           map[string]string{"api:write": "Write access"},
+			}))
+			Ω(schemes["key"].Type).Should(Equal("apiKey"))
+			Ω(schemes["key"].In).Should(Equal("query"))
+			Ω(schemes["key"].Name).Should(Equal("k"))
+		})
+
+		It("only lists scopes in OAuth2 security requirements", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(openapi.Paths["/jwt"].Get.Security).Should(Equal([]map[string][]string{{"jwt": {}}}))
+			Ω(openapi.Paths["/jwt"].Get.Description).Should(ContainSubstring("api:read"))
+			Ω(openapi.Paths["/oauth2"].Get.Security).Should(Equal([]map[string][]string{{"oauth2": {"api:write"}}}))
+		})
+	})
+})
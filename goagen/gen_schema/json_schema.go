@@ -48,7 +48,10 @@ type (
 		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
 
 		// Union
-		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+		AnyOf         []*JSONSchema `json:"anyOf,omitempty"`
+		OneOf         []*JSONSchema `json:"oneOf,omitempty"`
+		AllOf         []*JSONSchema `json:"allOf,omitempty"`
+		Discriminator string        `json:"discriminator,omitempty"`
 	}
 
 	// JSONType is the JSON type enum.
@@ -279,6 +282,32 @@ func GenerateTypeDefinition(api *design.APIDefinition, ut *design.UserTypeDefini
 	buildAttributeSchema(api, s, ut.AttributeDefinition)
 }
 
+// PrimitiveFormat returns the JSON schema format that describes the values of the given primitive
+// type, the empty string if there isn't one.
+func PrimitiveFormat(p design.Primitive) string {
+	switch p.Kind() {
+	case design.UUIDKind:
+		return "uuid"
+	case design.DateTimeKind:
+		return "date-time"
+	case design.NumberKind:
+		return "double"
+	case design.IntegerKind, design.Int64Kind:
+		return "int64"
+	case design.UInt64Kind:
+		return "uint64"
+	case design.Float32Kind:
+		return "float"
+	case design.BytesKind:
+		return "byte"
+	case design.DateKind:
+		return "date"
+	case design.DurationKind:
+		return "duration"
+	}
+	return ""
+}
+
 // TypeSchema produces the JSON schema corresponding to the given data type.
 func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	s := NewJSONSchema()
@@ -287,15 +316,10 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 		if name := actual.Name(); name != "any" {
 			s.Type = JSONType(actual.Name())
 		}
-		switch actual.Kind() {
-		case design.UUIDKind:
-			s.Format = "uuid"
-		case design.DateTimeKind:
-			s.Format = "date-time"
-		case design.NumberKind:
-			s.Format = "double"
-		case design.IntegerKind:
-			s.Format = "int64"
+		s.Format = PrimitiveFormat(actual)
+		if actual.Kind() == design.UInt64Kind {
+			min := 0.0
+			s.Minimum = &min
 		}
 	case *design.Array:
 		s.Type = JSONArray
@@ -311,6 +335,21 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	case *design.Hash:
 		s.Type = JSONObject
 		s.AdditionalProperties = true
+	case *design.Union:
+		values := make([]interface{}, len(actual.Variants))
+		for i, v := range actual.Variants {
+			s.OneOf = append(s.OneOf, TypeSchema(api, v.Type))
+			values[i] = actual.DiscriminatorValue(v)
+		}
+		if actual.Discriminator != "" {
+			s.Type = JSONObject
+			s.Discriminator = actual.Discriminator
+			prop := NewJSONSchema()
+			prop.Type = JSONString
+			prop.Enum = values
+			s.Properties[actual.Discriminator] = prop
+			s.Required = []string{actual.Discriminator}
+		}
 	case *design.UserTypeDefinition:
 		s.Ref = TypeRef(api, actual)
 	case *design.MediaTypeDefinition:
@@ -320,6 +359,12 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	return s
 }
 
+// AttributeSchema produces the JSON schema corresponding to the given attribute including its
+// description, default value, example and validations.
+func AttributeSchema(api *design.APIDefinition, at *design.AttributeDefinition) *JSONSchema {
+	return buildAttributeSchema(api, NewJSONSchema(), at)
+}
+
 type mergeItems []struct {
 	a, b   interface{}
 	needed bool
@@ -344,28 +389,31 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 		{&s.Format, other.Format, s.Format == ""},
 		{&s.Pattern, other.Pattern, s.Pattern == ""},
 		{&s.AdditionalProperties, other.AdditionalProperties, s.AdditionalProperties == false},
+		{&s.OneOf, other.OneOf, s.OneOf == nil},
+		{&s.AllOf, other.AllOf, s.AllOf == nil},
+		{&s.Discriminator, other.Discriminator, s.Discriminator == ""},
 		{
-			a: s.Minimum, b: other.Minimum,
+			a: &s.Minimum, b: other.Minimum,
 			needed: minFloat(s.Minimum, other.Minimum),
 		},
 		{
-			a: s.Maximum, b: other.Maximum,
+			a: &s.Maximum, b: other.Maximum,
 			needed: maxFloat(s.Maximum, other.Maximum),
 		},
 		{
-			a: s.MinLength, b: other.MinLength,
+			a: &s.MinLength, b: other.MinLength,
 			needed: minInt(s.MinLength, other.MinLength),
 		},
 		{
-			a: s.MaxLength, b: other.MaxLength,
+			a: &s.MaxLength, b: other.MaxLength,
 			needed: maxInt(s.MaxLength, other.MaxLength),
 		},
 		{
-			a: s.MinItems, b: other.MinItems,
+			a: &s.MinItems, b: other.MinItems,
 			needed: minInt(s.MinItems, other.MinItems),
 		},
 		{
-			a: s.MaxItems, b: other.MaxItems,
+			a: &s.MaxItems, b: other.MaxItems,
 			needed: maxInt(s.MaxItems, other.MaxItems),
 		},
 	}
@@ -429,6 +477,9 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		MaxItems:             s.MaxItems,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
+		OneOf:                s.OneOf,
+		AllOf:                s.AllOf,
+		Discriminator:        s.Discriminator,
 	}
 	for n, p := range s.Properties {
 		js.Properties[n] = p.Dup()
@@ -464,7 +515,9 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 		return s
 	}
 	s.Enum = val.Values
-	s.Format = val.Format
+	if val.Format != "" {
+		s.Format = val.Format
+	}
 	s.Pattern = val.Pattern
 	if val.Minimum != nil {
 		s.Minimum = val.Minimum
@@ -78,4 +78,65 @@ var _ = Describe("TypeSchema", func() {
 		})
 
 	})
+
+	Context("with sized and encoded primitives", func() {
+		BeforeEach(func() {
+			Type("Record", func() {
+				Attribute("size", design.Int64, func() { Maximum(1024) })
+				Attribute("count", design.UInt64)
+				Attribute("ratio", design.Float32)
+				Attribute("data", design.Bytes)
+				Attribute("day", design.Date)
+				Attribute("ttl", design.Duration)
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Record"].Type
+		})
+
+		It("sets the JSON types and formats", func() {
+			Ω(s).ShouldNot(BeNil())
+			formats := map[string]string{"size": "int64", "count": "uint64", "ratio": "float", "data": "byte", "day": "date", "ttl": "duration"}
+			for n, f := range formats {
+				Ω(s.Properties).Should(HaveKey(n))
+				Ω(s.Properties[n].Format).Should(Equal(f), n)
+			}
+			Ω(s.Properties["size"].Type).Should(Equal(genschema.JSONType(genschema.JSONInteger)))
+			Ω(*s.Properties["size"].Maximum).Should(Equal(1024.0))
+			Ω(*s.Properties["count"].Minimum).Should(Equal(0.0))
+			Ω(s.Properties["day"].Type).Should(Equal(genschema.JSONType(genschema.JSONString)))
+		})
+	})
+
+	Context("with a union", func() {
+		BeforeEach(func() {
+			Type("Cat", func() {
+				Attribute("kind", design.String, func() { Enum("cat") })
+				Attribute("lives", design.Integer)
+			})
+			Type("Dog", func() {
+				Attribute("kind", design.String, func() { Enum("dog") })
+				Attribute("bark", design.String)
+			})
+			Type("Pet", func() {
+				OneOf("Cat", "Dog")
+				Discriminator("kind")
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Pet"].Type
+		})
+
+		It("returns a discriminated oneOf JSON schema", func() {
+			Ω(s).ShouldNot(BeNil())
+			Ω(s.OneOf).Should(HaveLen(2))
+			Ω(s.OneOf[0].Ref).Should(Equal("#/definitions/Cat"))
+			Ω(s.OneOf[1].Ref).Should(Equal("#/definitions/Dog"))
+			Ω(s.Type).Should(Equal(genschema.JSONType(genschema.JSONObject)))
+			Ω(s.Discriminator).Should(Equal("kind"))
+			Ω(s.Required).Should(Equal([]string{"kind"}))
+			Ω(s.Properties).Should(HaveKey("kind"))
+			Ω(s.Properties["kind"].Enum).Should(Equal([]interface{}{"cat", "dog"}))
+		})
+	})
 })
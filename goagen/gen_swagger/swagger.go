@@ -460,10 +460,53 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 			d.Links = nil
 			s.Definitions[n] = d
 		}
+		unionDefinitions(s.Definitions)
 	}
 	return s, nil
 }
 
+// unionDefinitions adapts the definitions of unions to Swagger which does not support "oneOf".
+// The definition of a union with a discriminator becomes the base definition of its variants,
+// each variant definition is a composition of the base definition and of the variant attributes.
+func unionDefinitions(defs map[string]*genschema.JSONSchema) {
+	names := make([]string, 0, len(defs))
+	for n := range defs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	composed := make(map[string]bool)
+	for _, n := range names {
+		d := defs[n]
+		if d.OneOf == nil {
+			continue
+		}
+		variants := d.OneOf
+		d.OneOf = nil
+		if d.Discriminator == "" {
+			continue
+		}
+		for _, v := range variants {
+			vn := strings.TrimPrefix(v.Ref, "#/definitions/")
+			vd, ok := defs[vn]
+			if !ok {
+				continue
+			}
+			base := genschema.NewJSONSchema()
+			base.Ref = "#/definitions/" + n
+			if composed[vn] {
+				// The type is a variant of multiple unions.
+				vd.AllOf = append([]*genschema.JSONSchema{base}, vd.AllOf...)
+				continue
+			}
+			c := genschema.NewJSONSchema()
+			c.Title = vd.Title
+			c.AllOf = []*genschema.JSONSchema{base, vd}
+			defs[vn] = c
+			composed[vn] = true
+		}
+	}
+}
+
 // mustGenerate returns true if the metadata indicates that a Swagger specification should be
 // generated, false otherwise.
 func mustGenerate(meta dslengine.MetadataDefinition) bool {
@@ -719,6 +762,7 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 		Description: at.Description,
 		Required:    required,
 		Type:        at.Type.Name(),
+		Format:      paramFormat(at.Type),
 	}
 	if at.Type.IsArray() {
 		p.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
@@ -729,6 +773,16 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 	return p
 }
 
+// paramFormat returns the format of parameters, items and headers of the given type. The format is
+// only set for the primitive types whose values cannot be described by the type name alone.
+func paramFormat(t design.DataType) string {
+	switch t.Kind() {
+	case design.Int64Kind, design.UInt64Kind, design.Float32Kind, design.BytesKind, design.DateKind, design.DurationKind:
+		return genschema.PrimitiveFormat(t.(design.Primitive))
+	}
+	return ""
+}
+
 // toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
 func toStringMap(val interface{}) interface{} {
 	switch actual := val.(type) {
@@ -766,7 +820,7 @@ func toString(val interface{}) string {
 }
 
 func itemsFromDefinition(at *design.AttributeDefinition) *Items {
-	items := &Items{Type: at.Type.Name()}
+	items := &Items{Type: at.Type.Name(), Format: paramFormat(at.Type)}
 	initValidations(at, items)
 	if at.Type.IsArray() {
 		items.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
@@ -790,8 +844,12 @@ func responseSpecFromDefinition(s *Swagger, api *design.APIDefinition, r *design
 	if err != nil {
 		return nil, err
 	}
+	desc := r.Description
+	if r.Stream != nil {
+		desc = strings.TrimSpace(desc + "\n\nStream of server-sent events (text/event-stream), the schema describes the data of each event.")
+	}
 	return &Response{
-		Description: r.Description,
+		Description: desc,
 		Schema:      schema,
 		Headers:     headers,
 		Extensions:  extensionsFromDefinition(r.Metadata),
@@ -822,6 +880,76 @@ func responseFromDefinition(s *Swagger, api *design.APIDefinition, r *design.Res
 	return response, nil
 }
 
+// rateLimitResponse returns the response sent by the rate limiting middleware mounted by the
+// generated code when a client exceeds the action rate limit.
+func rateLimitResponse(s *Swagger, api *design.APIDefinition) (*Response, error) {
+	header := func(desc string) *design.AttributeDefinition {
+		return &design.AttributeDefinition{Type: design.Integer, Description: desc}
+	}
+	r := &design.ResponseDefinition{
+		Name:        design.TooManyRequests,
+		Description: "Rate limit exceeded",
+		Status:      429,
+		MediaType:   design.ErrorMediaIdentifier,
+		Headers: &design.AttributeDefinition{Type: design.Object{
+			"RateLimit-Limit":     header("Maximum number of requests allowed at once"),
+			"RateLimit-Remaining": header("Number of requests that can still be made right away"),
+			"RateLimit-Reset":     header("Number of seconds until the quota is fully restored"),
+			"Retry-After":         header("Number of seconds to wait before retrying the request"),
+		}},
+	}
+	return responseSpecFromDefinition(s, api, r)
+}
+
+// idempotencyKeyParam returns the Idempotency-Key header parameter of idempotent actions or nil
+// if the action defines the header explicitly.
+func idempotencyKeyParam(action *design.ActionDefinition) *Parameter {
+	if action.Headers != nil {
+		if _, ok := action.Headers.Type.ToObject()["Idempotency-Key"]; ok {
+			return nil
+		}
+	}
+	maxLength := 255 // Enforced by the idempotency middleware
+	return &Parameter{
+		Name:        "Idempotency-Key",
+		In:          "header",
+		Description: "Unique key used to retry the request safely, the response to the first request made with the key is sent back to retries",
+		Type:        "string",
+		MaxLength:   &maxLength,
+	}
+}
+
+// addIdempotencyResponses adds the responses sent by the idempotency middleware mounted by the
+// generated code when a client reuses an idempotency key.
+func addIdempotencyResponses(s *Swagger, api *design.APIDefinition, responses map[string]*Response) error {
+	defs := []*design.ResponseDefinition{
+		{
+			Name:        design.Conflict,
+			Description: "A request with the same idempotency key is in progress",
+			Status:      409,
+			MediaType:   design.ErrorMediaIdentifier,
+		},
+		{
+			Name:        design.UnprocessableEntity,
+			Description: "The idempotency key was used with a different request",
+			Status:      422,
+			MediaType:   design.ErrorMediaIdentifier,
+		},
+	}
+	for _, r := range defs {
+		code := strconv.Itoa(r.Status)
+		if _, ok := responses[code]; ok {
+			continue
+		}
+		resp, err := responseSpecFromDefinition(s, api, r)
+		if err != nil {
+			return err
+		}
+		responses[code] = resp
+	}
+	return nil
+}
+
 func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Header, error) {
 	if headers == nil {
 		return nil, nil
@@ -836,6 +964,7 @@ func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Hea
 			Default:     at.DefaultValue,
 			Description: at.Description,
 			Type:        at.Type.Name(),
+			Format:      paramFormat(at.Type),
 		}
 		initValidations(at, header)
 		res[n] = header
@@ -919,6 +1048,11 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 	}
 
 	params = append(params, paramsFromHeaders(action)...)
+	if action.Idempotent {
+		if p := idempotencyKeyParam(action); p != nil {
+			params = append(params, p)
+		}
+	}
 
 	responses := make(map[string]*Response, len(action.Responses))
 	for _, r := range action.Responses {
@@ -928,6 +1062,33 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		}
 		responses[strconv.Itoa(r.Status)] = resp
 	}
+	if action.RateLimit != nil {
+		if _, ok := responses["429"]; !ok {
+			resp, err := rateLimitResponse(s, api)
+			if err != nil {
+				return err
+			}
+			responses["429"] = resp
+		}
+	}
+	if action.Idempotent {
+		if err := addIdempotencyResponses(s, api, responses); err != nil {
+			return err
+		}
+	}
+	if action.Pagination != nil {
+		if resp, ok := responses["200"]; ok {
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]*Header)
+			}
+			if _, ok := resp.Headers["Link"]; !ok {
+				resp.Headers["Link"] = &Header{
+					Description: `Links to the next and previous pages as described in RFC 5988, e.g. </items?cursor=abc>; rel="next"`,
+					Type:        "string",
+				}
+			}
+		}
+	}
 
 	consumesMultipart := false
 	if action.Payload != nil {
@@ -995,7 +1156,9 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefinition) {
 	produces := make(map[string]struct{})
 	action.IterateResponses(func(resp *design.ResponseDefinition) error {
-		if resp.MediaType != "" {
+		if resp.Stream != nil {
+			produces["text/event-stream"] = struct{}{}
+		} else if resp.MediaType != "" {
 			produces[resp.MediaType] = struct{}{}
 		}
 		return nil
@@ -3,6 +3,7 @@ package genswagger_test
 import (
 	"bytes"
 	"encoding/json"
+	"time"
 
 	"github.com/go-openapi/loads"
 	_ "github.com/goadesign/goa-cellar/design"
@@ -281,6 +282,134 @@ var _ = Describe("New", func() {
 
 		})
 
+		Context("with an idempotent action", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							POST("/"),
+						)
+						Idempotent()
+						Response(Created)
+					})
+				})
+			})
+
+			It("documents the Idempotency-Key header and the error responses", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Post
+				Ω(op.Parameters).Should(HaveLen(1))
+				Ω(op.Parameters[0].Name).Should(Equal("Idempotency-Key"))
+				Ω(op.Parameters[0].In).Should(Equal("header"))
+				Ω(*op.Parameters[0].MaxLength).Should(Equal(255))
+				Ω(op.Responses).Should(HaveKey("409"))
+				Ω(op.Responses).Should(HaveKey("422"))
+				Ω(op.Responses["409"].Schema.Ref).Should(Equal("#/definitions/error"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a rate limited action", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						RateLimit(10, time.Second)
+						Response(OK)
+					})
+				})
+			})
+
+			It("documents the 429 response", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				resp := swagger.Paths["/"].(*genswagger.Path).Get.Responses["429"]
+				Ω(resp).ShouldNot(BeNil())
+				Ω(resp.Schema.Ref).Should(Equal("#/definitions/error"))
+				Ω(resp.Headers).Should(HaveKey("Retry-After"))
+				Ω(resp.Headers).Should(HaveKey("RateLimit-Remaining"))
+				Ω(resp.Headers["Retry-After"].Type).Should(Equal("integer"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a paginated action", func() {
+			BeforeEach(func() {
+				mt := MediaType("application/vnd.item", func() {
+					Attributes(func() {
+						Attribute("name", String)
+					})
+					View("default", func() {
+						Attribute("name")
+					})
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						Paginated(OffsetPagination)
+						Response(OK, CollectionOf(mt))
+					})
+				})
+			})
+
+			It("documents the pagination parameters and the Link header", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Get
+				var names []string
+				for _, p := range op.Parameters {
+					names = append(names, p.Name)
+				}
+				Ω(names).Should(ConsistOf("limit", "offset"))
+				resp := op.Responses["200"]
+				Ω(resp).ShouldNot(BeNil())
+				Ω(resp.Headers).Should(HaveKey("Link"))
+				Ω(resp.Headers["Link"].Type).Should(Equal("string"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a server-sent events action", func() {
+			BeforeEach(func() {
+				mt := MediaType("application/vnd.item", func() {
+					Attributes(func() {
+						Attribute("name", String)
+					})
+					View("default", func() {
+						Attribute("name")
+					})
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						Response(OK, func() {
+							Stream(mt)
+						})
+						Response(NotFound)
+					})
+				})
+			})
+
+			It("documents the text/event-stream response", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Get
+				Ω(op.Produces).Should(Equal([]string{"text/event-stream"}))
+				resp := op.Responses["200"]
+				Ω(resp).ShouldNot(BeNil())
+				Ω(resp.Schema.Ref).Should(Equal("#/definitions/Item"))
+				Ω(resp.Description).Should(ContainSubstring("server-sent events"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with multipart/form-data payload", func() {
 			BeforeEach(func() {
 				f := Type("MultipartPayload", func() {
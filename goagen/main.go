@@ -110,6 +110,14 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(swaggerCmd)
 
+	// openapiCmd implements the "openapi" command.
+	openapiCmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Generate OpenAPI 3.0",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genopenapi", c) },
+	}
+	rootCmd.AddCommand(openapiCmd)
+
 	// jsCmd implements the "js" command.
 	var (
 		timeout      = time.Duration(20) * time.Second
@@ -199,6 +207,65 @@ package and tool and the Swagger specification for the API.
 	controllerCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
 	rootCmd.AddCommand(controllerCmd)
 
+	// diffCmd implements the "diff" command.
+	var (
+		basePkg, from, to string
+		jsonOutput        bool
+	)
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Report the differences between two designs and flag breaking changes",
+		Long: `The diff command compares two design packages or two git revisions of a design package.
+
+It reports the resources, actions, routes, parameters, headers, payloads, response codes, media
+types, views, required attributes and validations that were added, removed or changed and
+classifies each change as breaking or not for existing clients. The command exits with a non
+zero status if at least one change is breaking.
+
+Examples:
+
+    goagen diff -d github.com/acme/api/design --base github.com/acme/api/v1/design
+    goagen diff -d github.com/acme/api/design --from origin/master --json
+`,
+		Run: func(c *cobra.Command, _ []string) { err = runDiff(designPkg, basePkg, from, to, jsonOutput, debug) },
+	}
+	diffCmd.Flags().StringVar(&basePkg, "base", "", "`import path` of the base design package, defaults to the design package")
+	diffCmd.Flags().StringVar(&from, "from", "", "git `revision` of the base design package, defaults to the working tree")
+	diffCmd.Flags().StringVar(&to, "to", "", "git `revision` of the design package, defaults to the working tree")
+	diffCmd.Flags().BoolVar(&jsonOutput, "json", false, "write the report in JSON instead of text")
+	rootCmd.AddCommand(diffCmd)
+
+	// lintCmd implements the "lint" command.
+	var disable string
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the design against API style rules",
+		Long: `The lint command checks the design against API style rules and reports each problem
+with the file and line of the DSL that created the offending definition. The command exits with a
+non zero status if at least one problem is found.
+
+The built-in rules are:
+
+    action-description     actions have a description
+    snake-case             attribute names are snake_case
+    paginated-collections  actions returning collections define pagination
+    error-media            error responses that have a body use ErrorMedia
+    delete-no-body         the success responses of DELETE actions have no body
+    default-view           media types define a default view
+    action-security        actions define security requirements when the API defines schemes
+
+Rules are disabled with the --disable flag or with the "lint:disable" metadata set on the API or
+on individual definitions. Design packages may register additional rules, see the genlint package.
+
+Example:
+
+    goagen lint -d github.com/acme/api/design --disable paginated-collections
+`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genlint", c) },
+	}
+	lintCmd.Flags().StringVar(&disable, "disable", "", "comma separated `list` of rules to disable")
+	rootCmd.AddCommand(lintCmd)
+
 	// cmdsCmd implements the commands command
 	// It lists all the commands and flags in JSON to enable shell integrations.
 	cmdsCmd := &cobra.Command{
@@ -81,8 +81,17 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 
 // Generate compiles and runs the generator and returns the generated filenames.
 func (m *Generator) Generate() ([]string, error) {
-	// Sanity checks
-	if os.Getenv("GOPATH") == "" {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sanity checks, GOPATH is not needed when running in a Go module.
+	mod, err := codegen.ModuleFor(wd)
+	if err != nil {
+		return nil, err
+	}
+	if mod == nil && os.Getenv("GOPATH") == "" {
 		return nil, fmt.Errorf("GOPATH not set")
 	}
 	if m.OutDir == "" {
@@ -97,11 +106,9 @@ func (m *Generator) Generate() ([]string, error) {
 		return nil, err
 	}
 
-	// Create temporary workspace used for generation
-	wd, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
+	// Create temporary workspace used for generation. The workspace lives
+	// in the current directory so that the generator gets compiled as part
+	// of the enclosing Go module if any.
 	tmpDir, err := ioutil.TempDir(wd, "goagen")
 	if err != nil {
 		if _, ok := err.(*os.PathError); ok {
@@ -28,6 +28,7 @@ var _ = Describe("Run", func() {
 		designPkgPath string
 		genPkgSource  string
 		customFlags   []string
+		moduleMode    string
 
 		m *meta.Generator
 	)
@@ -38,6 +39,10 @@ var _ = Describe("Run", func() {
 
 		var err error
 
+		// These specs exercise GOPATH mode
+		moduleMode = os.Getenv("GO111MODULE")
+		os.Setenv("GO111MODULE", "off")
+
 		outputWorkspace, err = codegen.NewWorkspace("output")
 		p, err := outputWorkspace.NewPackage("testOutput")
 		Ω(err).ShouldNot(HaveOccurred())
@@ -87,6 +92,7 @@ var _ = Describe("Run", func() {
 		designWorkspace.Delete()
 		outputWorkspace.Delete()
 		genWorkspace.Delete()
+		os.Setenv("GO111MODULE", moduleMode)
 	})
 
 	Context("with no GOPATH environment variable", func() {
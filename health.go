@@ -0,0 +1,238 @@
+package goa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// DefaultHealthTimeout is the default maximum duration of the checks run by the health endpoints.
+const DefaultHealthTimeout = 5 * time.Second
+
+// Health check statuses.
+const (
+	// HealthOK is the status of passing checks.
+	HealthOK = "ok"
+	// HealthFailed is the status of failing checks.
+	HealthFailed = "failed"
+	// HealthDraining is the readiness status of services being shut down.
+	HealthDraining = "draining"
+)
+
+type (
+	// Checker checks the health of a service dependency, it returns an error if the dependency
+	// is unhealthy.
+	Checker func(context.Context) error
+
+	// HealthOption configures the endpoints mounted by MountHealth.
+	HealthOption func(*health)
+
+	// HealthReport is the body of the health endpoint responses.
+	HealthReport struct {
+		// Status is HealthOK if all the checks passed, HealthFailed if one failed or
+		// HealthDraining if the service is shutting down.
+		Status string `json:"status"`
+		// Duration is the time it took to run all the checks.
+		Duration string `json:"duration"`
+		// Checks lists the result of each check indexed by name.
+		Checks map[string]*CheckResult `json:"checks,omitempty"`
+	}
+
+	// CheckResult is the result of a single health check.
+	CheckResult struct {
+		// Status is HealthOK or HealthFailed.
+		Status string `json:"status"`
+		// Error is the error returned by the check if any.
+		Error string `json:"error,omitempty"`
+		// Duration is the time it took to run the check.
+		Duration string `json:"duration"`
+	}
+
+	// health holds the configuration of the health endpoints.
+	health struct {
+		livenessPath  string
+		readinessPath string
+		routesPath    string
+		timeout       time.Duration
+		liveness      []*namedChecker
+		readiness     []*namedChecker
+	}
+
+	// namedChecker associates a checker with its name.
+	namedChecker struct {
+		name  string
+		check Checker
+	}
+)
+
+// LivenessCheck adds a check to the liveness endpoint. Liveness checks should only fail when the
+// process cannot recover and must be restarted.
+func LivenessCheck(name string, check Checker) HealthOption {
+	return func(h *health) {
+		h.liveness = append(h.liveness, &namedChecker{name: name, check: check})
+	}
+}
+
+// ReadinessCheck adds a check to the readiness endpoint. Readiness checks fail when the service
+// cannot handle requests, for example because a database is unreachable.
+func ReadinessCheck(name string, check Checker) HealthOption {
+	return func(h *health) {
+		h.readiness = append(h.readiness, &namedChecker{name: name, check: check})
+	}
+}
+
+// HealthPaths sets the request paths of the liveness and readiness endpoints, "/healthz" and
+// "/readyz" by default.
+func HealthPaths(liveness, readiness string) HealthOption {
+	return func(h *health) {
+		h.livenessPath, h.readinessPath = liveness, readiness
+	}
+}
+
+// HealthTimeout sets the maximum duration of the checks, checks that do not complete in time
+// fail. The default is DefaultHealthTimeout.
+func HealthTimeout(timeout time.Duration) HealthOption {
+	return func(h *health) {
+		h.timeout = timeout
+	}
+}
+
+// DebugRoutes mounts an endpoint at the given path that lists the routes of the service, see
+// Service.Routes.
+func DebugRoutes(path string) HealthOption {
+	return func(h *health) {
+		h.routesPath = path
+	}
+}
+
+// MountHealth mounts the liveness and readiness endpoints of the service. Both endpoints run their
+// checks concurrently and respond with a HealthReport encoded to JSON regardless of the service
+// encoders, the response status is 200 if all the
+// checks pass and 503 otherwise. The readiness endpoint also fails once Shutdown was called so
+// that load balancers stop sending requests during the drain. Example:
+//
+//	service.MountHealth(
+//		goa.ReadinessCheck("db", func(ctx context.Context) error {
+//			return db.PingContext(ctx)
+//		}),
+//		goa.DebugRoutes("/debug/routes"),
+//	)
+func (service *Service) MountHealth(opts ...HealthOption) {
+	h := &health{
+		livenessPath:  "/healthz",
+		readinessPath: "/readyz",
+		timeout:       DefaultHealthTimeout,
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	ctrl := service.NewController("Health")
+	service.Mount("GET", h.livenessPath, ctrl, "liveness", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return sendHealth(rw, h.run(ctx, h.liveness))
+	}, nil)
+	service.Mount("GET", h.readinessPath, ctrl, "readiness", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if service.Draining() {
+			return sendHealth(rw, &HealthReport{Status: HealthDraining, Duration: time.Duration(0).String()})
+		}
+		return sendHealth(rw, h.run(ctx, h.readiness))
+	}, nil)
+	if h.routesPath != "" {
+		service.Mount("GET", h.routesPath, ctrl, "routes", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			routes := service.Routes()
+			if routes == nil {
+				routes = []*Route{}
+			}
+			return sendJSON(rw, http.StatusOK, routes)
+		}, nil)
+	}
+}
+
+// Routes returns the routes registered with the service mux sorted by path and method. The
+// controller and action names are set for the routes registered with Service.Mount. Routes
+// returns nil if the service mux is not the default mux.
+func (service *Service) Routes() []*Route {
+	m, ok := service.Mux.(*mux)
+	if !ok {
+		return nil
+	}
+	routes := m.list()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
+}
+
+// sendHealth writes the given report with status 200 if it is successful, 503 otherwise.
+func sendHealth(rw http.ResponseWriter, report *HealthReport) error {
+	status := http.StatusOK
+	if report.Status != HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+	rw.Header().Set("Cache-Control", "no-cache")
+	return sendJSON(rw, status, report)
+}
+
+// sendJSON writes v encoded to JSON with the given status.
+func sendJSON(rw http.ResponseWriter, status int, v interface{}) error {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	return json.NewEncoder(rw).Encode(v)
+}
+
+// run runs the given checks concurrently and returns the report.
+func (h *health) run(ctx context.Context, checks []*namedChecker) *HealthReport {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		res  *CheckResult
+	}
+	results := make(chan *result, len(checks))
+	for _, c := range checks {
+		go func(c *namedChecker) {
+			start := time.Now()
+			res := &CheckResult{Status: HealthOK}
+			if err := c.check(ctx); err != nil {
+				res.Status = HealthFailed
+				res.Error = err.Error()
+			}
+			res.Duration = time.Since(start).String()
+			results <- &result{name: c.name, res: res}
+		}(c)
+	}
+
+	report := &HealthReport{Status: HealthOK, Checks: make(map[string]*CheckResult, len(checks))}
+	for range checks {
+		select {
+		case r := <-results:
+			report.Checks[r.name] = r.res
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	for _, c := range checks {
+		if _, ok := report.Checks[c.name]; !ok {
+			report.Checks[c.name] = &CheckResult{
+				Status:   HealthFailed,
+				Error:    ctx.Err().Error(),
+				Duration: time.Since(start).String(),
+			}
+		}
+	}
+	for _, res := range report.Checks {
+		if res.Status != HealthOK {
+			report.Status = HealthFailed
+		}
+	}
+	report.Duration = time.Since(start).String()
+	return report
+}
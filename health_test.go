@@ -0,0 +1,168 @@
+package goa_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MountHealth", func() {
+	var service *goa.Service
+	var opts []goa.HealthOption
+	var path string
+
+	var rw *httptest.ResponseRecorder
+
+	ok := func(context.Context) error { return nil }
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		opts = nil
+		path = "/healthz"
+	})
+
+	JustBeforeEach(func() {
+		service.MountHealth(opts...)
+		req, _ := http.NewRequest("GET", path, nil)
+		rw = httptest.NewRecorder()
+		service.Mux.ServeHTTP(rw, req)
+	})
+
+	report := func() *goa.HealthReport {
+		var r goa.HealthReport
+		Ω(json.Unmarshal(rw.Body.Bytes(), &r)).ShouldNot(HaveOccurred())
+		return &r
+	}
+
+	Context("with no check", func() {
+		It("reports a healthy service", func() {
+			Ω(rw.Code).Should(Equal(200))
+			r := report()
+			Ω(r.Status).Should(Equal(goa.HealthOK))
+			Ω(r.Duration).ShouldNot(BeEmpty())
+			Ω(r.Checks).Should(BeEmpty())
+		})
+	})
+
+	Context("with liveness and readiness checks", func() {
+		BeforeEach(func() {
+			opts = []goa.HealthOption{
+				goa.LivenessCheck("live", ok),
+				goa.ReadinessCheck("db", ok),
+				goa.ReadinessCheck("cache", func(context.Context) error { return errors.New("unreachable") }),
+			}
+		})
+
+		It("runs the liveness checks", func() {
+			Ω(rw.Code).Should(Equal(200))
+			r := report()
+			Ω(r.Checks).Should(HaveLen(1))
+			Ω(r.Checks).Should(HaveKey("live"))
+			Ω(r.Checks["live"].Status).Should(Equal(goa.HealthOK))
+			Ω(r.Checks["live"].Duration).ShouldNot(BeEmpty())
+		})
+
+		Context("requesting readiness", func() {
+			BeforeEach(func() {
+				path = "/readyz"
+			})
+
+			It("runs the readiness checks", func() {
+				Ω(rw.Code).Should(Equal(503))
+				r := report()
+				Ω(r.Status).Should(Equal(goa.HealthFailed))
+				Ω(r.Checks).Should(HaveLen(2))
+				Ω(r.Checks["db"].Status).Should(Equal(goa.HealthOK))
+				Ω(r.Checks["cache"].Status).Should(Equal(goa.HealthFailed))
+				Ω(r.Checks["cache"].Error).Should(Equal("unreachable"))
+			})
+		})
+	})
+
+	Context("with a slow check", func() {
+		BeforeEach(func() {
+			opts = []goa.HealthOption{
+				goa.HealthTimeout(10 * time.Millisecond),
+				goa.LivenessCheck("slow", func(context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				}),
+			}
+		})
+
+		It("fails the check once the timeout expires", func() {
+			Ω(rw.Code).Should(Equal(503))
+			r := report()
+			Ω(r.Checks["slow"].Status).Should(Equal(goa.HealthFailed))
+			Ω(r.Checks["slow"].Error).Should(Equal(context.DeadlineExceeded.Error()))
+		})
+	})
+
+	Context("with custom paths", func() {
+		BeforeEach(func() {
+			opts = []goa.HealthOption{goa.HealthPaths("/live", "/ready")}
+			path = "/ready"
+		})
+
+		It("mounts the endpoints on the paths", func() {
+			Ω(rw.Code).Should(Equal(200))
+		})
+	})
+
+	Context("with a draining service", func() {
+		BeforeEach(func() {
+			opts = []goa.HealthOption{goa.ReadinessCheck("db", ok)}
+			path = "/readyz"
+			Ω(service.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+		})
+
+		It("fails readiness without running the checks", func() {
+			Ω(rw.Code).Should(Equal(503))
+			r := report()
+			Ω(r.Status).Should(Equal(goa.HealthDraining))
+			Ω(r.Checks).Should(BeEmpty())
+		})
+	})
+
+	Context("with debug routes", func() {
+		BeforeEach(func() {
+			opts = []goa.HealthOption{goa.DebugRoutes("/debug/routes")}
+			path = "/debug/routes"
+			ctrl := service.NewController("Bottle")
+			service.Mount("GET", "/bottles/:id", &bottleController{ctrl}, "show", showBottle, nil)
+			service.Mux.Handle("POST", "/bottles", ctrl.MuxHandler("create", showBottle, nil))
+			service.Mux.Handle("PUT", "/bottles/:id", func(http.ResponseWriter, *http.Request, url.Values) {})
+		})
+
+		It("lists the routes", func() {
+			Ω(rw.Code).Should(Equal(200))
+			var routes []*goa.Route
+			Ω(json.Unmarshal(rw.Body.Bytes(), &routes)).ShouldNot(HaveOccurred())
+			Ω(routes).Should(Equal([]*goa.Route{
+				{Method: "POST", Path: "/bottles"},
+				{Method: "GET", Path: "/bottles/:id", Controller: "Bottle", Action: "show"},
+				{Method: "PUT", Path: "/bottles/:id"},
+				{Method: "GET", Path: "/debug/routes", Controller: "Health", Action: "routes"},
+				{Method: "GET", Path: "/healthz", Controller: "Health", Action: "liveness"},
+				{Method: "GET", Path: "/readyz", Controller: "Health", Action: "readiness"},
+			}))
+		})
+	})
+})
+
+// bottleController is a user controller that embeds the goa controller.
+type bottleController struct {
+	*goa.Controller
+}
+
+func showBottle(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	return nil
+}
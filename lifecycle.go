@@ -0,0 +1,169 @@
+package goa
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout is the default maximum duration of a graceful shutdown triggered by a
+// signal.
+const DefaultShutdownTimeout = 30 * time.Second
+
+type (
+	// Hook is a function run when the service starts or shuts down.
+	Hook func(context.Context) error
+
+	// lifecycle holds the state used to start and shut down services.
+	lifecycle struct {
+		lock       sync.Mutex
+		onStart    []Hook
+		onShutdown []Hook
+		started    bool
+		startErr   error
+		draining   int32
+		drain      chan struct{}
+		once       sync.Once
+		done       chan struct{}
+		err        error
+	}
+)
+
+// OnStart registers a hook that is run before the service starts accepting requests. Hooks run
+// in the order they were registered, the service does not start if a hook returns an error.
+func (service *Service) OnStart(hook Hook) {
+	service.lifecycle.lock.Lock()
+	defer service.lifecycle.lock.Unlock()
+	service.lifecycle.onStart = append(service.lifecycle.onStart, hook)
+}
+
+// OnShutdown registers a hook that is run by Shutdown once the in-flight requests completed, for
+// example to flush metrics or close database connections. Hooks run in the reverse order of their
+// registration.
+func (service *Service) OnShutdown(hook Hook) {
+	service.lifecycle.lock.Lock()
+	defer service.lifecycle.lock.Unlock()
+	service.lifecycle.onShutdown = append(service.lifecycle.onShutdown, hook)
+}
+
+// Draining returns true once Shutdown was called, readiness checks should fail from then on so
+// that load balancers stop sending requests to the service.
+func (service *Service) Draining() bool {
+	return atomic.LoadInt32(&service.lifecycle.draining) == 1
+}
+
+// Shutdown gracefully shuts down the service: it makes Draining return true, closes the event
+// streams, waits for the service DrainDelay, stops the listeners, waits for the in-flight
+// requests to complete, cancels the service context and runs the OnShutdown hooks. The context
+// bounds the time spent waiting for the requests, the service context is canceled and the hooks
+// run even if it expires. Shutdown returns the context error or the first error returned by a
+// hook. Calling Shutdown again waits for the first call to complete and returns its result.
+func (service *Service) Shutdown(ctx context.Context) error {
+	l := &service.lifecycle
+	l.once.Do(func() {
+		l.lock.Lock()
+		l.done = make(chan struct{})
+		if l.drain == nil {
+			l.drain = make(chan struct{})
+		}
+		hooks := l.onShutdown
+		l.lock.Unlock()
+		defer close(l.done)
+
+		atomic.StoreInt32(&l.draining, 1)
+		close(l.drain)
+		service.LogInfo("shutdown", "status", "draining")
+		if service.DrainDelay > 0 {
+			t := time.NewTimer(service.DrainDelay)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+			}
+		}
+		l.err = service.Server.Shutdown(ctx)
+		service.CancelAll()
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := hooks[i](ctx); err != nil {
+				service.LogError("shutdown hook", "err", err)
+				if l.err == nil {
+					l.err = err
+				}
+			}
+		}
+		service.LogInfo("shutdown", "status", "done")
+	})
+	<-l.done
+	return l.err
+}
+
+// drained returns a channel that is closed when Shutdown is called. Long-lived requests such as
+// event streams use it to complete so that Shutdown does not wait for them.
+func (service *Service) drained() <-chan struct{} {
+	l := &service.lifecycle
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.drain == nil {
+		l.drain = make(chan struct{})
+	}
+	return l.drain
+}
+
+// ShutdownOnSignal calls Shutdown when the process receives one of the given signals, SIGINT and
+// SIGTERM by default. timeout is the maximum duration of the shutdown.
+func (service *Service) ShutdownOnSignal(timeout time.Duration, signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, signals...)
+	go func() {
+		sig := <-c
+		signal.Stop(c)
+		service.LogInfo("shutdown", "signal", sig.String())
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		service.Shutdown(ctx)
+	}()
+}
+
+// start runs the OnStart hooks once.
+func (service *Service) start() error {
+	l := &service.lifecycle
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.started {
+		return l.startErr
+	}
+	l.started = true
+	for _, hook := range l.onStart {
+		if err := hook(service.Context); err != nil {
+			l.startErr = err
+			return err
+		}
+	}
+	return nil
+}
+
+// serve runs the OnStart hooks then calls run. It waits for Shutdown to complete when run returns
+// because of it and returns the result of Shutdown.
+func (service *Service) serve(run func() error) error {
+	if err := service.start(); err != nil {
+		return err
+	}
+	err := run()
+	if err != http.ErrServerClosed || !service.Draining() {
+		return err
+	}
+	l := &service.lifecycle
+	l.lock.Lock()
+	done := l.done
+	l.lock.Unlock()
+	<-done
+	return l.err
+}
@@ -0,0 +1,191 @@
+package goa_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Service lifecycle", func() {
+	var service *goa.Service
+	var listener net.Listener
+	var lock sync.Mutex
+	var calls []string
+	var served chan error
+
+	hook := func(name string, err error) goa.Hook {
+		return func(context.Context) error {
+			lock.Lock()
+			defer lock.Unlock()
+			calls = append(calls, name)
+			return err
+		}
+	}
+	called := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return calls
+	}
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		calls = nil
+		served = make(chan error, 1)
+	})
+
+	JustBeforeEach(func() {
+		go func() { served <- service.Serve(listener) }()
+	})
+
+	AfterEach(func() {
+		listener.Close()
+	})
+
+	Context("with start hooks", func() {
+		BeforeEach(func() {
+			service.OnStart(hook("first", nil))
+			service.OnStart(hook("second", nil))
+		})
+
+		It("runs the hooks in order before serving", func() {
+			Eventually(called).Should(Equal([]string{"first", "second"}))
+			Ω(service.Shutdown(context.Background())).ShouldNot(HaveOccurred())
+			Ω(<-served).ShouldNot(HaveOccurred())
+		})
+
+		Context("returning an error", func() {
+			BeforeEach(func() {
+				service.OnStart(hook("failed", errors.New("boom")))
+				service.OnStart(hook("third", nil))
+			})
+
+			It("does not serve", func() {
+				Eventually(served).Should(Receive(MatchError("boom")))
+				Ω(called()).Should(Equal([]string{"first", "second", "failed"}))
+			})
+		})
+	})
+
+	Context("with an in-flight request", func() {
+		var started, release chan struct{}
+		var canceled chan bool
+
+		BeforeEach(func() {
+			started, release = make(chan struct{}), make(chan struct{})
+			canceled = make(chan bool, 1)
+			ctrl := service.NewController("test")
+			service.Mux.Handle("GET", "/slow", ctrl.MuxHandler("slow", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				close(started)
+				<-release
+				canceled <- service.Context.Err() != nil
+				rw.WriteHeader(204)
+				return nil
+			}, nil))
+			service.OnShutdown(hook("first", nil))
+			service.OnShutdown(hook("second", errors.New("boom")))
+		})
+
+		It("drains the request before canceling the service context and running the hooks", func() {
+			resp := make(chan int, 1)
+			go func() {
+				r, err := http.Get("http://" + listener.Addr().String() + "/slow")
+				if err == nil {
+					resp <- r.StatusCode
+					r.Body.Close()
+				}
+			}()
+			<-started
+			shutdown := make(chan error, 1)
+			go func() { shutdown <- service.Shutdown(context.Background()) }()
+			Eventually(service.Draining).Should(BeTrue())
+			Consistently(shutdown, 20*time.Millisecond).ShouldNot(Receive())
+			close(release)
+			Eventually(resp).Should(Receive(Equal(204)))
+			Ω(<-canceled).Should(BeFalse())
+			Eventually(shutdown).Should(Receive(MatchError("boom")))
+			Ω(service.Context.Err()).Should(HaveOccurred())
+			Ω(called()).Should(Equal([]string{"second", "first"}))
+			Ω(<-served).Should(MatchError("boom"))
+			Ω(service.Shutdown(context.Background())).Should(MatchError("boom"))
+		})
+	})
+
+	Context("with a drain delay", func() {
+		const delay = 100 * time.Millisecond
+
+		BeforeEach(func() {
+			service.DrainDelay = delay
+			ctrl := service.NewController("test")
+			service.Mux.Handle("GET", "/ping", ctrl.MuxHandler("ping", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				rw.WriteHeader(204)
+				return nil
+			}, nil))
+		})
+
+		It("keeps accepting requests while draining until the delay elapses", func() {
+			url := "http://" + listener.Addr().String() + "/ping"
+			get := func() (int, error) {
+				r, err := http.Get(url)
+				if err != nil {
+					return 0, err
+				}
+				r.Body.Close()
+				return r.StatusCode, nil
+			}
+			Eventually(get).Should(Equal(204))
+			start := time.Now()
+			shutdown := make(chan error, 1)
+			go func() { shutdown <- service.Shutdown(context.Background()) }()
+			Eventually(service.Draining).Should(BeTrue())
+			Ω(get()).Should(Equal(204))
+			Eventually(shutdown, time.Second).Should(Receive(BeNil()))
+			Ω(time.Since(start)).Should(BeNumerically(">=", delay))
+			Ω(<-served).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with an open event stream", func() {
+		var opened chan *goa.EventStream
+
+		BeforeEach(func() {
+			opened = make(chan *goa.EventStream, 1)
+			ctrl := service.NewController("test")
+			service.Mux.Handle("GET", "/events", ctrl.MuxHandler("events", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				stream := goa.NewEventStream(ctx, 200, goa.DefaultHeartbeat)
+				opened <- stream
+				<-stream.Done()
+				return nil
+			}, nil))
+		})
+
+		It("closes the stream so that the shutdown does not wait for the deadline", func() {
+			go func() {
+				r, err := http.Get("http://" + listener.Addr().String() + "/events")
+				if err == nil {
+					ioutil.ReadAll(r.Body)
+					r.Body.Close()
+				}
+			}()
+			var stream *goa.EventStream
+			Eventually(opened).Should(Receive(&stream))
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			start := time.Now()
+			Ω(service.Shutdown(ctx)).ShouldNot(HaveOccurred())
+			Ω(time.Since(start)).Should(BeNumerically("<", time.Second))
+			Ω(stream.Err()).Should(Equal(goa.ErrStreamClosed))
+			Ω(<-served).ShouldNot(HaveOccurred())
+		})
+	})
+})
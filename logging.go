@@ -25,6 +25,17 @@ type (
 		New(keyvals ...interface{}) LogAdapter
 	}
 
+	// LeveledLogAdapter is implemented by the log adapters that also support the debug and
+	// warning levels. LogDebug and LogWarn make use of these levels when the logger stored
+	// in the context implements this interface.
+	LeveledLogAdapter interface {
+		LogAdapter
+		// Debug logs a debug message.
+		Debug(msg string, keyvals ...interface{})
+		// Warn logs a warning.
+		Warn(msg string, keyvals ...interface{})
+	}
+
 	// adapter is the stdlib logger adapter.
 	adapter struct {
 		*log.Logger
@@ -121,3 +132,46 @@ func LogError(ctx context.Context, msg string, keyvals ...interface{}) {
 		}
 	}
 }
+
+// LogDebug extracts the logger from the given context and calls Debug on it if the logger
+// implements LeveledLogAdapter. Debug messages are discarded otherwise.
+// This is intended for code that needs portable logging such as the internal code of goa and
+// middleware. User code should use the log adapters instead.
+func LogDebug(ctx context.Context, msg string, keyvals ...interface{}) {
+	if l := ctx.Value(logKey); l != nil {
+		if logger, ok := l.(LeveledLogAdapter); ok {
+			logger.Debug(msg, keyvals...)
+		}
+	}
+}
+
+// LogWarn extracts the logger from the given context and calls Warn on it if the logger
+// implements LeveledLogAdapter, Info otherwise.
+// This is intended for code that needs portable logging such as the internal code of goa and
+// middleware. User code should use the log adapters instead.
+func LogWarn(ctx context.Context, msg string, keyvals ...interface{}) {
+	if l := ctx.Value(logKey); l != nil {
+		switch logger := l.(type) {
+		case LeveledLogAdapter:
+			logger.Warn(msg, keyvals...)
+		case LogAdapter:
+			logger.Info(msg, keyvals...)
+		}
+	}
+}
+
+// DebugEnabled returns true if the logger stored in the given context implements
+// LeveledLogAdapter and logs debug messages. Code that builds costly debug messages can use it
+// to avoid doing so when they would be discarded. Adapters whose backend has a level check
+// implement a DebugEnabled() bool method that DebugEnabled calls, debug messages are assumed
+// to be logged otherwise.
+func DebugEnabled(ctx context.Context) bool {
+	logger, ok := ctx.Value(logKey).(LeveledLogAdapter)
+	if !ok {
+		return false
+	}
+	if l, ok := logger.(interface{ DebugEnabled() bool }); ok {
+		return l.DebugEnabled()
+	}
+	return true
+}
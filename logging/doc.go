@@ -1,7 +1,7 @@
 /*
 Package logging contains logger adapters that make it possible for goa to log messages to various
 logger backends. Each adapter exists in its own sub-package named after the corresponding logger
-package.
+package. Adapters are provided for go-kit log, log15, logrus, zap and log/slog (Go 1.21 and above).
 
 Once instantiated adapters can be used by setting the goa service logger with WithLogger:
 
@@ -20,6 +20,11 @@ Once instantiated adapters can be used by setting the goa service logger with Wi
 }
 ```
 
+The adapters also implement goa.LeveledLogAdapter which adds the debug and warning levels to
+goa.LogAdapter. Middleware such as LogRequest log verbose details at the debug level when the
+service logger implements that interface so that the backend configuration decides whether they
+get written.
+
 See http://goa.design/implement/logging/ for details.
 */
 package logging
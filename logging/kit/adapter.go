@@ -47,6 +47,20 @@ func (a *adapter) Info(msg string, data ...interface{}) {
 	a.Logger.Log(ctx...)
 }
 
+// Debug logs debug messages using go-kit.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	ctx := []interface{}{"lvl", "debug", "msg", msg}
+	ctx = append(ctx, data...)
+	a.Logger.Log(ctx...)
+}
+
+// Warn logs warning messages using go-kit.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	ctx := []interface{}{"lvl", "warn", "msg", msg}
+	ctx = append(ctx, data...)
+	a.Logger.Log(ctx...)
+}
+
 // Error logs error messages using go-kit.
 func (a *adapter) Error(msg string, data ...interface{}) {
 	ctx := []interface{}{"lvl", "error", "msg", msg}
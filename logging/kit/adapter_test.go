@@ -25,4 +25,10 @@ var _ = Describe("New", func() {
 		adapter.Info(msg)
 		Ω(buf.String()).Should(Equal("lvl=info msg=" + msg + "\n"))
 	})
+
+	It("supports the debug level", func() {
+		buf.Reset()
+		adapter.(goa.LeveledLogAdapter).Debug("msg", "key", "val")
+		Ω(buf.String()).Should(Equal("lvl=debug msg=msg key=val\n"))
+	})
 })
@@ -44,6 +44,16 @@ func (a *adapter) Info(msg string, data ...interface{}) {
 	a.Logger.Info(msg, data...)
 }
 
+// Debug logs debug messages using log15.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Logger.Debug(msg, data...)
+}
+
+// Warn logs warning messages using log15.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Logger.Warn(msg, data...)
+}
+
 // Error logs error messages using log15.
 func (a *adapter) Error(msg string, data ...interface{}) {
 	a.Logger.Error(msg, data...)
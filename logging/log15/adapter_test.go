@@ -38,6 +38,12 @@ var _ = Describe("New", func() {
 		Ω(handler.records[0].Msg).Should(ContainSubstring(msg))
 	})
 
+	It("supports the warning level", func() {
+		adapter.(goa.LeveledLogAdapter).Warn("msg")
+		Ω(handler.records).Should(HaveLen(1))
+		Ω(handler.records[0].Lvl).Should(Equal(log15.LvlWarn))
+	})
+
 	Context("Logger", func() {
 		var ctx context.Context
 
@@ -51,6 +51,21 @@ func (a *adapter) Info(msg string, data ...interface{}) {
 	a.Entry.WithFields(data2rus(data)).Info(msg)
 }
 
+// Debug logs debug messages using logrus.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Entry.WithFields(data2rus(data)).Debug(msg)
+}
+
+// DebugEnabled returns true if the logrus logger logs debug messages, see goa.DebugEnabled.
+func (a *adapter) DebugEnabled() bool {
+	return a.Entry.Logger.IsLevelEnabled(logrus.DebugLevel)
+}
+
+// Warn logs warnings using logrus.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Entry.WithFields(data2rus(data)).Warn(msg)
+}
+
 // Error logs errors using logrus.
 func (a *adapter) Error(msg string, data ...interface{}) {
 	a.Entry.WithFields(data2rus(data)).Error(msg)
@@ -28,6 +28,13 @@ var _ = Describe("goalogrus", func() {
 		adapter.Info(msg)
 		Ω(buf.String()).Should(ContainSubstring(msg))
 	})
+
+	It("reports whether debug messages are enabled", func() {
+		ctx := goa.WithLogger(context.Background(), adapter)
+		Ω(goa.DebugEnabled(ctx)).Should(BeFalse())
+		logger.SetLevel(logrus.DebugLevel)
+		Ω(goa.DebugEnabled(ctx)).Should(BeTrue())
+	})
 })
 
 var _ = Describe("FromEntry", func() {
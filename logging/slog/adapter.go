@@ -0,0 +1,99 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+Package goaslog contains an adapter that makes it possible to configure goa so it uses the
+standard library log/slog package as logger backend.
+Usage:
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	// Initialize goa service logger using adapter
+	service.WithLogger(goaslog.New(logger))
+	// ... Proceed with configuring and starting the goa service
+
+	// In handlers:
+	goaslog.Logger(ctx).Info("foo", "bar", "baz")
+*/
+package goaslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/goadesign/goa"
+)
+
+// adapter is the log/slog goa logger adapter.
+type adapter struct {
+	*slog.Logger
+}
+
+// New wraps a slog logger into a goa logger adapter. The returned adapter implements
+// goa.LeveledLogAdapter.
+func New(logger *slog.Logger) goa.LogAdapter {
+	return &adapter{Logger: logger}
+}
+
+// Logger returns the slog logger stored in the given context if any, nil otherwise.
+func Logger(ctx context.Context) *slog.Logger {
+	logger := goa.ContextLogger(ctx)
+	if a, ok := logger.(*adapter); ok {
+		return a.Logger
+	}
+	return nil
+}
+
+// Debug logs debug messages using slog.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.log(slog.LevelDebug, msg, data)
+}
+
+// DebugEnabled returns true if the slog handler logs debug messages, see goa.DebugEnabled.
+func (a *adapter) DebugEnabled() bool {
+	return a.Logger.Handler().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// Info logs informational messages using slog.
+func (a *adapter) Info(msg string, data ...interface{}) {
+	a.log(slog.LevelInfo, msg, data)
+}
+
+// Warn logs warning messages using slog.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.log(slog.LevelWarn, msg, data)
+}
+
+// Error logs error messages using slog.
+func (a *adapter) Error(msg string, data ...interface{}) {
+	a.log(slog.LevelError, msg, data)
+}
+
+// New creates a new logger whose handler records the given key/value pairs as attributes.
+func (a *adapter) New(data ...interface{}) goa.LogAdapter {
+	if len(data) == 0 {
+		return a
+	}
+	return &adapter{Logger: slog.New(a.Logger.Handler().WithAttrs(attrs(data)))}
+}
+
+func (a *adapter) log(level slog.Level, msg string, data []interface{}) {
+	ctx := context.Background()
+	if !a.Logger.Enabled(ctx, level) {
+		return
+	}
+	a.Logger.LogAttrs(ctx, level, msg, attrs(data)...)
+}
+
+// attrs converts the given key/value pairs into slog attributes.
+func attrs(keyvals []interface{}) []slog.Attr {
+	res := make([]slog.Attr, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = goa.ErrMissingLogValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		res = append(res, slog.Any(fmt.Sprintf("%v", keyvals[i]), v))
+	}
+	return res
+}
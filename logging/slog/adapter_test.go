@@ -0,0 +1,80 @@
+//go:build go1.21
+// +build go1.21
+
+package goaslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/logging/slog"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var buf bytes.Buffer
+	var logger *slog.Logger
+	var adapter goa.LogAdapter
+
+	entry := func() map[string]interface{} {
+		var e map[string]interface{}
+		Ω(json.Unmarshal(buf.Bytes(), &e)).ShouldNot(HaveOccurred())
+		return e
+	}
+
+	BeforeEach(func() {
+		buf.Reset()
+		logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		adapter = goaslog.New(logger)
+	})
+
+	It("creates an adapter that logs", func() {
+		adapter.Info("msg", "key", "val", 1, 2, "missing")
+		e := entry()
+		Ω(e["level"]).Should(Equal("INFO"))
+		Ω(e["msg"]).Should(Equal("msg"))
+		Ω(e["key"]).Should(Equal("val"))
+		Ω(e["1"]).Should(BeEquivalentTo(2))
+		Ω(e["missing"]).Should(Equal(goa.ErrMissingLogValue))
+	})
+
+	It("honors the handler level", func() {
+		adapter.(goa.LeveledLogAdapter).Debug("msg")
+		Ω(buf.Len()).Should(BeZero())
+		adapter.(goa.LeveledLogAdapter).Warn("msg")
+		Ω(entry()["level"]).Should(Equal("WARN"))
+	})
+
+	It("reports whether debug messages are enabled", func() {
+		ctx := goa.WithLogger(context.Background(), adapter)
+		Ω(goa.DebugEnabled(ctx)).Should(BeFalse())
+		logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		ctx = goa.WithLogger(context.Background(), goaslog.New(logger))
+		Ω(goa.DebugEnabled(ctx)).Should(BeTrue())
+	})
+
+	Context("with a log context", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = goa.WithLogger(context.Background(), adapter)
+			ctx = goa.WithLogContext(ctx, "req_id", "foo")
+		})
+
+		It("logs the context as attributes", func() {
+			goa.LogError(ctx, "msg", "status", 500)
+			e := entry()
+			Ω(e["req_id"]).Should(Equal("foo"))
+			Ω(e["status"]).Should(BeEquivalentTo(500))
+		})
+
+		It("extracts the logger", func() {
+			Ω(goaslog.Logger(ctx)).ShouldNot(BeNil())
+			Ω(goaslog.Logger(context.Background())).Should(BeNil())
+		})
+	})
+})
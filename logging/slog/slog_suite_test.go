@@ -0,0 +1,16 @@
+//go:build go1.21
+// +build go1.21
+
+package goaslog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSlog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Slog Suite")
+}
@@ -0,0 +1,72 @@
+package goazap_test
+
+import (
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/logging/zap"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("New", func() {
+	var logger *zap.Logger
+	var logs *observer.ObservedLogs
+	var adapter goa.LogAdapter
+
+	BeforeEach(func() {
+		var core zapcore.Core
+		core, logs = observer.New(zap.InfoLevel)
+		logger = zap.New(core)
+		adapter = goazap.New(logger)
+	})
+
+	It("creates an adapter that logs", func() {
+		adapter.Info("msg", "key", "val", "missing")
+		Ω(logs.Len()).Should(Equal(1))
+		entry := logs.All()[0]
+		Ω(entry.Message).Should(Equal("msg"))
+		Ω(entry.Level).Should(Equal(zap.InfoLevel))
+		Ω(entry.ContextMap()).Should(Equal(map[string]interface{}{
+			"key":     "val",
+			"missing": goa.ErrMissingLogValue,
+		}))
+	})
+
+	It("honors the core level", func() {
+		adapter.(goa.LeveledLogAdapter).Debug("msg")
+		Ω(logs.Len()).Should(BeZero())
+		adapter.(goa.LeveledLogAdapter).Warn("msg")
+		Ω(logs.All()[0].Level).Should(Equal(zap.WarnLevel))
+	})
+
+	It("reports whether debug messages are enabled", func() {
+		ctx := goa.WithLogger(context.Background(), adapter)
+		Ω(goa.DebugEnabled(ctx)).Should(BeFalse())
+		core, _ := observer.New(zap.DebugLevel)
+		ctx = goa.WithLogger(context.Background(), goazap.New(zap.New(core)))
+		Ω(goa.DebugEnabled(ctx)).Should(BeTrue())
+	})
+
+	Context("with a log context", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = goa.WithLogger(context.Background(), adapter)
+			ctx = goa.WithLogContext(ctx, "req_id", "foo")
+		})
+
+		It("logs the context as fields", func() {
+			goa.LogError(ctx, "msg")
+			Ω(logs.All()[0].ContextMap()).Should(HaveKeyWithValue("req_id", "foo"))
+		})
+
+		It("extracts the logger", func() {
+			Ω(goazap.Logger(ctx)).ShouldNot(BeNil())
+			Ω(goazap.Logger(context.Background())).Should(BeNil())
+		})
+	})
+})
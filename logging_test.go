@@ -27,6 +27,63 @@ var _ = Describe("Error", func() {
 	})
 })
 
+var _ = Describe("LogDebug", func() {
+	var out bytes.Buffer
+
+	BeforeEach(func() {
+		out.Reset()
+	})
+
+	It("discards messages if the logger does not support levels", func() {
+		ctx := goa.WithLogger(context.Background(), goa.NewLogger(log.New(&out, "", 0)))
+		goa.LogDebug(ctx, "foo", "bar", "baz")
+		Ω(goa.DebugEnabled(ctx)).Should(BeFalse())
+		Ω(out.String()).Should(BeEmpty())
+	})
+
+	It("doesn't log and doesn't crash with a nil Log", func() {
+		Ω(func() { goa.LogDebug(context.Background(), "foo", "bar") }).ShouldNot(Panic())
+	})
+})
+
+var _ = Describe("DebugEnabled", func() {
+	It("returns false if the logger does not support levels", func() {
+		ctx := goa.WithLogger(context.Background(), goa.NewLogger(log.New(&bytes.Buffer{}, "", 0)))
+		Ω(goa.DebugEnabled(ctx)).Should(BeFalse())
+		Ω(goa.DebugEnabled(context.Background())).Should(BeFalse())
+	})
+
+	It("returns true if the leveled logger has no level check", func() {
+		ctx := goa.WithLogger(context.Background(), &leveledLogger{})
+		Ω(goa.DebugEnabled(ctx)).Should(BeTrue())
+	})
+
+	It("asks the logger whether debug messages are enabled", func() {
+		ctx := goa.WithLogger(context.Background(), &checkedLogger{})
+		Ω(goa.DebugEnabled(ctx)).Should(BeFalse())
+		ctx = goa.WithLogger(context.Background(), &checkedLogger{debug: true})
+		Ω(goa.DebugEnabled(ctx)).Should(BeTrue())
+	})
+})
+
+var _ = Describe("LogWarn", func() {
+	var out bytes.Buffer
+
+	BeforeEach(func() {
+		out.Reset()
+	})
+
+	It("falls back to Info if the logger does not support levels", func() {
+		ctx := goa.WithLogger(context.Background(), goa.NewLogger(log.New(&out, "", 0)))
+		goa.LogWarn(ctx, "foo", "bar", "baz")
+		Ω(out.String()).Should(Equal("[INFO] foo bar=baz\n"))
+	})
+
+	It("doesn't log and doesn't crash with a nil Log", func() {
+		Ω(func() { goa.LogWarn(context.Background(), "foo", "bar") }).ShouldNot(Panic())
+	})
+})
+
 var _ = Describe("LogAdapter", func() {
 	Context("with a valid Log", func() {
 		var logger goa.LogAdapter
@@ -51,3 +108,20 @@ var _ = Describe("LogAdapter", func() {
 		})
 	})
 })
+
+// leveledLogger is a LeveledLogAdapter that discards all messages.
+type leveledLogger struct{}
+
+func (l *leveledLogger) Info(msg string, keyvals ...interface{})   {}
+func (l *leveledLogger) Error(msg string, keyvals ...interface{})  {}
+func (l *leveledLogger) Debug(msg string, keyvals ...interface{})  {}
+func (l *leveledLogger) Warn(msg string, keyvals ...interface{})   {}
+func (l *leveledLogger) New(keyvals ...interface{}) goa.LogAdapter { return l }
+
+// checkedLogger is a leveledLogger that reports whether debug messages are enabled.
+type checkedLogger struct {
+	leveledLogger
+	debug bool
+}
+
+func (l *checkedLogger) DebugEnabled() bool { return l.debug }
@@ -0,0 +1,25 @@
+/*
+Package metrics contains collectors that make it possible for goa to report its instrumentation to
+various metrics backends. Each collector exists in its own sub-package named after the corresponding
+backend.
+
+Once instantiated collectors can be used by setting the goa metrics collector with SetMetrics:
+
+```go
+  func main() {
+    // ...
+
+    // Setup metrics collector
+    collector := goaprometheus.New()
+    goa.SetMetrics(collector)
+
+    // Create service
+    service := goa.New("my service")
+    service.Use(collector.Middleware())
+    collector.Mount(service.Mux, "/metrics")
+
+    // ...
+}
+```
+*/
+package metrics
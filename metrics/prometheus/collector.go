@@ -0,0 +1,316 @@
+/*
+Package goaprometheus contains a goa metrics collector that records the goa instrumentation in
+Prometheus metrics and exposes them via a HTTP handler.
+Usage:
+
+    collector := goaprometheus.New()
+    // Record goa metrics in Prometheus
+    goa.SetMetrics(collector)
+    // Record the request latency and status of each action
+    service.Use(collector.Middleware())
+    // Expose the metrics to the Prometheus scraper
+    collector.Mount(service.Mux, "/metrics")
+
+The collector records the metrics emitted by goa with labels instead of dotted keys:
+
+    goa_decode_duration_seconds{content_type}         request body decoding latency
+    goa_encode_duration_seconds{content_type}         response body encoding latency
+    goa_responses_total{status}                       responses by status code
+    goa_validation_errors_total{format}               format validation errors
+
+The middleware records:
+
+    goa_http_request_duration_seconds{resource,action,status,content_type}
+    goa_http_requests_total{resource,action,status,content_type}
+
+All other keys are recorded as unlabeled metrics named after the key elements joined with
+underscores.
+*/
+package goaprometheus
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type (
+	// Collector is a goa metrics collector backed by Prometheus.
+	Collector struct {
+		registerer prometheus.Registerer
+		gatherer   prometheus.Gatherer
+		namespace  string
+		buckets    []float64
+
+		decodeDuration   *prometheus.HistogramVec
+		encodeDuration   *prometheus.HistogramVec
+		responses        *prometheus.CounterVec
+		validationErrors *prometheus.CounterVec
+		requestDuration  *prometheus.HistogramVec
+		requests         *prometheus.CounterVec
+
+		// lock protects the metrics created for arbitrary keys.
+		lock       sync.Mutex
+		counters   map[string]prometheus.Counter
+		gauges     map[string]prometheus.Gauge
+		histograms map[string]prometheus.Histogram
+		summaries  map[string]prometheus.Summary
+	}
+
+	// Option is a constructor option that makes it possible to customize the collector.
+	Option func(*Collector) *Collector
+)
+
+// invalidNameCharsRE matches the characters that are not allowed in Prometheus metric names.
+var invalidNameCharsRE = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// Registry sets the registry used to register and gather the metrics. Defaults to the Prometheus
+// default registry.
+func Registry(r *prometheus.Registry) Option {
+	if r == nil {
+		panic("registry cannot be nil")
+	}
+	return func(c *Collector) *Collector {
+		c.registerer = r
+		c.gatherer = r
+		return c
+	}
+}
+
+// Namespace sets the prefix of the goa metric names. Defaults to "goa".
+func Namespace(ns string) Option {
+	return func(c *Collector) *Collector {
+		c.namespace = ns
+		return c
+	}
+}
+
+// Buckets sets the buckets of the latency histograms in seconds. Defaults to
+// prometheus.DefBuckets.
+func Buckets(b []float64) Option {
+	if len(b) == 0 {
+		panic("buckets cannot be empty")
+	}
+	return func(c *Collector) *Collector {
+		c.buckets = b
+		return c
+	}
+}
+
+// New creates a collector and registers its metrics. It panics if the metrics cannot be
+// registered, e.g. because they are already registered by another collector.
+func New(opts ...Option) *Collector {
+	c := &Collector{
+		registerer: prometheus.DefaultRegisterer,
+		gatherer:   prometheus.DefaultGatherer,
+		namespace:  "goa",
+		buckets:    prometheus.DefBuckets,
+		counters:   make(map[string]prometheus.Counter),
+		gauges:     make(map[string]prometheus.Gauge),
+		histograms: make(map[string]prometheus.Histogram),
+		summaries:  make(map[string]prometheus.Summary),
+	}
+	for _, opt := range opts {
+		c = opt(c)
+	}
+	c.decodeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Name:      "decode_duration_seconds",
+		Help:      "Time spent decoding request bodies.",
+		Buckets:   c.buckets,
+	}, []string{"content_type"})
+	c.encodeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Name:      "encode_duration_seconds",
+		Help:      "Time spent encoding response bodies.",
+		Buckets:   c.buckets,
+	}, []string{"content_type"})
+	c.responses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Name:      "responses_total",
+		Help:      "Number of responses by status code.",
+	}, []string{"status"})
+	c.validationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Name:      "validation_errors_total",
+		Help:      "Number of format validation errors.",
+	}, []string{"format"})
+	c.requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time spent handling requests by resource, action, status code and content type.",
+		Buckets:   c.buckets,
+	}, []string{"resource", "action", "status", "content_type"})
+	c.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of requests by resource, action, status code and content type.",
+	}, []string{"resource", "action", "status", "content_type"})
+	c.registerer.MustRegister(
+		c.decodeDuration,
+		c.encodeDuration,
+		c.responses,
+		c.validationErrors,
+		c.requestDuration,
+		c.requests,
+	)
+	return c
+}
+
+// Handler returns the HTTP handler that serves the metrics in the Prometheus exposition format.
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.gatherer, promhttp.HandlerOpts{})
+}
+
+// Mount registers the metrics handler with the given mux under path (typically "/metrics").
+func (c *Collector) Mount(mux goa.ServeMux, path string) {
+	h := c.Handler()
+	mux.Handle("GET", path, func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		h.ServeHTTP(rw, req)
+	})
+}
+
+// AddSample records the sample in a summary.
+func (c *Collector) AddSample(key []string, val float32) {
+	if s := c.summary(key); s != nil {
+		s.Observe(float64(val))
+	}
+}
+
+// EmitKey sets the gauge named after key.
+func (c *Collector) EmitKey(key []string, val float32) {
+	c.SetGauge(key, val)
+}
+
+// IncrCounter increments the counter named after key. Response and validation error counters are
+// recorded with labels.
+func (c *Collector) IncrCounter(key []string, val float32) {
+	switch {
+	case hasPrefix(key, "goa", "response") && len(key) == 3:
+		c.responses.WithLabelValues(key[2]).Add(float64(val))
+	case hasPrefix(key, "goa", "validation", "error") && len(key) == 4:
+		c.validationErrors.WithLabelValues(key[3]).Add(float64(val))
+	default:
+		if ct := c.counter(key); ct != nil {
+			ct.Add(float64(val))
+		}
+	}
+}
+
+// MeasureSince records the duration since start in seconds in a histogram. Decoding and encoding
+// durations are recorded with labels.
+func (c *Collector) MeasureSince(key []string, start time.Time) {
+	elapsed := time.Since(start).Seconds()
+	switch {
+	case hasPrefix(key, "goa", "decode") && len(key) == 3:
+		c.decodeDuration.WithLabelValues(key[2]).Observe(elapsed)
+	case hasPrefix(key, "goa", "encode") && len(key) == 3:
+		c.encodeDuration.WithLabelValues(key[2]).Observe(elapsed)
+	default:
+		if h := c.histogram(key); h != nil {
+			h.Observe(elapsed)
+		}
+	}
+}
+
+// SetGauge sets the gauge named after key.
+func (c *Collector) SetGauge(key []string, val float32) {
+	if g := c.gauge(key); g != nil {
+		g.Set(float64(val))
+	}
+}
+
+// counter returns the counter named after key, nil if it cannot be registered.
+func (c *Collector) counter(key []string) prometheus.Counter {
+	name := metricName(key)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if ct, ok := c.counters[name]; ok {
+		return ct
+	}
+	ct := prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: name})
+	if err := c.registerer.Register(ct); err != nil {
+		ct = nil
+	}
+	c.counters[name] = ct
+	return ct
+}
+
+// gauge returns the gauge named after key, nil if it cannot be registered.
+func (c *Collector) gauge(key []string) prometheus.Gauge {
+	name := metricName(key)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if g, ok := c.gauges[name]; ok {
+		return g
+	}
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: name})
+	if err := c.registerer.Register(g); err != nil {
+		g = nil
+	}
+	c.gauges[name] = g
+	return g
+}
+
+// histogram returns the histogram named after key, nil if it cannot be registered.
+func (c *Collector) histogram(key []string) prometheus.Histogram {
+	name := metricName(key) + "_seconds"
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if h, ok := c.histograms[name]; ok {
+		return h
+	}
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: name, Help: name, Buckets: c.buckets})
+	if err := c.registerer.Register(h); err != nil {
+		h = nil
+	}
+	c.histograms[name] = h
+	return h
+}
+
+// summary returns the summary named after key, nil if it cannot be registered.
+func (c *Collector) summary(key []string) prometheus.Summary {
+	name := metricName(key)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if s, ok := c.summaries[name]; ok {
+		return s
+	}
+	s := prometheus.NewSummary(prometheus.SummaryOpts{Name: name, Help: name})
+	if err := c.registerer.Register(s); err != nil {
+		s = nil
+	}
+	c.summaries[name] = s
+	return s
+}
+
+// metricName joins the key elements into a valid Prometheus metric name.
+func metricName(key []string) string {
+	name := invalidNameCharsRE.ReplaceAllString(strings.Join(key, "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// hasPrefix returns true if key starts with the given elements.
+func hasPrefix(key []string, prefix ...string) bool {
+	if len(key) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if key[i] != p {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package goaprometheus_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/metrics/prometheus"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ = Describe("Collector", func() {
+	var collector *goaprometheus.Collector
+
+	BeforeEach(func() {
+		collector = goaprometheus.New(goaprometheus.Registry(prometheus.NewRegistry()))
+	})
+
+	scrape := func() string {
+		mux := goa.NewMux()
+		collector.Mount(mux, "/metrics")
+		rw := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/metrics", nil)
+		mux.ServeHTTP(rw, req)
+		Ω(rw.Code).Should(Equal(200))
+		b, err := ioutil.ReadAll(rw.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	It("records goa metrics with labels", func() {
+		collector.MeasureSince([]string{"goa", "decode", "application_json"}, time.Now())
+		collector.MeasureSince([]string{"goa", "encode", "application_json"}, time.Now())
+		collector.IncrCounter([]string{"goa", "response", "200"}, 1.0)
+		collector.IncrCounter([]string{"goa", "response", "200"}, 1.0)
+		collector.IncrCounter([]string{"goa", "validation", "error", "email"}, 1.0)
+		metrics := scrape()
+		Ω(metrics).Should(ContainSubstring(`goa_decode_duration_seconds_count{content_type="application_json"} 1`))
+		Ω(metrics).Should(ContainSubstring(`goa_encode_duration_seconds_count{content_type="application_json"} 1`))
+		Ω(metrics).Should(ContainSubstring(`goa_responses_total{status="200"} 2`))
+		Ω(metrics).Should(ContainSubstring(`goa_validation_errors_total{format="email"} 1`))
+	})
+
+	It("records other metrics named after their keys", func() {
+		collector.IncrCounter([]string{"my", "counter"}, 3.0)
+		collector.SetGauge([]string{"my", "gauge"}, 2.0)
+		collector.EmitKey([]string{"my", "key.value"}, 5.0)
+		collector.AddSample([]string{"my", "sample"}, 4.0)
+		collector.MeasureSince([]string{"my", "timer"}, time.Now())
+		metrics := scrape()
+		Ω(metrics).Should(ContainSubstring("my_counter 3"))
+		Ω(metrics).Should(ContainSubstring("my_gauge 2"))
+		Ω(metrics).Should(ContainSubstring("my_key_value 5"))
+		Ω(metrics).Should(ContainSubstring("my_sample_sum 4"))
+		Ω(metrics).Should(ContainSubstring("my_timer_seconds_count 1"))
+	})
+
+	It("ignores keys conflicting with registered metrics", func() {
+		collector.IncrCounter([]string{"my", "metric"}, 1.0)
+		Ω(func() { collector.SetGauge([]string{"my", "metric"}, 1.0) }).ShouldNot(Panic())
+		Ω(scrape()).Should(ContainSubstring("my_metric 1"))
+	})
+
+	Describe("Middleware", func() {
+		var handler goa.Handler
+
+		dispatch := func() {
+			ctrl := goa.New("test").NewController("things")
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/things/1", nil)
+			ctx := goa.NewContext(goa.WithAction(ctrl.Context, "show"), rw, req, nil)
+			collector.Middleware()(handler)(ctx, goa.ContextResponse(ctx), req)
+		}
+
+		Context("with a successful response", func() {
+			BeforeEach(func() {
+				handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					rw.Header().Set("Content-Type", "application/json")
+					rw.WriteHeader(200)
+					return nil
+				}
+			})
+
+			It("records the request latency and count by action", func() {
+				dispatch()
+				dispatch()
+				metrics := scrape()
+				Ω(metrics).Should(ContainSubstring(`goa_http_request_duration_seconds_count{action="show",content_type="application/json",resource="things",status="200"} 2`))
+				Ω(metrics).Should(ContainSubstring(`goa_http_requests_total{action="show",content_type="application/json",resource="things",status="200"} 2`))
+			})
+		})
+
+		Context("with an unhandled error", func() {
+			BeforeEach(func() {
+				handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					return goa.ErrNotFound("not found")
+				}
+			})
+
+			It("records the error status", func() {
+				dispatch()
+				Ω(scrape()).Should(ContainSubstring(`goa_http_requests_total{action="show",content_type="",resource="things",status="404"} 1`))
+			})
+		})
+	})
+})
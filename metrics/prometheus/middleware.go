@@ -0,0 +1,47 @@
+package goaprometheus
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// Middleware returns a middleware that records the latency and the number of requests handled by
+// each action labeled with the resource and action names, the response status code and the
+// response content type. Mount it before the ErrorHandler middleware so that the status codes of
+// error responses are recorded accurately.
+func (c *Collector) Middleware() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			started := time.Now()
+			err := h(ctx, rw, req)
+
+			var status int
+			if resp := goa.ContextResponse(ctx); resp != nil {
+				status = resp.Status
+			}
+			if status == 0 {
+				status = http.StatusOK
+				if err != nil {
+					status = http.StatusInternalServerError
+					if serr, ok := err.(goa.ServiceError); ok {
+						status = serr.ResponseStatus()
+					}
+				}
+			}
+			labels := []string{
+				goa.ContextController(ctx),
+				goa.ContextAction(ctx),
+				strconv.Itoa(status),
+				rw.Header().Get("Content-Type"),
+			}
+			c.requestDuration.WithLabelValues(labels...).Observe(time.Since(started).Seconds())
+			c.requests.WithLabelValues(labels...).Inc()
+
+			return err
+		}
+	}
+}
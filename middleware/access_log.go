@@ -0,0 +1,293 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	// CommonLogFormat is the Apache Common Log Format:
+	//     host ident user [time] "request line" status bytes
+	CommonLogFormat AccessLogFormat = iota + 1
+
+	// CombinedLogFormat is the Apache Combined Log Format, it adds the
+	// referer and user agent to the Common Log Format.
+	CombinedLogFormat
+
+	// JSONLogFormat writes each entry as a JSON object on a single line.
+	JSONLogFormat
+)
+
+const (
+	// RemoteAddrField is the client IP, see LogRequest.
+	RemoteAddrField AccessLogField = "remote_addr"
+	// UserField is the user name given with basic auth if any.
+	UserField AccessLogField = "user"
+	// TimeField is the time the request was received.
+	TimeField AccessLogField = "time"
+	// MethodField is the request HTTP method.
+	MethodField AccessLogField = "method"
+	// URIField is the request URI.
+	URIField AccessLogField = "uri"
+	// ProtoField is the request protocol.
+	ProtoField AccessLogField = "proto"
+	// StatusField is the response status code.
+	StatusField AccessLogField = "status"
+	// BytesField is the length of the response body.
+	BytesField AccessLogField = "bytes"
+	// RefererField is the value of the request Referer header.
+	RefererField AccessLogField = "referer"
+	// UserAgentField is the value of the request User-Agent header.
+	UserAgentField AccessLogField = "user_agent"
+	// RequestIDField is the request ID set by the RequestID middleware or
+	// sent in the RequestIDHeader header.
+	RequestIDField AccessLogField = "request_id"
+	// ControllerField is the name of the controller handling the request.
+	ControllerField AccessLogField = "ctrl"
+	// ActionField is the name of the action handling the request.
+	ActionField AccessLogField = "action"
+	// LatencyField is the time spent handling the request in milliseconds.
+	LatencyField AccessLogField = "latency_ms"
+	// TraceIDField is the trace ID set by the tracer middleware or sent by
+	// the upstream service in the trace headers.
+	TraceIDField AccessLogField = "trace_id"
+)
+
+type (
+	// AccessLogFormat is the format of the lines written by the AccessLog
+	// middleware.
+	AccessLogFormat int
+
+	// AccessLogField is the name of a field written by the AccessLog
+	// middleware.
+	AccessLogField string
+
+	// AccessLogOption is a constructor option that makes it possible to
+	// customize the AccessLog middleware.
+	AccessLogOption func(*accessLogOptions) *accessLogOptions
+
+	// accessLogOptions is the struct storing all the options.
+	accessLogOptions struct {
+		format AccessLogFormat
+		fields []AccessLogField
+	}
+
+	// accessLogEntry holds the data of a single access log line.
+	accessLogEntry struct {
+		ctx     context.Context
+		req     *http.Request
+		start   time.Time
+		latency time.Duration
+		status  int
+		bytes   int
+	}
+)
+
+// DefaultAccessLogFields lists the fields written by the JSON format when no
+// field is set with AccessLogFields.
+var DefaultAccessLogFields = []AccessLogField{
+	TimeField, RemoteAddrField, UserField, MethodField, URIField, ProtoField,
+	StatusField, BytesField, RefererField, UserAgentField, RequestIDField,
+	ControllerField, ActionField, LatencyField, TraceIDField,
+}
+
+// accessLogTimeLayout is the layout of the time in the Apache formats.
+const accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// LogFormat is a constructor option that sets the format of the access log
+// lines. Defaults to CommonLogFormat.
+func LogFormat(f AccessLogFormat) AccessLogOption {
+	if f < CommonLogFormat || f > JSONLogFormat {
+		panic(fmt.Sprintf("invalid access log format %d", int(f)))
+	}
+	return func(o *accessLogOptions) *accessLogOptions {
+		o.format = f
+		return o
+	}
+}
+
+// AccessLogFields is a constructor option that sets the fields written by the
+// JSON format in the given order. The Common and Combined formats append the
+// fields that are not already part of the format to each line as key=value
+// pairs.
+func AccessLogFields(fields ...AccessLogField) AccessLogOption {
+	return func(o *accessLogOptions) *accessLogOptions {
+		o.fields = fields
+		return o
+	}
+}
+
+// AccessLog creates a middleware that writes one line per request to w once
+// the request is handled. The line contains the response status and length
+// so the middleware should be mounted before the ErrorHandler middleware.
+// It should be mounted after the RequestID and Tracer middlewares for the
+// request and trace IDs they create to be logged. Lines are written with a
+// single call to w.Write.
+func AccessLog(w io.Writer, opts ...AccessLogOption) goa.Middleware {
+	o := &accessLogOptions{format: CommonLogFormat}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	if o.format == JSONLogFormat && o.fields == nil {
+		o.fields = DefaultAccessLogFields
+	}
+	var lock sync.Mutex
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			start := time.Now()
+			err := h(ctx, rw, req)
+			e := &accessLogEntry{
+				ctx:     ctx,
+				req:     req,
+				start:   start,
+				latency: time.Since(start),
+				status:  responseStatus(ctx, err),
+			}
+			if resp := goa.ContextResponse(ctx); resp != nil {
+				e.bytes = resp.Length
+			}
+			var buf bytes.Buffer
+			if o.format == JSONLogFormat {
+				e.writeJSON(&buf, o.fields)
+			} else {
+				e.writeApache(&buf, o.format, o.fields)
+			}
+			buf.WriteByte('\n')
+			lock.Lock()
+			w.Write(buf.Bytes())
+			lock.Unlock()
+			return err
+		}
+	}
+}
+
+// writeApache writes the entry using the Common or Combined format followed
+// by the extra fields.
+func (e *accessLogEntry) writeApache(buf *bytes.Buffer, format AccessLogFormat, fields []AccessLogField) {
+	size := "-"
+	if e.bytes > 0 {
+		size = strconv.Itoa(e.bytes)
+	}
+	reqLine := e.req.Method + " " + e.value(URIField) + " " + e.req.Proto
+	fmt.Fprintf(buf, "%s - %s [%s] %s %d %s",
+		e.apacheValue(RemoteAddrField), e.apacheValue(UserField), e.value(TimeField),
+		strconv.Quote(reqLine), e.status, size)
+	skip := map[AccessLogField]bool{
+		RemoteAddrField: true, UserField: true, TimeField: true, MethodField: true,
+		URIField: true, ProtoField: true, StatusField: true, BytesField: true,
+	}
+	if format == CombinedLogFormat {
+		fmt.Fprintf(buf, " %s %s", strconv.Quote(e.apacheValue(RefererField)), strconv.Quote(e.apacheValue(UserAgentField)))
+		skip[RefererField] = true
+		skip[UserAgentField] = true
+	}
+	for _, f := range fields {
+		if skip[f] {
+			continue
+		}
+		v := e.apacheValue(f)
+		if strings.ContainsAny(v, " \"") {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(buf, " %s=%s", f, v)
+	}
+}
+
+// writeJSON writes the entry as a JSON object containing the given fields.
+func (e *accessLogEntry) writeJSON(buf *bytes.Buffer, fields []AccessLogField) {
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(string(f))
+		buf.Write(k)
+		buf.WriteByte(':')
+		var v interface{}
+		switch f {
+		case StatusField:
+			v = e.status
+		case BytesField:
+			v = e.bytes
+		case LatencyField:
+			v = json.Number(e.value(f))
+		case TimeField:
+			v = e.start.Format(time.RFC3339Nano)
+		default:
+			v = e.value(f)
+		}
+		js, _ := json.Marshal(v)
+		buf.Write(js)
+	}
+	buf.WriteByte('}')
+}
+
+// apacheValue returns the value of the given field as written in the Apache
+// formats where "-" denotes a missing value.
+func (e *accessLogEntry) apacheValue(f AccessLogField) string {
+	if v := e.value(f); v != "" {
+		return v
+	}
+	return "-"
+}
+
+// value returns the value of the given field, the empty string if there is
+// none.
+func (e *accessLogEntry) value(f AccessLogField) string {
+	switch f {
+	case RemoteAddrField:
+		return from(e.req)
+	case UserField:
+		if u, _, ok := e.req.BasicAuth(); ok {
+			return u
+		}
+	case TimeField:
+		return e.start.Format(accessLogTimeLayout)
+	case MethodField:
+		return e.req.Method
+	case URIField:
+		if e.req.RequestURI != "" {
+			return e.req.RequestURI
+		}
+		return e.req.URL.RequestURI()
+	case ProtoField:
+		return e.req.Proto
+	case StatusField:
+		return strconv.Itoa(e.status)
+	case BytesField:
+		return strconv.Itoa(e.bytes)
+	case RefererField:
+		return e.req.Referer()
+	case UserAgentField:
+		return e.req.UserAgent()
+	case RequestIDField:
+		if id := ContextRequestID(e.ctx); id != "" {
+			return id
+		}
+		return e.req.Header.Get(RequestIDHeader)
+	case ControllerField:
+		return goa.ContextController(e.ctx)
+	case ActionField:
+		return goa.ContextAction(e.ctx)
+	case LatencyField:
+		return strconv.FormatFloat(float64(e.latency)/float64(time.Millisecond), 'f', 3, 64)
+	case TraceIDField:
+		if id := ContextTraceID(e.ctx); id != "" {
+			return id
+		}
+		if tc, ok := extractTrace(e.req, []TraceFormat{GoaTraceFormat, W3CTraceFormat, B3TraceFormat}); ok {
+			return tc.traceID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AccessLog", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var service *goa.Service
+	var opts []middleware.AccessLogOption
+	var handler goa.Handler
+	var out bytes.Buffer
+	var err error
+
+	BeforeEach(func() {
+		out.Reset()
+		opts = nil
+		service = newService(nil)
+		req, err = http.NewRequest("GET", "/goo?param=value", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.RemoteAddr = "10.0.0.1:4242"
+		req.Header.Set("User-Agent", "test agent")
+		req.Header.Set("Referer", "http://goa.design")
+		req.Header.Set(middleware.RequestIDHeader, "reqid")
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		req.SetBasicAuth("joe", "secret")
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, url.Values{"param": []string{"value"}})
+		ctx = goa.WithAction(ctx, "show")
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, "ok")
+		}
+	})
+
+	JustBeforeEach(func() {
+		err = middleware.AccessLog(&out, opts...)(handler)(ctx, rw, req)
+	})
+
+	It("writes lines using the Common Log Format", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(out.String()).Should(MatchRegexp(
+			`^10\.0\.0\.1 - joe \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /goo\?param=value HTTP/1\.1" 200 5\n$`))
+	})
+
+	Context("using the Combined Log Format with extra fields", func() {
+		BeforeEach(func() {
+			opts = []middleware.AccessLogOption{
+				middleware.LogFormat(middleware.CombinedLogFormat),
+				middleware.AccessLogFields(middleware.StatusField, middleware.RequestIDField,
+					middleware.ControllerField, middleware.ActionField, middleware.LatencyField,
+					middleware.TraceIDField),
+			}
+		})
+
+		It("appends the fields", func() {
+			Ω(out.String()).Should(MatchRegexp(
+				`" 200 5 "http://goa\.design" "test agent" request_id=reqid ctrl=test action=show ` +
+					`latency_ms=\d+\.\d{3} trace_id=4bf92f3577b34da6a3ce929d0e0e4736\n$`))
+		})
+	})
+
+	Context("using the JSON format", func() {
+		BeforeEach(func() {
+			opts = []middleware.AccessLogOption{
+				middleware.LogFormat(middleware.JSONLogFormat),
+				middleware.AccessLogFields(middleware.StatusField, middleware.BytesField,
+					middleware.UserAgentField, middleware.RequestIDField),
+			}
+			handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return goa.ErrBadRequest("invalid")
+			}
+		})
+
+		It("writes the selected fields", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(out.String()).Should(Equal(`{"status":400,"bytes":0,"user_agent":"test agent","request_id":"reqid"}` + "\n"))
+		})
+
+		Context("with the default fields", func() {
+			BeforeEach(func() {
+				opts = opts[:1]
+			})
+
+			It("writes all the fields", func() {
+				Ω(out.String()).Should(MatchRegexp(`^\{"time":"[^"]+","remote_addr":"10\.0\.0\.1","user":"joe",` +
+					`"method":"GET","uri":"/goo\?param=value","proto":"HTTP/1\.1","status":400,"bytes":0,` +
+					`"referer":"http://goa\.design","user_agent":"test agent","request_id":"reqid",` +
+					`"ctrl":"test","action":"show","latency_ms":\d+\.\d{3},"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"\}\n$`))
+			})
+		})
+	})
+})
@@ -0,0 +1,206 @@
+package contract
+
+import (
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// FromDesign builds the Spec describing the API defined by the given design. The design must
+// have been run, typically api is design.Design once the DSL has been executed.
+func FromDesign(api *design.APIDefinition) (*Spec, error) {
+	s := &Spec{}
+	c := &converter{seen: make(map[*design.UserTypeDefinition]*schema)}
+	err := api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			for _, route := range a.Routes {
+				o, err := c.operation(api, a, route)
+				if err != nil {
+					return err
+				}
+				s.operations = append(s.operations, o)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.sortOperations()
+	if err := s.compilePatterns(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// converter converts design attributes into schemas. User types are converted once so that
+// recursive types are supported.
+type converter struct {
+	seen map[*design.UserTypeDefinition]*schema
+}
+
+// operation builds the operation corresponding to the given action route.
+func (c *converter) operation(api *design.APIDefinition, a *design.ActionDefinition, route *design.RouteDefinition) (*operation, error) {
+	p := route.FullPath()
+	o := &operation{
+		method:    strings.ToUpper(route.Verb),
+		path:      p,
+		responses: make(map[int]*response),
+		segments: newSegments(p, func(s string) (string, bool, bool) {
+			switch s[0] {
+			case ':':
+				return s[1:], false, true
+			case '*':
+				return s[1:], true, true
+			}
+			return "", false, false
+		}),
+	}
+	params := a.AllParams()
+	wildcards := design.ExtractWildcards(p)
+	params.Type.ToObject().IterateAttributes(func(n string, att *design.AttributeDefinition) error {
+		param := &parameter{Name: n, In: "query", Required: params.IsRequired(n), schema: *c.schema(att)}
+		for _, w := range wildcards {
+			if w == n {
+				param.In = "path"
+				param.Required = true
+				break
+			}
+		}
+		if att.Type.IsArray() {
+			param.CollectionFormat = "multi"
+		}
+		o.params = append(o.params, param)
+		return nil
+	})
+	a.IterateHeaders(func(n string, required bool, att *design.AttributeDefinition) error {
+		param := &parameter{Name: n, In: "header", Required: required, schema: *c.schema(att)}
+		if att.Type.IsArray() {
+			param.CollectionFormat = "multi"
+		}
+		o.params = append(o.params, param)
+		return nil
+	})
+	if a.Payload != nil && !a.PayloadMultipart {
+		o.body = c.schema(a.Payload.AttributeDefinition)
+		o.bodyRequired = !a.PayloadOptional
+	}
+	for _, r := range a.Responses {
+		resp, err := c.response(api, r)
+		if err != nil {
+			return nil, err
+		}
+		o.responses[r.Status] = resp
+	}
+	return o, nil
+}
+
+// response builds the response corresponding to the given response definition.
+func (c *converter) response(api *design.APIDefinition, r *design.ResponseDefinition) (*response, error) {
+	if r.Type != nil {
+		if _, ok := r.Type.(*design.MediaTypeDefinition); !ok {
+			return &response{Schema: c.schema(&design.AttributeDefinition{Type: r.Type})}, nil
+		}
+	}
+	mt := api.MediaTypeWithIdentifier(r.MediaType)
+	if mt == nil {
+		return &response{}, nil
+	}
+	if len(mt.Views) == 0 {
+		return &response{Schema: c.schema(mt.AttributeDefinition)}, nil
+	}
+	view := r.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	return &response{Schema: c.schema(p.AttributeDefinition)}, nil
+}
+
+// schema returns the schema describing the values of the given attribute.
+func (c *converter) schema(att *design.AttributeDefinition) *schema {
+	switch t := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		return c.userType(t)
+	case *design.MediaTypeDefinition:
+		return c.userType(t.UserTypeDefinition)
+	}
+	sc := &schema{}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		sc.Type = "boolean"
+	case design.IntegerKind:
+		sc.Type = "integer"
+	case design.Int64Kind:
+		sc.Type, sc.Format = "integer", "int64"
+	case design.UInt64Kind:
+		sc.Type, sc.Format = "integer", "uint64"
+	case design.NumberKind:
+		sc.Type = "number"
+	case design.Float32Kind:
+		sc.Type, sc.Format = "number", "float"
+	case design.StringKind:
+		sc.Type = "string"
+	case design.BytesKind:
+		sc.Type, sc.Format = "string", "byte"
+	case design.DateKind:
+		sc.Type, sc.Format = "string", "date"
+	case design.DurationKind:
+		sc.Type, sc.Format = "string", "duration"
+	case design.DateTimeKind:
+		sc.Type, sc.Format = "string", "date-time"
+	case design.UUIDKind:
+		sc.Type, sc.Format = "string", "uuid"
+	case design.FileKind:
+		sc.Type = "file"
+	case design.ArrayKind:
+		sc.Type = "array"
+		sc.Items = c.schema(att.Type.ToArray().ElemType)
+	case design.ObjectKind:
+		sc.Type = "object"
+		sc.Properties = make(map[string]*schema)
+		for n, child := range att.Type.ToObject() {
+			sc.Properties[n] = c.schema(child)
+		}
+	case design.HashKind:
+		sc.Type = "object"
+	}
+	if att.Validation != nil {
+		c.validations(att.Type, att.Validation, sc)
+	}
+	return sc
+}
+
+// userType returns the schema describing the values of the given user type.
+func (c *converter) userType(ut *design.UserTypeDefinition) *schema {
+	if sc, ok := c.seen[ut]; ok {
+		return sc
+	}
+	sc := &schema{}
+	c.seen[ut] = sc
+	*sc = *c.schema(ut.AttributeDefinition)
+	return sc
+}
+
+// validations initializes the schema validations from the design validations.
+func (c *converter) validations(t design.DataType, v *dslengine.ValidationDefinition, sc *schema) {
+	sc.Enum = v.Values
+	if v.Format != "" {
+		sc.Format = v.Format
+	}
+	sc.Pattern = v.Pattern
+	sc.Minimum = v.Minimum
+	sc.Maximum = v.Maximum
+	if t.IsArray() {
+		sc.MinItems = v.MinLength
+		sc.MaxItems = v.MaxLength
+	} else {
+		sc.MinLength = v.MinLength
+		sc.MaxLength = v.MaxLength
+	}
+	sc.Required = v.Required
+}
@@ -0,0 +1,104 @@
+package contract_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/middleware/contract"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FromDesign", func() {
+	var spec *contract.Spec
+	var err error
+	var trace string
+
+	dispatch := func(method, url, payload string) error {
+		var req *http.Request
+		if payload == "" {
+			req, _ = http.NewRequest(method, url, nil)
+		} else {
+			req, _ = http.NewRequest(method, url, strings.NewReader(payload))
+		}
+		if trace != "" {
+			req.Header.Set("X-Trace", trace)
+		}
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return nil
+		}
+		return contract.New(spec)(handler)(ctx, goa.ContextResponse(ctx), req)
+	}
+
+	BeforeEach(func() {
+		trace = ""
+		dslengine.Reset()
+		var Node = Type("Node", func() {
+			Attribute("name", design.String, func() {
+				Pattern("^[a-z]+$")
+			})
+			Required("name")
+		})
+		Node.Type.ToObject()["children"] = &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: Node}}}
+		API("test", func() {
+			BasePath("/api")
+		})
+		Resource("nodes", func() {
+			BasePath("/nodes")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", design.Integer, func() {
+						Minimum(1)
+					})
+				})
+				Headers(func() {
+					Header("X-Trace", design.UUID)
+				})
+				Response(design.NoContent)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(Node)
+				Response(design.Created)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		spec, err = contract.FromDesign(design.Design)
+	})
+
+	It("builds the spec", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(spec).ShouldNot(BeNil())
+	})
+
+	It("validates path parameters", func() {
+		Ω(dispatch("GET", "http://example.com/api/nodes/1", "")).ShouldNot(HaveOccurred())
+		err := dispatch("GET", "http://example.com/api/nodes/0", "")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("id must be greater than or equal to 1"))
+	})
+
+	It("validates headers", func() {
+		trace = "not-a-uuid"
+		err := dispatch("GET", "http://example.com/api/nodes/1", "")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("X-Trace must be formatted as a uuid"))
+	})
+
+	It("validates recursive payloads", func() {
+		Ω(dispatch("POST", "http://example.com/api/nodes", `{"name": "root", "children": [{"name": "leaf"}]}`)).ShouldNot(HaveOccurred())
+		err := dispatch("POST", "http://example.com/api/nodes", `{"name": "root", "children": [{"name": "Leaf"}, {}]}`)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("payload.children[0].name"))
+		Ω(err.Error()).Should(ContainSubstring("attribute \"name\" of payload.children[1] is missing"))
+	})
+})
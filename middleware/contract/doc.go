@@ -0,0 +1,29 @@
+/*
+Package contract provides a middleware that validates requests and responses against the API
+contract described by a Swagger specification or by a design.
+
+The code generated by goagen already validates the payloads and parameters of the actions
+defined in the design. The contract middleware makes it possible to enforce a Swagger
+specification on services that are not entirely generated or to double check that the handlers
+follow the design during development. The contract is loaded from the swagger.json file produced
+by goagen:
+
+    spec, err := contract.LoadSwaggerFile("swagger/swagger.json")
+
+or built directly from the design:
+
+    spec, err := contract.FromDesign(design.Design)
+
+The middleware validates the path parameters, query string parameters, headers and JSON bodies of
+the requests. Violations are reported with the goa error classes (e.g. goa.ErrBadRequest or
+goa.InvalidAttributeTypeError) so that they are rendered like the errors returned by the
+generated code:
+
+    service.Use(contract.New(spec))
+
+The ValidateResponses option makes the middleware also check the status codes and bodies of the
+responses, it should only be enabled in development and testing environments:
+
+    service.Use(contract.New(spec, contract.ValidateResponses()))
+*/
+package contract
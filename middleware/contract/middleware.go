@@ -0,0 +1,184 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+// New returns a middleware that validates the requests against the operations described by
+// spec. Requests whose path parameters, query string parameters, headers or body do not follow
+// the contract are rejected with a bad request error built with the goa error classes (e.g.
+// goa.InvalidAttributeTypeError). Requests that do not match any operation are passed through
+// unchanged. Usage:
+//
+//    spec, err := contract.LoadSwaggerFile("swagger/swagger.json")
+//    if err != nil {
+//        log.Fatal(err)
+//    }
+//    service.Use(contract.New(spec))
+//
+// Use the ValidateResponses option to also check the responses written by the handlers.
+func New(spec *Spec, opts ...Option) goa.Middleware {
+	o := newOptions(opts)
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			op, pathParams := spec.find(req)
+			if op == nil {
+				return h(ctx, rw, req)
+			}
+			if err := spec.validateRequest(op, pathParams, req); err != nil {
+				return err
+			}
+			resp := goa.ContextResponse(ctx)
+			if !o.validateResponses || resp == nil || isUpgrade(req) {
+				return h(ctx, rw, req)
+			}
+
+			rec := &recorder{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(rec)
+			err := h(ctx, rw, req)
+			resp.SwitchWriter(rec.ResponseWriter)
+			if err != nil {
+				if !resp.Written() {
+					return err
+				}
+				rec.flush()
+				return err
+			}
+			if verr := spec.validateResponse(op, rec); verr != nil {
+				goa.LogError(ctx, "invalid response", "method", op.method, "path", op.path, "err", verr)
+				resp.Status = 0
+				resp.Length = 0
+				return goa.ErrInternal(fmt.Sprintf("response does not follow the contract of %s %s", op.method, op.path))
+			}
+			rec.flush()
+			return nil
+		}
+	}
+}
+
+// validateRequest validates the request parameters and body.
+func (s *Spec) validateRequest(op *operation, pathParams map[string]string, req *http.Request) error {
+	err := s.validateParams(op, pathParams, req)
+	if op.body == nil || !isJSON(req.Header.Get("Content-Type")) {
+		return err
+	}
+	var b []byte
+	if req.Body != nil {
+		var rerr error
+		b, rerr = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		if rerr != nil {
+			return goa.MergeErrors(err, goa.ErrBadRequest(rerr))
+		}
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		if op.bodyRequired {
+			err = goa.MergeErrors(err, goa.MissingPayloadError())
+		}
+		return err
+	}
+	val, derr := decode(b)
+	if derr != nil {
+		return goa.MergeErrors(err, goa.ErrBadRequest(fmt.Sprintf("invalid JSON body: %s", derr)))
+	}
+	if val == nil && op.bodyRequired {
+		return goa.MergeErrors(err, goa.MissingPayloadError())
+	}
+	return goa.MergeErrors(err, s.validate("payload", val, op.body))
+}
+
+// validateResponse validates the response recorded by rec.
+func (s *Spec) validateResponse(op *operation, rec *recorder) error {
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	r, ok := op.responses[status]
+	if !ok {
+		r = op.defaultResponse
+	}
+	if r == nil {
+		if status < 400 {
+			return fmt.Errorf("undefined response status %d", status)
+		}
+		return nil
+	}
+	if r.Schema == nil || rec.body.Len() == 0 || !isJSON(rec.Header().Get("Content-Type")) {
+		return nil
+	}
+	val, err := decode(rec.body.Bytes())
+	if err != nil {
+		return fmt.Errorf("invalid JSON body: %s", err)
+	}
+	return s.validate("response", val, r.Schema)
+}
+
+// recorder is the response writer used to buffer the responses so that they can be validated
+// before being sent.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the response status.
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+// Write buffers the response body.
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// flush writes the recorded response to the underlying writer.
+func (r *recorder) flush() {
+	if r.status == 0 {
+		return
+	}
+	r.ResponseWriter.WriteHeader(r.status)
+	r.ResponseWriter.Write(r.body.Bytes())
+}
+
+// decode decodes the given JSON document preserving the numbers as json.Number values.
+func decode(b []byte) (interface{}, error) {
+	var val interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// isJSON returns true if the content type is empty or denotes a JSON document.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(mt, "/json") || strings.HasSuffix(mt, "+json")
+}
+
+// isUpgrade returns true if the request is a websocket upgrade request.
+func isUpgrade(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
@@ -0,0 +1,227 @@
+package contract_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/contract"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var spec *contract.Spec
+	var opts []contract.Option
+	var request *http.Request
+	var rw *httptest.ResponseRecorder
+	var handler goa.Handler
+	var called bool
+	var body string
+
+	dispatch := func() error {
+		rw = httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, request, nil)
+		return contract.New(spec, opts...)(handler)(ctx, goa.ContextResponse(ctx), request)
+	}
+
+	newRequest := func(method, url, payload string) *http.Request {
+		var req *http.Request
+		if payload == "" {
+			req, _ = http.NewRequest(method, url, nil)
+		} else {
+			req, _ = http.NewRequest(method, url, strings.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("X-Version", "v1")
+		return req
+	}
+
+	BeforeEach(func() {
+		var err error
+		spec, err = contract.LoadSwagger(strings.NewReader(swaggerJSON))
+		Ω(err).ShouldNot(HaveOccurred())
+		opts = nil
+		called = false
+		body = ""
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			if req.Body != nil {
+				b, _ := ioutil.ReadAll(req.Body)
+				body = string(b)
+			}
+			rw.WriteHeader(http.StatusOK)
+			return nil
+		}
+	})
+
+	It("passes through requests that do not match any operation", func() {
+		request = newRequest("GET", "http://example.com/unknown", "")
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("accepts valid requests", func() {
+		request = newRequest("GET", "http://example.com/api/bottles?limit=10&tags=a,b", "")
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("validates query string parameters", func() {
+		request = newRequest("GET", "http://example.com/api/bottles?limit=1000", "")
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+		Ω(err.Error()).Should(ContainSubstring("limit"))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("validates the parameter types", func() {
+		request = newRequest("GET", "http://example.com/api/bottles?limit=ten", "")
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("invalid value \"ten\" for parameter \"limit\", must be a integer"))
+	})
+
+	It("validates headers", func() {
+		request = newRequest("GET", "http://example.com/api/bottles", "")
+		request.Header.Set("X-Version", "v3")
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("X-Version"))
+	})
+
+	It("reports missing required headers", func() {
+		request = newRequest("GET", "http://example.com/api/bottles", "")
+		request.Header.Del("X-Version")
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("missing required HTTP header \"X-Version\""))
+	})
+
+	It("validates path parameters", func() {
+		request = newRequest("GET", "http://example.com/api/bottles/abc", "")
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("\"id\""))
+	})
+
+	It("favors literal path segments", func() {
+		request = newRequest("GET", "http://example.com/api/bottles/latest", "")
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("validates request bodies and restores them", func() {
+		payload := `{"name": "Cabernet", "vintage": 2012, "origin": {"country": "FR"}}`
+		request = newRequest("POST", "http://example.com/api/bottles", payload)
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+		Ω(body).Should(Equal(payload))
+	})
+
+	It("reports all the body violations", func() {
+		payload := `{"name": "C", "vintage": 1800, "color": "pink", "email": "nope", "code": "abc", "tags": ["a", "b", "c"], "origin": {}}`
+		request = newRequest("POST", "http://example.com/api/bottles", payload)
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+		msg := err.Error()
+		Ω(msg).Should(ContainSubstring("payload.name"))
+		Ω(msg).Should(ContainSubstring("payload.vintage"))
+		Ω(msg).Should(ContainSubstring("payload.color"))
+		Ω(msg).Should(ContainSubstring("payload.email"))
+		Ω(msg).Should(ContainSubstring("payload.code"))
+		Ω(msg).Should(ContainSubstring("payload.tags"))
+		Ω(msg).Should(ContainSubstring("attribute \"country\" of payload.origin is missing"))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("reports attribute type errors", func() {
+		request = newRequest("POST", "http://example.com/api/bottles", `{"name": 42}`)
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("type of payload.name must be string but got value 42"))
+	})
+
+	It("reports missing bodies", func() {
+		request = newRequest("POST", "http://example.com/api/bottles", "")
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("missing required payload"))
+	})
+
+	It("reports invalid JSON bodies", func() {
+		request = newRequest("POST", "http://example.com/api/bottles", `{"name":`)
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+	})
+
+	It("does not validate responses by default", func() {
+		request = newRequest("GET", "http://example.com/api/bottles/1", "")
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(`{"id": "one"}`))
+			return nil
+		}
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal(`{"id": "one"}`))
+	})
+
+	Context("with response validation", func() {
+		var status int
+		var resp string
+
+		BeforeEach(func() {
+			opts = []contract.Option{contract.ValidateResponses()}
+			request = newRequest("GET", "http://example.com/api/bottles/1", "")
+			handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				rw.Header().Set("Content-Type", "application/json")
+				rw.WriteHeader(status)
+				rw.Write([]byte(resp))
+				return nil
+			}
+		})
+
+		It("sends valid responses", func() {
+			status, resp = 200, `{"id": 1, "name": "Cabernet"}`
+			Ω(dispatch()).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Body.String()).Should(Equal(resp))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+		})
+
+		It("rejects invalid responses", func() {
+			status, resp = 200, `{"id": "one"}`
+			err := dispatch()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(500))
+			Ω(rw.Body.Len()).Should(Equal(0))
+		})
+
+		It("rejects undefined success statuses", func() {
+			status, resp = 202, ``
+			err := dispatch()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(500))
+		})
+
+		It("lets undefined error statuses through", func() {
+			status, resp = 404, `{"id": "not found"}`
+			Ω(dispatch()).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(404))
+		})
+
+		It("uses the default response", func() {
+			request = newRequest("POST", "http://example.com/api/bottles", `{"name": "Cabernet"}`)
+			status, resp = 400, `{"msg": "oops"}`
+			err := dispatch()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("response does not follow the contract of POST /api/bottles"))
+		})
+	})
+})
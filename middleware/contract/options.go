@@ -0,0 +1,31 @@
+package contract
+
+type (
+	// Option is a constructor option that makes it possible to customize the middleware.
+	Option func(*options) *options
+
+	// options is the struct storing all the options.
+	options struct {
+		validateResponses bool
+	}
+)
+
+// ValidateResponses makes the middleware also check the responses written by the handlers.
+// Responses that do not follow the contract are replaced with an internal error response and
+// the violation is logged. The response body is buffered so that it can be validated, this
+// option is thus meant for development and testing environments.
+func ValidateResponses() Option {
+	return func(o *options) *options {
+		o.validateResponses = true
+		return o
+	}
+}
+
+// newOptions initializes the options with their default values and applies opts.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	return o
+}
@@ -0,0 +1,189 @@
+package contract
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	// Spec is the API contract enforced by the middleware. It lists the operations exposed by
+	// the API together with the schemas of their parameters, request body and responses. A
+	// Spec is created from a Swagger specification with LoadSwagger or from a design with
+	// FromDesign.
+	Spec struct {
+		operations  []*operation
+		definitions map[string]*schema
+		parameters  map[string]*parameter
+		patterns    map[string]*regexp.Regexp
+	}
+
+	// operation describes the requests accepted by an endpoint and the responses it sends.
+	operation struct {
+		// method is the HTTP method in upper case.
+		method string
+		// path is the path template used in error messages.
+		path string
+		// segments are the path segments used to match request paths.
+		segments []*segment
+		// params lists the path, query string and header parameters.
+		params []*parameter
+		// body is the schema of the request body if any.
+		body *schema
+		// bodyRequired is true if requests must have a body.
+		bodyRequired bool
+		// responses indexes the response schemas by HTTP status code.
+		responses map[int]*response
+		// defaultResponse describes the responses with a status not listed in responses.
+		defaultResponse *response
+	}
+
+	// segment is a path segment, either a literal or a wildcard.
+	segment struct {
+		// literal is the segment value if the segment is not a wildcard.
+		literal string
+		// param is the name of the wildcard parameter.
+		param string
+		// catchAll is true if the wildcard matches the rest of the path.
+		catchAll bool
+	}
+
+	// parameter describes a path, query string or header parameter.
+	parameter struct {
+		// Name is the parameter or header name.
+		Name string `json:"name"`
+		// In is the parameter location: "path", "query", "header", "formData" or "body".
+		In string `json:"in"`
+		// Required is true if the parameter must be present.
+		Required bool `json:"required"`
+		// CollectionFormat is the format of array values.
+		CollectionFormat string `json:"collectionFormat"`
+		// Schema is the schema of body parameters.
+		Schema *schema `json:"schema"`
+		// schema describes the values of the other parameters.
+		schema
+	}
+
+	// response describes a response body.
+	response struct {
+		// Schema is the schema of the body, nil if the body is not checked.
+		Schema *schema `json:"schema"`
+	}
+
+	// schema describes the values of parameters and bodies. The fields follow the JSON
+	// schema subset used by Swagger.
+	schema struct {
+		Ref        string             `json:"$ref"`
+		Type       string             `json:"type"`
+		Format     string             `json:"format"`
+		Enum       []interface{}      `json:"enum"`
+		Pattern    string             `json:"pattern"`
+		Minimum    *float64           `json:"minimum"`
+		Maximum    *float64           `json:"maximum"`
+		MinLength  *int               `json:"minLength"`
+		MaxLength  *int               `json:"maxLength"`
+		MinItems   *int               `json:"minItems"`
+		MaxItems   *int               `json:"maxItems"`
+		Items      *schema            `json:"items"`
+		Properties map[string]*schema `json:"properties"`
+		Required   []string           `json:"required"`
+	}
+)
+
+// newSegments splits a path into segments. isWildcard returns the name of the wildcard
+// parameter and whether it matches the rest of the path if the segment is a wildcard.
+func newSegments(path string, isWildcard func(string) (string, bool, bool)) []*segment {
+	var segs []*segment
+	for _, s := range strings.Split(strings.Trim(path, "/"), "/") {
+		if s == "" {
+			continue
+		}
+		if name, catchAll, ok := isWildcard(s); ok {
+			segs = append(segs, &segment{param: name, catchAll: catchAll})
+			continue
+		}
+		segs = append(segs, &segment{literal: s})
+	}
+	return segs
+}
+
+// match returns the values of the path parameters and the number of literal segments if the
+// operation path matches the given request path.
+func (o *operation) match(path string) (map[string]string, int, bool) {
+	elems := strings.Split(strings.Trim(path, "/"), "/")
+	if len(elems) == 1 && elems[0] == "" {
+		elems = nil
+	}
+	params := make(map[string]string)
+	literals := 0
+	for i, seg := range o.segments {
+		if seg.catchAll {
+			params[seg.param] = strings.Join(elems[i:], "/")
+			return params, literals, true
+		}
+		if i >= len(elems) {
+			return nil, 0, false
+		}
+		if seg.param != "" {
+			if elems[i] == "" {
+				return nil, 0, false
+			}
+			params[seg.param] = elems[i]
+			continue
+		}
+		if seg.literal != elems[i] {
+			return nil, 0, false
+		}
+		literals++
+	}
+	if len(elems) != len(o.segments) {
+		return nil, 0, false
+	}
+	return params, literals, true
+}
+
+// find returns the operation matching the request method and path and the values of the path
+// parameters. It favors the operations with the most literal segments when multiple operations
+// match. It returns nil if no operation matches.
+func (s *Spec) find(req *http.Request) (*operation, map[string]string) {
+	var (
+		best     *operation
+		params   map[string]string
+		literals = -1
+	)
+	for _, o := range s.operations {
+		if o.method != req.Method {
+			continue
+		}
+		p, l, ok := o.match(req.URL.Path)
+		if ok && l > literals {
+			best, params, literals = o, p, l
+		}
+	}
+	return best, params
+}
+
+// resolve follows the schema references.
+func (s *Spec) resolve(sc *schema) *schema {
+	seen := make(map[string]bool)
+	for sc != nil && sc.Ref != "" {
+		if seen[sc.Ref] {
+			return nil
+		}
+		seen[sc.Ref] = true
+		sc = s.definitions[strings.TrimPrefix(sc.Ref, "#/definitions/")]
+	}
+	return sc
+}
+
+// sortOperations sorts the operations by path and method so that Spec behaves
+// deterministically.
+func (s *Spec) sortOperations() {
+	sort.Slice(s.operations, func(i, j int) bool {
+		if s.operations[i].path == s.operations[j].path {
+			return s.operations[i].method < s.operations[j].method
+		}
+		return s.operations[i].path < s.operations[j].path
+	})
+}
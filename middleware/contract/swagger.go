@@ -0,0 +1,154 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	// swaggerSpec is the subset of the Swagger 2.0 specification used to build a Spec.
+	swaggerSpec struct {
+		Swagger     string                                `json:"swagger"`
+		BasePath    string                                `json:"basePath"`
+		Paths       map[string]map[string]json.RawMessage `json:"paths"`
+		Definitions map[string]*schema                    `json:"definitions"`
+		Parameters  map[string]*parameter                 `json:"parameters"`
+		Responses   map[string]*response                  `json:"responses"`
+	}
+
+	// swaggerOperation is the subset of a Swagger operation used to build a Spec.
+	swaggerOperation struct {
+		Parameters []*parameter                `json:"parameters"`
+		Responses  map[string]*swaggerResponse `json:"responses"`
+	}
+
+	// swaggerResponse is a Swagger response or a reference to a response defined at the top
+	// level.
+	swaggerResponse struct {
+		Ref    string  `json:"$ref"`
+		Schema *schema `json:"schema"`
+	}
+)
+
+// swaggerPathParam matches the path template parameters, e.g. "{id}".
+var swaggerPathParam = regexp.MustCompile(`^\{(.+)\}$`)
+
+// LoadSwagger reads the Swagger 2.0 specification encoded in JSON from r, for example the
+// swagger.json file produced by goagen, and builds the corresponding Spec.
+func LoadSwagger(r io.Reader) (*Spec, error) {
+	var sw swaggerSpec
+	if err := json.NewDecoder(r).Decode(&sw); err != nil {
+		return nil, fmt.Errorf("invalid swagger specification: %s", err)
+	}
+	if sw.Swagger != "2.0" {
+		return nil, fmt.Errorf("unsupported swagger version %q, must be 2.0", sw.Swagger)
+	}
+	s := &Spec{definitions: sw.Definitions, parameters: sw.Parameters}
+	for p, item := range sw.Paths {
+		// Parameters may be defined at the path level and overridden by operations.
+		var common []*parameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &common); err != nil {
+				return nil, fmt.Errorf("invalid parameters of path %s: %s", p, err)
+			}
+		}
+		full := path.Join("/", sw.BasePath, p)
+		for method, raw := range item {
+			if method == "parameters" || strings.HasPrefix(method, "x-") {
+				continue
+			}
+			var op swaggerOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %s", strings.ToUpper(method), p, err)
+			}
+			o, err := s.newSwaggerOperation(strings.ToUpper(method), full, common, &op, sw.Responses)
+			if err != nil {
+				return nil, err
+			}
+			s.operations = append(s.operations, o)
+		}
+	}
+	s.sortOperations()
+	if err := s.compilePatterns(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSwaggerFile reads the Swagger 2.0 specification encoded in JSON from the file with the
+// given path and builds the corresponding Spec.
+func LoadSwaggerFile(filename string) (*Spec, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadSwagger(f)
+}
+
+// newSwaggerOperation builds an operation from its Swagger definition.
+func (s *Spec) newSwaggerOperation(method, p string, common []*parameter, op *swaggerOperation, responses map[string]*response) (*operation, error) {
+	o := &operation{
+		method:    method,
+		path:      p,
+		responses: make(map[int]*response),
+		segments: newSegments(p, func(s string) (string, bool, bool) {
+			if m := swaggerPathParam.FindStringSubmatch(s); m != nil {
+				return m[1], false, true
+			}
+			return "", false, false
+		}),
+	}
+	params := make(map[string]*parameter)
+	var names []string
+	for _, param := range append(common, op.Parameters...) {
+		if param.Ref != "" && param.In == "" {
+			ref, ok := s.parameters[strings.TrimPrefix(param.Ref, "#/parameters/")]
+			if !ok {
+				return nil, fmt.Errorf("%s %s: unknown parameter %s", method, p, param.Ref)
+			}
+			param = ref
+		}
+		key := param.In + ":" + param.Name
+		if _, ok := params[key]; !ok {
+			names = append(names, key)
+		}
+		params[key] = param
+	}
+	for _, key := range names {
+		param := params[key]
+		switch param.In {
+		case "body":
+			o.body = param.Schema
+			o.bodyRequired = param.Required
+		case "path", "query", "header":
+			o.params = append(o.params, param)
+		}
+	}
+	for code, resp := range op.Responses {
+		r := &response{Schema: resp.Schema}
+		if resp.Ref != "" {
+			ref, ok := responses[strings.TrimPrefix(resp.Ref, "#/responses/")]
+			if !ok {
+				return nil, fmt.Errorf("%s %s: unknown response %s", method, p, resp.Ref)
+			}
+			r = ref
+		}
+		if code == "default" {
+			o.defaultResponse = r
+			continue
+		}
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: invalid response status %q", method, p, code)
+		}
+		o.responses[status] = r
+	}
+	return o, nil
+}
@@ -0,0 +1,146 @@
+package contract_test
+
+import (
+	"strings"
+
+	"github.com/goadesign/goa/middleware/contract"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// swaggerJSON is the Swagger specification used by the tests.
+const swaggerJSON = `{
+	"swagger": "2.0",
+	"basePath": "/api",
+	"paths": {
+		"/bottles": {
+			"get": {
+				"parameters": [
+					{"name": "limit", "in": "query", "type": "integer", "minimum": 1, "maximum": 100},
+					{"name": "tags", "in": "query", "type": "array", "items": {"type": "string"}, "collectionFormat": "csv"},
+					{"$ref": "#/parameters/Version"}
+				],
+				"responses": {"200": {"schema": {"type": "array", "items": {"$ref": "#/definitions/Bottle"}}}}
+			},
+			"post": {
+				"parameters": [
+					{"name": "payload", "in": "body", "required": true, "schema": {"$ref": "#/definitions/BottlePayload"}}
+				],
+				"responses": {
+					"201": {"description": "Created"},
+					"default": {"$ref": "#/responses/Error"}
+				}
+			}
+		},
+		"/bottles/{id}": {
+			"parameters": [
+				{"name": "id", "in": "path", "required": true, "type": "integer"}
+			],
+			"get": {
+				"responses": {"200": {"schema": {"$ref": "#/definitions/Bottle"}}}
+			}
+		},
+		"/bottles/latest": {
+			"get": {
+				"x-extension": true,
+				"responses": {"200": {"schema": {"$ref": "#/definitions/Bottle"}}}
+			}
+		}
+	},
+	"parameters": {
+		"Version": {"name": "X-Version", "in": "header", "required": true, "type": "string", "enum": ["v1", "v2"]}
+	},
+	"responses": {
+		"Error": {"schema": {"type": "object", "required": ["message"], "properties": {"message": {"type": "string"}}}}
+	},
+	"definitions": {
+		"Bottle": {
+			"type": "object",
+			"required": ["id", "name"],
+			"properties": {
+				"id": {"type": "integer"},
+				"name": {"type": "string", "minLength": 2}
+			}
+		},
+		"BottlePayload": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "minLength": 2, "maxLength": 20},
+				"vintage": {"type": "integer", "minimum": 1900},
+				"color": {"type": "string", "enum": ["red", "white"]},
+				"email": {"type": "string", "format": "email"},
+				"code": {"type": "string", "pattern": "^[A-Z]+$"},
+				"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+				"origin": {"$ref": "#/definitions/Origin"}
+			}
+		},
+		"Origin": {
+			"type": "object",
+			"required": ["country"],
+			"properties": {"country": {"type": "string"}}
+		}
+	}
+}`
+
+var _ = Describe("LoadSwagger", func() {
+	var doc string
+	var spec *contract.Spec
+	var err error
+
+	BeforeEach(func() {
+		doc = swaggerJSON
+	})
+
+	JustBeforeEach(func() {
+		spec, err = contract.LoadSwagger(strings.NewReader(doc))
+	})
+
+	It("loads the specification", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(spec).ShouldNot(BeNil())
+	})
+
+	Context("with an unsupported version", func() {
+		BeforeEach(func() {
+			doc = `{"swagger": "3.0", "paths": {}}`
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("unsupported swagger version"))
+		})
+	})
+
+	Context("with an invalid document", func() {
+		BeforeEach(func() {
+			doc = `{"swagger": `
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an unknown parameter reference", func() {
+		BeforeEach(func() {
+			doc = `{"swagger": "2.0", "paths": {"/": {"get": {"parameters": [{"$ref": "#/parameters/Unknown"}]}}}}`
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("unknown parameter"))
+		})
+	})
+
+	Context("with an invalid pattern", func() {
+		BeforeEach(func() {
+			doc = `{"swagger": "2.0", "paths": {"/": {"get": {"parameters": [{"name": "q", "in": "query", "type": "string", "pattern": "["}]}}}}`
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("invalid pattern"))
+		})
+	})
+})
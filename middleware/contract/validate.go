@@ -0,0 +1,341 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goadesign/goa"
+)
+
+// formats lists the formats validated by the middleware, the other formats (e.g. "int64") are
+// ignored.
+var formats = map[string]bool{
+	string(goa.FormatDate):     true,
+	string(goa.FormatDateTime): true,
+	string(goa.FormatUUID):     true,
+	goa.FormatEmail:            true,
+	goa.FormatHostname:         true,
+	goa.FormatIPv4:             true,
+	goa.FormatIPv6:             true,
+	goa.FormatIP:               true,
+	goa.FormatURI:              true,
+	goa.FormatMAC:              true,
+	goa.FormatCIDR:             true,
+	goa.FormatRegexp:           true,
+	goa.FormatRFC1123:          true,
+}
+
+// validateParams validates the path, query string and header parameters of the request.
+func (s *Spec) validateParams(o *operation, pathParams map[string]string, req *http.Request) error {
+	var err error
+	query := req.URL.Query()
+	for _, p := range o.params {
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathParams[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = req.Header[http.CanonicalHeaderKey(p.Name)]
+		}
+		if len(values) == 0 {
+			if p.Required {
+				if p.In == "header" {
+					err = goa.MergeErrors(err, goa.MissingHeaderError(p.Name))
+				} else {
+					err = goa.MergeErrors(err, goa.MissingParamError(p.Name))
+				}
+			}
+			continue
+		}
+		err = goa.MergeErrors(err, s.validateParam(p, values))
+	}
+	return err
+}
+
+// validateParam coerces the raw parameter values to the parameter type and validates them.
+func (s *Spec) validateParam(p *parameter, values []string) error {
+	sc := &p.schema
+	if sc.Type != "array" {
+		val, err := coerce(p.Name, values[0], sc)
+		if err != nil {
+			return err
+		}
+		return s.validate(p.Name, val, sc)
+	}
+	if p.CollectionFormat != "multi" {
+		values = split(values[0], p.CollectionFormat)
+	}
+	arr := make([]interface{}, len(values))
+	for i, v := range values {
+		var items *schema
+		if sc.Items != nil {
+			items = s.resolve(sc.Items)
+		}
+		val, err := coerce(p.Name, v, items)
+		if err != nil {
+			return err
+		}
+		arr[i] = val
+	}
+	return s.validate(p.Name, arr, sc)
+}
+
+// coerce converts the raw parameter value into the value the schema describes.
+func coerce(name, raw string, sc *schema) (interface{}, error) {
+	if sc == nil {
+		return raw, nil
+	}
+	switch sc.Type {
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, goa.InvalidParamTypeError(name, raw, "boolean")
+		}
+		return b, nil
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, goa.InvalidParamTypeError(name, raw, "integer")
+		}
+		return json.Number(raw), nil
+	case "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, goa.InvalidParamTypeError(name, raw, "number")
+		}
+		return json.Number(raw), nil
+	}
+	return raw, nil
+}
+
+// split splits an array parameter value according to the Swagger collection format.
+func split(raw, format string) []string {
+	sep := ","
+	switch format {
+	case "ssv":
+		sep = " "
+	case "tsv":
+		sep = "\t"
+	case "pipes":
+		sep = "|"
+	}
+	return strings.Split(raw, sep)
+}
+
+// validate checks that val is described by sc. val is a value decoded from JSON with numbers
+// represented as json.Number. ctx is the name of the value used in error messages.
+func (s *Spec) validate(ctx string, val interface{}, sc *schema) error {
+	sc = s.resolve(sc)
+	if sc == nil || val == nil {
+		return nil
+	}
+	switch sc.Type {
+	case "boolean":
+		if _, ok := val.(bool); !ok {
+			return goa.InvalidAttributeTypeError(ctx, display(val), "boolean")
+		}
+	case "integer":
+		n, ok := val.(json.Number)
+		if !ok {
+			return goa.InvalidAttributeTypeError(ctx, display(val), "integer")
+		}
+		if _, err := n.Int64(); err != nil {
+			return goa.InvalidAttributeTypeError(ctx, display(val), "integer")
+		}
+	case "number":
+		if _, ok := val.(json.Number); !ok {
+			return goa.InvalidAttributeTypeError(ctx, display(val), "number")
+		}
+	case "string":
+		if _, ok := val.(string); !ok {
+			return goa.InvalidAttributeTypeError(ctx, display(val), "string")
+		}
+	case "array":
+		if _, ok := val.([]interface{}); !ok {
+			return goa.InvalidAttributeTypeError(ctx, display(val), "array")
+		}
+	case "object":
+		if _, ok := val.(map[string]interface{}); !ok {
+			return goa.InvalidAttributeTypeError(ctx, display(val), "object")
+		}
+	}
+
+	var err error
+	if len(sc.Enum) > 0 {
+		found := false
+		for _, e := range sc.Enum {
+			if equal(val, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError(ctx, display(val), sc.Enum))
+		}
+	}
+	switch v := val.(type) {
+	case string:
+		err = goa.MergeErrors(err, s.validateString(ctx, v, sc))
+	case json.Number:
+		f, _ := v.Float64()
+		if sc.Minimum != nil && f < *sc.Minimum {
+			err = goa.MergeErrors(err, goa.InvalidRangeError(ctx, display(v), *sc.Minimum, true))
+		}
+		if sc.Maximum != nil && f > *sc.Maximum {
+			err = goa.MergeErrors(err, goa.InvalidRangeError(ctx, display(v), *sc.Maximum, false))
+		}
+	case []interface{}:
+		if sc.MinItems != nil && len(v) < *sc.MinItems {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(ctx, v, len(v), *sc.MinItems, true))
+		}
+		if sc.MaxItems != nil && len(v) > *sc.MaxItems {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(ctx, v, len(v), *sc.MaxItems, false))
+		}
+		for i, e := range v {
+			err = goa.MergeErrors(err, s.validate(fmt.Sprintf("%s[%d]", ctx, i), e, sc.Items))
+		}
+	case map[string]interface{}:
+		for _, r := range sc.Required {
+			if e, ok := v[r]; !ok || e == nil {
+				err = goa.MergeErrors(err, goa.MissingAttributeError(ctx, r))
+			}
+		}
+		for n, e := range v {
+			if child, ok := sc.Properties[n]; ok {
+				err = goa.MergeErrors(err, s.validate(ctx+"."+n, e, child))
+			}
+		}
+	}
+	return err
+}
+
+// validateString runs the string validations.
+func (s *Spec) validateString(ctx, v string, sc *schema) error {
+	var err error
+	if sc.Format != "" && formats[sc.Format] {
+		if ferr := goa.ValidateFormat(goa.Format(sc.Format), v); ferr != nil {
+			err = goa.MergeErrors(err, goa.InvalidFormatError(ctx, v, goa.Format(sc.Format), ferr))
+		}
+	}
+	if sc.Pattern != "" {
+		if re, ok := s.patterns[sc.Pattern]; ok && !re.MatchString(v) {
+			err = goa.MergeErrors(err, goa.InvalidPatternError(ctx, v, sc.Pattern))
+		}
+	}
+	if sc.MinLength != nil || sc.MaxLength != nil {
+		ln := utf8.RuneCountInString(v)
+		if sc.MinLength != nil && ln < *sc.MinLength {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(ctx, v, ln, *sc.MinLength, true))
+		}
+		if sc.MaxLength != nil && ln > *sc.MaxLength {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(ctx, v, ln, *sc.MaxLength, false))
+		}
+	}
+	return err
+}
+
+// compilePatterns compiles the regular expressions used by the schema pattern validations.
+func (s *Spec) compilePatterns() error {
+	s.patterns = make(map[string]*regexp.Regexp)
+	seen := make(map[*schema]bool)
+	var walk func(sc *schema) error
+	walk = func(sc *schema) error {
+		if sc == nil || seen[sc] {
+			return nil
+		}
+		seen[sc] = true
+		if sc.Pattern != "" {
+			if _, ok := s.patterns[sc.Pattern]; !ok {
+				re, err := regexp.Compile(sc.Pattern)
+				if err != nil {
+					return fmt.Errorf("invalid pattern %q: %s", sc.Pattern, err)
+				}
+				s.patterns[sc.Pattern] = re
+			}
+		}
+		if err := walk(sc.Items); err != nil {
+			return err
+		}
+		for _, p := range sc.Properties {
+			if err := walk(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, d := range s.definitions {
+		if err := walk(d); err != nil {
+			return err
+		}
+	}
+	for _, o := range s.operations {
+		for _, p := range o.params {
+			if err := walk(&p.schema); err != nil {
+				return err
+			}
+		}
+		if err := walk(o.body); err != nil {
+			return err
+		}
+		for _, r := range o.responses {
+			if err := walk(r.Schema); err != nil {
+				return err
+			}
+		}
+		if o.defaultResponse != nil {
+			if err := walk(o.defaultResponse.Schema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// equal compares a value decoded from JSON with a value listed in an enum.
+func equal(val, e interface{}) bool {
+	if n, ok := val.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return false
+		}
+		switch ev := e.(type) {
+		case json.Number:
+			ef, err := ev.Float64()
+			return err == nil && ef == f
+		case float64:
+			return ev == f
+		case float32:
+			return float64(ev) == f
+		case int:
+			return float64(ev) == f
+		case int64:
+			return float64(ev) == f
+		}
+		return false
+	}
+	return reflect.DeepEqual(val, e)
+}
+
+// display converts json.Number values into int64 or float64 values so that error messages
+// show plain numbers.
+func display(val interface{}) interface{} {
+	n, ok := val.(json.Number)
+	if !ok {
+		return val
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return string(n)
+}
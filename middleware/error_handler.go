@@ -22,6 +22,12 @@ func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 			if e == nil {
 				return nil
 			}
+			if resp := goa.ContextResponse(ctx); resp != nil && resp.Written() {
+				// The response was already sent, for example the action opened an event
+				// stream, the error cannot be reported to the client anymore.
+				goa.LogError(ctx, "uncaught error", "err", fmt.Sprintf("%+v", e))
+				return nil
+			}
 			cause := cause(e)
 			status := http.StatusInternalServerError
 			var respBody interface{}
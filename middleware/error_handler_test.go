@@ -163,6 +163,27 @@ var _ = Describe("ErrorHandler", func() {
 		})
 	})
 
+	Context("with a handler returning an error after writing the response", func() {
+		var logger *testLogger
+
+		BeforeEach(func() {
+			logger = new(testLogger)
+			service = newService(logger)
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				resp := goa.ContextResponse(ctx)
+				resp.WriteHeader(200)
+				resp.Write([]byte("partial"))
+				return errors.New("boom")
+			}
+		})
+
+		It("logs the error and keeps the response", func() {
+			Ω(rw.Status).Should(Equal(200))
+			Ω(string(rw.Body)).Should(Equal("partial"))
+			Ω(logger.ErrorEntries).Should(HaveLen(1))
+		})
+	})
+
 	Context("with a handler returning a pkg errors wrapped error", func() {
 		var wrappedError error
 		var logger *testLogger
@@ -0,0 +1,34 @@
+/*
+Package idempotency provides a middleware that makes retries of unsafe requests safe.
+
+Clients send a unique key with the Idempotency-Key request header. The middleware stores the
+status, headers and body of the response to the first request made with a given key and sends
+them back to the requests that reuse the key instead of invoking the handler again, these
+responses have the Idempotent-Replayed header set. A request that reuses a key while the first
+request is still in progress gets a goa.ErrConflict error (HTTP status 409) and a request that
+reuses a key with a different method, path or payload gets a goa.ErrUnprocessableEntity error
+(HTTP status 422). Requests that do not set the header are handled normally.
+
+The responses are kept in a Store, the package provides an in-memory implementation suitable for
+services running a single instance. Services running multiple instances should use a Store
+backed by a shared database.
+
+The middleware is typically mounted by the code generated by goagen for the actions that use
+Idempotent in the design:
+
+	Action("create", func() {
+		Routing(POST(""))
+		Payload(CreatePayload)
+		Idempotent()
+	})
+
+The generated code uses DefaultStore which must be set before the controllers are mounted to
+use a different store:
+
+	idempotency.DefaultStore = store
+
+The middleware can also be mounted on the service or controllers directly:
+
+	service.Use(idempotency.New(idempotency.UseStore(store)))
+*/
+package idempotency
@@ -0,0 +1,154 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	// KeyHeader is the name of the request header containing the idempotency key.
+	KeyHeader = "Idempotency-Key"
+
+	// ReplayedHeader is the name of the response header set to "true" on the responses sent
+	// back from the store.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// MaxKeyLength is the maximum length of idempotency keys.
+	MaxKeyLength = 255
+)
+
+// DefaultStore is the store used by the middlewares created without the UseStore option. It
+// keeps the responses in memory for 24 hours.
+var DefaultStore Store = NewMemoryStore(24 * time.Hour)
+
+type (
+	// recorder wraps an http.ResponseWriter and records the response.
+	recorder struct {
+		http.ResponseWriter
+		status int
+		header http.Header
+		body   bytes.Buffer
+	}
+)
+
+// New returns a middleware that replays the responses of requests made with the same
+// Idempotency-Key header value. Usage:
+//
+//	service.Use(idempotency.New(idempotency.UseStore(store)))
+//
+// The code generated by goagen mounts the middleware for the actions that use Idempotent in the
+// design. Only the responses written by the handler with a status lower than 500 are stored,
+// the key is released when the handler returns an error so that the request can be retried.
+func New(opts ...Option) goa.Middleware {
+	o := newOptions(opts)
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := req.Header.Get(KeyHeader)
+			resp := goa.ContextResponse(ctx)
+			if key == "" || resp == nil {
+				return h(ctx, rw, req)
+			}
+			if len(key) > MaxKeyLength {
+				return goa.ErrBadRequest("idempotency key is too long", "max_length", MaxKeyLength)
+			}
+			fp := fingerprint(ctx, req)
+			rec, err := o.store.Reserve(ctx, key, fp)
+			if err != nil {
+				return err
+			}
+			if rec != nil {
+				switch {
+				case rec.Fingerprint != fp:
+					return goa.ErrUnprocessableEntity("idempotency key was used with a different request", "key", key)
+				case !rec.Done:
+					return goa.ErrConflict("a request with the same idempotency key is in progress", "key", key)
+				}
+				replay(rw, rec)
+				return nil
+			}
+
+			r := &recorder{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(r)
+			err = h(ctx, rw, req)
+			resp.SwitchWriter(r.ResponseWriter)
+			if err != nil || r.status == 0 || r.status >= 500 {
+				if rerr := o.store.Release(ctx, key); rerr != nil {
+					goa.LogError(ctx, "idempotency", "key", key, "err", rerr)
+				}
+				return err
+			}
+			rec = &Record{
+				Fingerprint: fp,
+				Done:        true,
+				Status:      r.status,
+				Header:      r.header,
+				Body:        r.body.Bytes(),
+			}
+			if err := o.store.Save(ctx, key, rec); err != nil {
+				goa.LogError(ctx, "idempotency", "key", key, "err", err)
+			}
+			return nil
+		}
+	}
+}
+
+// WriteHeader records the status code and the headers.
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.header = cloneHeader(r.Header())
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body.
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// replay writes the response stored in rec. The headers already set by the middlewares that run
+// before this one are not overridden.
+func replay(rw http.ResponseWriter, rec *Record) {
+	header := rw.Header()
+	for k, v := range rec.Header {
+		if _, ok := header[k]; !ok {
+			header[k] = v
+		}
+	}
+	header.Set(ReplayedHeader, "true")
+	rw.WriteHeader(rec.Status)
+	rw.Write(rec.Body)
+}
+
+// fingerprint returns a hash of the request method, path, query string and payload.
+func fingerprint(ctx context.Context, req *http.Request) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s?%s\n", req.Method, req.URL.Path, req.URL.RawQuery)
+	if r := goa.ContextRequest(ctx); r != nil && r.Payload != nil {
+		if err := json.NewEncoder(h).Encode(r.Payload); err != nil {
+			fmt.Fprintf(h, "%#v", r.Payload)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cloneHeader returns a copy of h.
+func cloneHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
@@ -0,0 +1,149 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/idempotency"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type payload struct {
+	Name string `json:"name"`
+}
+
+var _ = Describe("Middleware", func() {
+	var store *idempotency.MemoryStore
+	var handler goa.Handler
+	var calls int32
+	var key string
+
+	var rw *httptest.ResponseRecorder
+
+	dispatch := func(method, path string, p interface{}) error {
+		req, _ := http.NewRequest(method, "http://example.com"+path, nil)
+		if key != "" {
+			req.Header.Set(idempotency.KeyHeader, key)
+		}
+		rw = httptest.NewRecorder()
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		goa.ContextRequest(ctx).Payload = p
+		h := idempotency.New(idempotency.UseStore(store))(handler)
+		return h(ctx, goa.ContextResponse(ctx), req)
+	}
+
+	BeforeEach(func() {
+		store = idempotency.NewMemoryStore(time.Minute)
+		calls = 0
+		key = "abc"
+		handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			n := atomic.AddInt32(&calls, 1)
+			rw.Header().Set("Location", "/bottles/1")
+			rw.WriteHeader(201)
+			rw.Write([]byte{byte('0' + n)})
+			return nil
+		}
+	})
+
+	It("replays the response to retries", func() {
+		Ω(dispatch("POST", "/bottles", &payload{Name: "a"})).ShouldNot(HaveOccurred())
+		Ω(rw.Code).Should(Equal(201))
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(BeEmpty())
+
+		Ω(dispatch("POST", "/bottles", &payload{Name: "a"})).ShouldNot(HaveOccurred())
+		Ω(calls).Should(BeEquivalentTo(1))
+		Ω(rw.Code).Should(Equal(201))
+		Ω(rw.Body.String()).Should(Equal("1"))
+		Ω(rw.Header().Get("Location")).Should(Equal("/bottles/1"))
+		Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(Equal("true"))
+	})
+
+	It("rejects retries with a different payload", func() {
+		Ω(dispatch("POST", "/bottles", &payload{Name: "a"})).ShouldNot(HaveOccurred())
+		err := dispatch("POST", "/bottles", &payload{Name: "b"})
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(422))
+		Ω(calls).Should(BeEquivalentTo(1))
+	})
+
+	It("rejects retries with a different path", func() {
+		Ω(dispatch("POST", "/bottles", nil)).ShouldNot(HaveOccurred())
+		err := dispatch("POST", "/accounts", nil)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(422))
+	})
+
+	It("rejects keys that are too long", func() {
+		key = strings.Repeat("a", idempotency.MaxKeyLength+1)
+		err := dispatch("POST", "/bottles", nil)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+	})
+
+	Context("without key", func() {
+		BeforeEach(func() {
+			key = ""
+		})
+
+		It("invokes the handler for each request", func() {
+			Ω(dispatch("POST", "/bottles", nil)).ShouldNot(HaveOccurred())
+			Ω(dispatch("POST", "/bottles", nil)).ShouldNot(HaveOccurred())
+			Ω(calls).Should(BeEquivalentTo(2))
+		})
+	})
+
+	Context("with a request in progress", func() {
+		var started, release chan struct{}
+
+		BeforeEach(func() {
+			started, release = make(chan struct{}), make(chan struct{})
+			h := handler
+			handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				close(started)
+				<-release
+				return h(ctx, rw, req)
+			}
+		})
+
+		It("rejects the retries", func() {
+			done := make(chan error, 1)
+			go func() { done <- dispatch("POST", "/bottles", nil) }()
+			<-started
+			req, _ := http.NewRequest("POST", "http://example.com/bottles", nil)
+			req.Header.Set(idempotency.KeyHeader, key)
+			w := httptest.NewRecorder()
+			ctx := goa.NewContext(context.Background(), w, req, nil)
+			err := idempotency.New(idempotency.UseStore(store))(handler)(ctx, goa.ContextResponse(ctx), req)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(409))
+			close(release)
+			Ω(<-done).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with a failing handler", func() {
+		BeforeEach(func() {
+			h := handler
+			handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if atomic.LoadInt32(&calls) == 0 {
+					atomic.AddInt32(&calls, 1)
+					return goa.ErrInternal("boom")
+				}
+				return h(ctx, rw, req)
+			}
+		})
+
+		It("lets the request be retried", func() {
+			Ω(dispatch("POST", "/bottles", nil)).Should(HaveOccurred())
+			Ω(dispatch("POST", "/bottles", nil)).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(201))
+			Ω(rw.Header().Get(idempotency.ReplayedHeader)).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package idempotency
+
+type (
+	// Option is a constructor option that makes it possible to customize the middleware.
+	Option func(*options) *options
+
+	// options is the struct storing all the options.
+	options struct {
+		store Store
+	}
+)
+
+// UseStore sets the store used to keep the responses. Defaults to DefaultStore.
+func UseStore(s Store) Option {
+	if s == nil {
+		panic("store cannot be nil")
+	}
+	return func(o *options) *options {
+		o.store = s
+		return o
+	}
+}
+
+// newOptions initializes the options with their default values and applies opts.
+func newOptions(opts []Option) *options {
+	o := &options{store: DefaultStore}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	return o
+}
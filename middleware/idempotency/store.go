@@ -0,0 +1,113 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// Record is the state of the request made with an idempotency key.
+	Record struct {
+		// Fingerprint identifies the request method, path and payload.
+		Fingerprint string
+		// Done is false while the request is being handled and true once its response is
+		// stored.
+		Done bool
+		// Status is the response status code.
+		Status int
+		// Header contains the response headers.
+		Header http.Header
+		// Body is the response body.
+		Body []byte
+	}
+
+	// Store persists the records of the requests made with idempotency keys. Implementations
+	// must be safe for concurrent use.
+	Store interface {
+		// Reserve creates an in progress record with the given fingerprint for key if there
+		// is none and returns nil. It returns the existing record otherwise. The check and
+		// the creation must be atomic.
+		Reserve(ctx context.Context, key, fingerprint string) (*Record, error)
+		// Save stores the record of the completed request made with key.
+		Save(ctx context.Context, key string, r *Record) error
+		// Release deletes the record of key so that the request may be retried. It is
+		// called when the request fails.
+		Release(ctx context.Context, key string) error
+	}
+
+	// MemoryStore is a Store that keeps the records in memory for a limited time.
+	MemoryStore struct {
+		ttl     time.Duration
+		now     func() time.Time
+		lock    sync.Mutex
+		records map[string]*memoryRecord
+		swept   time.Time
+	}
+
+	// memoryRecord is a record kept by MemoryStore.
+	memoryRecord struct {
+		record  Record
+		expires time.Time
+	}
+)
+
+// NewMemoryStore returns a store that keeps the records in memory for the duration ttl after
+// which the keys may be reused. It panics if ttl is not greater than 0.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		panic("ttl must be greater than 0")
+	}
+	return &MemoryStore{
+		ttl:     ttl,
+		now:     time.Now,
+		records: make(map[string]*memoryRecord),
+	}
+}
+
+// Reserve implements Store.
+func (s *MemoryStore) Reserve(_ context.Context, key, fingerprint string) (*Record, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	s.sweep(now)
+	if r, ok := s.records[key]; ok && now.Before(r.expires) {
+		rec := r.record
+		return &rec, nil
+	}
+	s.records[key] = &memoryRecord{
+		record:  Record{Fingerprint: fingerprint},
+		expires: now.Add(s.ttl),
+	}
+	return nil, nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(_ context.Context, key string, r *Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.records[key] = &memoryRecord{record: *r, expires: s.now().Add(s.ttl)}
+	return nil
+}
+
+// Release implements Store.
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// sweep deletes the expired records, at most once per ttl.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < s.ttl {
+		return
+	}
+	s.swept = now
+	for k, r := range s.records {
+		if !now.Before(r.expires) {
+			delete(s.records, k)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package idempotency_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/goadesign/goa/middleware/idempotency"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryStore", func() {
+	var ttl time.Duration
+	var store *idempotency.MemoryStore
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ttl = time.Minute
+		ctx = context.Background()
+	})
+
+	JustBeforeEach(func() {
+		store = idempotency.NewMemoryStore(ttl)
+	})
+
+	It("reserves keys", func() {
+		Ω(store.Reserve(ctx, "a", "fp")).Should(BeNil())
+		rec, err := store.Reserve(ctx, "a", "other")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rec).Should(Equal(&idempotency.Record{Fingerprint: "fp"}))
+		Ω(store.Reserve(ctx, "b", "fp")).Should(BeNil())
+	})
+
+	It("saves records", func() {
+		store.Reserve(ctx, "a", "fp")
+		saved := &idempotency.Record{Fingerprint: "fp", Done: true, Status: 201, Body: []byte("ok")}
+		Ω(store.Save(ctx, "a", saved)).ShouldNot(HaveOccurred())
+		Ω(store.Reserve(ctx, "a", "fp")).Should(Equal(saved))
+	})
+
+	It("releases keys", func() {
+		store.Reserve(ctx, "a", "fp")
+		Ω(store.Release(ctx, "a")).ShouldNot(HaveOccurred())
+		Ω(store.Reserve(ctx, "a", "fp")).Should(BeNil())
+	})
+
+	Context("with expired records", func() {
+		BeforeEach(func() {
+			ttl = time.Millisecond
+		})
+
+		It("reuses the keys", func() {
+			store.Reserve(ctx, "a", "fp")
+			time.Sleep(2 * ttl)
+			Ω(store.Reserve(ctx, "a", "other")).Should(BeNil())
+		})
+	})
+})
@@ -1,32 +1,93 @@
 package middleware
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/goadesign/goa"
 
 	"context"
 )
 
+type (
+	// LogOption is a constructor option that makes it possible to customize the LogRequest
+	// and LogResponse middlewares.
+	LogOption func(*logOptions) *logOptions
+
+	// logOptions is the struct storing all the options.
+	logOptions struct {
+		suppressed    map[string]struct{}
+		paths         []string
+		maxBodyLength int
+	}
+)
+
+// SensitiveHeaders is a constructor option that hides the values of the given request headers.
+// Header names are case insensitive.
+func SensitiveHeaders(names ...string) LogOption {
+	return func(o *logOptions) *logOptions {
+		if o.suppressed == nil && len(names) > 0 {
+			o.suppressed = make(map[string]struct{}, len(names))
+		}
+		for _, n := range names {
+			o.suppressed[strings.ToLower(n)] = struct{}{}
+		}
+		return o
+	}
+}
+
+// RedactPaths is a constructor option that hides the values found at the given paths of the
+// request payloads and of the JSON response bodies. See goa.RedactJSON for the path syntax.
+// Payloads that implement goa.Redactor are redacted prior to applying the paths.
+func RedactPaths(paths ...string) LogOption {
+	return func(o *logOptions) *logOptions {
+		o.paths = append(o.paths, paths...)
+		return o
+	}
+}
+
+// MaxBodyLength is a constructor option that truncates the logged payloads and response bodies
+// to n bytes. There is no limit by default.
+func MaxBodyLength(n int) LogOption {
+	if n < 0 {
+		panic("max body length cannot be negative")
+	}
+	return func(o *logOptions) *logOptions {
+		o.maxBodyLength = n
+		return o
+	}
+}
+
 // LogRequest creates a request logger middleware.
 // This middleware is aware of the RequestID middleware and if registered after it leverages the
 // request ID for logging.
-// If verbose is true then the middlware logs the request and response bodies.
+// The middleware also logs the request headers, parameters and payload. These details are
+// logged at the info level if verbose is true, otherwise they are logged at the debug level
+// when the logger adapter implements goa.LeveledLogAdapter.
 func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
-	var suppressed map[string]struct{}
-	if len(sensitiveHeaders) > 0 {
-		suppressed = make(map[string]struct{}, len(sensitiveHeaders))
-		for _, sh := range sensitiveHeaders {
-			suppressed[strings.ToLower(sh)] = struct{}{}
-		}
+	return LogRequestWithOptions(verbose, SensitiveHeaders(sensitiveHeaders...))
+}
+
+// LogRequestWithOptions creates a request logger middleware configured with the given options.
+// See LogRequest.
+func LogRequestWithOptions(verbose bool, opts ...LogOption) goa.Middleware {
+	logDetails := goa.LogDebug
+	if verbose {
+		logDetails = goa.LogInfo
+	}
+	o := new(logOptions)
+	for _, opt := range opts {
+		o = opt(o)
 	}
 
 	return func(h goa.Handler) goa.Handler {
@@ -40,7 +101,7 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 			r := goa.ContextRequest(ctx)
 			goa.LogInfo(ctx, "started", r.Method, r.URL.String(), "from", from(req),
 				"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx))
-			if verbose {
+			if verbose || goa.DebugEnabled(ctx) {
 				if len(r.Header) > 0 {
 					logCtx := make([]interface{}, 2*len(r.Header))
 					i := 0
@@ -54,14 +115,14 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 					for _, k := range keys {
 						v := r.Header[k]
 						logCtx[i] = k
-						if _, ok := suppressed[strings.ToLower(k)]; ok {
-							logCtx[i+1] = "<hidden>"
+						if _, ok := o.suppressed[strings.ToLower(k)]; ok {
+							logCtx[i+1] = goa.HiddenLogValue
 						} else {
 							logCtx[i+1] = interface{}(strings.Join(v, ", "))
 						}
 						i = i + 2
 					}
-					goa.LogInfo(ctx, "headers", logCtx...)
+					logDetails(ctx, "headers", logCtx...)
 				}
 				if len(r.Params) > 0 {
 					logCtx := make([]interface{}, 2*len(r.Params))
@@ -71,25 +132,29 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 						logCtx[i+1] = interface{}(strings.Join(v, ", "))
 						i = i + 2
 					}
-					goa.LogInfo(ctx, "params", logCtx...)
+					logDetails(ctx, "params", logCtx...)
 				}
 				if r.ContentLength > 0 {
-					if mp, ok := r.Payload.(map[string]interface{}); ok {
+					payload := o.redact(r.Payload)
+					if mp, ok := payload.(map[string]interface{}); ok {
 						logCtx := make([]interface{}, 2*len(mp))
 						i := 0
 						for k, v := range mp {
 							logCtx[i] = k
+							if s, ok := v.(string); ok {
+								v = o.truncate(s)
+							}
 							logCtx[i+1] = interface{}(v)
 							i = i + 2
 						}
-						goa.LogInfo(ctx, "payload", logCtx...)
+						logDetails(ctx, "payload", logCtx...)
 					} else {
 						// Not the most efficient but this is used for debugging
-						js, err := json.Marshal(r.Payload)
+						js, err := marshalLog(payload)
 						if err != nil {
 							js = []byte("<invalid JSON>")
 						}
-						goa.LogInfo(ctx, "payload", "raw", string(js))
+						logDetails(ctx, "payload", "raw", o.truncate(string(js)))
 					}
 				}
 			}
@@ -109,6 +174,41 @@ func LogRequest(verbose bool, sensitiveHeaders ...string) goa.Middleware {
 	}
 }
 
+// redact returns the representation of the given payload that is safe to log.
+func (o *logOptions) redact(payload interface{}) interface{} {
+	if r, ok := payload.(goa.Redactor); ok {
+		payload = r.Redact()
+	}
+	if len(o.paths) > 0 {
+		payload = goa.RedactJSON(payload, o.paths...)
+	}
+	return payload
+}
+
+// truncate truncates s to the maximum body length if any.
+func (o *logOptions) truncate(s string) string {
+	if o.maxBodyLength == 0 || len(s) <= o.maxBodyLength {
+		return s
+	}
+	n := o.maxBodyLength
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s... (%d bytes)", s[:n], len(s))
+}
+
+// marshalLog returns the JSON representation of v without escaping HTML characters so that
+// redacted values remain readable.
+func marshalLog(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
 // shortID produces a "unique" 6 bytes long string.
 // Do not use as a reliable way to get unique IDs, instead use for things like logging.
 func shortID() string {
@@ -104,6 +104,65 @@ var _ = Describe("LogRequest", func() {
 		Ω(logger.InfoEntries[1].Data[13]).Should(Equal("<unknown>"))
 	})
 
+	Context("with a leveled logger", func() {
+		var leveled *testLeveledLogger
+
+		BeforeEach(func() {
+			leveled = new(testLeveledLogger)
+			ctx = goa.WithLogger(ctx, leveled)
+		})
+
+		It("logs the details at the debug level", func() {
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return service.Send(ctx, 200, "ok")
+			}
+			lg := middleware.LogRequest(false)(h)
+			Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(leveled.InfoEntries).Should(HaveLen(2))
+			Ω(leveled.InfoEntries[0].Msg).Should(Equal("started"))
+			Ω(leveled.InfoEntries[1].Msg).Should(Equal("completed"))
+			Ω(leveled.DebugEntries).Should(HaveLen(2))
+			Ω(leveled.DebugEntries[0].Msg).Should(Equal("params"))
+			Ω(leveled.DebugEntries[1].Msg).Should(Equal("payload"))
+			Ω(leveled.DebugEntries[1].Data[3]).Should(Equal(42))
+		})
+
+		It("logs the details at the info level when verbose", func() {
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return service.Send(ctx, 200, "ok")
+			}
+			lg := middleware.LogRequest(true)(h)
+			Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(leveled.InfoEntries).Should(HaveLen(4))
+			Ω(leveled.DebugEntries).Should(BeEmpty())
+		})
+	})
+
+	Context("with sensitive payload data", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, "ok")
+		}
+
+		It("redacts payloads that implement Redactor", func() {
+			goa.ContextRequest(ctx).Payload = &credentials{User: "joe", Password: "secret"}
+			lg := middleware.LogRequest(true)(h)
+			Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(logger.InfoEntries[2].Msg).Should(Equal("payload"))
+			Ω(logger.InfoEntries[2].Data).Should(ContainElement("joe"))
+			Ω(logger.InfoEntries[2].Data).ShouldNot(ContainElement("secret"))
+			Ω(logger.InfoEntries[2].Data).Should(ContainElement(goa.HiddenLogValue))
+		})
+
+		It("redacts the given paths and truncates", func() {
+			goa.ContextRequest(ctx).Payload = map[string]interface{}{"token": "secret", "bio": "long biography"}
+			lg := middleware.LogRequestWithOptions(true, middleware.RedactPaths("token"), middleware.MaxBodyLength(8))(h)
+			Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(logger.InfoEntries[2].Msg).Should(Equal("payload"))
+			Ω(logger.InfoEntries[2].Data).Should(ContainElement(goa.HiddenLogValue))
+			Ω(logger.InfoEntries[2].Data).Should(ContainElement("long bio... (14 bytes)"))
+		})
+	})
+
 	It("hides secret headers", func() {
 		// Add Action name to the context to make sure we log it properly.
 		ctx = goa.WithAction(ctx, "goo")
@@ -125,3 +184,13 @@ var _ = Describe("LogRequest", func() {
 
 	})
 })
+
+// credentials is a payload that implements goa.Redactor.
+type credentials struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func (c *credentials) Redact() interface{} {
+	return goa.RedactJSON(c, "password")
+}
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/goadesign/goa"
 
@@ -13,18 +15,32 @@ import (
 // are logged elsewhere (i.e. by the LogRequest middleware).
 type loggingResponseWriter struct {
 	http.ResponseWriter
-	ctx context.Context
+	ctx  context.Context
+	opts *logOptions
 }
 
 // Write will write raw data to logger and response writer.
 func (lrw *loggingResponseWriter) Write(buf []byte) (int, error) {
-	goa.LogInfo(lrw.ctx, "response", "body", string(buf))
+	body := lrw.opts.body(lrw.Header(), buf)
+	if _, ok := goa.ContextLogger(lrw.ctx).(goa.LeveledLogAdapter); ok {
+		goa.LogDebug(lrw.ctx, "response", "body", body)
+	} else {
+		goa.LogInfo(lrw.ctx, "response", "body", body)
+	}
 	return lrw.ResponseWriter.Write(buf)
 }
 
 // LogResponse creates a response logger middleware.
 // Only Logs the raw response data without accumulating any statistics.
-func LogResponse() goa.Middleware {
+// The data is logged at the debug level if the logger adapter implements
+// goa.LeveledLogAdapter, so that it is discarded when the adapter does not log debug messages,
+// and at the info level otherwise. The RedactPaths and MaxBodyLength options apply to the logged
+// data.
+func LogResponse(opts ...LogOption) goa.Middleware {
+	o := new(logOptions)
+	for _, opt := range opts {
+		o = opt(o)
+	}
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			// chain a new logging writer to the current response writer.
@@ -33,6 +49,7 @@ func LogResponse() goa.Middleware {
 				&loggingResponseWriter{
 					ResponseWriter: resp.SwitchWriter(nil),
 					ctx:            ctx,
+					opts:           o,
 				})
 
 			// next
@@ -40,3 +57,19 @@ func LogResponse() goa.Middleware {
 		}
 	}
 }
+
+// body returns the representation of the given response body that is safe to log. Redaction
+// only applies to JSON bodies.
+func (o *logOptions) body(header http.Header, buf []byte) string {
+	if len(o.paths) > 0 && strings.Contains(header.Get("Content-Type"), "json") {
+		if !json.Valid(buf) {
+			return goa.HiddenLogValue
+		}
+		js, err := marshalLog(goa.RedactJSON(json.RawMessage(buf), o.paths...))
+		if err != nil {
+			return goa.HiddenLogValue
+		}
+		buf = js
+	}
+	return o.truncate(string(buf))
+}
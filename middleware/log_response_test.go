@@ -8,9 +8,13 @@ import (
 	"context"
 
 	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/logging/zap"
 	"github.com/goadesign/goa/middleware"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 var _ = Describe("LogResponse", func() {
@@ -47,4 +51,51 @@ var _ = Describe("LogResponse", func() {
 		Ω(logger.InfoEntries[0].Data[0]).Should(Equal("body"))
 		Ω(logger.InfoEntries[0].Data[1]).Should(Equal(responseText))
 	})
+
+	It("redacts and truncates JSON responses", func() {
+		rw = newTestResponseWriter()
+		ctx = newContext(newService(logger), rw, req, params)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			goa.ContextResponse(ctx).Header().Set("Content-Type", "application/json")
+			goa.ContextResponse(ctx).WriteHeader(200)
+			goa.ContextResponse(ctx).Write([]byte(`{"token":"secret","user":"joe"}`))
+			goa.ContextResponse(ctx).Write([]byte(`{"token":`))
+			return nil
+		}
+		lg := middleware.LogResponse(middleware.RedactPaths("token"), middleware.MaxBodyLength(24))(h)
+		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(HaveLen(2))
+		Ω(logger.InfoEntries[0].Data[1]).Should(Equal(`{"token":"<hidden>","use... (33 bytes)`))
+		Ω(logger.InfoEntries[1].Data[1]).Should(Equal(goa.HiddenLogValue))
+	})
+
+	Context("with a leveled logger", func() {
+		var logs *observer.ObservedLogs
+
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			goa.ContextResponse(ctx).WriteHeader(200)
+			goa.ContextResponse(ctx).Write([]byte(responseText))
+			return nil
+		}
+
+		withLevel := func(level zapcore.Level) {
+			var core zapcore.Core
+			core, logs = observer.New(level)
+			ctx = newContext(newService(goazap.New(zap.New(core))), rw, req, params)
+		}
+
+		It("does not log responses if debug messages are disabled", func() {
+			withLevel(zap.InfoLevel)
+			Ω(middleware.LogResponse()(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(logs.Len()).Should(BeZero())
+		})
+
+		It("logs responses at the debug level", func() {
+			withLevel(zap.DebugLevel)
+			Ω(middleware.LogResponse()(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(logs.Len()).Should(Equal(1))
+			Ω(logs.All()[0].Level).Should(Equal(zap.DebugLevel))
+			Ω(logs.All()[0].ContextMap()).Should(HaveKeyWithValue("body", responseText))
+		})
+	})
 })
@@ -50,6 +50,27 @@ func (t *testLogger) New(data ...interface{}) goa.LogAdapter {
 	return t
 }
 
+type testLeveledLogger struct {
+	testLogger
+	DebugEntries []logEntry
+	WarnEntries  []logEntry
+}
+
+func (t *testLeveledLogger) Debug(msg string, data ...interface{}) {
+	e := logEntry{msg, append(t.Context, data...)}
+	t.DebugEntries = append(t.DebugEntries, e)
+}
+
+func (t *testLeveledLogger) Warn(msg string, data ...interface{}) {
+	e := logEntry{msg, append(t.Context, data...)}
+	t.WarnEntries = append(t.WarnEntries, e)
+}
+
+func (t *testLeveledLogger) New(data ...interface{}) goa.LogAdapter {
+	t.Context = append(t.Context, data...)
+	return t
+}
+
 type testResponseWriter struct {
 	ParentHeader http.Header
 	Body         []byte
@@ -0,0 +1,25 @@
+/*
+Package ratelimit provides a middleware that throttles clients using token buckets.
+
+Each client gets a bucket that holds up to "burst" tokens and that is refilled at the rate of
+"limit" tokens per "period". Each request takes a token, requests made while the bucket is empty
+are rejected with a goa.ErrTooManyRequests error (HTTP status 429). Clients are identified by a
+KeyFunc: the package provides functions that use the client IP address, the API key or the JWT
+subject validated by the security middlewares, any other function may be used as well.
+
+The middleware is typically mounted by the code generated by goagen for the actions that define
+a rate limit in the design:
+
+    Action("list", func() {
+        Routing(GET(""))
+        RateLimit(100, time.Minute, func() {
+            Burst(20)
+            KeyBy(RateLimitByAPIKey)
+        })
+    })
+
+It can also be mounted on the service or controllers directly:
+
+    service.Use(ratelimit.New(10, time.Second))
+*/
+package ratelimit
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	jwtpkg "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa/middleware/security/apikey"
+	"github.com/goadesign/goa/middleware/security/jwt"
+)
+
+// KeyFunc computes the key identifying the client making a request. Requests with the same key
+// share the same token bucket. The middleware falls back to ClientIP when the function returns
+// the empty string.
+type KeyFunc func(ctx context.Context, req *http.Request) string
+
+// ClientIP returns the IP address of the peer that sent the request.
+func ClientIP(ctx context.Context, req *http.Request) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return ip
+}
+
+// ForwardedClientIP returns the first IP address listed in the X-Forwarded-For header if any,
+// the IP address of the peer that sent the request otherwise. Use it only when the service runs
+// behind a proxy that sets the header as clients may set arbitrary values.
+func ForwardedClientIP(ctx context.Context, req *http.Request) string {
+	if f := req.Header.Get("X-Forwarded-For"); f != "" {
+		return strings.TrimSpace(strings.Split(f, ",")[0])
+	}
+	return ClientIP(ctx, req)
+}
+
+// APIKey returns the API key validated by the apikey security middleware. The rate limit
+// middleware must run after the security middleware.
+func APIKey(ctx context.Context, req *http.Request) string {
+	return apikey.ContextAPIKey(ctx)
+}
+
+// JWTSubject returns the "sub" claim of the token validated by the jwt security middleware. The
+// rate limit middleware must run after the security middleware.
+func JWTSubject(ctx context.Context, req *http.Request) string {
+	token := jwt.ContextJWT(ctx)
+	if token == nil {
+		return ""
+	}
+	claims, ok := token.Claims.(jwtpkg.MapClaims)
+	if !ok {
+		return ""
+	}
+	sub, _ := claims["sub"].(string)
+	return sub
+}
+
+// Header returns a KeyFunc that returns the value of the given request header.
+func Header(name string) KeyFunc {
+	return func(ctx context.Context, req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	// Limiter limits the rate of events using one token bucket per key. Each bucket holds up
+	// to burst tokens and is refilled at the rate of limit tokens per period. Limiter is safe
+	// for concurrent use.
+	Limiter struct {
+		limit   int
+		period  time.Duration
+		burst   int
+		keyFunc KeyFunc
+		now     func() time.Time
+
+		lock      sync.Mutex
+		buckets   map[string]*bucket
+		lastSweep time.Time
+	}
+
+	// Result describes the outcome of a call to Allow.
+	Result struct {
+		// Allowed is true if the event is allowed.
+		Allowed bool
+		// Limit is the maximum number of events allowed in a burst.
+		Limit int
+		// Remaining is the number of events that can still be allowed right away.
+		Remaining int
+		// Reset is the time left until the bucket is full again.
+		Reset time.Duration
+		// RetryAfter is the time left until the next event is allowed, zero if
+		// Allowed is true.
+		RetryAfter time.Duration
+	}
+
+	// bucket is a token bucket.
+	bucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+// NewLimiter creates a limiter that allows limit events per period for each key. The
+// bucket size defaults to limit and can be set with the Burst option. It panics if limit or
+// period is not greater than 0.
+func NewLimiter(limit int, period time.Duration, opts ...Option) *Limiter {
+	if limit <= 0 {
+		panic("limit must be greater than 0")
+	}
+	if period <= 0 {
+		panic("period must be greater than 0")
+	}
+	o := newOptions(limit, opts)
+	return &Limiter{
+		limit:   limit,
+		period:  period,
+		burst:   o.burst,
+		keyFunc: o.keyFunc,
+		now:     o.now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket identified by key and reports whether the event is
+// allowed.
+func (l *Limiter) Allow(key string) *Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+	b.refill(now, l.rate(), float64(l.burst))
+
+	res := &Result{Limit: l.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = l.duration(1 - b.tokens)
+	}
+	res.Remaining = int(math.Floor(b.tokens))
+	res.Reset = l.duration(float64(l.burst) - b.tokens)
+	return res
+}
+
+// rate returns the number of tokens added to the buckets per nanosecond.
+func (l *Limiter) rate() float64 {
+	return float64(l.limit) / float64(l.period)
+}
+
+// duration returns the time needed to add the given number of tokens to a bucket.
+func (l *Limiter) duration(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens / l.rate()))
+}
+
+// sweep deletes the buckets that are full at most once per period so that the memory used
+// by the limiter is bounded by the number of keys active during a period.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.period {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		b.refill(now, l.rate(), float64(l.burst))
+		if b.tokens >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (b *bucket) refill(now time.Time, rate, capacity float64) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)*rate)
+		b.last = now
+	}
+}
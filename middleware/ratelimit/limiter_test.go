@@ -0,0 +1,62 @@
+package ratelimit_test
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/middleware/ratelimit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Limiter", func() {
+	var now time.Time
+	var limiter *ratelimit.Limiter
+
+	BeforeEach(func() {
+		now = time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock := func() time.Time { return now }
+		limiter = ratelimit.NewLimiter(2, time.Second, ratelimit.Burst(3), ratelimit.Clock(clock))
+	})
+
+	It("allows bursts", func() {
+		for i := 2; i >= 0; i-- {
+			res := limiter.Allow("a")
+			Ω(res.Allowed).Should(BeTrue())
+			Ω(res.Limit).Should(Equal(3))
+			Ω(res.Remaining).Should(Equal(i))
+			Ω(res.RetryAfter).Should(BeZero())
+		}
+		res := limiter.Allow("a")
+		Ω(res.Allowed).Should(BeFalse())
+		Ω(res.Remaining).Should(Equal(0))
+		Ω(res.RetryAfter).Should(Equal(500 * time.Millisecond))
+		Ω(res.Reset).Should(Equal(1500 * time.Millisecond))
+	})
+
+	It("refills the buckets", func() {
+		for i := 0; i < 3; i++ {
+			limiter.Allow("a")
+		}
+		now = now.Add(500 * time.Millisecond)
+		Ω(limiter.Allow("a").Allowed).Should(BeTrue())
+		Ω(limiter.Allow("a").Allowed).Should(BeFalse())
+		now = now.Add(time.Hour)
+		res := limiter.Allow("a")
+		Ω(res.Allowed).Should(BeTrue())
+		Ω(res.Remaining).Should(Equal(2))
+	})
+
+	It("uses one bucket per key", func() {
+		for i := 0; i < 3; i++ {
+			limiter.Allow("a")
+		}
+		Ω(limiter.Allow("a").Allowed).Should(BeFalse())
+		Ω(limiter.Allow("b").Allowed).Should(BeTrue())
+	})
+
+	It("panics with invalid arguments", func() {
+		Ω(func() { ratelimit.NewLimiter(0, time.Second) }).Should(Panic())
+		Ω(func() { ratelimit.NewLimiter(1, 0) }).Should(Panic())
+		Ω(func() { ratelimit.Burst(0) }).Should(Panic())
+	})
+})
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	// LimitHeader is the name of the response header containing the maximum number of
+	// requests allowed at once.
+	LimitHeader = "RateLimit-Limit"
+
+	// RemainingHeader is the name of the response header containing the number of requests
+	// that can still be made right away.
+	RemainingHeader = "RateLimit-Remaining"
+
+	// ResetHeader is the name of the response header containing the number of seconds until
+	// the quota is fully restored.
+	ResetHeader = "RateLimit-Reset"
+
+	// RetryAfterHeader is the name of the response header containing the number of seconds
+	// the client should wait before retrying a request that was rejected.
+	RetryAfterHeader = "Retry-After"
+)
+
+// New returns a middleware that limits the rate of requests to limit requests per period for
+// each client. Clients are identified by their IP address unless the KeyBy option is used.
+// The middleware sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset response
+// headers. Requests that exceed the rate are rejected with a goa.ErrTooManyRequests error
+// and the Retry-After header is set. Usage:
+//
+//    // Allow 100 requests per minute and per API key with bursts of up to 20 requests.
+//    service.Use(ratelimit.New(100, time.Minute, ratelimit.Burst(20), ratelimit.KeyBy(ratelimit.APIKey)))
+//
+// The code generated by goagen mounts the middleware for the actions that define a rate limit
+// in the design with the RateLimit DSL.
+func New(limit int, period time.Duration, opts ...Option) goa.Middleware {
+	return NewLimiter(limit, period, opts...).Middleware()
+}
+
+// Middleware returns a middleware that limits the rate of requests using l.
+func (l *Limiter) Middleware() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := l.keyFunc(ctx, req)
+			if key == "" {
+				key = ClientIP(ctx, req)
+			}
+			res := l.Allow(key)
+			header := rw.Header()
+			header.Set(LimitHeader, strconv.Itoa(res.Limit))
+			header.Set(RemainingHeader, strconv.Itoa(res.Remaining))
+			header.Set(ResetHeader, strconv.Itoa(seconds(res.Reset)))
+			if !res.Allowed {
+				retry := seconds(res.RetryAfter)
+				header.Set(RetryAfterHeader, strconv.Itoa(retry))
+				return goa.ErrTooManyRequests("rate limit exceeded", "retry_after", retry)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// seconds rounds d up to the second.
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,144 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	jwtpkg "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/ratelimit"
+	"github.com/goadesign/goa/middleware/security/apikey"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var opts []ratelimit.Option
+	var middleware goa.Middleware
+	var ctx context.Context
+	var request *http.Request
+	var rw *httptest.ResponseRecorder
+	var calls int
+
+	dispatch := func() error {
+		rw = httptest.NewRecorder()
+		handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			calls++
+			return nil
+		}
+		return middleware(handler)(ctx, rw, request)
+	}
+
+	BeforeEach(func() {
+		opts = nil
+		ctx = context.Background()
+		request, _ = http.NewRequest("GET", "http://example.com/", nil)
+		request.RemoteAddr = "10.0.0.1:4242"
+		calls = 0
+	})
+
+	JustBeforeEach(func() {
+		middleware = ratelimit.New(2, time.Minute, opts...)
+	})
+
+	It("sets the rate limit headers", func() {
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get(ratelimit.LimitHeader)).Should(Equal("2"))
+		Ω(rw.Header().Get(ratelimit.RemainingHeader)).Should(Equal("1"))
+		Ω(rw.Header().Get(ratelimit.ResetHeader)).Should(Equal("30"))
+		Ω(rw.Header().Get(ratelimit.RetryAfterHeader)).Should(BeEmpty())
+	})
+
+	It("rejects requests exceeding the rate", func() {
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+		err := dispatch()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(429))
+		Ω(err.(goa.ServiceError).Token()).ShouldNot(BeEmpty())
+		Ω(rw.Header().Get(ratelimit.RemainingHeader)).Should(Equal("0"))
+		Ω(rw.Header().Get(ratelimit.RetryAfterHeader)).Should(Equal("30"))
+		Ω(calls).Should(Equal(2))
+	})
+
+	It("limits each client IP separately", func() {
+		dispatch()
+		dispatch()
+		request.RemoteAddr = "10.0.0.2:4242"
+		Ω(dispatch()).ShouldNot(HaveOccurred())
+	})
+
+	Context("keyed by API key", func() {
+		BeforeEach(func() {
+			opts = append(opts, ratelimit.KeyBy(ratelimit.APIKey))
+		})
+
+		It("limits each key separately", func() {
+			ctx = apikey.WithAPIKey(context.Background(), "key-a", "a")
+			dispatch()
+			dispatch()
+			Ω(dispatch()).Should(HaveOccurred())
+			ctx = apikey.WithAPIKey(context.Background(), "key-b", "b")
+			Ω(dispatch()).ShouldNot(HaveOccurred())
+		})
+
+		It("falls back to the client IP", func() {
+			dispatch()
+			dispatch()
+			Ω(dispatch()).Should(HaveOccurred())
+			ctx = apikey.WithAPIKey(context.Background(), "key-a", "a")
+			Ω(dispatch()).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("keyed by JWT subject", func() {
+		BeforeEach(func() {
+			opts = append(opts, ratelimit.KeyBy(ratelimit.JWTSubject))
+		})
+
+		It("limits each subject separately", func() {
+			withSub := func(sub string) context.Context {
+				return jwt.WithJWT(context.Background(), &jwtpkg.Token{Claims: jwtpkg.MapClaims{"sub": sub}})
+			}
+			ctx = withSub("alice")
+			dispatch()
+			dispatch()
+			Ω(dispatch()).Should(HaveOccurred())
+			ctx = withSub("bob")
+			Ω(dispatch()).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("keyed by a custom function", func() {
+		BeforeEach(func() {
+			opts = append(opts, ratelimit.KeyBy(ratelimit.Header("X-Tenant")))
+		})
+
+		It("limits each key separately", func() {
+			request.Header.Set("X-Tenant", "acme")
+			dispatch()
+			dispatch()
+			Ω(dispatch()).Should(HaveOccurred())
+			request.Header.Set("X-Tenant", "globex")
+			Ω(dispatch()).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("keyed by forwarded client IP", func() {
+		BeforeEach(func() {
+			opts = append(opts, ratelimit.KeyBy(ratelimit.ForwardedClientIP))
+		})
+
+		It("uses the first forwarded address", func() {
+			request.Header.Set("X-Forwarded-For", "192.168.0.1, 10.0.0.9")
+			dispatch()
+			dispatch()
+			Ω(dispatch()).Should(HaveOccurred())
+			request.Header.Set("X-Forwarded-For", "192.168.0.2, 10.0.0.9")
+			Ω(dispatch()).ShouldNot(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package ratelimit
+
+import "time"
+
+type (
+	// Option is a constructor option that makes it possible to customize the limiter.
+	Option func(*options) *options
+
+	// options is the struct storing all the options.
+	options struct {
+		burst   int
+		keyFunc KeyFunc
+		now     func() time.Time
+	}
+)
+
+// Burst sets the maximum number of requests allowed at once for a key, that is the size of
+// the token buckets. Defaults to the limit. It panics if n is not greater than 0.
+func Burst(n int) Option {
+	if n <= 0 {
+		panic("burst must be greater than 0")
+	}
+	return func(o *options) *options {
+		o.burst = n
+		return o
+	}
+}
+
+// KeyBy sets the function used by the middleware to compute the key identifying the client
+// making the request. Defaults to ClientIP.
+func KeyBy(f KeyFunc) Option {
+	if f == nil {
+		panic("key function cannot be nil")
+	}
+	return func(o *options) *options {
+		o.keyFunc = f
+		return o
+	}
+}
+
+// Clock sets the function used by the limiter to get the current time. Defaults to
+// time.Now. This is mainly useful for tests.
+func Clock(now func() time.Time) Option {
+	if now == nil {
+		panic("clock function cannot be nil")
+	}
+	return func(o *options) *options {
+		o.now = now
+		return o
+	}
+}
+
+// newOptions initializes the options with their default values and applies opts.
+func newOptions(limit int, opts []Option) *options {
+	o := &options{
+		burst:   limit,
+		keyFunc: ClientIP,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	return o
+}
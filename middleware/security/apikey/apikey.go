@@ -0,0 +1,55 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// ErrAPIKeyFailed means the request API key is missing or invalid.
+var ErrAPIKeyFailed = goa.NewErrorClass("api_key_failed", 401)
+
+// New returns a middleware to be used with the APIKeySecurity DSL definitions of goa. The
+// middleware reads the key from the header or query string parameter defined by the scheme and
+// validates it against the store. It stores the key and the identity returned by the store in the
+// request context, use ContextAPIKey and ContextIdentity to retrieve them.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//    store := apikey.NewStaticStore(map[string]interface{}{"secret-key": "service-a"})
+//    app.UseAPIKeyMiddleware(service, apikey.New(store, app.NewAPIKeySecurity()))
+//
+func New(store Store, scheme *goa.APIKeySecurity) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var key string
+			switch scheme.In {
+			case goa.LocHeader:
+				key = req.Header.Get(scheme.Name)
+				if key == "" {
+					return ErrAPIKeyFailed(fmt.Sprintf("missing header %q", scheme.Name))
+				}
+			case goa.LocQuery:
+				key = req.URL.Query().Get(scheme.Name)
+				if key == "" {
+					return ErrAPIKeyFailed(fmt.Sprintf("missing parameter %q", scheme.Name))
+				}
+			default:
+				return fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
+			}
+
+			identity, err := store.Lookup(ctx, key)
+			if err != nil {
+				return err
+			}
+			if identity == nil {
+				return ErrAPIKeyFailed("invalid API key")
+			}
+
+			return nextHandler(WithAPIKey(ctx, key, identity), rw, req)
+		}
+	}
+}
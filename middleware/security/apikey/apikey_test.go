@@ -0,0 +1,105 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/apikey"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var scheme *goa.APIKeySecurity
+	var store apikey.Store
+	var request *http.Request
+	var dispatchResult error
+	var handlerCtx context.Context
+
+	BeforeEach(func() {
+		scheme = &goa.APIKeySecurity{In: goa.LocHeader, Name: "X-API-Key"}
+		store = apikey.NewStaticStore(map[string]interface{}{"secret": "service-a"})
+		request, _ = http.NewRequest("GET", "http://example.com/", nil)
+		handlerCtx = nil
+	})
+
+	JustBeforeEach(func() {
+		handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			handlerCtx = ctx
+			return nil
+		}
+		dispatchResult = apikey.New(store, scheme)(handler)(context.Background(), httptest.NewRecorder(), request)
+	})
+
+	Context("with a valid key in the header", func() {
+		BeforeEach(func() {
+			request.Header.Set("X-API-Key", "secret")
+		})
+
+		It("stores the key and identity in the context", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(apikey.ContextAPIKey(handlerCtx)).Should(Equal("secret"))
+			Ω(apikey.ContextIdentity(handlerCtx)).Should(Equal("service-a"))
+		})
+	})
+
+	Context("with an invalid key", func() {
+		BeforeEach(func() {
+			request.Header.Set("X-API-Key", "guess")
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+			Ω(dispatchResult.Error()).Should(ContainSubstring("invalid API key"))
+			Ω(handlerCtx).Should(BeNil())
+		})
+	})
+
+	Context("with a missing header", func() {
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.Error()).Should(ContainSubstring(`missing header "X-API-Key"`))
+		})
+	})
+
+	Context("with a key in the query string", func() {
+		BeforeEach(func() {
+			scheme = &goa.APIKeySecurity{In: goa.LocQuery, Name: "api_key"}
+			request.URL.RawQuery = "api_key=secret"
+		})
+
+		It("validates the key", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(apikey.ContextIdentity(handlerCtx)).Should(Equal("service-a"))
+		})
+	})
+
+	Context("with a failing store", func() {
+		var storeErr = errors.New("boom")
+
+		BeforeEach(func() {
+			request.Header.Set("X-API-Key", "secret")
+			store = apikey.StoreFunc(func(ctx context.Context, key string) (interface{}, error) {
+				return nil, storeErr
+			})
+		})
+
+		It("returns the store error", func() {
+			Ω(dispatchResult).Should(Equal(storeErr))
+		})
+	})
+
+	Context("with an unsupported location", func() {
+		BeforeEach(func() {
+			scheme.In = "cookie"
+		})
+
+		It("returns an error", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+		})
+	})
+})
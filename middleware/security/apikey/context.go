@@ -0,0 +1,29 @@
+package apikey
+
+import "context"
+
+type contextKey int
+
+const (
+	keyKey contextKey = iota + 1
+	identityKey
+)
+
+// WithAPIKey creates a child context containing the given API key and the identity associated with
+// it by the store.
+func WithAPIKey(ctx context.Context, key string, identity interface{}) context.Context {
+	ctx = context.WithValue(ctx, keyKey, key)
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// ContextAPIKey retrieves the API key from a `context` that went through our security middleware.
+func ContextAPIKey(ctx context.Context) string {
+	key, _ := ctx.Value(keyKey).(string)
+	return key
+}
+
+// ContextIdentity retrieves the identity returned by the store for the API key from a `context`
+// that went through our security middleware.
+func ContextIdentity(ctx context.Context) interface{} {
+	return ctx.Value(identityKey)
+}
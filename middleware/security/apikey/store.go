@@ -0,0 +1,47 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+)
+
+type (
+	// Store validates API keys.
+	Store interface {
+		// Lookup returns the identity associated with the given key, e.g. the account owning
+		// the key. It returns a nil identity and no error if the key is not valid.
+		Lookup(ctx context.Context, key string) (interface{}, error)
+	}
+
+	// StoreFunc is an adapter that makes it possible to use a function as a Store.
+	StoreFunc func(ctx context.Context, key string) (interface{}, error)
+
+	// staticStore is a store backed by an immutable map of keys to identities.
+	staticStore map[string]interface{}
+)
+
+// NewStaticStore returns a store that validates the keys of the given map. The map values are the
+// identities associated with the keys. The store compares the keys in constant time.
+func NewStaticStore(keys map[string]interface{}) Store {
+	s := make(staticStore, len(keys))
+	for k, id := range keys {
+		s[k] = id
+	}
+	return s
+}
+
+// Lookup calls f(ctx, key).
+func (f StoreFunc) Lookup(ctx context.Context, key string) (interface{}, error) {
+	return f(ctx, key)
+}
+
+// Lookup compares the given key with all the store keys.
+func (s staticStore) Lookup(ctx context.Context, key string) (interface{}, error) {
+	var identity interface{}
+	for k, id := range s {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			identity = id
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,175 @@
+package jwt
+
+import (
+	"fmt"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+)
+
+type (
+	// Option is a constructor option that makes it possible to customize the validations
+	// performed by the middleware on the token claims. Options override the corresponding
+	// settings of the security scheme given to New.
+	Option func(*options) *options
+
+	// options is the struct storing all the options.
+	options struct {
+		issuers           []string
+		audiences         []string
+		leeway            time.Duration
+		requiredClaims    []string
+		signingAlgorithms []string
+	}
+)
+
+// Issuers sets the accepted values of the "iss" claim. The middleware rejects tokens issued by
+// other issuers.
+func Issuers(issuers ...string) Option {
+	return func(o *options) *options {
+		o.issuers = issuers
+		return o
+	}
+}
+
+// Audiences sets the accepted values of the "aud" claim. The middleware rejects tokens that do not
+// list at least one of the audiences.
+func Audiences(audiences ...string) Option {
+	return func(o *options) *options {
+		o.audiences = audiences
+		return o
+	}
+}
+
+// Leeway sets the clock skew tolerated when validating the "exp", "nbf" and "iat" claims.
+// It panics if d is negative.
+func Leeway(d time.Duration) Option {
+	if d < 0 {
+		panic("leeway cannot be negative")
+	}
+	return func(o *options) *options {
+		o.leeway = d
+		return o
+	}
+}
+
+// RequiredClaims sets the names of the claims that must be present in the token.
+func RequiredClaims(names ...string) Option {
+	return func(o *options) *options {
+		o.requiredClaims = names
+		return o
+	}
+}
+
+// SigningAlgorithms sets the accepted signing algorithms, e.g. "RS256" or "ES256". The middleware
+// rejects tokens signed with other algorithms before validating their signature.
+func SigningAlgorithms(algs ...string) Option {
+	return func(o *options) *options {
+		o.signingAlgorithms = algs
+		return o
+	}
+}
+
+// newOptions initializes the options from the security scheme and applies opts.
+func newOptions(scheme *goa.JWTSecurity, opts []Option) *options {
+	o := &options{
+		issuers:           scheme.Issuers,
+		audiences:         scheme.Audiences,
+		leeway:            scheme.Leeway,
+		requiredClaims:    scheme.RequiredClaims,
+		signingAlgorithms: scheme.SigningAlgorithms,
+	}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	return o
+}
+
+// validateAlgorithm checks that the token signing algorithm is allowed.
+func (o *options) validateAlgorithm(incomingToken string) error {
+	if len(o.signingAlgorithms) == 0 {
+		return nil
+	}
+	token, _, err := new(jwt.Parser).ParseUnverified(incomingToken, jwt.MapClaims{})
+	if err != nil {
+		return ErrJWTError("JWT validation failed")
+	}
+	alg, _ := token.Header["alg"].(string)
+	if !contains(o.signingAlgorithms, alg) {
+		return ErrJWTError(fmt.Sprintf("signing algorithm %q is not allowed", alg),
+			"allowed", o.signingAlgorithms)
+	}
+	return nil
+}
+
+// validateClaims validates the standard claims of the token.
+func (o *options) validateClaims(token *jwt.Token) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ErrJWTError("unsupported claims shape")
+	}
+	now := time.Now().Unix()
+	leeway := int64(o.leeway / time.Second)
+	if !claims.VerifyExpiresAt(now-leeway, false) {
+		return ErrJWTError("token is expired")
+	}
+	if !claims.VerifyNotBefore(now+leeway, false) {
+		return ErrJWTError("token is not valid yet")
+	}
+	if !claims.VerifyIssuedAt(now+leeway, false) {
+		return ErrJWTError("token used before issued")
+	}
+	if len(o.issuers) > 0 {
+		iss, _ := claims["iss"].(string)
+		if !contains(o.issuers, iss) {
+			return ErrJWTError(fmt.Sprintf("invalid issuer %q", iss), "allowed", o.issuers)
+		}
+	}
+	if len(o.audiences) > 0 {
+		var valid bool
+		for _, aud := range claimAudiences(claims) {
+			if contains(o.audiences, aud) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return ErrJWTError("invalid audience", "allowed", o.audiences)
+		}
+	}
+	for _, name := range o.requiredClaims {
+		if v, ok := claims[name]; !ok || v == nil {
+			return ErrJWTError(fmt.Sprintf("missing required claim %q", name))
+		}
+	}
+	return nil
+}
+
+// claimAudiences returns the audiences listed in the "aud" claim which may be a
+// single string or a list of strings.
+func claimAudiences(claims jwt.MapClaims) []string {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		auds := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				auds = append(auds, s)
+			}
+		}
+		return auds
+	}
+	return nil
+}
+
+// contains returns true if vals contains val.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
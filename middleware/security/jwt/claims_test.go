@@ -0,0 +1,172 @@
+package jwt_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	jwtpkg "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Claim validation", func() {
+	var scheme *goa.JWTSecurity
+	var opts []jwt.Option
+	var method jwtpkg.SigningMethod
+	var signingKey interface{}
+	var claims jwtpkg.MapClaims
+	var dispatchResult error
+
+	BeforeEach(func() {
+		scheme = &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}
+		opts = nil
+		method = jwtpkg.SigningMethodRS256
+		signingKey = rsaKey1
+		claims = jwtpkg.MapClaims{
+			"iss": "https://issuer.example.com",
+			"aud": []interface{}{"api", "admin"},
+			"sub": "user",
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+	})
+
+	JustBeforeEach(func() {
+		signed, err := jwtpkg.NewWithClaims(method, claims).SignedString(signingKey)
+		Ω(err).ShouldNot(HaveOccurred())
+		req, _ := http.NewRequest("GET", "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer "+signed)
+		resolver := jwt.NewSimpleResolver([]jwt.Key{rsaPubKey1, ecPubKey1})
+		handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+		middleware := jwt.New(resolver, nil, scheme, opts...)
+		dispatchResult = middleware(handler)(context.Background(), httptest.NewRecorder(), req)
+	})
+
+	It("accepts valid tokens", func() {
+		Ω(dispatchResult).ShouldNot(HaveOccurred())
+	})
+
+	Context("with an expired token", func() {
+		BeforeEach(func() {
+			claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+		})
+
+		It("rejects the token", func() {
+			Ω(dispatchResult).Should(MatchError(ContainSubstring("token is expired")))
+		})
+
+		Context("and a leeway", func() {
+			BeforeEach(func() {
+				opts = append(opts, jwt.Leeway(time.Minute))
+			})
+
+			It("accepts the token", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a token that is not valid yet", func() {
+		BeforeEach(func() {
+			claims["nbf"] = time.Now().Add(10 * time.Second).Unix()
+		})
+
+		It("rejects the token", func() {
+			Ω(dispatchResult).Should(MatchError(ContainSubstring("token is not valid yet")))
+		})
+
+		Context("and a leeway set in the scheme", func() {
+			BeforeEach(func() {
+				scheme.Leeway = time.Minute
+			})
+
+			It("accepts the token", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with expected issuers", func() {
+		BeforeEach(func() {
+			opts = append(opts, jwt.Issuers("https://other.example.com", "https://issuer.example.com"))
+		})
+
+		It("accepts tokens from the issuers", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+		})
+
+		Context("and a token from another issuer", func() {
+			BeforeEach(func() {
+				claims["iss"] = "https://evil.example.com"
+			})
+
+			It("rejects the token", func() {
+				Ω(dispatchResult).Should(MatchError(ContainSubstring(`invalid issuer "https://evil.example.com"`)))
+			})
+		})
+	})
+
+	Context("with expected audiences", func() {
+		BeforeEach(func() {
+			scheme.Audiences = []string{"admin"}
+		})
+
+		It("accepts tokens listing one of the audiences", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+		})
+
+		Context("and a token for a single other audience", func() {
+			BeforeEach(func() {
+				claims["aud"] = "other"
+			})
+
+			It("rejects the token", func() {
+				Ω(dispatchResult).Should(MatchError(ContainSubstring("invalid audience")))
+			})
+		})
+
+		Context("overridden by an option", func() {
+			BeforeEach(func() {
+				opts = append(opts, jwt.Audiences("other"))
+			})
+
+			It("rejects the token", func() {
+				Ω(dispatchResult).Should(MatchError(ContainSubstring("invalid audience")))
+			})
+		})
+	})
+
+	Context("with required claims", func() {
+		BeforeEach(func() {
+			opts = append(opts, jwt.RequiredClaims("sub", "email"))
+		})
+
+		It("rejects tokens missing a claim", func() {
+			Ω(dispatchResult).Should(MatchError(ContainSubstring(`missing required claim "email"`)))
+		})
+	})
+
+	Context("with allowed signing algorithms", func() {
+		BeforeEach(func() {
+			opts = append(opts, jwt.SigningAlgorithms("ES256"))
+		})
+
+		It("rejects tokens signed with other algorithms", func() {
+			Ω(dispatchResult).Should(MatchError(ContainSubstring(`signing algorithm "RS256" is not allowed`)))
+		})
+
+		Context("and a token signed with an allowed algorithm", func() {
+			BeforeEach(func() {
+				method = jwtpkg.SigningMethodES256
+				signingKey = ecKey1
+			})
+
+			It("accepts the token", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,401 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// JWKSFetcher retrieves JSON Web Key Set documents (RFC 7517).
+	JWKSFetcher interface {
+		// Fetch returns the raw JWKS document.
+		Fetch(ctx context.Context) ([]byte, error)
+	}
+
+	// JWKSOption is a constructor option that makes it possible to customize
+	// the JWKS resolver.
+	JWKSOption func(*jwksOptions) *jwksOptions
+
+	// JWKSResolver is a key resolver that loads the keys from a JWKS
+	// document. The keys are indexed by key ID so that the middleware may
+	// select the key identified by the "kid" header of the incoming token.
+	// The resolver refreshes the keys periodically and whenever a token
+	// refers to an unknown key ID. Refreshes triggered by unknown key IDs are
+	// rate limited. The resolver keeps using the previously loaded keys when
+	// a refresh fails.
+	JWKSResolver struct {
+		fetcher JWKSFetcher
+		options *jwksOptions
+
+		// lock protects keys and all
+		lock sync.RWMutex
+		keys map[string]Key
+		all  []Key
+
+		// fetchLock serializes fetches and protects lastFetch
+		fetchLock sync.Mutex
+		lastFetch time.Time
+
+		done      chan struct{}
+		closeOnce sync.Once
+	}
+
+	// jwksOptions is the struct storing all the options.
+	jwksOptions struct {
+		refreshInterval    time.Duration
+		minRefreshInterval time.Duration
+		errorHandler       func(error)
+	}
+
+	// httpFetcher retrieves JWKS documents over HTTP.
+	httpFetcher struct {
+		url    string
+		client *http.Client
+	}
+
+	// jwks is the JSON representation of a JWKS document.
+	jwks struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}
+
+	// jsonWebKey is the JSON representation of a JSON Web Key (RFC 7518).
+	jsonWebKey struct {
+		Kty string `json:"kty"`
+		Use string `json:"use,omitempty"`
+		Kid string `json:"kid,omitempty"`
+		Alg string `json:"alg,omitempty"`
+		// RSA keys
+		N string `json:"n,omitempty"`
+		E string `json:"e,omitempty"`
+		// EC keys
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+		Y   string `json:"y,omitempty"`
+		// Symmetric keys
+		K string `json:"k,omitempty"`
+	}
+)
+
+// jwkAlgorithms lists the prefixes of the signing algorithms supported for each
+// key type.
+var jwkAlgorithms = map[string]string{"RSA": "RS", "EC": "ES", "oct": "HS"}
+
+// NewJWKSFetcher returns a fetcher that retrieves the JWKS document located at
+// the given URL using the given HTTP client. The fetcher uses
+// http.DefaultClient if client is nil.
+func NewJWKSFetcher(url string, client *http.Client) JWKSFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpFetcher{url: url, client: client}
+}
+
+// JWKSRefreshInterval sets the interval between two background refreshes of the
+// keys. A value of 0 disables background refreshes. Defaults to one hour.
+func JWKSRefreshInterval(d time.Duration) JWKSOption {
+	if d < 0 {
+		panic("refresh interval cannot be negative")
+	}
+	return func(o *jwksOptions) *jwksOptions {
+		o.refreshInterval = d
+		return o
+	}
+}
+
+// JWKSMinRefreshInterval sets the minimum duration between two fetches
+// triggered by tokens referring to unknown key IDs. Defaults to one minute.
+func JWKSMinRefreshInterval(d time.Duration) JWKSOption {
+	if d < 0 {
+		panic("minimum refresh interval cannot be negative")
+	}
+	return func(o *jwksOptions) *jwksOptions {
+		o.minRefreshInterval = d
+		return o
+	}
+}
+
+// JWKSErrorHandler sets a function called with the errors that occur when
+// refreshing the keys in the background or upon encountering an unknown key ID.
+// It is also called with the errors describing the keys of the JWKS document
+// that cannot be loaded, such keys are skipped.
+func JWKSErrorHandler(h func(error)) JWKSOption {
+	return func(o *jwksOptions) *jwksOptions {
+		o.errorHandler = h
+		return o
+	}
+}
+
+// NewJWKSResolver returns a key resolver that loads its keys using the given
+// fetcher. It fetches the keys once before returning and fails if the keys
+// cannot be loaded. Call Close to stop the background refreshes.
+//
+//    fetcher := jwt.NewJWKSFetcher("https://example.com/.well-known/jwks.json", nil)
+//    resolver, err := jwt.NewJWKSResolver(fetcher, jwt.JWKSRefreshInterval(15*time.Minute))
+//    if err != nil {
+//        return err
+//    }
+//    defer resolver.Close()
+//    app.UseJWT(jwt.New(resolver, nil, app.NewJWTSecurity()))
+//
+func NewJWKSResolver(fetcher JWKSFetcher, opts ...JWKSOption) (*JWKSResolver, error) {
+	o := &jwksOptions{
+		refreshInterval:    time.Hour,
+		minRefreshInterval: time.Minute,
+	}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	r := &JWKSResolver{
+		fetcher: fetcher,
+		options: o,
+		keys:    make(map[string]Key),
+		done:    make(chan struct{}),
+	}
+	if err := r.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	if o.refreshInterval > 0 {
+		go r.refreshLoop()
+	}
+	return r, nil
+}
+
+// SelectKeys returns all the keys loaded by the resolver.
+func (r *JWKSResolver) SelectKeys(req *http.Request) []Key {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.all
+}
+
+// SelectKIDKeys returns the key with the given ID. The resolver refreshes the
+// keys if the ID is unknown and the last fetch is older than the minimum
+// refresh interval.
+func (r *JWKSResolver) SelectKIDKeys(req *http.Request, kid string) []Key {
+	if key, ok := r.key(kid); ok {
+		return []Key{key}
+	}
+	if err := r.refreshUnknown(req.Context(), kid); err != nil {
+		r.handleError(err)
+	}
+	if key, ok := r.key(kid); ok {
+		return []Key{key}
+	}
+	return nil
+}
+
+// Refresh fetches and loads the keys. The resolver keeps the previously loaded
+// keys if Refresh fails.
+func (r *JWKSResolver) Refresh(ctx context.Context) error {
+	r.fetchLock.Lock()
+	defer r.fetchLock.Unlock()
+	return r.refresh(ctx)
+}
+
+// Close stops the background refreshes.
+func (r *JWKSResolver) Close() {
+	r.closeOnce.Do(func() { close(r.done) })
+}
+
+// key returns the key with the given ID if loaded.
+func (r *JWKSResolver) key(kid string) (Key, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// refreshUnknown refreshes the keys unless the key with the given ID was
+// loaded by a concurrent refresh or the last fetch is too recent.
+func (r *JWKSResolver) refreshUnknown(ctx context.Context, kid string) error {
+	r.fetchLock.Lock()
+	defer r.fetchLock.Unlock()
+	if _, ok := r.key(kid); ok {
+		return nil
+	}
+	if time.Since(r.lastFetch) < r.options.minRefreshInterval {
+		return nil
+	}
+	return r.refresh(ctx)
+}
+
+// refresh fetches and loads the keys, fetchLock must be held.
+func (r *JWKSResolver) refresh(ctx context.Context) error {
+	r.lastFetch = time.Now()
+	doc, err := r.fetcher.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %s", err)
+	}
+	keys, all, err := parseJWKS(doc, r.handleError)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	r.keys = keys
+	r.all = all
+	r.lock.Unlock()
+	return nil
+}
+
+// refreshLoop refreshes the keys periodically until the resolver is closed.
+func (r *JWKSResolver) refreshLoop() {
+	ticker := time.NewTicker(r.options.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			if err := r.Refresh(context.Background()); err != nil {
+				r.handleError(err)
+			}
+		}
+	}
+}
+
+// handleError calls the error handler if any.
+func (r *JWKSResolver) handleError(err error) {
+	if r.options.errorHandler != nil {
+		r.options.errorHandler(err)
+	}
+}
+
+// Fetch retrieves the JWKS document with a GET request.
+func (f *httpFetcher) Fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequest("GET", f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := f.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %s", f.url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseJWKS parses the given JWKS document and returns the signature
+// verification keys indexed by key ID as well as the list of all keys. Keys
+// that are not used for signatures or that use unsupported key types are
+// ignored. Keys that cannot be loaded are skipped and reported to skipped,
+// parseJWKS fails only if no signature verification key remains.
+func parseJWKS(doc []byte, skipped func(error)) (map[string]Key, []Key, error) {
+	var set jwks
+	if err := json.Unmarshal(doc, &set); err != nil {
+		return nil, nil, fmt.Errorf("invalid JWKS document: %s", err)
+	}
+	keys := make(map[string]Key)
+	var all []Key
+	var errs []string
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.key()
+		if err != nil {
+			err = fmt.Errorf("invalid JWKS key %q: %s", jwk.Kid, err)
+			errs = append(errs, err.Error())
+			skipped(err)
+			continue
+		}
+		if key == nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+		all = append(all, key)
+	}
+	if len(all) == 0 {
+		if len(errs) > 0 {
+			return nil, nil, fmt.Errorf("invalid JWKS document: no signature verification key (%s)",
+				strings.Join(errs, ", "))
+		}
+		return nil, nil, fmt.Errorf("invalid JWKS document: no signature verification key")
+	}
+	return keys, all, nil
+}
+
+// key returns the public key or secret described by the JWK, nil if the key
+// type is not supported.
+func (jwk *jsonWebKey) key() (Key, error) {
+	if prefix, ok := jwkAlgorithms[jwk.Kty]; ok && jwk.Alg != "" && !strings.HasPrefix(jwk.Alg, prefix) {
+		return nil, fmt.Errorf("unsupported algorithm %q", jwk.Alg)
+	}
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %s", err)
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %s", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %s", err)
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %s", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", jwk.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		k, err := decodeBase64URL(jwk.K)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret: %s", err)
+		}
+		return k, nil
+	default:
+		return nil, nil
+	}
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := decodeBase64URL(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// decodeBase64URL decodes base64url encoded values with or without padding.
+func decodeBase64URL(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,297 @@
+package jwt_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	jwtpkg "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JWKSResolver", func() {
+	var server *jwksServer
+	var ts *httptest.Server
+	var opts []jwt.JWKSOption
+	var resolver *jwt.JWKSResolver
+	var newErr error
+
+	BeforeEach(func() {
+		server = &jwksServer{status: 200}
+		server.setKeys(
+			rsaJWK("rsa1", rsaPubKey1),
+			ecJWK("ec1", ecPubKey1),
+			map[string]string{"kty": "oct", "kid": "hmac1", "k": b64([]byte("keys"))},
+			map[string]string{"kty": "RSA", "kid": "enc1", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		)
+		ts = httptest.NewServer(server)
+		opts = []jwt.JWKSOption{jwt.JWKSRefreshInterval(0)}
+	})
+
+	JustBeforeEach(func() {
+		resolver, newErr = jwt.NewJWKSResolver(jwt.NewJWKSFetcher(ts.URL, nil), opts...)
+	})
+
+	AfterEach(func() {
+		if resolver != nil {
+			resolver.Close()
+		}
+		ts.Close()
+	})
+
+	It("loads the signature keys indexed by key ID", func() {
+		Ω(newErr).ShouldNot(HaveOccurred())
+		req, _ := http.NewRequest("GET", "/", nil)
+		Ω(resolver.SelectKeys(req)).Should(HaveLen(3))
+		Ω(resolver.SelectKIDKeys(req, "rsa1")).Should(Equal([]jwt.Key{rsaPubKey1}))
+		Ω(resolver.SelectKIDKeys(req, "hmac1")).Should(Equal([]jwt.Key{[]byte("keys")}))
+		keys := resolver.SelectKIDKeys(req, "ec1")
+		Ω(keys).Should(HaveLen(1))
+		ec := keys[0].(*ecdsa.PublicKey)
+		Ω(ec.X.Cmp(ecPubKey1.X)).Should(Equal(0))
+		Ω(ec.Y.Cmp(ecPubKey1.Y)).Should(Equal(0))
+		Ω(resolver.SelectKIDKeys(req, "enc1")).Should(BeEmpty())
+	})
+
+	Context("with a failing JWKS endpoint", func() {
+		BeforeEach(func() {
+			server.status = 500
+		})
+
+		It("fails to load the keys", func() {
+			Ω(newErr).Should(HaveOccurred())
+			Ω(newErr.Error()).Should(ContainSubstring("500"))
+		})
+	})
+
+	Context("with an invalid JWKS document", func() {
+		BeforeEach(func() {
+			server.setKeys(map[string]string{"kty": "EC", "kid": "ec1", "crv": "P-256", "x": "AQAB", "y": "AQAB"})
+		})
+
+		It("fails to load the keys", func() {
+			Ω(newErr).Should(MatchError(ContainSubstring("not on curve")))
+		})
+	})
+
+	Context("with keys that cannot be loaded", func() {
+		var errs []error
+
+		BeforeEach(func() {
+			errs = nil
+			server.setKeys(
+				rsaJWK("rsa1", rsaPubKey1),
+				map[string]string{"kty": "EC", "kid": "ec1", "crv": "P-256", "x": "AQAB", "y": "AQAB"},
+				map[string]string{"kty": "EC", "kid": "ec2", "crv": "secp256k1", "x": "AQAB", "y": "AQAB"},
+				map[string]string{"kty": "oct", "kid": "hmac1", "alg": "A128KW", "k": b64([]byte("keys"))},
+			)
+			opts = append(opts, jwt.JWKSErrorHandler(func(err error) { errs = append(errs, err) }))
+		})
+
+		It("skips and reports them", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			req, _ := http.NewRequest("GET", "/", nil)
+			Ω(resolver.SelectKeys(req)).Should(Equal([]jwt.Key{rsaPubKey1}))
+			Ω(resolver.SelectKIDKeys(req, "rsa1")).Should(Equal([]jwt.Key{rsaPubKey1}))
+			Ω(errs).Should(HaveLen(3))
+			Ω(errs[0]).Should(MatchError(ContainSubstring("not on curve")))
+			Ω(errs[1]).Should(MatchError(ContainSubstring("unsupported curve")))
+			Ω(errs[2]).Should(MatchError(ContainSubstring("unsupported algorithm")))
+		})
+	})
+
+	Context("with a token signed by a new key", func() {
+		var req *http.Request
+
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", "/", nil)
+			opts = append(opts, jwt.JWKSMinRefreshInterval(0))
+		})
+
+		JustBeforeEach(func() {
+			server.setKeys(rsaJWK("rsa1", rsaPubKey1), rsaJWK("rsa2", rsaPubKey2))
+		})
+
+		It("refreshes the keys", func() {
+			Ω(server.count()).Should(Equal(1))
+			Ω(resolver.SelectKIDKeys(req, "rsa2")).Should(Equal([]jwt.Key{rsaPubKey2}))
+			Ω(server.count()).Should(Equal(2))
+			Ω(resolver.SelectKIDKeys(req, "rsa2")).Should(HaveLen(1))
+			Ω(server.count()).Should(Equal(2))
+		})
+
+		Context("and a recent fetch", func() {
+			BeforeEach(func() {
+				opts = append(opts, jwt.JWKSMinRefreshInterval(time.Hour))
+			})
+
+			It("rate limits the refreshes", func() {
+				Ω(resolver.SelectKIDKeys(req, "rsa2")).Should(BeEmpty())
+				Ω(server.count()).Should(Equal(1))
+			})
+		})
+
+		Context("and a failing JWKS endpoint", func() {
+			var errs []error
+
+			BeforeEach(func() {
+				errs = nil
+				opts = append(opts, jwt.JWKSErrorHandler(func(err error) { errs = append(errs, err) }))
+			})
+
+			JustBeforeEach(func() {
+				server.status = 503
+			})
+
+			It("keeps serving the cached keys", func() {
+				Ω(resolver.SelectKIDKeys(req, "rsa2")).Should(BeEmpty())
+				Ω(errs).Should(HaveLen(1))
+				Ω(resolver.SelectKIDKeys(req, "rsa1")).Should(Equal([]jwt.Key{rsaPubKey1}))
+				Ω(resolver.Refresh(context.Background())).Should(HaveOccurred())
+				Ω(resolver.SelectKeys(req)).Should(HaveLen(3))
+			})
+		})
+	})
+
+	Context("with a refresh interval", func() {
+		BeforeEach(func() {
+			opts = []jwt.JWKSOption{jwt.JWKSRefreshInterval(10 * time.Millisecond)}
+		})
+
+		It("refreshes the keys in the background", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			server.setKeys(rsaJWK("rsa2", rsaPubKey2))
+			req, _ := http.NewRequest("GET", "/", nil)
+			Eventually(func() []jwt.Key { return resolver.SelectKeys(req) }).Should(Equal([]jwt.Key{rsaPubKey2}))
+		})
+	})
+
+	Context("used by the middleware", func() {
+		var scheme *goa.JWTSecurity
+		var signed string
+		var dispatchResult error
+
+		BeforeEach(func() {
+			scheme = &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}
+		})
+
+		JustBeforeEach(func() {
+			req, _ := http.NewRequest("GET", "http://example.com/", nil)
+			req.Header.Set("Authorization", "Bearer "+signed)
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+			dispatchResult = jwt.New(resolver, nil, scheme)(handler)(context.Background(), httptest.NewRecorder(), req)
+		})
+
+		Context("with a token referring to a known key", func() {
+			BeforeEach(func() {
+				signed = signToken(jwtpkg.SigningMethodRS256, "rsa1", rsaKey1)
+			})
+
+			It("authorizes the request", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with a token signed with an HMAC key", func() {
+			BeforeEach(func() {
+				signed = signToken(jwtpkg.SigningMethodHS256, "hmac1", []byte("keys"))
+			})
+
+			It("authorizes the request", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with a token signed by a key with a different ID", func() {
+			BeforeEach(func() {
+				signed = signToken(jwtpkg.SigningMethodES256, "rsa1", ecKey1)
+			})
+
+			It("rejects the request", func() {
+				Ω(dispatchResult).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a token with no key ID", func() {
+			BeforeEach(func() {
+				signed = signToken(jwtpkg.SigningMethodES256, "", ecKey1)
+			})
+
+			It("tries all the keys", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+})
+
+// jwksServer serves JWKS documents and counts the requests.
+type jwksServer struct {
+	sync.Mutex
+	status   int
+	doc      []byte
+	requests int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	s.requests++
+	w.WriteHeader(s.status)
+	w.Write(s.doc)
+}
+
+func (s *jwksServer) setKeys(keys ...map[string]string) {
+	doc, _ := json.Marshal(map[string]interface{}{"keys": keys})
+	s.Lock()
+	defer s.Unlock()
+	s.doc = doc
+}
+
+func (s *jwksServer) count() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.requests
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": key.Curve.Params().Name,
+		"x":   b64(key.X.Bytes()),
+		"y":   b64(key.Y.Bytes()),
+	}
+}
+
+func signToken(method jwtpkg.SigningMethod, kid string, key interface{}) string {
+	token := jwtpkg.NewWithClaims(method, jwtpkg.MapClaims{"scopes": "scope1"})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	Ω(err).ShouldNot(HaveOccurred())
+	return signed
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
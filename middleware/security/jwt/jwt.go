@@ -25,7 +25,19 @@ import (
 //        against the scopes presented by the JWT in the claim "scope", or if
 //        that's not defined, "scopes".
 //
-// The `exp` (expiration) and `nbf` (not before) date checks are validated by the JWT library.
+// The `exp` (expiration), `nbf` (not before) and `iat` (issued at) date checks tolerate the clock
+// skew configured with the Leeway option.
+//
+// The optional opts make it possible to restrict the accepted issuers, audiences and signing
+// algorithms and to require claims. They default to the settings of the scheme which are
+// initialized from the design (see the Issuer, Audience, Leeway, RequiredClaims and
+// SigningAlgorithms DSL functions). Each failed validation returns a distinct ErrJWTError message:
+//
+//    app.UseJWT(jwt.New(resolver, nil, app.NewJWTSecurity(),
+//        jwt.Issuers("https://accounts.example.com"),
+//        jwt.Audiences("my-api"),
+//        jwt.Leeway(30*time.Second),
+//    ))
 //
 // validationKeys can be one of these:
 //
@@ -38,6 +50,9 @@ import (
 // Keys of type string or []byte are interpreted according to the signing method defined in the JWT
 // token's `typ` header element: `HS`, `RS`, `ES`, etc.
 //
+// Resolvers that implement KIDResolver such as the resolver returned by NewJWKSResolver select the
+// key using the token `kid` header element.
+//
 // You can define an optional function to do additional validations on the token once the signature
 // and the claims requirements are proven to be valid.  Example:
 //
@@ -59,7 +74,8 @@ import (
 //    jwtResolver, _ := jwt.NewSimpleResolver("secret")
 //    app.UseJWT(jwt.New(jwtResolver, validationHandler, app.NewJWTSecurity()))
 //
-func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSecurity) goa.Middleware {
+func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSecurity, opts ...Option) goa.Middleware {
+	o := newOptions(scheme, opts)
 	return func(nextHandler goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			var (
@@ -81,7 +97,11 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 				return fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
 			}
 
-			rsaKeys, ecdsaKeys, hmacKeys := partitionKeys(resolver.SelectKeys(req))
+			if err := o.validateAlgorithm(incomingToken); err != nil {
+				return err
+			}
+
+			rsaKeys, ecdsaKeys, hmacKeys := partitionKeys(selectKeys(resolver, req, incomingToken))
 
 			var (
 				token     *jwt.Token
@@ -113,6 +133,10 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 				return ErrJWTError("JWT validation failed")
 			}
 
+			if err := o.validateClaims(token); err != nil {
+				return err
+			}
+
 			scopesInClaim, scopesInClaimList, err := parseClaimScopes(token)
 			if err != nil {
 				goa.LogError(ctx, err.Error())
@@ -161,6 +185,20 @@ func extractTokenFromQueryParam(schemeName string, req *http.Request) (string, e
 	return incomingToken, nil
 }
 
+// selectKeys returns the keys used to validate the incoming token. It uses the
+// token "kid" header if the resolver supports key IDs.
+func selectKeys(resolver KeyResolver, req *http.Request, incomingToken string) []Key {
+	if kr, ok := resolver.(KIDResolver); ok {
+		token, _, err := new(jwt.Parser).ParseUnverified(incomingToken, jwt.MapClaims{})
+		if err == nil {
+			if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+				return kr.SelectKIDKeys(req, kid)
+			}
+		}
+	}
+	return resolver.SelectKeys(req)
+}
+
 // partitionKeys sorts keys by their type.
 func partitionKeys(keys []Key) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte) {
 	var (
@@ -185,6 +223,10 @@ func partitionKeys(keys []Key) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte)
 	return rsaKeys, ecdsaKeys, hmacKeys
 }
 
+// parser parses and validates the token signatures. The claims are validated by
+// the middleware so that the leeway applies.
+var parser = &jwt.Parser{SkipClaimsValidation: true}
+
 // validScopeClaimKeys are the claims under which scopes may be found in a token
 var validScopeClaimKeys = []string{"scope", "scopes"}
 
@@ -230,7 +272,7 @@ func parseClaimScopes(token *jwt.Token) (map[string]bool, []string, error) {
 
 func validateRSAKeys(rsaKeys []*rsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, pubkey := range rsaKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
@@ -245,7 +287,7 @@ func validateRSAKeys(rsaKeys []*rsa.PublicKey, algo, incomingToken string) (toke
 
 func validateECDSAKeys(ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, pubkey := range ecdsaKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
@@ -260,7 +302,7 @@ func validateECDSAKeys(ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string)
 
 func validateHMACKeys(hmacKeys [][]byte, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, key := range hmacKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (interface{}, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
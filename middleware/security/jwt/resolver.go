@@ -23,6 +23,15 @@ type (
 		SelectKeys(req *http.Request) []Key
 	}
 
+	// KIDResolver is a key resolver that selects keys using the key ID contained in the "kid"
+	// header of the incoming token. The middleware calls SelectKIDKeys instead of SelectKeys
+	// when the token has a "kid" header.
+	KIDResolver interface {
+		KeyResolver
+		// SelectKIDKeys returns the keys identified by kid.
+		SelectKIDKeys(req *http.Request, kid string) []Key
+	}
+
 	// GroupResolver is a key resolver that switches on the value of a specified request header
 	// for selecting the key group used to authorize the incoming request.
 	GroupResolver struct {
@@ -0,0 +1,88 @@
+package oauth2
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+type (
+	// cache stores token introspection results indexed by token hash.
+	cache struct {
+		sync.Mutex
+		ttl     time.Duration
+		size    int
+		entries map[[sha256.Size]byte]*cacheEntry
+	}
+
+	// cacheEntry is a cached introspection result.
+	cacheEntry struct {
+		introspection *Introspection
+		expires       time.Time
+	}
+)
+
+// newCache returns a cache that keeps up to size results for at most ttl.
+func newCache(ttl time.Duration, size int) *cache {
+	return &cache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[[sha256.Size]byte]*cacheEntry),
+	}
+}
+
+// get returns the cached introspection result for token if any.
+func (c *cache) get(token string, now time.Time) (*Introspection, bool) {
+	key := sha256.Sum256([]byte(token))
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.introspection, true
+}
+
+// set caches the introspection result for token. Results are not cached past the token
+// expiration.
+func (c *cache) set(token string, i *Introspection, now time.Time) {
+	if c.ttl <= 0 || c.size <= 0 {
+		return
+	}
+	expires := now.Add(c.ttl)
+	if i.Exp != 0 {
+		if exp := time.Unix(i.Exp, 0); exp.Before(expires) {
+			expires = exp
+		}
+	}
+	if !now.Before(expires) {
+		return
+	}
+	key := sha256.Sum256([]byte(token))
+	c.Lock()
+	defer c.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[key] = &cacheEntry{introspection: i, expires: expires}
+}
+
+// evict removes the expired entries or an arbitrary entry if none has expired.
+func (c *cache) evict(now time.Time) {
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) < c.size {
+		return
+	}
+	for k := range c.entries {
+		delete(c.entries, k)
+		return
+	}
+}
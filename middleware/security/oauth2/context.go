@@ -0,0 +1,24 @@
+package oauth2
+
+import "context"
+
+type contextKey int
+
+const (
+	introspectionKey contextKey = iota + 1
+)
+
+// WithIntrospection creates a child context containing the given token introspection result.
+func WithIntrospection(ctx context.Context, i *Introspection) context.Context {
+	return context.WithValue(ctx, introspectionKey, i)
+}
+
+// ContextIntrospection retrieves the token introspection result from a `context` that went through
+// our security middleware.
+func ContextIntrospection(ctx context.Context) *Introspection {
+	i, ok := ctx.Value(introspectionKey).(*Introspection)
+	if !ok {
+		return nil
+	}
+	return i
+}
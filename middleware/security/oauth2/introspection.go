@@ -0,0 +1,117 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type (
+	// Introspector retrieves the state of OAuth2 tokens.
+	Introspector interface {
+		// Introspect returns the state of the given token.
+		Introspect(ctx context.Context, token string) (*Introspection, error)
+	}
+
+	// Introspection is the response of a token introspection endpoint as defined by RFC 7662.
+	Introspection struct {
+		// Active indicates whether the token is currently active.
+		Active bool `json:"active"`
+		// Scope is the space-separated list of scopes associated with the token.
+		Scope string `json:"scope,omitempty"`
+		// ClientID is the identifier of the client that requested the token.
+		ClientID string `json:"client_id,omitempty"`
+		// Username is the human-readable identifier of the resource owner.
+		Username string `json:"username,omitempty"`
+		// TokenType is the type of the token.
+		TokenType string `json:"token_type,omitempty"`
+		// Exp is the time at which the token expires in seconds since the epoch.
+		Exp int64 `json:"exp,omitempty"`
+		// Iat is the time at which the token was issued in seconds since the epoch.
+		Iat int64 `json:"iat,omitempty"`
+		// Nbf is the time before which the token must not be used in seconds since the epoch.
+		Nbf int64 `json:"nbf,omitempty"`
+		// Sub is the subject of the token.
+		Sub string `json:"sub,omitempty"`
+		// Aud is the audience of the token.
+		Aud interface{} `json:"aud,omitempty"`
+		// Iss is the issuer of the token.
+		Iss string `json:"iss,omitempty"`
+		// Jti is the identifier of the token.
+		Jti string `json:"jti,omitempty"`
+	}
+
+	// httpIntrospector calls a RFC 7662 token introspection endpoint.
+	httpIntrospector struct {
+		endpoint     string
+		clientID     string
+		clientSecret string
+		client       *http.Client
+	}
+)
+
+// NewIntrospector returns an introspector that calls the RFC 7662 token introspection endpoint
+// located at the given URL. The introspector authenticates with the endpoint using HTTP basic
+// authentication if clientID is not empty. It uses http.DefaultClient if client is nil.
+func NewIntrospector(endpoint, clientID, clientSecret string, client *http.Client) Introspector {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpIntrospector{
+		endpoint:     endpoint,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       client,
+	}
+}
+
+// Introspect posts the token to the introspection endpoint.
+func (i *httpIntrospector) Introspect(ctx context.Context, token string) (*Introspection, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequest("POST", i.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+	}
+	resp, err := i.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("token introspection failed: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token introspection failed: unexpected status %s", resp.Status)
+	}
+	var res Introspection
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("token introspection failed: invalid response: %s", err)
+	}
+	return &res, nil
+}
+
+// Scopes returns the list of scopes associated with the token.
+func (i *Introspection) Scopes() []string {
+	return strings.Fields(i.Scope)
+}
+
+// Valid returns true if the token is active and the current time is within the token validity
+// period.
+func (i *Introspection) Valid(now time.Time) bool {
+	if !i.Active {
+		return false
+	}
+	if i.Exp != 0 && now.Unix() >= i.Exp {
+		return false
+	}
+	if i.Nbf != 0 && now.Unix() < i.Nbf {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+var (
+	// ErrInvalidToken is the error returned by this middleware when the request bearer token
+	// is missing, inactive or expired.
+	ErrInvalidToken = goa.NewErrorClass("invalid_token", 401)
+
+	// ErrInsufficientScope is the error returned by this middleware when the request bearer
+	// token does not have the scopes required by the action.
+	ErrInsufficientScope = goa.NewErrorClass("insufficient_scope", 403)
+)
+
+type (
+	// Option is a constructor option that makes it possible to customize the middleware.
+	Option func(*options) *options
+
+	// options is the struct storing all the options.
+	options struct {
+		cacheTTL  time.Duration
+		cacheSize int
+	}
+)
+
+// CacheTTL sets the maximum duration during which the middleware caches the introspection results.
+// Results are never cached past the token expiration. A value of 0 disables caching. Defaults to
+// one minute.
+func CacheTTL(d time.Duration) Option {
+	if d < 0 {
+		panic("cache TTL cannot be negative")
+	}
+	return func(o *options) *options {
+		o.cacheTTL = d
+		return o
+	}
+}
+
+// CacheSize sets the maximum number of cached introspection results. Defaults to 10,000.
+func CacheSize(n int) Option {
+	if n <= 0 {
+		panic("cache size must be greater than 0")
+	}
+	return func(o *options) *options {
+		o.cacheSize = n
+		return o
+	}
+}
+
+// New returns a middleware to be used with the OAuth2Security DSL definitions of goa. The
+// middleware validates the opaque bearer token of the incoming request using the given
+// introspector (see RFC 7662) and caches the results.
+//
+// The steps taken by the middleware are:
+//     1. Extract the "Bearer" token from the Authorization header
+//     2. Introspect the token unless the result is already cached
+//     3. Check that the token is active and not expired
+//     4. If scopes are defined in the design for the action, validate them
+//        against the scopes listed by the introspection result
+//
+// The introspection result is available to the handlers via ContextIntrospection.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//    introspector := oauth2.NewIntrospector("https://auth.example.com/introspect", "id", "secret", nil)
+//    app.UseOAuth2Middleware(service, oauth2.New(introspector))
+//
+func New(introspector Introspector, opts ...Option) goa.Middleware {
+	o := &options{
+		cacheTTL:  time.Minute,
+		cacheSize: 10000,
+	}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	c := newCache(o.cacheTTL, o.cacheSize)
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			token, err := extractToken(req)
+			if err != nil {
+				rw.Header().Set("WWW-Authenticate", "Bearer")
+				return err
+			}
+
+			now := time.Now()
+			introspection, ok := c.get(token, now)
+			if !ok {
+				introspection, err = introspector.Introspect(ctx, token)
+				if err != nil {
+					return err
+				}
+				c.set(token, introspection, now)
+			}
+
+			if !introspection.Valid(now) {
+				rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				return ErrInvalidToken("token is inactive or expired")
+			}
+
+			requiredScopes := goa.ContextRequiredScopes(ctx)
+			scopes := introspection.Scopes()
+			for _, scope := range requiredScopes {
+				if !contains(scopes, scope) {
+					rw.Header().Set("WWW-Authenticate",
+						fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, strings.Join(requiredScopes, " ")))
+					return ErrInsufficientScope("authorization failed: required scopes not granted to token",
+						"required", requiredScopes, "scopes", scopes)
+				}
+			}
+
+			return nextHandler(WithIntrospection(ctx, introspection), rw, req)
+		}
+	}
+}
+
+// extractToken returns the bearer token of the request.
+func extractToken(req *http.Request) (string, error) {
+	val := req.Header.Get("Authorization")
+	if val == "" {
+		return "", ErrInvalidToken(`missing header "Authorization"`)
+	}
+	if !strings.HasPrefix(strings.ToLower(val), "bearer ") {
+		return "", ErrInvalidToken("invalid or malformed \"Authorization\" header, expected 'Bearer token...'")
+	}
+	token := strings.TrimSpace(val[len("bearer "):])
+	if token == "" {
+		return "", ErrInvalidToken("invalid or malformed \"Authorization\" header, expected 'Bearer token...'")
+	}
+	return token, nil
+}
+
+// contains returns true if vals contains val.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package oauth2_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/oauth2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var server *introspectionServer
+	var ts *httptest.Server
+	var opts []oauth2.Option
+	var request *http.Request
+	var respRecord *httptest.ResponseRecorder
+	var requiredScopes []string
+	var handlerCtx context.Context
+	var middleware goa.Middleware
+	var dispatchResult error
+
+	dispatch := func() {
+		handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			handlerCtx = ctx
+			return nil
+		}
+		ctx := goa.WithRequiredScopes(context.Background(), requiredScopes)
+		respRecord = httptest.NewRecorder()
+		dispatchResult = middleware(handler)(ctx, respRecord, request)
+	}
+
+	BeforeEach(func() {
+		server = &introspectionServer{tokens: map[string]*oauth2.Introspection{
+			"good": {Active: true, Scope: "read write", Sub: "user", Exp: time.Now().Add(time.Hour).Unix()},
+			"old":  {Active: true, Scope: "read", Exp: time.Now().Add(-time.Minute).Unix()},
+		}}
+		ts = httptest.NewServer(server)
+		opts = nil
+		requiredScopes = nil
+		handlerCtx = nil
+		request, _ = http.NewRequest("GET", "http://example.com/", nil)
+		request.Header.Set("Authorization", "Bearer good")
+	})
+
+	JustBeforeEach(func() {
+		middleware = oauth2.New(oauth2.NewIntrospector(ts.URL, "client", "s3cr3t", nil), opts...)
+		dispatch()
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("authenticates with the introspection endpoint", func() {
+		Ω(dispatchResult).ShouldNot(HaveOccurred())
+		Ω(server.user).Should(Equal("client"))
+		Ω(server.password).Should(Equal("s3cr3t"))
+	})
+
+	It("stores the introspection result in the context", func() {
+		i := oauth2.ContextIntrospection(handlerCtx)
+		Ω(i).ShouldNot(BeNil())
+		Ω(i.Sub).Should(Equal("user"))
+		Ω(i.Scopes()).Should(Equal([]string{"read", "write"}))
+	})
+
+	It("caches the introspection results", func() {
+		dispatch()
+		Ω(dispatchResult).ShouldNot(HaveOccurred())
+		Ω(server.count()).Should(Equal(1))
+	})
+
+	Context("with caching disabled", func() {
+		BeforeEach(func() {
+			opts = append(opts, oauth2.CacheTTL(0))
+		})
+
+		It("introspects each request", func() {
+			dispatch()
+			Ω(server.count()).Should(Equal(2))
+		})
+	})
+
+	Context("with the required scopes", func() {
+		BeforeEach(func() {
+			requiredScopes = []string{"write"}
+		})
+
+		It("authorizes the request", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with missing scopes", func() {
+		BeforeEach(func() {
+			requiredScopes = []string{"read", "admin"}
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(goa.ServiceError).ResponseStatus()).Should(Equal(403))
+			Ω(respRecord.Header().Get("WWW-Authenticate")).Should(Equal(`Bearer error="insufficient_scope", scope="read admin"`))
+			Ω(handlerCtx).Should(BeNil())
+		})
+	})
+
+	Context("with an unknown token", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "Bearer bad")
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+			Ω(respRecord.Header().Get("WWW-Authenticate")).Should(Equal(`Bearer error="invalid_token"`))
+		})
+	})
+
+	Context("with an expired token", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "Bearer old")
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.Error()).Should(ContainSubstring("inactive or expired"))
+		})
+	})
+
+	Context("with no token", func() {
+		BeforeEach(func() {
+			request.Header.Del("Authorization")
+		})
+
+		It("rejects the request without introspecting", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+			Ω(server.count()).Should(Equal(0))
+		})
+	})
+
+	Context("with a failing introspection endpoint", func() {
+		BeforeEach(func() {
+			server.fail = true
+		})
+
+		It("returns an error", func() {
+			Ω(dispatchResult).Should(MatchError(ContainSubstring("token introspection failed")))
+		})
+	})
+})
+
+// introspectionServer is a fake RFC 7662 introspection endpoint.
+type introspectionServer struct {
+	sync.Mutex
+	tokens   map[string]*oauth2.Introspection
+	fail     bool
+	requests int
+	user     string
+	password string
+}
+
+func (s *introspectionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	s.requests++
+	s.user, s.password, _ = r.BasicAuth()
+	if s.fail {
+		w.WriteHeader(500)
+		return
+	}
+	i, ok := s.tokens[r.FormValue("token")]
+	if !ok {
+		i = &oauth2.Introspection{Active: false}
+	}
+	json.NewEncoder(w).Encode(i)
+}
+
+func (s *introspectionServer) count() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.requests
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// Span describes the handling of a sampled request by the tracer
+	// middleware.
+	Span struct {
+		// TraceID is the ID of the trace the span belongs to.
+		TraceID string
+		// SpanID is the ID of the span.
+		SpanID string
+		// ParentSpanID is the ID of the parent span if any.
+		ParentSpanID string
+		// TraceState is the W3C tracestate value propagated with the
+		// trace if any.
+		TraceState string
+		// Name is the name of the span, "<resource>.<action>" when the
+		// request is handled by a goa controller, "<method> <path>"
+		// otherwise.
+		Name string
+		// StartTime is the time the middleware received the request.
+		StartTime time.Time
+		// EndTime is the time the handler returned.
+		EndTime time.Time
+		// Status is the HTTP status code of the response.
+		Status int
+		// Error is the error returned by the handler if any.
+		Error error
+	}
+
+	// SpanExporter is the interface implemented by the objects that send the
+	// spans recorded by the tracer middleware to a tracing backend.
+	// Implementations must be safe for concurrent use and should not block,
+	// ExportSpan is called synchronously before the middleware returns.
+	SpanExporter interface {
+		// ExportSpan exports the given span. ctx is the request context.
+		ExportSpan(ctx context.Context, span *Span)
+	}
+
+	// InMemoryExporter is a SpanExporter that keeps the spans in memory.
+	// It is intended for tests.
+	InMemoryExporter struct {
+		lock  sync.Mutex
+		spans []*Span
+	}
+)
+
+// NewInMemoryExporter creates an exporter that records the spans in memory.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan records the span.
+func (e *InMemoryExporter) ExportSpan(_ context.Context, span *Span) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the spans recorded so far in the order they were exported.
+func (e *InMemoryExporter) Spans() []*Span {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	spans := make([]*Span, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Reset deletes the recorded spans.
+func (e *InMemoryExporter) Reset() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = nil
+}
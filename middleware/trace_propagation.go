@@ -0,0 +1,257 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceParentHeader is the name of the W3C Trace Context header containing
+	// the trace ID, the parent span ID and the trace flags.
+	TraceParentHeader = "traceparent"
+
+	// TraceStateHeader is the name of the W3C Trace Context header containing
+	// the vendor specific trace information.
+	TraceStateHeader = "tracestate"
+
+	// B3TraceIDHeader is the name of the B3 header containing the trace ID.
+	B3TraceIDHeader = "X-B3-TraceId"
+
+	// B3SpanIDHeader is the name of the B3 header containing the span ID.
+	B3SpanIDHeader = "X-B3-SpanId"
+
+	// B3ParentSpanIDHeader is the name of the B3 header containing the parent
+	// span ID.
+	B3ParentSpanIDHeader = "X-B3-ParentSpanId"
+
+	// B3SampledHeader is the name of the B3 header containing the sampling
+	// decision.
+	B3SampledHeader = "X-B3-Sampled"
+
+	// B3FlagsHeader is the name of the B3 header containing the debug flag.
+	B3FlagsHeader = "X-B3-Flags"
+
+	// B3SingleHeader is the name of the B3 single header format.
+	B3SingleHeader = "b3"
+)
+
+const (
+	// GoaTraceFormat propagates the trace using the TraceIDHeader and
+	// ParentSpanIDHeader headers.
+	GoaTraceFormat TraceFormat = iota + 1
+
+	// W3CTraceFormat propagates the trace using the W3C Trace Context
+	// traceparent and tracestate headers, see https://www.w3.org/TR/trace-context/.
+	W3CTraceFormat
+
+	// B3TraceFormat propagates the trace using the Zipkin B3 headers, see
+	// https://github.com/openzipkin/b3-propagation. Both the multiple and
+	// single header encodings are read, the multiple headers encoding is
+	// written.
+	B3TraceFormat
+)
+
+type (
+	// TraceFormat identifies the set of HTTP headers used to propagate the
+	// trace information across services.
+	TraceFormat int
+
+	// traceContext is the trace information extracted from a request.
+	traceContext struct {
+		traceID  string
+		parentID string
+		state    string
+		sampled  bool
+	}
+)
+
+// String returns the name of the format.
+func (f TraceFormat) String() string {
+	switch f {
+	case GoaTraceFormat:
+		return "goa"
+	case W3CTraceFormat:
+		return "w3c"
+	case B3TraceFormat:
+		return "b3"
+	}
+	return fmt.Sprintf("TraceFormat(%d)", int(f))
+}
+
+// W3CTraceID is a trace ID creation algorithm which produces values that are
+// compatible with W3C Trace Context and B3 (32 lowercase hex characters).
+func W3CTraceID() string {
+	return randomHex(16)
+}
+
+// W3CSpanID is a span ID creation algorithm which produces values that are
+// compatible with W3C Trace Context and B3 (16 lowercase hex characters).
+func W3CSpanID() string {
+	return randomHex(8)
+}
+
+// extractTrace reads the trace information from the request headers using the
+// first format in formats for which the request contains valid headers.
+func extractTrace(req *http.Request, formats []TraceFormat) (*traceContext, bool) {
+	for _, f := range formats {
+		var (
+			tc *traceContext
+			ok bool
+		)
+		switch f {
+		case GoaTraceFormat:
+			tc, ok = extractGoaTrace(req)
+		case W3CTraceFormat:
+			tc, ok = extractW3CTrace(req)
+		case B3TraceFormat:
+			tc, ok = extractB3Trace(req)
+		}
+		if ok {
+			return tc, true
+		}
+	}
+	return nil, false
+}
+
+// injectTrace writes the trace headers for each format in formats. spanID
+// is the ID of the span making the request, it becomes the parent span ID of
+// the downstream service.
+func injectTrace(req *http.Request, formats []TraceFormat, traceID, spanID, state string, sampled bool) {
+	for _, f := range formats {
+		switch f {
+		case GoaTraceFormat:
+			req.Header.Set(TraceIDHeader, traceID)
+			req.Header.Set(ParentSpanIDHeader, spanID)
+		case W3CTraceFormat:
+			if !isHexID(traceID, 32) || !isHexID(spanID, 16) {
+				continue
+			}
+			flags := "00"
+			if sampled {
+				flags = "01"
+			}
+			req.Header.Set(TraceParentHeader, "00-"+traceID+"-"+spanID+"-"+flags)
+			if state != "" {
+				req.Header.Set(TraceStateHeader, state)
+			}
+		case B3TraceFormat:
+			if !isHexID(traceID, 16) && !isHexID(traceID, 32) || !isHexID(spanID, 16) {
+				continue
+			}
+			sampledVal := "0"
+			if sampled {
+				sampledVal = "1"
+			}
+			req.Header.Set(B3TraceIDHeader, traceID)
+			req.Header.Set(B3SpanIDHeader, spanID)
+			req.Header.Set(B3SampledHeader, sampledVal)
+		}
+	}
+}
+
+// extractGoaTrace reads the goa trace headers. Requests carrying a trace ID
+// are always sampled.
+func extractGoaTrace(req *http.Request) (*traceContext, bool) {
+	traceID := req.Header.Get(TraceIDHeader)
+	if traceID == "" {
+		return nil, false
+	}
+	return &traceContext{
+		traceID:  traceID,
+		parentID: req.Header.Get(ParentSpanIDHeader),
+		sampled:  true,
+	}, true
+}
+
+// extractW3CTrace parses the traceparent and tracestate headers. The
+// tracestate header is only considered if the traceparent header is valid.
+func extractW3CTrace(req *http.Request) (*traceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(req.Header.Get(TraceParentHeader)), "-")
+	if len(parts) < 4 {
+		return nil, false
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isHex(version, 2) || version == "ff" || version == "00" && len(parts) != 4 {
+		return nil, false
+	}
+	if !isHexID(traceID, 32) || !isHexID(parentID, 16) || !isHex(flags, 2) {
+		return nil, false
+	}
+	fl, _ := hex.DecodeString(flags)
+	return &traceContext{
+		traceID:  traceID,
+		parentID: parentID,
+		state:    strings.Join(req.Header[http.CanonicalHeaderKey(TraceStateHeader)], ","),
+		sampled:  fl[0]&1 == 1,
+	}, true
+}
+
+// extractB3Trace parses the B3 headers, using the single header encoding if
+// present.
+func extractB3Trace(req *http.Request) (*traceContext, bool) {
+	if single := req.Header.Get(B3SingleHeader); single != "" {
+		return parseB3Single(single)
+	}
+	traceID := strings.ToLower(req.Header.Get(B3TraceIDHeader))
+	spanID := strings.ToLower(req.Header.Get(B3SpanIDHeader))
+	if !isHexID(traceID, 16) && !isHexID(traceID, 32) || !isHexID(spanID, 16) {
+		return nil, false
+	}
+	sampled := true
+	switch req.Header.Get(B3SampledHeader) {
+	case "0", "false":
+		sampled = false
+	}
+	if req.Header.Get(B3FlagsHeader) == "1" {
+		sampled = true
+	}
+	return &traceContext{traceID: traceID, parentID: spanID, sampled: sampled}, true
+}
+
+// parseB3Single parses the B3 single header encoding
+// {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}.
+func parseB3Single(val string) (*traceContext, bool) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(val)), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		// A lone sampling state ("0", "1" or "d") carries no trace.
+		return nil, false
+	}
+	traceID, spanID := parts[0], parts[1]
+	if !isHexID(traceID, 16) && !isHexID(traceID, 32) || !isHexID(spanID, 16) {
+		return nil, false
+	}
+	sampled := true
+	if len(parts) > 2 && parts[2] == "0" {
+		sampled = false
+	}
+	return &traceContext{traceID: traceID, parentID: spanID, sampled: sampled}, true
+}
+
+// isHexID returns true if id is a lowercase hex string of length n that is not
+// made only of zeros.
+func isHexID(id string, n int) bool {
+	return isHex(id, n) && strings.Trim(id, "0") != ""
+}
+
+// isHex returns true if s is a lowercase hex string of length n.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex returns n random bytes encoded in lowercase hex.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
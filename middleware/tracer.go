@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/client"
@@ -34,12 +36,15 @@ type (
 		samplingPercent int
 		maxSamplingRate int
 		sampleSize      int
+		formats         []TraceFormat
+		exporter        SpanExporter
 	}
 
 	// tracedDoer is a goa client Doer that inserts the tracing headers for
 	// each request it makes.
 	tracedDoer struct {
 		client.Doer
+		formats []TraceFormat
 	}
 )
 
@@ -106,6 +111,39 @@ func SampleSize(s int) TracerOption {
 	}
 }
 
+// PropagationFormats sets the formats of the headers used to propagate the
+// trace. The middleware uses the first format for which the incoming request
+// contains valid headers, TraceDoer writes the headers of all the formats.
+// Defaults to GoaTraceFormat. The default trace and span ID functions produce
+// W3C compatible IDs when W3CTraceFormat or B3TraceFormat is used.
+func PropagationFormats(formats ...TraceFormat) TracerOption {
+	if len(formats) == 0 {
+		panic("propagation formats cannot be empty")
+	}
+	for _, f := range formats {
+		if f < GoaTraceFormat || f > B3TraceFormat {
+			panic(fmt.Sprintf("invalid propagation format %d", int(f)))
+		}
+	}
+	return func(o *tracerOptions) *tracerOptions {
+		o.formats = formats
+		return o
+	}
+}
+
+// ExportSpans sets the exporter used to export the spans of sampled requests.
+// Requests whose incoming trace headers indicate that the trace is not sampled
+// are propagated but not exported.
+func ExportSpans(e SpanExporter) TracerOption {
+	return func(o *tracerOptions) *tracerOptions {
+		if e == nil {
+			panic("span exporter cannot be nil")
+		}
+		o.exporter = e
+		return o
+	}
+}
+
 // NewTracer returns a trace middleware that initializes the trace information
 // in the request context. The information can be retrieved using any of the
 // ContextXXX functions.
@@ -118,16 +156,13 @@ func SampleSize(s int) TracerOption {
 // IDs respectively. This is configurable so that the created IDs are compatible
 // with the various backend tracing systems. The xray package provides
 // implementations that produce AWS X-Ray compatible IDs.
+//
+// The trace headers are read using the formats given to PropagationFormats,
+// W3C Trace Context and B3 are supported in addition to the goa headers.
+// The spans of sampled requests are given to the exporter set with
+// ExportSpans if any.
 func NewTracer(opts ...TracerOption) goa.Middleware {
-	o := &tracerOptions{
-		traceIDFunc:     shortID,
-		spanIDFunc:      shortID,
-		samplingPercent: 100,
-		sampleSize:      1000, // only applies if maxSamplingRate is set
-	}
-	for _, opt := range opts {
-		o = opt(o)
-	}
+	o := newTracerOptions(opts)
 	var sampler Sampler
 	if o.maxSamplingRate > 0 {
 		sampler = NewAdaptiveSampler(o.maxSamplingRate, o.sampleSize)
@@ -137,21 +172,42 @@ func NewTracer(opts ...TracerOption) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			// insert a new trace ID only if not already being traced.
-			traceID := req.Header.Get(TraceIDHeader)
-			if traceID == "" {
+			tc, ok := extractTrace(req, o.formats)
+			if !ok {
 				// insert tracing only within sample.
-				if sampler.Sample() {
-					traceID = o.traceIDFunc()
-				} else {
+				if !sampler.Sample() {
 					return h(ctx, rw, req)
 				}
+				tc = &traceContext{traceID: o.traceIDFunc(), sampled: true}
 			}
 
 			// insert IDs into context to enable tracing.
 			spanID := o.spanIDFunc()
-			parentID := req.Header.Get(ParentSpanIDHeader)
-			ctx = WithTrace(ctx, traceID, spanID, parentID)
-			return h(ctx, rw, req)
+			ctx = WithTrace(ctx, tc.traceID, spanID, tc.parentID)
+			if tc.state != "" {
+				ctx = context.WithValue(ctx, traceStateKey, tc.state)
+			}
+			if !tc.sampled {
+				ctx = context.WithValue(ctx, traceSampledKey, false)
+			}
+			if o.exporter == nil || !tc.sampled {
+				return h(ctx, rw, req)
+			}
+
+			span := &Span{
+				TraceID:      tc.traceID,
+				SpanID:       spanID,
+				ParentSpanID: tc.parentID,
+				TraceState:   tc.state,
+				StartTime:    time.Now(),
+			}
+			err := h(ctx, rw, req)
+			span.EndTime = time.Now()
+			span.Error = err
+			span.Name = spanName(ctx, req)
+			span.Status = responseStatus(ctx, err)
+			o.exporter.ExportSpan(ctx, span)
+			return err
 		}
 	}
 }
@@ -163,8 +219,10 @@ func Tracer(sampleRate int, spanIDFunc, traceIDFunc IDFunc) goa.Middleware {
 
 // TraceDoer wraps a goa client Doer and sets the trace headers so that the
 // downstream service may properly retrieve the parent span ID and trace ID.
-func TraceDoer(doer client.Doer) client.Doer {
-	return &tracedDoer{doer}
+// The headers are written using the formats given to PropagationFormats,
+// other options are ignored.
+func TraceDoer(doer client.Doer, opts ...TracerOption) client.Doer {
+	return &tracedDoer{Doer: doer, formats: newTracerOptions(opts).formats}
 }
 
 // ContextTraceID returns the trace ID extracted from the given context if any,
@@ -194,6 +252,28 @@ func ContextParentSpanID(ctx context.Context) string {
 	return ""
 }
 
+// ContextTraceState returns the W3C tracestate value extracted from the given
+// context if any, the empty string otherwise.
+func ContextTraceState(ctx context.Context) string {
+	if s := ctx.Value(traceStateKey); s != nil {
+		return s.(string)
+	}
+	return ""
+}
+
+// ContextTraceSampled returns true if the given context contains a trace that
+// is sampled. Traces propagated with headers indicating that the upstream
+// service did not sample them are not sampled, all the other traces are.
+func ContextTraceSampled(ctx context.Context) bool {
+	if ContextTraceID(ctx) == "" {
+		return false
+	}
+	if s := ctx.Value(traceSampledKey); s != nil {
+		return s.(bool)
+	}
+	return true
+}
+
 // WithTrace returns a context containing the given trace, span and parent span
 // IDs.
 func WithTrace(ctx context.Context, traceID, spanID, parentID string) context.Context {
@@ -212,9 +292,64 @@ func (d *tracedDoer) Do(ctx context.Context, req *http.Request) (*http.Response,
 		spanID  = ContextSpanID(ctx)
 	)
 	if traceID != "" {
-		req.Header.Set(TraceIDHeader, traceID)
-		req.Header.Set(ParentSpanIDHeader, spanID)
+		injectTrace(req, d.formats, traceID, spanID, ContextTraceState(ctx), ContextTraceSampled(ctx))
 	}
 
 	return d.Doer.Do(ctx, req)
 }
+
+// newTracerOptions initializes the options with their default values and
+// applies opts.
+func newTracerOptions(opts []TracerOption) *tracerOptions {
+	o := &tracerOptions{
+		samplingPercent: 100,
+		sampleSize:      1000, // only applies if maxSamplingRate is set
+		formats:         []TraceFormat{GoaTraceFormat},
+	}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	var hexIDs bool
+	for _, f := range o.formats {
+		if f != GoaTraceFormat {
+			hexIDs = true
+		}
+	}
+	if o.traceIDFunc == nil {
+		o.traceIDFunc = shortID
+		if hexIDs {
+			o.traceIDFunc = W3CTraceID
+		}
+	}
+	if o.spanIDFunc == nil {
+		o.spanIDFunc = shortID
+		if hexIDs {
+			o.spanIDFunc = W3CSpanID
+		}
+	}
+	return o
+}
+
+// spanName computes the name of the span recorded for the request.
+func spanName(ctx context.Context, req *http.Request) string {
+	if action := goa.ContextAction(ctx); action != "<unknown>" {
+		return goa.ContextController(ctx) + "." + action
+	}
+	return req.Method + " " + req.URL.Path
+}
+
+// responseStatus returns the status of the response written by the handler,
+// the status of the error returned by the handler if the response was not
+// written yet.
+func responseStatus(ctx context.Context, err error) int {
+	if resp := goa.ContextResponse(ctx); resp != nil && resp.Status != 0 {
+		return resp.Status
+	}
+	if err == nil {
+		return http.StatusOK
+	}
+	if serr, ok := err.(goa.ServiceError); ok {
+		return serr.ResponseStatus()
+	}
+	return http.StatusInternalServerError
+}
@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/goadesign/goa"
 )
 
 func TestNewTracer(t *testing.T) {
@@ -169,3 +172,313 @@ func TestTracerMiddleware(t *testing.T) {
 		}
 	}
 }
+
+func TestTracerPropagation(t *testing.T) {
+	var (
+		traceID    = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID   = "00f067aa0ba902b7"
+		spanID     = "b7ad6b7169203331"
+		newTraceID = func() string { return "0af7651916cd43dd8448eb211c80319c" }
+		newID      = func() string { return spanID }
+	)
+
+	cases := map[string]struct {
+		Formats []TraceFormat
+		Headers map[string]string
+		// output
+		CtxTraceID, CtxParentID, CtxTraceState string
+		CtxSampled                             bool
+	}{
+		"w3c": {
+			[]TraceFormat{W3CTraceFormat},
+			map[string]string{TraceParentHeader: "00-" + traceID + "-" + parentID + "-01", TraceStateHeader: "congo=t61rcWkgMzE"},
+			traceID, parentID, "congo=t61rcWkgMzE", true,
+		},
+		"w3c-not-sampled": {
+			[]TraceFormat{W3CTraceFormat},
+			map[string]string{TraceParentHeader: "00-" + traceID + "-" + parentID + "-00"},
+			traceID, parentID, "", false,
+		},
+		"w3c-future-version": {
+			[]TraceFormat{W3CTraceFormat},
+			map[string]string{TraceParentHeader: "cc-" + traceID + "-" + parentID + "-01-what-the-future-will-be-like"},
+			traceID, parentID, "", true,
+		},
+		"w3c-invalid-trace-id": {
+			[]TraceFormat{W3CTraceFormat},
+			map[string]string{TraceParentHeader: "00-00000000000000000000000000000000-" + parentID + "-01", TraceStateHeader: "congo=t61rcWkgMzE"},
+			"0af7651916cd43dd8448eb211c80319c", "", "", true,
+		},
+		"w3c-invalid-version": {
+			[]TraceFormat{W3CTraceFormat},
+			map[string]string{TraceParentHeader: "ff-" + traceID + "-" + parentID + "-01"},
+			"0af7651916cd43dd8448eb211c80319c", "", "", true,
+		},
+		"w3c-ignores-goa-headers": {
+			[]TraceFormat{W3CTraceFormat},
+			map[string]string{TraceIDHeader: "trace", ParentSpanIDHeader: "parent"},
+			"0af7651916cd43dd8448eb211c80319c", "", "", true,
+		},
+		"b3": {
+			[]TraceFormat{B3TraceFormat},
+			map[string]string{B3TraceIDHeader: traceID, B3SpanIDHeader: parentID, B3SampledHeader: "0"},
+			traceID, parentID, "", false,
+		},
+		"b3-debug": {
+			[]TraceFormat{B3TraceFormat},
+			map[string]string{B3TraceIDHeader: "a3ce929d0e0e4736", B3SpanIDHeader: parentID, B3SampledHeader: "0", B3FlagsHeader: "1"},
+			"a3ce929d0e0e4736", parentID, "", true,
+		},
+		"b3-single": {
+			[]TraceFormat{B3TraceFormat},
+			map[string]string{B3SingleHeader: traceID + "-" + parentID + "-1-" + spanID},
+			traceID, parentID, "", true,
+		},
+		"first-format-wins": {
+			[]TraceFormat{GoaTraceFormat, W3CTraceFormat},
+			map[string]string{TraceIDHeader: "trace", ParentSpanIDHeader: "parent", TraceParentHeader: "00-" + traceID + "-" + parentID + "-01"},
+			"trace", "parent", "", true,
+		},
+		"fallback-format": {
+			[]TraceFormat{GoaTraceFormat, W3CTraceFormat},
+			map[string]string{TraceParentHeader: "00-" + traceID + "-" + parentID + "-01"},
+			traceID, parentID, "", true,
+		},
+	}
+
+	for k, c := range cases {
+		var (
+			ctxTraceID, ctxSpanID, ctxParentID, ctxTraceState string
+			ctxSampled                                        bool
+
+			m = NewTracer(PropagationFormats(c.Formats...), TraceIDFunc(newTraceID), SpanIDFunc(newID))
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				ctxTraceID = ContextTraceID(ctx)
+				ctxSpanID = ContextSpanID(ctx)
+				ctxParentID = ContextParentSpanID(ctx)
+				ctxTraceState = ContextTraceState(ctx)
+				ctxSampled = ContextTraceSampled(ctx)
+				return nil
+			}
+		)
+		req, _ := http.NewRequest("GET", "/", nil)
+		for h, v := range c.Headers {
+			req.Header.Set(h, v)
+		}
+
+		m(h)(context.Background(), httptest.NewRecorder(), req)
+
+		if ctxTraceID != c.CtxTraceID {
+			t.Errorf("%s: invalid TraceID, expected %v - got %v", k, c.CtxTraceID, ctxTraceID)
+		}
+		if ctxSpanID != spanID {
+			t.Errorf("%s: invalid SpanID, expected %v - got %v", k, spanID, ctxSpanID)
+		}
+		if ctxParentID != c.CtxParentID {
+			t.Errorf("%s: invalid ParentSpanID, expected %v - got %v", k, c.CtxParentID, ctxParentID)
+		}
+		if ctxTraceState != c.CtxTraceState {
+			t.Errorf("%s: invalid TraceState, expected %v - got %v", k, c.CtxTraceState, ctxTraceState)
+		}
+		if ctxSampled != c.CtxSampled {
+			t.Errorf("%s: invalid sampling decision, expected %v - got %v", k, c.CtxSampled, ctxSampled)
+		}
+	}
+}
+
+func TestTracerDefaultIDs(t *testing.T) {
+	var traceID, spanID string
+	m := NewTracer(PropagationFormats(W3CTraceFormat))
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		traceID = ContextTraceID(ctx)
+		spanID = ContextSpanID(ctx)
+		return nil
+	}
+	req, _ := http.NewRequest("GET", "/", nil)
+
+	m(h)(context.Background(), httptest.NewRecorder(), req)
+
+	if !isHexID(traceID, 32) {
+		t.Errorf("invalid W3C trace ID %q", traceID)
+	}
+	if !isHexID(spanID, 16) {
+		t.Errorf("invalid W3C span ID %q", spanID)
+	}
+}
+
+func TestInvalidPropagationFormats(t *testing.T) {
+	cases := map[string]struct {
+		Formats []TraceFormat
+		Panic   string
+	}{
+		"empty":   {nil, "propagation formats cannot be empty"},
+		"unknown": {[]TraceFormat{W3CTraceFormat, TraceFormat(42)}, "invalid propagation format 42"},
+	}
+	for k, c := range cases {
+		func() {
+			defer func() {
+				r := recover()
+				if r != c.Panic {
+					t.Errorf("%s: PropagationFormats did *not* panic as expected: %v", k, r)
+				}
+			}()
+			PropagationFormats(c.Formats...)
+		}()
+	}
+}
+
+func TestTracerExport(t *testing.T) {
+	var (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+		spanID   = "b7ad6b7169203331"
+		errTest  = errors.New("boom")
+	)
+
+	cases := map[string]struct {
+		Rate        int
+		TraceParent string
+		Err         error
+		// output
+		Exported bool
+		Status   int
+	}{
+		"sampled":           {100, "", nil, true, 200},
+		"not-sampled":       {0, "", nil, false, 0},
+		"upstream-sampled":  {0, "00-" + traceID + "-" + parentID + "-01", nil, true, 200},
+		"upstream-dropped":  {100, "00-" + traceID + "-" + parentID + "-00", nil, false, 0},
+		"error":             {100, "", errTest, true, 500},
+		"service-error":     {100, "", goa.ErrNotFound("nope"), true, 404},
+		"upstream-with-err": {100, "00-" + traceID + "-" + parentID + "-01", errTest, true, 500},
+	}
+
+	for k, c := range cases {
+		var (
+			exporter = NewInMemoryExporter()
+			m        = NewTracer(
+				SamplingPercent(c.Rate),
+				PropagationFormats(W3CTraceFormat),
+				SpanIDFunc(func() string { return spanID }),
+				ExportSpans(exporter),
+			)
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return c.Err
+			}
+		)
+		req, _ := http.NewRequest("GET", "/things", nil)
+		if c.TraceParent != "" {
+			req.Header.Set(TraceParentHeader, c.TraceParent)
+		}
+
+		err := m(h)(context.Background(), httptest.NewRecorder(), req)
+
+		if err != c.Err {
+			t.Errorf("%s: invalid error, expected %v - got %v", k, c.Err, err)
+		}
+		spans := exporter.Spans()
+		if !c.Exported {
+			if len(spans) != 0 {
+				t.Errorf("%s: expected no span to be exported, got %d", k, len(spans))
+			}
+			continue
+		}
+		if len(spans) != 1 {
+			t.Errorf("%s: expected one span to be exported, got %d", k, len(spans))
+			continue
+		}
+		span := spans[0]
+		if span.SpanID != spanID {
+			t.Errorf("%s: invalid SpanID, expected %v - got %v", k, spanID, span.SpanID)
+		}
+		if c.TraceParent != "" && (span.TraceID != traceID || span.ParentSpanID != parentID) {
+			t.Errorf("%s: invalid trace, expected %v/%v - got %v/%v", k, traceID, parentID, span.TraceID, span.ParentSpanID)
+		}
+		if span.Name != "GET /things" {
+			t.Errorf("%s: invalid Name, expected %v - got %v", k, "GET /things", span.Name)
+		}
+		if span.Status != c.Status {
+			t.Errorf("%s: invalid Status, expected %v - got %v", k, c.Status, span.Status)
+		}
+		if span.Error != c.Err {
+			t.Errorf("%s: invalid Error, expected %v - got %v", k, c.Err, span.Error)
+		}
+		if span.StartTime.IsZero() || span.EndTime.Before(span.StartTime) {
+			t.Errorf("%s: invalid span times %v - %v", k, span.StartTime, span.EndTime)
+		}
+		exporter.Reset()
+		if len(exporter.Spans()) != 0 {
+			t.Errorf("%s: Reset did not delete the spans", k)
+		}
+	}
+}
+
+func TestTraceDoer(t *testing.T) {
+	var (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "b7ad6b7169203331"
+	)
+
+	cases := map[string]struct {
+		Formats  []TraceFormat
+		TraceID  string
+		State    string
+		Sampled  bool
+		Expected map[string]string
+	}{
+		"default": {nil, traceID, "", true, map[string]string{
+			TraceIDHeader: traceID, ParentSpanIDHeader: spanID, TraceParentHeader: "",
+		}},
+		"w3c": {[]TraceFormat{W3CTraceFormat}, traceID, "congo=t61rcWkgMzE", true, map[string]string{
+			TraceParentHeader: "00-" + traceID + "-" + spanID + "-01", TraceStateHeader: "congo=t61rcWkgMzE", TraceIDHeader: "",
+		}},
+		"w3c-not-sampled": {[]TraceFormat{W3CTraceFormat}, traceID, "", false, map[string]string{
+			TraceParentHeader: "00-" + traceID + "-" + spanID + "-00", TraceStateHeader: "",
+		}},
+		"w3c-incompatible-ids": {[]TraceFormat{W3CTraceFormat}, "trace", "", true, map[string]string{
+			TraceParentHeader: "",
+		}},
+		"b3": {[]TraceFormat{B3TraceFormat}, traceID, "", true, map[string]string{
+			B3TraceIDHeader: traceID, B3SpanIDHeader: spanID, B3SampledHeader: "1",
+		}},
+		"all": {[]TraceFormat{GoaTraceFormat, W3CTraceFormat, B3TraceFormat}, traceID, "", true, map[string]string{
+			TraceIDHeader: traceID, TraceParentHeader: "00-" + traceID + "-" + spanID + "-01", B3SpanIDHeader: spanID,
+		}},
+	}
+
+	for k, c := range cases {
+		var (
+			header http.Header
+			doer   = doerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+				header = req.Header
+				return nil, nil
+			})
+			opts []TracerOption
+		)
+		if c.Formats != nil {
+			opts = append(opts, PropagationFormats(c.Formats...))
+		}
+		ctx := WithTrace(context.Background(), c.TraceID, spanID, "")
+		if c.State != "" {
+			ctx = context.WithValue(ctx, traceStateKey, c.State)
+		}
+		if !c.Sampled {
+			ctx = context.WithValue(ctx, traceSampledKey, false)
+		}
+		req, _ := http.NewRequest("GET", "/", nil)
+
+		TraceDoer(doer, opts...).Do(ctx, req)
+
+		for h, v := range c.Expected {
+			if actual := header.Get(h); actual != v {
+				t.Errorf("%s: invalid %s header, expected %q - got %q", k, h, v, actual)
+			}
+		}
+	}
+}
+
+// doerFunc is a client.Doer implemented by a function.
+type doerFunc func(context.Context, *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return f(ctx, req)
+}
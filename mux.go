@@ -3,6 +3,7 @@ package goa
 import (
 	"net/http"
 	"net/url"
+	"sync"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -42,10 +43,24 @@ type (
 		MuxHandler(string, Handler, Unmarshaler) MuxHandler
 	}
 
+	// Route describes a route registered with the default ServeMux.
+	Route struct {
+		// Method is the route HTTP method.
+		Method string `json:"method"`
+		// Path is the route path.
+		Path string `json:"path"`
+		// Controller is the name of the controller that handles the requests if any.
+		Controller string `json:"controller,omitempty"`
+		// Action is the name of the controller action that handles the requests if any.
+		Action string `json:"action,omitempty"`
+	}
+
 	// mux is the default ServeMux implementation.
 	mux struct {
 		router  *httptreemux.TreeMux
 		handles map[string]MuxHandler
+		lock    sync.Mutex
+		routes  []*Route
 	}
 )
 
@@ -70,6 +85,15 @@ func (m *mux) Handle(method, path string, handle MuxHandler) {
 	}
 	m.handles[method+path] = handle
 	m.router.Handle(method, path, hthandle)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, r := range m.routes {
+		if r.Method == method && r.Path == path {
+			r.Controller, r.Action = "", ""
+			return
+		}
+	}
+	m.routes = append(m.routes, &Route{Method: method, Path: path})
 }
 
 // HandleNotFound sets the MuxHandler invoked for requests that don't match any
@@ -95,6 +119,31 @@ func (m *mux) Lookup(method, path string) MuxHandler {
 	return m.handles[method+path]
 }
 
+// describe records the controller and action names of the route registered with Handle for the
+// given HTTP method and path.
+func (m *mux) describe(method, path, ctrl, action string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, r := range m.routes {
+		if r.Method == method && r.Path == path {
+			r.Controller, r.Action = ctrl, action
+			return
+		}
+	}
+}
+
+// list returns a copy of the registered routes.
+func (m *mux) list() []*Route {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	routes := make([]*Route, len(m.routes))
+	for i, r := range m.routes {
+		route := *r
+		routes[i] = &route
+	}
+	return routes
+}
+
 // ServeHTTP is the function called back by the underlying HTTP server to handle incoming requests.
 func (m *mux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	m.router.ServeHTTP(rw, req)
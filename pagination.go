@@ -0,0 +1,32 @@
+package goa
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// LinkHeader is the name of the response header that contains the links to the related
+// resources as described in RFC 5988, for example the next and previous pages of a paginated
+// collection.
+const LinkHeader = "Link"
+
+// PageURL returns the URL of the request with the given query string parameters set to the given
+// values, the other parameters are kept. The returned URL is relative to the host so that it
+// remains valid when the service is behind a proxy. It is used by the generated code to build the
+// links to the next and previous pages of paginated actions.
+func PageURL(req *http.Request, params map[string]string) string {
+	u := url.URL{Path: req.URL.Path, RawPath: req.URL.RawPath}
+	query := req.URL.Query()
+	for n, v := range params {
+		query.Set(n, v)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// AddLink adds a link with the given target URL and relation type (e.g. "next" or "prev") to the
+// response Link header.
+func (r *ResponseData) AddLink(target, rel string) {
+	r.Header().Add(LinkHeader, fmt.Sprintf("<%s>; rel=%q", target, rel))
+}
@@ -0,0 +1,64 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageURL", func() {
+	var req *http.Request
+	var params map[string]string
+
+	var pageURL string
+
+	BeforeEach(func() {
+		var err error
+		req, err = http.NewRequest("GET", "http://goa.design/bottles?limit=10&cursor=abc&filter=red", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		params = map[string]string{"cursor": "def"}
+	})
+
+	JustBeforeEach(func() {
+		pageURL = goa.PageURL(req, params)
+	})
+
+	It("overrides the given parameters and keeps the others", func() {
+		Ω(pageURL).Should(Equal("/bottles?cursor=def&filter=red&limit=10"))
+	})
+
+	Context("with parameters missing from the request", func() {
+		BeforeEach(func() {
+			params = map[string]string{"offset": "20"}
+		})
+
+		It("adds them", func() {
+			Ω(pageURL).Should(Equal("/bottles?cursor=abc&filter=red&limit=10&offset=20"))
+		})
+	})
+})
+
+var _ = Describe("AddLink", func() {
+	var data *goa.ResponseData
+	var rw *TestResponseWriter
+
+	BeforeEach(func() {
+		req, err := http.NewRequest("GET", "/bottles", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		ctx := goa.NewContext(context.Background(), rw, req, nil)
+		data = goa.ContextResponse(ctx)
+	})
+
+	It("adds the links to the Link header", func() {
+		data.AddLink("/bottles?offset=20", "next")
+		data.AddLink("/bottles?offset=0", "prev")
+		Ω(rw.ParentHeader[goa.LinkHeader]).Should(Equal([]string{
+			`</bottles?offset=20>; rel="next"`,
+			`</bottles?offset=0>; rel="prev"`,
+		}))
+	})
+})
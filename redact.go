@@ -0,0 +1,76 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// HiddenLogValue is the value logged in place of sensitive data.
+const HiddenLogValue = "<hidden>"
+
+// Redactor is implemented by values that hold sensitive data. Redact returns a representation
+// of the value that is safe to log. The payload types generated for designs that make use of the
+// Sensitive DSL implement this interface.
+type Redactor interface {
+	// Redact returns a copy of the value where the sensitive data is hidden.
+	Redact() interface{}
+}
+
+// RedactJSON returns a copy of the JSON representation of v where the values found at the given
+// paths are replaced with HiddenLogValue. A path lists the keys of nested objects separated with
+// dots, "[n]" denotes the n-th element of an array and "[*]" all the elements of an array or all
+// the values of an object, for example "password", "credentials.token" or "users[*].ssn".
+// RedactJSON returns HiddenLogValue if v cannot be serialized to JSON. It does not modify v.
+func RedactJSON(v interface{}, paths ...string) interface{} {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return HiddenLogValue
+	}
+	var res interface{}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	if err := dec.Decode(&res); err != nil {
+		return HiddenLogValue
+	}
+	for _, p := range paths {
+		res = redact(res, splitPath(p))
+	}
+	return res
+}
+
+// splitPath returns the segments of the given redaction path.
+func splitPath(p string) []string {
+	p = strings.Replace(strings.Replace(strings.TrimPrefix(p, "$"), "[", ".", -1), "]", "", -1)
+	var segs []string
+	for _, seg := range strings.Split(p, ".") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+// redact replaces the values of v found at the path described by segs with HiddenLogValue.
+func redact(v interface{}, segs []string) interface{} {
+	if len(segs) == 0 {
+		return HiddenLogValue
+	}
+	seg, rest := segs[0], segs[1:]
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, e := range val {
+			if seg == "*" || seg == k {
+				val[k] = redact(e, rest)
+			}
+		}
+	case []interface{}:
+		for i, e := range val {
+			if seg == "*" || seg == strconv.Itoa(i) {
+				val[i] = redact(e, rest)
+			}
+		}
+	}
+	return v
+}
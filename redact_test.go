@@ -0,0 +1,78 @@
+package goa_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RedactJSON", func() {
+	var v interface{}
+	var paths []string
+	var redacted interface{}
+
+	JustBeforeEach(func() {
+		redacted = goa.RedactJSON(v, paths...)
+	})
+
+	asJSON := func(v interface{}) string {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		Ω(enc.Encode(v)).ShouldNot(HaveOccurred())
+		return strings.TrimSpace(buf.String())
+	}
+
+	Context("with a struct", func() {
+		type creds struct {
+			User     string `json:"user"`
+			Password string `json:"password"`
+		}
+		var c *creds
+
+		BeforeEach(func() {
+			c = &creds{User: "joe", Password: "secret"}
+			v = c
+			paths = []string{"password", "missing.path"}
+		})
+
+		It("hides the values", func() {
+			Ω(asJSON(redacted)).Should(Equal(`{"password":"<hidden>","user":"joe"}`))
+			Ω(c.Password).Should(Equal("secret"))
+		})
+	})
+
+	Context("with nested arrays and objects", func() {
+		BeforeEach(func() {
+			v = map[string]interface{}{
+				"users": []interface{}{
+					map[string]interface{}{"ssn": 123456789, "tokens": map[string]interface{}{"a": "x", "b": "y"}},
+					map[string]interface{}{"ssn": 987654321, "tokens": map[string]interface{}{"a": "z"}},
+				},
+				"count": 2,
+			}
+			paths = []string{"$.users[*].tokens[*]", "users[1].ssn"}
+		})
+
+		It("hides the values at the paths", func() {
+			Ω(asJSON(redacted)).Should(Equal(`{"count":2,"users":[` +
+				`{"ssn":123456789,"tokens":{"a":"<hidden>","b":"<hidden>"}},` +
+				`{"ssn":"<hidden>","tokens":{"a":"<hidden>"}}]}`))
+		})
+	})
+
+	Context("with a value that cannot be serialized", func() {
+		BeforeEach(func() {
+			v = make(chan int)
+			paths = nil
+		})
+
+		It("hides the whole value", func() {
+			Ω(redacted).Should(Equal(goa.HiddenLogValue))
+		})
+	})
+})
@@ -1,6 +1,9 @@
 package goa
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Location is the enum defining where the value of key based security schemes should be read:
 // either a HTTP request header or a URL querystring value
@@ -74,4 +77,15 @@ type JWTSecurity struct {
 	TokenURL string
 	// Scopes defines a list of scopes for the security scheme, along with their description.
 	Scopes map[string]string
+	// Issuers lists the accepted values of the "iss" claim, any issuer is accepted if empty.
+	Issuers []string
+	// Audiences lists the accepted values of the "aud" claim, any audience is accepted if empty.
+	Audiences []string
+	// Leeway is the clock skew tolerated when validating the "exp", "nbf" and "iat" claims.
+	Leeway time.Duration
+	// RequiredClaims lists the names of the claims that must be present in the token.
+	RequiredClaims []string
+	// SigningAlgorithms lists the accepted signing algorithms (e.g. "RS256"), any algorithm
+	// is accepted if empty.
+	SigningAlgorithms []string
 }
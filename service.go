@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -39,9 +40,14 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
+		// DrainDelay is the time Shutdown waits once Draining returns true before it stops
+		// the listeners so that readiness probes fail and load balancers stop sending new
+		// requests first. Defaults to 0.
+		DrainDelay time.Duration
 
 		middleware []Middleware       // Middleware chain
 		cancel     context.CancelFunc // Service context cancel signal trigger
+		lifecycle  lifecycle          // Start and shutdown hooks and state
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -197,23 +203,44 @@ func (service *Service) LogError(msg string, keyvals ...interface{}) {
 	LogError(service.Context, msg, keyvals...)
 }
 
-// ListenAndServe starts a HTTP server and sets up a listener on the given host/port.
+// ListenAndServe starts a HTTP server and sets up a listener on the given host/port. It runs the
+// OnStart hooks first. Once a graceful shutdown initiated with Shutdown completes it returns the
+// result of Shutdown, that is nil unless the shutdown context expired or an OnShutdown hook failed.
 func (service *Service) ListenAndServe(addr string) error {
 	service.LogInfo("listen", "transport", "http", "addr", addr)
 	service.Server.Addr = addr
-	return service.Server.ListenAndServe()
+	return service.serve(service.Server.ListenAndServe)
 }
 
-// ListenAndServeTLS starts a HTTPS server and sets up a listener on the given host/port.
+// ListenAndServeTLS starts a HTTPS server and sets up a listener on the given host/port. It behaves
+// like ListenAndServe with regard to the OnStart hooks and Shutdown.
 func (service *Service) ListenAndServeTLS(addr, certFile, keyFile string) error {
 	service.LogInfo("listen", "transport", "https", "addr", addr)
 	service.Server.Addr = addr
-	return service.Server.ListenAndServeTLS(certFile, keyFile)
+	return service.serve(func() error { return service.Server.ListenAndServeTLS(certFile, keyFile) })
 }
 
 // Serve accepts incoming HTTP connections on the listener l, invoking the service mux handler for each.
+// It behaves like ListenAndServe with regard to the OnStart hooks and Shutdown.
 func (service *Service) Serve(l net.Listener) error {
-	return service.Server.Serve(l)
+	return service.serve(func() error { return service.Server.Serve(l) })
+}
+
+// Mount registers the handler of the given controller action with the service mux for the given
+// HTTP method and path, the mux handler is created with the controller MuxHandler method. Mount
+// also records the controller and action names of the route, see Routes. The controller name is
+// only known if ctrl is a *Controller or embeds one.
+func (service *Service) Mount(method, path string, ctrl Muxer, action string, hdlr Handler, unm Unmarshaler) {
+	service.Mux.Handle(method, path, ctrl.MuxHandler(action, hdlr, unm))
+	m, ok := service.Mux.(*mux)
+	if !ok {
+		return
+	}
+	var name string
+	if c, ok := ctrl.(interface{ controller() *Controller }); ok {
+		name = c.controller().Name
+	}
+	m.describe(method, path, name, action)
 }
 
 // NewController returns a controller for the given resource. This method is mainly intended for
@@ -231,14 +258,20 @@ func (service *Service) NewController(name string) *Controller {
 }
 
 // Send serializes the given body matching the request Accept header against the service
-// encoders. It uses the default service encoder if no match is found.
+// encoders and sets the response Content-Type header accordingly. It uses the default service
+// encoder if no match is found. If the service encoder is strict and code is lower than 400 then
+// Send returns an ErrNotAcceptable error without writing the response when no match is found.
 func (service *Service) Send(ctx context.Context, code int, body interface{}) error {
 	r := ContextResponse(ctx)
 	if r == nil {
 		return fmt.Errorf("no response data in context")
 	}
+	contentType, err := service.negotiate(ctx, code)
+	if err != nil {
+		return err
+	}
 	r.WriteHeader(code)
-	return service.EncodeResponse(ctx, body)
+	return service.Encoder.encode(body, r, contentType)
 }
 
 // ServeFiles create a "FileServer" controller and calls ServerFiles on it.
@@ -261,10 +294,45 @@ func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
 }
 
 // EncodeResponse uses the HTTP encoder to marshal and write the response body based on the request
-// Accept header.
+// Accept header. It also sets the response Content-Type header if the response header hasn't been
+// written yet.
 func (service *Service) EncodeResponse(ctx context.Context, v interface{}) error {
+	r := ContextResponse(ctx)
+	contentType, err := service.negotiate(ctx, r.Status)
+	if err != nil {
+		return err
+	}
+	return service.Encoder.encode(v, r, contentType)
+}
+
+// negotiate returns the content type of the encoder used to write the response to the request
+// held by ctx and sets the response Content-Type header accordingly. The existing Content-Type
+// header is kept if it is compatible with the negotiated content type, e.g. a media type
+// identifier such as application/vnd.goa.example+json is kept when the negotiated content type is
+// application/json. Error responses (status 400 and above) always fall back to the default encoder
+// so that clients get a description of the error.
+func (service *Service) negotiate(ctx context.Context, status int) (string, error) {
 	accept := ContextRequest(ctx).Header.Get("Accept")
-	return service.Encoder.Encode(v, ContextResponse(ctx), accept)
+	contentType, ok := service.Encoder.Negotiate(accept)
+	if !ok {
+		if service.Encoder.Strict && status < 400 {
+			return "", ErrNotAcceptable("no encoder matches the request Accept header", "accept", accept)
+		}
+		return "*/*", nil
+	}
+	if contentType == "*/*" {
+		return contentType, nil
+	}
+	header := ContextResponse(ctx).Header()
+	if current := header.Get("Content-Type"); current != "" {
+		// Content-Type values parse as media ranges, match returns 4 for an exact match and 3
+		// for a structured syntax suffix match.
+		if ranges := parseAccept(current); len(ranges) == 1 && ranges[0].match(contentType) > 2 {
+			return contentType, nil
+		}
+	}
+	header.Set("Content-Type", contentType)
+	return contentType, nil
 }
 
 // ServeFiles replies to the request with the contents of the named file or directory. See
@@ -280,7 +348,7 @@ func (ctrl *Controller) ServeFiles(path, filename string) error {
 		}
 		return nil
 	}
-	ctrl.Service.Mux.Handle("GET", path, ctrl.MuxHandler("serve", handler, nil))
+	ctrl.Service.Mount("GET", path, ctrl, "serve", handler, nil)
 	return nil
 }
 
@@ -301,7 +369,7 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 	var handler Handler
 	var initHandler sync.Once
 
-	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+	mh := func(rw http.ResponseWriter, req *http.Request, params url.Values) {
 		// Build handler middleware chains on first invocation
 		initHandler.Do(func() {
 			handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -320,6 +388,7 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 
 		// Build context
 		ctx := NewContext(WithAction(ctrl.Context, name), rw, req, params)
+		ContextResponse(ctx).Service = ctrl.Service
 
 		// Protect against request bodies with unreasonable length
 		if ctrl.MaxRequestBodyLength > 0 {
@@ -340,12 +409,28 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 		}
 
 		// Invoke handler
-		if err := handler(ctx, ContextResponse(ctx), req); err != nil {
+		resp := ContextResponse(ctx)
+		err := handler(ctx, resp, req)
+		if resp.stream != nil {
+			// The response writer cannot be used once the handler returns.
+			resp.stream.Close()
+		}
+		if err != nil {
 			LogError(ctx, "uncaught error", "err", err)
+			if resp.Written() {
+				return
+			}
 			respBody := fmt.Sprintf("Internal error: %s", err) // Sprintf catches panics
 			ctrl.Service.Send(ctx, 500, respBody)
 		}
 	}
+	return mh
+}
+
+// controller returns the controller, it makes it possible for Service.Mount to retrieve the
+// controller of the user types that embed it.
+func (ctrl *Controller) controller() *Controller {
+	return ctrl
 }
 
 // FileHandler returns a handler that serves files under the given filename for the given route path.
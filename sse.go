@@ -0,0 +1,322 @@
+package goa
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventStreamMIME is the content type of server-sent events streams.
+const EventStreamMIME = "text/event-stream"
+
+// DefaultHeartbeat is the default interval between two heartbeats sent on idle event streams.
+const DefaultHeartbeat = 15 * time.Second
+
+// ErrStreamClosed is the error returned when sending an event on a closed event stream.
+var ErrStreamClosed = errors.New("event stream closed")
+
+type (
+	// Event is a server-sent event as described in the HTML specification
+	// (https://html.spec.whatwg.org/multipage/server-sent-events.html).
+	Event struct {
+		// ID is the event identifier, clients send the ID of the last event they received
+		// in the Last-Event-ID header when reconnecting.
+		ID string
+		// Event is the event type, clients use "message" if empty.
+		Event string
+		// Data is the event payload.
+		Data []byte
+		// Retry is the reconnection delay the client should use.
+		Retry time.Duration
+	}
+
+	// EventOption sets optional fields of events sent on event streams.
+	EventOption func(*Event)
+
+	// EventStream writes server-sent events to the response of a request. The response is
+	// flushed after each event and a comment is sent periodically on idle streams so that
+	// proxies do not close the connection. The stream is closed once the request context is
+	// done, the client disconnects, the service shuts down or the action handler returns.
+	EventStream struct {
+		ctx     context.Context
+		resp    *ResponseData
+		lock    sync.Mutex
+		err     error
+		written time.Time
+		done    chan struct{}
+		stopped chan struct{}
+	}
+
+	// EventDecoder reads server-sent events from a stream.
+	EventDecoder struct {
+		r *bufio.Reader
+	}
+)
+
+// EventID sets the ID of the event.
+func EventID(id string) EventOption {
+	return func(e *Event) { e.ID = id }
+}
+
+// EventType sets the type of the event.
+func EventType(typ string) EventOption {
+	return func(e *Event) { e.Event = typ }
+}
+
+// EventRetry sets the reconnection delay sent with the event.
+func EventRetry(d time.Duration) EventOption {
+	return func(e *Event) { e.Retry = d }
+}
+
+// NewEventStream writes the response header with the given status and returns a stream that
+// sends server-sent events in the response body. A heartbeat is sent when no event was sent for
+// the given duration, heartbeat may be zero or negative to disable heartbeats. Calling
+// NewEventStream again for the same request returns the existing stream.
+// This function is intended for the generated code, user code should use the stream methods of
+// the action contexts.
+func NewEventStream(ctx context.Context, status int, heartbeat time.Duration) *EventStream {
+	resp := ContextResponse(ctx)
+	if resp.stream != nil {
+		return resp.stream
+	}
+	s := &EventStream{
+		ctx:     ctx,
+		resp:    resp,
+		written: time.Now(),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	resp.stream = s
+	h := resp.Header()
+	h.Set("Content-Type", EventStreamMIME)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	resp.WriteHeader(status)
+	resp.Flush()
+
+	var disconnected, drained <-chan struct{}
+	if req := ContextRequest(ctx); req != nil && req.Request != nil {
+		disconnected = req.Context().Done()
+	}
+	if resp.Service != nil {
+		drained = resp.Service.drained()
+	}
+	go s.run(heartbeat, disconnected, drained)
+	return s
+}
+
+// Send sends an event with the given data and options. data is sent as is if it is a byte slice
+// or a string, it is encoded to JSON otherwise.
+func (s *EventStream) Send(data interface{}, opts ...EventOption) error {
+	var e Event
+	switch actual := data.(type) {
+	case []byte:
+		e.Data = actual
+	case string:
+		e.Data = []byte(actual)
+	default:
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		e.Data = b
+	}
+	for _, o := range opts {
+		o(&e)
+	}
+	return s.SendEvent(&e)
+}
+
+// SendEvent sends the given event and flushes the response. It returns an error if the stream
+// is closed, for example because the client disconnected.
+func (s *EventStream) SendEvent(e *Event) error {
+	return s.write(e.encode())
+}
+
+// Done returns a channel that is closed when the stream is closed.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the reason the stream was closed or nil if the stream is still open.
+func (s *EventStream) Err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+// Close closes the stream and stops the heartbeats. It is safe to call Close multiple times.
+func (s *EventStream) Close() error {
+	s.stop(ErrStreamClosed)
+	<-s.stopped
+	return nil
+}
+
+// run sends the heartbeats and closes the stream when the context is done, the client
+// disconnects or the service shuts down. A heartbeat is only sent if nothing was written during
+// the last interval.
+func (s *EventStream) run(heartbeat time.Duration, disconnected, drained <-chan struct{}) {
+	defer close(s.stopped)
+	var timer *time.Timer
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		timer = time.NewTimer(heartbeat)
+		defer timer.Stop()
+		tick = timer.C
+	}
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.ctx.Done():
+			s.stop(s.ctx.Err())
+			return
+		case <-disconnected:
+			s.stop(context.Canceled)
+			return
+		case <-drained:
+			s.stop(ErrStreamClosed)
+			return
+		case <-tick:
+			if idle := s.idle(); idle < heartbeat {
+				timer.Reset(heartbeat - idle)
+				continue
+			}
+			if err := s.write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+			timer.Reset(heartbeat)
+		}
+	}
+}
+
+// write writes b to the response and flushes it.
+func (s *EventStream) write(b []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.ctx.Err(); err != nil {
+		s.close(err)
+		return err
+	}
+	if _, err := s.resp.Write(b); err != nil {
+		s.close(err)
+		return err
+	}
+	s.resp.Flush()
+	s.written = time.Now()
+	return nil
+}
+
+// idle returns the time elapsed since the last write.
+func (s *EventStream) idle() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return time.Since(s.written)
+}
+
+// stop closes the stream with the given reason.
+func (s *EventStream) stop(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.close(err)
+}
+
+// close records the reason the stream was closed, the caller must hold the lock.
+func (s *EventStream) close(err error) {
+	if s.err == nil {
+		s.err = err
+		close(s.done)
+	}
+}
+
+// encode returns the wire representation of the event.
+func (e *Event) encode() []byte {
+	var buf bytes.Buffer
+	if e.ID != "" {
+		buf.WriteString("id: " + singleLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		buf.WriteString("event: " + singleLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(int64(e.Retry/time.Millisecond), 10) + "\n")
+	}
+	data := strings.Replace(string(e.Data), "\r\n", "\n", -1)
+	for _, line := range strings.Split(data, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// singleLine removes the line breaks from s.
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// NewEventDecoder returns a decoder that reads server-sent events from r.
+func NewEventDecoder(r io.Reader) *EventDecoder {
+	return &EventDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next event. Comments such as heartbeats are skipped. Decode returns io.EOF
+// when the stream ends.
+func (d *EventDecoder) Decode() (*Event, error) {
+	var (
+		e     Event
+		data  []string
+		found bool
+	)
+	for {
+		line, err := d.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				// Incomplete events are discarded as per the specification.
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !found {
+				continue
+			}
+			e.Data = []byte(strings.Join(data, "\n"))
+			return &e, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			e.ID = value
+		case "event":
+			e.Event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			ms, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			e.Retry = time.Duration(ms) * time.Millisecond
+		default:
+			continue
+		}
+		found = true
+	}
+}
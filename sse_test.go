@@ -0,0 +1,154 @@
+package goa_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventStream", func() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var rw *httptest.ResponseRecorder
+	var heartbeat time.Duration
+
+	var stream *goa.EventStream
+
+	BeforeEach(func() {
+		req, err := http.NewRequest("GET", "/events", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = httptest.NewRecorder()
+		ctx, cancel = context.WithCancel(context.Background())
+		ctx = goa.NewContext(ctx, rw, req, nil)
+		heartbeat = 0
+	})
+
+	JustBeforeEach(func() {
+		stream = goa.NewEventStream(ctx, 200, heartbeat)
+	})
+
+	AfterEach(func() {
+		cancel()
+		stream.Close()
+	})
+
+	It("writes and flushes the response header", func() {
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal(goa.EventStreamMIME))
+		Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-cache"))
+		Ω(rw.Flushed).Should(BeTrue())
+	})
+
+	It("returns the existing stream when called again", func() {
+		Ω(goa.NewEventStream(ctx, 200, heartbeat)).Should(BeIdenticalTo(stream))
+	})
+
+	It("sends events", func() {
+		Ω(stream.Send("hello")).ShouldNot(HaveOccurred())
+		Ω(stream.Send([]byte("a\nb"), goa.EventID("2"), goa.EventType("update"), goa.EventRetry(3*time.Second))).ShouldNot(HaveOccurred())
+		Ω(stream.Send(map[string]int{"count": 1})).ShouldNot(HaveOccurred())
+		Ω(stream.Close()).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal("data: hello\n\n" +
+			"id: 2\nevent: update\nretry: 3000\ndata: a\ndata: b\n\n" +
+			"data: {\"count\":1}\n\n"))
+	})
+
+	Context("with a heartbeat", func() {
+		BeforeEach(func() {
+			heartbeat = 5 * time.Millisecond
+		})
+
+		It("sends comments on idle streams", func() {
+			time.Sleep(30 * time.Millisecond)
+			stream.Close()
+			Ω(rw.Body.String()).Should(HavePrefix(": ping\n\n"))
+		})
+
+		Context("on busy streams", func() {
+			BeforeEach(func() {
+				heartbeat = 40 * time.Millisecond
+			})
+
+			It("does not send comments", func() {
+				for i := 0; i < 12; i++ {
+					Ω(stream.Send("hello")).ShouldNot(HaveOccurred())
+					time.Sleep(5 * time.Millisecond)
+				}
+				stream.Close()
+				Ω(rw.Body.String()).ShouldNot(ContainSubstring(": ping"))
+			})
+		})
+	})
+
+	Context("when the context is canceled", func() {
+		JustBeforeEach(func() {
+			cancel()
+		})
+
+		It("closes the stream", func() {
+			Eventually(stream.Done()).Should(BeClosed())
+			Ω(stream.Err()).Should(Equal(context.Canceled))
+			Ω(stream.Send("hello")).Should(Equal(context.Canceled))
+			Ω(rw.Body.String()).Should(BeEmpty())
+		})
+	})
+
+	Context("once closed", func() {
+		JustBeforeEach(func() {
+			stream.Close()
+		})
+
+		It("refuses to send events", func() {
+			Ω(stream.Send("hello")).Should(Equal(goa.ErrStreamClosed))
+			Ω(stream.Close()).ShouldNot(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("EventDecoder", func() {
+	const body = ": ping\n\n" +
+		"data: hello\n\n" +
+		"id: 2\r\nevent: update\r\nretry: 3000\r\ndata: a\r\ndata:b\r\n\r\n" +
+		"unknown: field\ndata: {\"count\":1}\n\n" +
+		"data: incomplete\n"
+
+	It("decodes the events", func() {
+		dec := goa.NewEventDecoder(strings.NewReader(body))
+		e, err := dec.Decode()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(*e).Should(Equal(goa.Event{Data: []byte("hello")}))
+		e, err = dec.Decode()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(*e).Should(Equal(goa.Event{ID: "2", Event: "update", Retry: 3 * time.Second, Data: []byte("a\nb")}))
+		e, err = dec.Decode()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(e.Data)).Should(Equal(`{"count":1}`))
+		_, err = dec.Decode()
+		Ω(err).Should(Equal(io.EOF))
+	})
+})
+
+var _ = Describe("MuxHandler with an event stream", func() {
+	It("closes the stream when the handler returns", func() {
+		service := goa.New("test")
+		ctrl := service.NewController("test")
+		var stream *goa.EventStream
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			stream = goa.NewEventStream(ctx, 200, goa.DefaultHeartbeat)
+			return stream.Send("hello")
+		}
+		req, err := http.NewRequest("GET", "/events", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw := httptest.NewRecorder()
+		ctrl.MuxHandler("events", handler, nil)(rw, req, nil)
+		Ω(stream.Err()).Should(Equal(goa.ErrStreamClosed))
+		Ω(rw.Body.String()).Should(Equal("data: hello\n\n"))
+	})
+})
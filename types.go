@@ -0,0 +1,136 @@
+package goa
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout used to format and parse Date values, it corresponds to the RFC3339
+// full-date production.
+const DateLayout = "2006-01-02"
+
+type (
+	// Date is the Go type used by generated code for attributes of type design.Date. It
+	// represents a calendar date without a time component and is encoded as a RFC3339
+	// full-date string (e.g. "2017-05-29").
+	Date struct {
+		time.Time
+	}
+
+	// Duration is the Go type used by generated code for attributes of type
+	// design.Duration. It is encoded as a string using the format produced by
+	// time.Duration.String (e.g. "1h30m0s").
+	Duration time.Duration
+)
+
+// ParseDate parses a RFC3339 full-date string (e.g. "2017-05-29").
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{Time: t}, nil
+}
+
+// String returns the RFC3339 full-date representation of d.
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Date) UnmarshalText(text []byte) error {
+	res, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = res
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer so that dates can be stored in SQL date columns.
+func (d Date) Value() (driver.Value, error) {
+	return d.Time, nil
+}
+
+// Scan implements sql.Scanner.
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		y, m, day := v.Date()
+		d.Time = time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case []byte:
+		return d.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a date", src)
+	}
+}
+
+// ParseDuration parses a duration string using time.ParseDuration.
+func ParseDuration(s string) (Duration, error) {
+	d, err := time.ParseDuration(s)
+	return Duration(d), err
+}
+
+// String returns the time.Duration representation of d.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	res, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = res
+	return nil
+}
+
+// Value implements driver.Valuer, durations are stored as a number of nanoseconds.
+func (d Duration) Value() (driver.Value, error) {
+	return int64(d), nil
+}
+
+// Scan implements sql.Scanner.
+func (d *Duration) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*d = Duration(v)
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case []byte:
+		return d.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a duration", src)
+	}
+}
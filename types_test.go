@@ -0,0 +1,66 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Date", func() {
+	var d goa.Date
+
+	BeforeEach(func() {
+		d = goa.Date{Time: time.Date(2017, time.May, 29, 0, 0, 0, 0, time.UTC)}
+	})
+
+	It("encodes to a full-date JSON string", func() {
+		b, err := json.Marshal(d)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`"2017-05-29"`))
+	})
+
+	It("decodes full-date JSON strings", func() {
+		var res goa.Date
+		Ω(json.Unmarshal([]byte(`"2017-05-29"`), &res)).ShouldNot(HaveOccurred())
+		Ω(res).Should(Equal(d))
+		Ω(json.Unmarshal([]byte(`"2017-05-29T10:00:00Z"`), &res)).Should(HaveOccurred())
+	})
+
+	It("scans SQL values", func() {
+		var res goa.Date
+		Ω(res.Scan(time.Date(2017, time.May, 29, 10, 30, 0, 0, time.UTC))).ShouldNot(HaveOccurred())
+		Ω(res).Should(Equal(d))
+		Ω(res.Scan([]byte("2017-05-29"))).ShouldNot(HaveOccurred())
+		Ω(res).Should(Equal(d))
+		Ω(res.Scan(42)).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("Duration", func() {
+	d := goa.Duration(90 * time.Minute)
+
+	It("encodes to a JSON string", func() {
+		b, err := json.Marshal(d)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`"1h30m0s"`))
+	})
+
+	It("decodes JSON strings", func() {
+		var res goa.Duration
+		Ω(json.Unmarshal([]byte(`"1h30m"`), &res)).ShouldNot(HaveOccurred())
+		Ω(res).Should(Equal(d))
+		Ω(json.Unmarshal([]byte(`"forever"`), &res)).Should(HaveOccurred())
+	})
+
+	It("stores a number of nanoseconds in SQL", func() {
+		v, err := d.Value()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v).Should(Equal(int64(90 * time.Minute)))
+		var res goa.Duration
+		Ω(res.Scan(v)).ShouldNot(HaveOccurred())
+		Ω(res).Should(Equal(d))
+	})
+})
@@ -0,0 +1,25 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// UnmarshalVariant decodes the JSON document data into v and returns true if the document
+// describes a valid value of the type of v: the document must not contain object fields that v
+// does not define and v must pass its validations if it implements a Validate method. The code
+// generated for union types that are not discriminated uses UnmarshalVariant to find the variant
+// that matches a value.
+func UnmarshalVariant(data []byte, v interface{}) bool {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return false
+	}
+	if val, ok := v.(interface {
+		Validate() error
+	}); ok {
+		return val.Validate() == nil
+	}
+	return true
+}
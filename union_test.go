@@ -0,0 +1,64 @@
+package goa_test
+
+import (
+	"errors"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// variant is a union variant used by the UnmarshalVariant tests.
+type variant struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// Validate requires the name to be set.
+func (v *variant) Validate() error {
+	if v.Name == nil {
+		return errors.New("missing name")
+	}
+	return nil
+}
+
+var _ = Describe("UnmarshalVariant", func() {
+	var data string
+	var v *variant
+	var ok bool
+
+	JustBeforeEach(func() {
+		v = &variant{}
+		ok = goa.UnmarshalVariant([]byte(data), v)
+	})
+
+	Context("with a matching document", func() {
+		BeforeEach(func() {
+			data = `{"name": "felix"}`
+		})
+
+		It("decodes the variant", func() {
+			Ω(ok).Should(BeTrue())
+			Ω(*v.Name).Should(Equal("felix"))
+		})
+	})
+
+	Context("with unknown fields", func() {
+		BeforeEach(func() {
+			data = `{"name": "felix", "barks": true}`
+		})
+
+		It("does not match", func() {
+			Ω(ok).Should(BeFalse())
+		})
+	})
+
+	Context("with an invalid value", func() {
+		BeforeEach(func() {
+			data = `{}`
+		})
+
+		It("does not match", func() {
+			Ω(ok).Should(BeFalse())
+		})
+	})
+})
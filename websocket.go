@@ -0,0 +1,138 @@
+package goa
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// DefaultPingInterval is the default interval between two pings sent on websocket connections.
+const DefaultPingInterval = 30 * time.Second
+
+type (
+	// WebSocketConn exchanges JSON messages over a websocket connection. Messages that define
+	// validations are validated before being sent and after being received. A ping frame is
+	// sent periodically so that proxies do not close idle connections, the connection is
+	// closed when a ping cannot be written which unblocks pending calls to Receive.
+	WebSocketConn struct {
+		*websocket.Conn
+		wlock     sync.Mutex
+		interval  time.Duration
+		stopOnce  sync.Once
+		closeOnce sync.Once
+		err       error
+		done      chan struct{}
+		stopped   chan struct{}
+	}
+
+	// validator is implemented by the generated types that define validations.
+	validator interface {
+		Validate() error
+	}
+)
+
+// NewWebSocketConn wraps the given connection and starts sending pings at the given interval,
+// interval may be zero or negative to disable pings.
+// This function is intended for the generated code, user code should use the connection types
+// generated for the actions that define websocket messages.
+func NewWebSocketConn(ws *websocket.Conn, interval time.Duration) *WebSocketConn {
+	c := &WebSocketConn{
+		Conn:     ws,
+		interval: interval,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go c.keepAlive()
+	return c
+}
+
+// Send validates msg if it defines a Validate method and sends it encoded to JSON.
+func (c *WebSocketConn) Send(msg interface{}) error {
+	if v, ok := msg.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+	return websocket.JSON.Send(c.Conn, msg)
+}
+
+// Receive reads the next message into msg and validates it if it defines a Validate method.
+// Receive returns io.EOF when the peer closes the connection.
+func (c *WebSocketConn) Receive(msg interface{}) error {
+	if err := websocket.JSON.Receive(c.Conn, msg); err != nil {
+		return err
+	}
+	if v, ok := msg.(validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// Ping sends a ping frame, the peer replies with a pong frame.
+func (c *WebSocketConn) Ping() error {
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+	if c.interval > 0 {
+		c.SetWriteDeadline(time.Now().Add(c.interval))
+		defer c.SetWriteDeadline(time.Time{})
+	}
+	payloadType := c.PayloadType
+	c.PayloadType = websocket.PingFrame
+	defer func() { c.PayloadType = payloadType }()
+	_, err := c.Write(nil)
+	return err
+}
+
+// Done returns a channel that is closed when the connection is closed by Close or because a ping
+// failed.
+func (c *WebSocketConn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close stops the pings and closes the connection. It is safe to call Close multiple times.
+func (c *WebSocketConn) Close() error {
+	c.stop()
+	<-c.stopped
+	return c.close()
+}
+
+// keepAlive sends the pings until the connection is closed.
+func (c *WebSocketConn) keepAlive() {
+	defer close(c.stopped)
+	if c.interval <= 0 {
+		<-c.done
+		return
+	}
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+			if err := c.Ping(); err != nil {
+				c.stop()
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// stop closes the done channel.
+func (c *WebSocketConn) stop() {
+	c.stopOnce.Do(func() { close(c.done) })
+}
+
+// close closes the underlying connection once.
+func (c *WebSocketConn) close() error {
+	c.closeOnce.Do(func() {
+		c.wlock.Lock()
+		defer c.wlock.Unlock()
+		c.err = c.Conn.Close()
+	})
+	return c.err
+}
@@ -0,0 +1,126 @@
+package goa_test
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/websocket"
+)
+
+type wsMessage struct {
+	Body string `json:"body"`
+}
+
+func (m *wsMessage) Validate() error {
+	if m.Body == "" {
+		return errors.New("missing body")
+	}
+	return nil
+}
+
+var _ = Describe("WebSocketConn", func() {
+	var interval time.Duration
+	var handler func(*goa.WebSocketConn)
+	var server *httptest.Server
+
+	var conn *goa.WebSocketConn
+
+	BeforeEach(func() {
+		interval = 0
+		handler = func(c *goa.WebSocketConn) {
+			for {
+				var msg wsMessage
+				if err := c.Receive(&msg); err != nil {
+					return
+				}
+				if err := c.Send(&msg); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	JustBeforeEach(func() {
+		h, i := handler, interval
+		server = httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+			c := goa.NewWebSocketConn(ws, i)
+			defer c.Close()
+			h(c)
+		}))
+		url := "ws" + strings.TrimPrefix(server.URL, "http")
+		ws, err := websocket.Dial(url, "", server.URL)
+		Ω(err).ShouldNot(HaveOccurred())
+		conn = goa.NewWebSocketConn(ws, interval)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		server.Close()
+	})
+
+	It("sends and receives messages", func() {
+		Ω(conn.Send(&wsMessage{Body: "hello"})).ShouldNot(HaveOccurred())
+		var msg wsMessage
+		Ω(conn.Receive(&msg)).ShouldNot(HaveOccurred())
+		Ω(msg.Body).Should(Equal("hello"))
+	})
+
+	It("validates the messages being sent", func() {
+		Ω(conn.Send(&wsMessage{})).Should(MatchError("missing body"))
+	})
+
+	Context("with a peer sending invalid messages", func() {
+		BeforeEach(func() {
+			handler = func(c *goa.WebSocketConn) {
+				c.Send(map[string]string{"body": ""})
+			}
+		})
+
+		It("validates the messages being received", func() {
+			var msg wsMessage
+			Ω(conn.Receive(&msg)).Should(MatchError("missing body"))
+		})
+	})
+
+	Context("with a peer closing the connection", func() {
+		BeforeEach(func() {
+			handler = func(c *goa.WebSocketConn) {}
+		})
+
+		It("returns io.EOF", func() {
+			var msg wsMessage
+			Ω(conn.Receive(&msg)).Should(Equal(io.EOF))
+		})
+	})
+
+	Context("with pings", func() {
+		BeforeEach(func() {
+			interval = 5 * time.Millisecond
+		})
+
+		It("keeps exchanging messages", func() {
+			time.Sleep(30 * time.Millisecond)
+			Ω(conn.Send(&wsMessage{Body: "hello"})).ShouldNot(HaveOccurred())
+			var msg wsMessage
+			Ω(conn.Receive(&msg)).ShouldNot(HaveOccurred())
+			Ω(msg.Body).Should(Equal("hello"))
+		})
+	})
+
+	Context("once closed", func() {
+		JustBeforeEach(func() {
+			Ω(conn.Close()).ShouldNot(HaveOccurred())
+		})
+
+		It("closes the done channel", func() {
+			Ω(conn.Done()).Should(BeClosed())
+			Ω(conn.Close()).ShouldNot(HaveOccurred())
+		})
+	})
+})